@@ -0,0 +1,163 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "maps"
+
+// ----- AliasSet definition -----
+
+// An AliasSet is a set modulo aliasing, like the entities of an entity
+// resolution pipeline, where several names are found to denote the same
+// entity. Elements can be declared equivalent by Alias, which merges their
+// identities: an identity is in the set if any of its aliases was added,
+// and Contains is true for all of its aliases. The aliases are kept in a
+// DisjointSet.
+type AliasSet[T comparable] struct {
+	aliases *DisjointSet[T]
+	members map[T]struct{} // the representatives of the identities in the set
+}
+
+// ----- constructor -----
+
+// NewAliasSet creates a new alias set and initializes it with the argument
+// values, each an identity of its own.
+func NewAliasSet[T comparable](e ...T) *AliasSet[T] {
+	s := &AliasSet[T]{aliases: NewDisjoint[T](), members: map[T]struct{}{}}
+	s.Add(e...)
+	return s
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds the identities of one or more elements to the given set.
+func (s *AliasSet[T]) Add(e ...T) {
+	for _, x := range e {
+		s.aliases.Add(x)
+		r, _ := s.aliases.Find(x)
+		s.members[r] = struct{}{}
+	}
+}
+
+// Alias declares a and b as aliases of the same identity, which is in the
+// set if either identity was. The elements need not have been added; an
+// alias of elements which are not in the set only records the equivalence.
+// Alias returns false if a and b were already aliases.
+func (s *AliasSet[T]) Alias(a, b T) bool {
+	in := s.member(a) || s.member(b)
+	ra, _ := s.aliases.Find(a)
+	rb, _ := s.aliases.Find(b)
+	if !s.aliases.Union(a, b) {
+		return false
+	}
+	delete(s.members, ra)
+	delete(s.members, rb)
+	if in {
+		r, _ := s.aliases.Find(a)
+		s.members[r] = struct{}{}
+	}
+	return true
+}
+
+// Remove removes the identities of one or more elements from the given set,
+// with all of their aliases. The aliases remain equivalent.
+func (s *AliasSet[T]) Remove(e ...T) {
+	for _, x := range e {
+		if r, ok := s.aliases.Find(x); ok {
+			delete(s.members, r)
+		}
+	}
+}
+
+// Clear removes all identities and aliases from the given set.
+func (s *AliasSet[T]) Clear() {
+	s.aliases.Clear()
+	clear(s.members)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// member checks if the identity of x is in the set.
+func (s *AliasSet[T]) member(x T) bool {
+	r, ok := s.aliases.Find(x)
+	if !ok {
+		return false
+	}
+	_, ok = s.members[r]
+	return ok
+}
+
+// IsEmpty tests if the set is empty.
+func (s *AliasSet[T]) IsEmpty() bool {
+	return len(s.members) == 0
+}
+
+// Len returns the number of distinct identities of the set.
+func (s *AliasSet[T]) Len() int {
+	return len(s.members)
+}
+
+// Contains checks if a set contains the identities of one or more elements,
+// under any of their aliases. The return value is true only if all given
+// elements are in the set.
+func (s *AliasSet[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if !s.member(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAlias checks if a and b are aliases of the same identity.
+func (s *AliasSet[T]) IsAlias(a, b T) bool {
+	return a == b || s.aliases.SameSet(a, b)
+}
+
+// Canonical returns the representative of the identity of x, which is one
+// of its aliases. The second return value is false if x is not in the set.
+func (s *AliasSet[T]) Canonical(x T) (T, bool) {
+	if !s.member(x) {
+		return x, false
+	}
+	return s.aliases.Find(x)
+}
+
+// Aliases returns all aliases of x, including x, as a new set.
+func (s *AliasSet[T]) Aliases(x T) Set[T] {
+	if !s.aliases.Contains(x) {
+		return New(x)
+	}
+	return s.aliases.SetOf(x)
+}
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *AliasSet[T]) Copy() *AliasSet[T] {
+	return &AliasSet[T]{aliases: s.aliases.Copy(), members: maps.Clone(s.members)}
+}
+
+// ----- methods that return other data types -----
+
+// Canonicals returns the representatives of the identities of the set as a
+// new set, with one element per identity.
+func (s *AliasSet[T]) Canonicals() Set[T] {
+	r := Set[T]{set: make(map[T]struct{}, len(s.members))}
+	for x := range s.members {
+		r.set[x] = struct{}{}
+	}
+	return r
+}
+
+// Set returns the elements of the set with all of their aliases as a new
+// set.
+func (s *AliasSet[T]) Set() Set[T] {
+	r := New[T]()
+	for x := range s.aliases.parent {
+		if s.member(x) {
+			r.set[x] = struct{}{}
+		}
+	}
+	return r
+}
@@ -0,0 +1,57 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "testing"
+
+func TestAliasSet(t *testing.T) {
+	s := NewAliasSet("IBM", "Apple")
+	if !s.Alias("IBM", "International Business Machines") || s.Alias("International Business Machines", "IBM") {
+		t.Errorf("Alias failed: wrong return values.\n")
+	}
+	s.Alias("Big Blue", "IBM")
+	if !s.Contains("Big Blue", "International Business Machines", "Apple") || s.Len() != 2 {
+		t.Errorf("Contains failed: got %v.\n", s.Set())
+	}
+	if !s.Aliases("IBM").IsEqual(New("IBM", "International Business Machines", "Big Blue")) {
+		t.Errorf("Aliases failed: got %v.\n", s.Aliases("IBM"))
+	}
+	if c, ok := s.Canonical("Big Blue"); !ok || !s.IsAlias(c, "IBM") || s.Canonicals().Len() != 2 {
+		t.Errorf("Canonical failed: got %v, %v.\n", c, ok)
+	}
+
+	// aliases of elements not in the set only record the equivalence
+	s.Alias("Alphabet", "Google")
+	if s.Contains("Google") || s.Len() != 2 {
+		t.Errorf("Alias failed: got %v.\n", s.Set())
+	}
+	s.Add("Alphabet")
+	if !s.Contains("Google") || s.Len() != 3 {
+		t.Errorf("Add failed: got %v.\n", s.Set())
+	}
+
+	// merging two identities in the set leaves one
+	c := s.Copy()
+	s.Alias("Google", "Apple")
+	if s.Len() != 2 || c.Len() != 3 {
+		t.Errorf("Alias failed: got %d and %d identities.\n", s.Len(), c.Len())
+	}
+
+	s.Remove("Big Blue")
+	if s.Contains("IBM") || !s.IsAlias("IBM", "Big Blue") || s.Len() != 1 {
+		t.Errorf("Remove failed: got %v.\n", s.Set())
+	}
+	if !s.Set().IsEqual(New("Alphabet", "Google", "Apple")) {
+		t.Errorf("Set failed: got %v.\n", s.Set())
+	}
+	if _, ok := s.Canonical("IBM"); ok {
+		t.Errorf("Canonical failed for a removed element.\n")
+	}
+	s.Clear()
+	if !s.IsEmpty() || !s.Aliases("IBM").IsEqual(New("IBM")) {
+		t.Errorf("Clear failed.\n")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	// ErrExhausted is returned by Allocate when all IDs are in use.
+	ErrExhausted = errors.New("set: no free ID left")
+
+	// ErrAllocated is returned by Reserve when an ID of the range is
+	// already in use or outside of the bounds of the allocator.
+	ErrAllocated = errors.New("set: ID not available")
+
+	// errAllocatorEncoding is returned when decoding a malformed allocator.
+	errAllocatorEncoding = errors.New("set: invalid allocator encoding")
+)
+
+// ----- Allocator definition -----
+
+// An Allocator hands out integer IDs, like sequence numbers, ports or VLAN
+// tags, from a closed range. The IDs in use are kept in an interval set, so
+// large blocks of allocated IDs take only constant space. An Allocator is not
+// safe for concurrent use.
+type Allocator[T Integer] struct {
+	used *IntervalSet[T]
+}
+
+// NewAllocator creates an allocator for the IDs in [lo, hi].
+func NewAllocator[T Integer](lo, hi T) *Allocator[T] {
+	return &Allocator[T]{used: NewIntervalSetIn[T](lo, hi)}
+}
+
+// ----- methods that modify the receiver -----
+
+// Allocate returns the smallest free ID and marks it as used. If all IDs are
+// in use, ErrExhausted is returned.
+func (a *Allocator[T]) Allocate() (T, error) {
+	u, r := a.used.universe, a.used.ranges
+	id := u.Lo
+	if len(r) > 0 && r[0].Lo == u.Lo {
+		if r[0].Hi == u.Hi {
+			return 0, ErrExhausted
+		}
+		id = r[0].Hi + 1
+	}
+	a.used.Add(id)
+	return id, nil
+}
+
+// Reserve marks all IDs in [lo, hi] as used. If any of them is in use or
+// outside of the bounds of the allocator, nothing is reserved and
+// ErrAllocated is returned.
+func (a *Allocator[T]) Reserve(lo, hi T) error {
+	if lo > hi {
+		return nil
+	}
+	u := a.used.universe
+	if lo < u.Lo || hi > u.Hi {
+		return ErrAllocated
+	}
+	for _, r := range a.used.ranges {
+		if r.Lo <= hi && r.Hi >= lo {
+			return ErrAllocated
+		}
+	}
+	a.used.AddRange(lo, hi)
+	return nil
+}
+
+// Release marks one or more IDs as free. Releasing a free ID has no effect.
+func (a *Allocator[T]) Release(id ...T) {
+	a.used.Remove(id...)
+}
+
+// ReleaseRange marks all IDs in [lo, hi] as free.
+func (a *Allocator[T]) ReleaseRange(lo, hi T) {
+	a.used.RemoveRange(lo, hi)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsAllocated checks if an ID is in use.
+func (a *Allocator[T]) IsAllocated(id T) bool {
+	return a.used.Contains(id)
+}
+
+// Used returns the number of IDs in use.
+func (a *Allocator[T]) Used() uint64 {
+	return a.used.Count()
+}
+
+// Free returns the number of free IDs.
+func (a *Allocator[T]) Free() uint64 {
+	return a.used.Complement().Count()
+}
+
+// InUse returns a copy of the set of IDs in use.
+func (a *Allocator[T]) InUse() *IntervalSet[T] {
+	return a.used.Copy()
+}
+
+// ----- persistence -----
+
+// MarshalBinary encodes the allocator compactly, as the lower bound, the
+// size and the delta encoded ranges of used IDs. It implements the
+// encoding.BinaryMarshaler interface.
+func (a *Allocator[T]) MarshalBinary() ([]byte, error) {
+	u := a.used.universe
+	b := make([]byte, 0, 2*binary.MaxVarintLen64+2*len(a.used.ranges)+1)
+	var zero T
+	if ^zero < zero {
+		b = binary.AppendVarint(b, int64(u.Lo)) // signed
+	} else {
+		b = binary.AppendUvarint(b, uint64(u.Lo))
+	}
+	b = binary.AppendUvarint(b, uint64(u.Hi)-uint64(u.Lo))
+	b = binary.AppendUvarint(b, uint64(len(a.used.ranges)))
+	prev := uint64(u.Lo) // the first ID after the previous range
+	for _, r := range a.used.ranges {
+		b = binary.AppendUvarint(b, uint64(r.Lo)-prev)
+		b = binary.AppendUvarint(b, uint64(r.Hi)-uint64(r.Lo))
+		prev = uint64(r.Hi) + 1
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes an allocator encoded by MarshalBinary, replacing the
+// state of a. It implements the encoding.BinaryUnmarshaler interface.
+func (a *Allocator[T]) UnmarshalBinary(data []byte) error {
+	var v [3]uint64
+	var zero T
+	for i := range v {
+		x, n := binary.Uvarint(data)
+		if i == 0 && ^zero < zero {
+			var sx int64
+			sx, n = binary.Varint(data)
+			x = uint64(sx)
+		}
+		if n <= 0 {
+			return errAllocatorEncoding
+		}
+		v[i], data = x, data[n:]
+	}
+	lo, hi := T(v[0]), T(v[0]+v[1])
+	if uint64(lo) != v[0] || uint64(hi) != v[0]+v[1] || lo > hi || v[2] > uint64(len(data)) {
+		return errAllocatorEncoding
+	}
+	used := NewIntervalSetIn(lo, hi)
+	used.ranges = make([]Range[T], 0, v[2])
+	prev := v[0]
+	for i := uint64(0); i < v[2]; i++ {
+		gap, n := binary.Uvarint(data)
+		if n <= 0 || (i > 0 && gap == 0) {
+			return errAllocatorEncoding
+		}
+		data = data[n:]
+		length, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errAllocatorEncoding
+		}
+		data = data[n:]
+		r := Range[T]{T(prev + gap), T(prev + gap + length)}
+		if r.Lo < used.universe.Lo || r.Hi > hi || r.Lo > r.Hi ||
+			(i > 0 && r.Lo <= used.ranges[i-1].Hi) {
+			return errAllocatorEncoding
+		}
+		used.ranges = append(used.ranges, r)
+		prev = uint64(r.Hi) + 1
+	}
+	if len(data) != 0 {
+		return errAllocatorEncoding
+	}
+	a.used = used
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	a := NewAllocator[uint16](1, 4094) // VLAN tags
+	for want := uint16(1); want <= 3; want++ {
+		if id, err := a.Allocate(); err != nil || id != want {
+			t.Errorf("Allocate failed: got %d, %v, expected %d.\n", id, err, want)
+		}
+	}
+	a.Release(2)
+	if id, _ := a.Allocate(); id != 2 {
+		t.Errorf("Allocate failed: got %d, expected the released ID 2.\n", id)
+	}
+	if err := a.Reserve(100, 199); err != nil {
+		t.Errorf("Reserve failed: %v.\n", err)
+	}
+	if err := a.Reserve(150, 250); !errors.Is(err, ErrAllocated) || a.IsAllocated(250) {
+		t.Errorf("Reserve failed: overlapping range accepted.\n")
+	}
+	if err := a.Reserve(4000, 5000); !errors.Is(err, ErrAllocated) {
+		t.Errorf("Reserve failed: range out of bounds accepted.\n")
+	}
+	if a.Used() != 103 || a.Free() != 4094-103 {
+		t.Errorf("Used/Free failed: got %d and %d.\n", a.Used(), a.Free())
+	}
+
+	small := NewAllocator[int8](-2, -1)
+	small.Allocate()
+	small.Allocate()
+	if _, err := small.Allocate(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Allocate failed: expected ErrExhausted, got %v.\n", err)
+	}
+}
+
+func TestAllocatorBinary(t *testing.T) {
+	a := NewAllocator[int32](-1000, 1000)
+	a.Reserve(-1000, -990)
+	a.Reserve(-5, 5)
+	a.Reserve(1000, 1000)
+	data, _ := a.MarshalBinary()
+	if len(data) > 20 {
+		t.Errorf("MarshalBinary failed: encoding of %d bytes is not compact.\n", len(data))
+	}
+
+	var b Allocator[int32]
+	if err := b.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v.\n", err)
+	}
+	if !b.InUse().IsEqual(a.InUse()) || b.used.Universe() != a.used.Universe() {
+		t.Errorf("UnmarshalBinary failed: got %v, expected %v.\n", b.InUse(), a.InUse())
+	}
+	if id, _ := b.Allocate(); id != -989 {
+		t.Errorf("Allocate failed after decoding: got %d.\n", id)
+	}
+	if err := b.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Errorf("UnmarshalBinary failed: truncated data accepted.\n")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+
+	"github.com/hweidner/set/v2/internal/elemcodec"
+)
+
+// ErrBinaryFormat is returned by UnmarshalBinary for malformed input.
+var ErrBinaryFormat = errors.New("set: malformed binary encoding")
+
+// ----- binary encoding -----
+
+// The binary format consists of the magic bytes "SETB", a format byte, the
+// number of elements as a uvarint, and the elements. Sets of integers are
+// written in ascending order as the first element and the gaps between
+// consecutive elements as uvarints, so dense ID sets take about one byte per
+// element. Signed integers are offset by 2^63 before, to keep their order.
+// All other elements are written like for Digest, in an undefined order. The
+// element type is not recorded, so sets are decoded into the element type
+// they were encoded from, or an integer type of the same signedness.
+
+// the magic bytes of the binary format
+const binaryMagic = "SETB"
+
+// the formats of the elements
+const (
+	binaryValues byte = 'v'
+	binaryDeltas byte = 'd'
+)
+
+// intBits returns an integer element as a uint64 which keeps the order of
+// the elements. The second return value is false for other element types.
+func intBits[T comparable](e T) (uint64, bool) {
+	v := reflect.ValueOf(&e).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int()) ^ 1<<63, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	}
+	return 0, false
+}
+
+// fromIntBits converts the result of intBits back to an element. The second
+// return value is false if the value overflows the element type.
+func fromIntBits[T comparable](x uint64) (T, bool) {
+	var e T
+	v := reflect.ValueOf(&e).Elem()
+	if v.CanInt() {
+		i := int64(x ^ 1<<63)
+		if v.OverflowInt(i) {
+			return e, false
+		}
+		v.SetInt(i)
+	} else {
+		if v.OverflowUint(x) {
+			return e, false
+		}
+		v.SetUint(x)
+	}
+	return e, true
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. Sets of
+// element types without a stable encoding return ErrNoStableEncoding.
+func (s Set[T]) MarshalBinary() ([]byte, error) {
+	var zero T
+	b := append([]byte(binaryMagic), 0)
+	if _, ok := intBits(zero); ok {
+		b[len(binaryMagic)] = binaryDeltas
+		b = binary.AppendUvarint(b, uint64(len(s.set)))
+		l := make([]uint64, 0, len(s.set))
+		for k := range s.set {
+			x, _ := intBits(k)
+			l = append(l, x)
+		}
+		slices.Sort(l)
+		prev := uint64(0)
+		for _, x := range l {
+			b = binary.AppendUvarint(b, x-prev)
+			prev = x
+		}
+		return b, nil
+	}
+
+	b[len(binaryMagic)] = binaryValues
+	b = binary.AppendUvarint(b, uint64(len(s.set)))
+	for k := range s.set {
+		var ok bool
+		if b, ok = elemcodec.AppendValue(b, k); !ok {
+			return nil, fmt.Errorf("%w: %T", ErrNoStableEncoding, zero)
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. Like
+// UnmarshalJSON, it replaces the elements of the set, initializes the zero
+// value of Set, and adds the elements like with TryAdd. Malformed input is
+// reported as ErrBinaryFormat, or a *DecodeError for a malformed element.
+func (s *Set[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return ErrBinaryFormat
+	}
+	format := data[len(binaryMagic)]
+	r := bytes.NewReader(data[len(binaryMagic)+1:])
+	n, err := binary.ReadUvarint(r)
+	// each element takes at least one byte
+	if err != nil || n > uint64(r.Len()) {
+		return ErrBinaryFormat
+	}
+
+	l := make([]T, 0, n)
+	switch format {
+	case binaryDeltas:
+		var zero T
+		if _, ok := intBits(zero); !ok {
+			return fmt.Errorf("%w: integers for %T", ErrWrongType, zero)
+		}
+		x := uint64(0)
+		for i := range n {
+			d, err := binary.ReadUvarint(r)
+			if err != nil || (i > 0 && d == 0) || x+d < x {
+				return &DecodeError{Pos: int(i), Err: ErrBinaryFormat}
+			}
+			x += d
+			e, ok := fromIntBits[T](x)
+			if !ok {
+				return &DecodeError{Pos: int(i), Err: fmt.Errorf("%w: value overflows %T", ErrWrongType, zero)}
+			}
+			l = append(l, e)
+		}
+	case binaryValues:
+		for i := range n {
+			e, err := elemcodec.ReadValue[T](r, len(data))
+			if errors.Is(err, elemcodec.ErrNoValueEncoding) {
+				return fmt.Errorf("%w: %T", ErrNoStableEncoding, e)
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return &DecodeError{Pos: int(i), Err: err}
+			}
+			l = append(l, e)
+		}
+	default:
+		return ErrBinaryFormat
+	}
+	if r.Len() != 0 {
+		return ErrBinaryFormat
+	}
+
+	if s.set == nil {
+		s.set = make(map[T]struct{}, len(l))
+	} else {
+		clear(s.set)
+	}
+	return s.TryAdd(l...)
+}
@@ -0,0 +1,64 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBinary(t *testing.T) {
+	ids := New[uint64]()
+	for i := uint64(0); i < 100000; i++ {
+		ids.Add(1_000_000 + i*3)
+	}
+	b, err := ids.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v.\n", err)
+	}
+	j, _ := json.Marshal(ids)
+	if len(b) > 100010 || len(j) < 5*len(b) {
+		t.Errorf("MarshalBinary failed: %d bytes, %d for JSON.\n", len(b), len(j))
+	}
+	var r Set[uint64]
+	if err := r.UnmarshalBinary(b); err != nil || !r.IsEqual(ids) {
+		t.Errorf("UnmarshalBinary failed: %v.\n", err)
+	}
+
+	signed := New[int8](math.MinInt8, -1, 0, 1, math.MaxInt8)
+	b, _ = signed.MarshalBinary()
+	var rs Set[int8]
+	if err := rs.UnmarshalBinary(b); err != nil || !rs.IsEqual(signed) {
+		t.Errorf("UnmarshalBinary failed for int8: got %v, %v.\n", rs, err)
+	}
+	var de *DecodeError
+	b, _ = New[int](1000).MarshalBinary()
+	if err := rs.UnmarshalBinary(b); !errors.As(err, &de) || !errors.Is(err, ErrWrongType) {
+		t.Errorf("UnmarshalBinary failed: got %v for an overflow.\n", err)
+	}
+
+	strs := New("", "a", "hello, world")
+	b, _ = strs.MarshalBinary()
+	rstr := New("old")
+	if err := rstr.UnmarshalBinary(b); err != nil || !rstr.IsEqual(strs) {
+		t.Errorf("UnmarshalBinary failed for strings: got %v, %v.\n", rstr, err)
+	}
+	for _, d := range [][]byte{nil, []byte("SETB"), []byte("SETBx\x00"), b[:len(b)-1], append(b, 0)} {
+		if err := rstr.UnmarshalBinary(d); err == nil {
+			t.Errorf("UnmarshalBinary failed: no error for %q.\n", d)
+		}
+	}
+
+	if _, err := New(testPoint{1, 2}).MarshalBinary(); !errors.Is(err, ErrNoStableEncoding) {
+		t.Errorf("MarshalBinary failed: got %v for structs.\n", err)
+	}
+	var e Set[int]
+	if err := e.UnmarshalBinary([]byte("SETBd\x00")); err != nil || !e.IsEmpty() {
+		t.Errorf("UnmarshalBinary failed for the empty set: %v.\n", err)
+	}
+}
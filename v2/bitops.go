@@ -0,0 +1,97 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"math/bits"
+)
+
+// ----- bitmap kernels -----
+
+// The kernels below combine bitmaps word by word. They are used by all
+// bitmap backed sets, and dominate the cost of their set algebra. On amd64,
+// andWords, orWords, xorWords and andNotWords are implemented in assembly
+// using 256 bit AVX2 registers if the CPU supports them, and 128 bit SSE2
+// registers, which every amd64 CPU supports, otherwise. On arm64, they use
+// 128 bit NEON registers. The build tag purego selects the portable Go
+// versions instead. All kernels process len(dst) words; a and b must be at
+// least as long.
+
+// andWords sets dst[i] = a[i] & b[i].
+func andWords(dst, a, b []uint64) {
+	if len(dst) > 0 {
+		_, _ = a[len(dst)-1], b[len(dst)-1]
+		andWordsImpl(dst, a, b)
+	}
+}
+
+// orWords sets dst[i] = a[i] | b[i].
+func orWords(dst, a, b []uint64) {
+	if len(dst) > 0 {
+		_, _ = a[len(dst)-1], b[len(dst)-1]
+		orWordsImpl(dst, a, b)
+	}
+}
+
+// xorWords sets dst[i] = a[i] ^ b[i].
+func xorWords(dst, a, b []uint64) {
+	if len(dst) > 0 {
+		_, _ = a[len(dst)-1], b[len(dst)-1]
+		xorWordsImpl(dst, a, b)
+	}
+}
+
+// andNotWords sets dst[i] = a[i] &^ b[i].
+func andNotWords(dst, a, b []uint64) {
+	if len(dst) > 0 {
+		_, _ = a[len(dst)-1], b[len(dst)-1]
+		andNotWordsImpl(dst, a, b)
+	}
+}
+
+// popcount returns the number of set bits in the words. The compiler turns
+// bits.OnesCount64 into a POPCNT instruction where available, so this is not
+// written in assembly.
+func popcount(w []uint64) int {
+	n0, n1, n2, n3 := 0, 0, 0, 0
+	i := 0
+	for ; i+4 <= len(w); i += 4 {
+		n0 += bits.OnesCount64(w[i])
+		n1 += bits.OnesCount64(w[i+1])
+		n2 += bits.OnesCount64(w[i+2])
+		n3 += bits.OnesCount64(w[i+3])
+	}
+	for ; i < len(w); i++ {
+		n0 += bits.OnesCount64(w[i])
+	}
+	return n0 + n1 + n2 + n3
+}
+
+// ----- portable implementations -----
+
+func andWordsGeneric(dst, a, b []uint64) {
+	for i := range dst {
+		dst[i] = a[i] & b[i]
+	}
+}
+
+func orWordsGeneric(dst, a, b []uint64) {
+	for i := range dst {
+		dst[i] = a[i] | b[i]
+	}
+}
+
+func xorWordsGeneric(dst, a, b []uint64) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+func andNotWordsGeneric(dst, a, b []uint64) {
+	for i := range dst {
+		dst[i] = a[i] &^ b[i]
+	}
+}
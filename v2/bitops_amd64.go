@@ -0,0 +1,93 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build amd64 && !purego
+
+package set
+
+// useAVX2 selects the AVX2 kernels, if the CPU and the operating system
+// support them.
+var useAVX2 = hasAVX2()
+
+// hasAVX2 reports whether the CPU supports AVX2 and the operating system
+// saves the 256 bit registers on context switches.
+func hasAVX2() bool {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+	_, _, ecx1, _ := cpuid(1, 0)
+	const osxsave, avx = 1 << 27, 1 << 28
+	if ecx1&osxsave == 0 || ecx1&avx == 0 {
+		return false
+	}
+	if xcr0, _ := xgetbv(); xcr0&6 != 6 { // XMM and YMM state
+		return false
+	}
+	_, ebx7, _, _ := cpuid(7, 0)
+	return ebx7&(1<<5) != 0
+}
+
+func andWordsImpl(dst, a, b []uint64) {
+	if useAVX2 {
+		andWordsAVX2(dst, a, b)
+	} else {
+		andWordsSSE2(dst, a, b)
+	}
+}
+
+func orWordsImpl(dst, a, b []uint64) {
+	if useAVX2 {
+		orWordsAVX2(dst, a, b)
+	} else {
+		orWordsSSE2(dst, a, b)
+	}
+}
+
+func xorWordsImpl(dst, a, b []uint64) {
+	if useAVX2 {
+		xorWordsAVX2(dst, a, b)
+	} else {
+		xorWordsSSE2(dst, a, b)
+	}
+}
+
+func andNotWordsImpl(dst, a, b []uint64) {
+	if useAVX2 {
+		andNotWordsAVX2(dst, a, b)
+	} else {
+		andNotWordsSSE2(dst, a, b)
+	}
+}
+
+// implemented in bitops_amd64.s
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func xgetbv() (eax, edx uint32)
+
+//go:noescape
+func andWordsSSE2(dst, a, b []uint64)
+
+//go:noescape
+func orWordsSSE2(dst, a, b []uint64)
+
+//go:noescape
+func xorWordsSSE2(dst, a, b []uint64)
+
+//go:noescape
+func andNotWordsSSE2(dst, a, b []uint64)
+
+//go:noescape
+func andWordsAVX2(dst, a, b []uint64)
+
+//go:noescape
+func orWordsAVX2(dst, a, b []uint64)
+
+//go:noescape
+func xorWordsAVX2(dst, a, b []uint64)
+
+//go:noescape
+func andNotWordsAVX2(dst, a, b []uint64)
@@ -0,0 +1,322 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// Each function combines len(dst) words of a and b into dst. The SSE2
+// versions handle four words in two 128 bit registers per iteration of the
+// main loop, the AVX2 versions eight words in two 256 bit registers. The
+// tail loops handle single words.
+
+// func andWordsSSE2(dst, a, b []uint64)
+TEXT ·andWordsSSE2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), DX
+loop4:
+	CMPQ CX, $4
+	JL   loop1
+	MOVOU (SI), X0
+	MOVOU 16(SI), X2
+	MOVOU (DX), X1
+	MOVOU 16(DX), X3
+	PAND X1, X0
+	PAND X3, X2
+	MOVOU X0, (DI)
+	MOVOU X2, 16(DI)
+	ADDQ $32, SI
+	ADDQ $32, DX
+	ADDQ $32, DI
+	SUBQ $4, CX
+	JMP  loop4
+loop1:
+	CMPQ CX, $0
+	JE   done
+	MOVQ (SI), AX
+	ANDQ (DX), AX
+	MOVQ AX, (DI)
+	ADDQ $8, SI
+	ADDQ $8, DX
+	ADDQ $8, DI
+	DECQ CX
+	JMP  loop1
+done:
+	RET
+
+// func andWordsAVX2(dst, a, b []uint64)
+TEXT ·andWordsAVX2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), DX
+loop8:
+	CMPQ CX, $8
+	JL   last
+	VMOVDQU (SI), Y0
+	VMOVDQU 32(SI), Y2
+	VPAND (DX), Y0, Y0
+	VPAND 32(DX), Y2, Y2
+	VMOVDQU Y0, (DI)
+	VMOVDQU Y2, 32(DI)
+	ADDQ $64, SI
+	ADDQ $64, DX
+	ADDQ $64, DI
+	SUBQ $8, CX
+	JMP  loop8
+last:
+	VZEROUPPER
+loop1:
+	CMPQ CX, $0
+	JE   done
+	MOVQ (SI), AX
+	ANDQ (DX), AX
+	MOVQ AX, (DI)
+	ADDQ $8, SI
+	ADDQ $8, DX
+	ADDQ $8, DI
+	DECQ CX
+	JMP  loop1
+done:
+	RET
+
+// func orWordsSSE2(dst, a, b []uint64)
+TEXT ·orWordsSSE2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), DX
+loop4:
+	CMPQ CX, $4
+	JL   loop1
+	MOVOU (SI), X0
+	MOVOU 16(SI), X2
+	MOVOU (DX), X1
+	MOVOU 16(DX), X3
+	POR X1, X0
+	POR X3, X2
+	MOVOU X0, (DI)
+	MOVOU X2, 16(DI)
+	ADDQ $32, SI
+	ADDQ $32, DX
+	ADDQ $32, DI
+	SUBQ $4, CX
+	JMP  loop4
+loop1:
+	CMPQ CX, $0
+	JE   done
+	MOVQ (SI), AX
+	ORQ (DX), AX
+	MOVQ AX, (DI)
+	ADDQ $8, SI
+	ADDQ $8, DX
+	ADDQ $8, DI
+	DECQ CX
+	JMP  loop1
+done:
+	RET
+
+// func orWordsAVX2(dst, a, b []uint64)
+TEXT ·orWordsAVX2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), DX
+loop8:
+	CMPQ CX, $8
+	JL   last
+	VMOVDQU (SI), Y0
+	VMOVDQU 32(SI), Y2
+	VPOR (DX), Y0, Y0
+	VPOR 32(DX), Y2, Y2
+	VMOVDQU Y0, (DI)
+	VMOVDQU Y2, 32(DI)
+	ADDQ $64, SI
+	ADDQ $64, DX
+	ADDQ $64, DI
+	SUBQ $8, CX
+	JMP  loop8
+last:
+	VZEROUPPER
+loop1:
+	CMPQ CX, $0
+	JE   done
+	MOVQ (SI), AX
+	ORQ (DX), AX
+	MOVQ AX, (DI)
+	ADDQ $8, SI
+	ADDQ $8, DX
+	ADDQ $8, DI
+	DECQ CX
+	JMP  loop1
+done:
+	RET
+
+// func xorWordsSSE2(dst, a, b []uint64)
+TEXT ·xorWordsSSE2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), DX
+loop4:
+	CMPQ CX, $4
+	JL   loop1
+	MOVOU (SI), X0
+	MOVOU 16(SI), X2
+	MOVOU (DX), X1
+	MOVOU 16(DX), X3
+	PXOR X1, X0
+	PXOR X3, X2
+	MOVOU X0, (DI)
+	MOVOU X2, 16(DI)
+	ADDQ $32, SI
+	ADDQ $32, DX
+	ADDQ $32, DI
+	SUBQ $4, CX
+	JMP  loop4
+loop1:
+	CMPQ CX, $0
+	JE   done
+	MOVQ (SI), AX
+	XORQ (DX), AX
+	MOVQ AX, (DI)
+	ADDQ $8, SI
+	ADDQ $8, DX
+	ADDQ $8, DI
+	DECQ CX
+	JMP  loop1
+done:
+	RET
+
+// func xorWordsAVX2(dst, a, b []uint64)
+TEXT ·xorWordsAVX2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), DX
+loop8:
+	CMPQ CX, $8
+	JL   last
+	VMOVDQU (SI), Y0
+	VMOVDQU 32(SI), Y2
+	VPXOR (DX), Y0, Y0
+	VPXOR 32(DX), Y2, Y2
+	VMOVDQU Y0, (DI)
+	VMOVDQU Y2, 32(DI)
+	ADDQ $64, SI
+	ADDQ $64, DX
+	ADDQ $64, DI
+	SUBQ $8, CX
+	JMP  loop8
+last:
+	VZEROUPPER
+loop1:
+	CMPQ CX, $0
+	JE   done
+	MOVQ (SI), AX
+	XORQ (DX), AX
+	MOVQ AX, (DI)
+	ADDQ $8, SI
+	ADDQ $8, DX
+	ADDQ $8, DI
+	DECQ CX
+	JMP  loop1
+done:
+	RET
+
+// func andNotWordsSSE2(dst, a, b []uint64)
+TEXT ·andNotWordsSSE2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), DX
+loop4:
+	CMPQ CX, $4
+	JL   loop1
+	MOVOU (DX), X0
+	MOVOU 16(DX), X2
+	MOVOU (SI), X1
+	MOVOU 16(SI), X3
+	PANDN X1, X0
+	PANDN X3, X2
+	MOVOU X0, (DI)
+	MOVOU X2, 16(DI)
+	ADDQ $32, SI
+	ADDQ $32, DX
+	ADDQ $32, DI
+	SUBQ $4, CX
+	JMP  loop4
+loop1:
+	CMPQ CX, $0
+	JE   done
+	MOVQ (DX), AX
+	NOTQ AX
+	ANDQ (SI), AX
+	MOVQ AX, (DI)
+	ADDQ $8, SI
+	ADDQ $8, DX
+	ADDQ $8, DI
+	DECQ CX
+	JMP  loop1
+done:
+	RET
+
+// func andNotWordsAVX2(dst, a, b []uint64)
+TEXT ·andNotWordsAVX2(SB), NOSPLIT, $0-72
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ a_base+24(FP), SI
+	MOVQ b_base+48(FP), DX
+loop8:
+	CMPQ CX, $8
+	JL   last
+	VMOVDQU (DX), Y0
+	VMOVDQU 32(DX), Y2
+	VPANDN (SI), Y0, Y0
+	VPANDN 32(SI), Y2, Y2
+	VMOVDQU Y0, (DI)
+	VMOVDQU Y2, 32(DI)
+	ADDQ $64, SI
+	ADDQ $64, DX
+	ADDQ $64, DI
+	SUBQ $8, CX
+	JMP  loop8
+last:
+	VZEROUPPER
+loop1:
+	CMPQ CX, $0
+	JE   done
+	MOVQ (DX), AX
+	NOTQ AX
+	ANDQ (SI), AX
+	MOVQ AX, (DI)
+	ADDQ $8, SI
+	ADDQ $8, DX
+	ADDQ $8, DI
+	DECQ CX
+	JMP  loop1
+done:
+	RET
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
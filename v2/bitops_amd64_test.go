@@ -0,0 +1,54 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build amd64 && !purego
+
+package set
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestBitopsAMD64(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	kernels := []struct {
+		name            string
+		sse2, avx2, ref func(dst, a, b []uint64)
+	}{
+		{"and", andWordsSSE2, andWordsAVX2, andWordsGeneric},
+		{"or", orWordsSSE2, orWordsAVX2, orWordsGeneric},
+		{"xor", xorWordsSSE2, xorWordsAVX2, xorWordsGeneric},
+		{"andNot", andNotWordsSSE2, andNotWordsAVX2, andNotWordsGeneric},
+	}
+	// lengths around the unrolling boundaries of both variants
+	for _, n := range []int{0, 1, 3, 4, 5, 7, 8, 9, 15, 16, 17, 1023} {
+		a, b := make([]uint64, n), make([]uint64, n)
+		for i := range a {
+			a[i], b[i] = r.Uint64(), r.Uint64()
+		}
+		for _, k := range kernels {
+			want := make([]uint64, n)
+			k.ref(want, a, b)
+			got := make([]uint64, n)
+			k.sse2(got, a, b)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s SSE2 kernel failed for %d words.\n", k.name, n)
+			}
+			if !useAVX2 {
+				continue
+			}
+			got = make([]uint64, n)
+			k.avx2(got, a, b)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s AVX2 kernel failed for %d words.\n", k.name, n)
+			}
+		}
+	}
+	if !useAVX2 {
+		t.Log("AVX2 is not supported, AVX2 kernels not tested.")
+	}
+}
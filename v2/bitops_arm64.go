@@ -0,0 +1,22 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build arm64 && !purego
+
+package set
+
+// implemented in bitops_arm64.s; NEON is part of every arm64 CPU
+
+//go:noescape
+func andWordsImpl(dst, a, b []uint64)
+
+//go:noescape
+func orWordsImpl(dst, a, b []uint64)
+
+//go:noescape
+func xorWordsImpl(dst, a, b []uint64)
+
+//go:noescape
+func andNotWordsImpl(dst, a, b []uint64)
@@ -0,0 +1,120 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build arm64 && !purego
+
+#include "textflag.h"
+
+// Each function combines len(dst) words of a and b into dst. The main loop
+// handles four words in two 128 bit NEON registers per iteration, the tail
+// loop single words.
+
+// func andWordsImpl(dst, a, b []uint64)
+TEXT ·andWordsImpl(SB), NOSPLIT, $0-72
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R3
+	MOVD a_base+24(FP), R1
+	MOVD b_base+48(FP), R2
+loop4:
+	CMP  $4, R3
+	BLT  loop1
+	VLD1.P 32(R1), [V0.B16, V1.B16]
+	VLD1.P 32(R2), [V2.B16, V3.B16]
+	VAND V2.B16, V0.B16, V0.B16
+	VAND V3.B16, V1.B16, V1.B16
+	VST1.P [V0.B16, V1.B16], 32(R0)
+	SUB  $4, R3
+	B    loop4
+loop1:
+	CBZ  R3, done
+	MOVD.P 8(R1), R4
+	MOVD.P 8(R2), R5
+	AND  R5, R4
+	MOVD.P R4, 8(R0)
+	SUB  $1, R3
+	B    loop1
+done:
+	RET
+
+// func orWordsImpl(dst, a, b []uint64)
+TEXT ·orWordsImpl(SB), NOSPLIT, $0-72
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R3
+	MOVD a_base+24(FP), R1
+	MOVD b_base+48(FP), R2
+loop4:
+	CMP  $4, R3
+	BLT  loop1
+	VLD1.P 32(R1), [V0.B16, V1.B16]
+	VLD1.P 32(R2), [V2.B16, V3.B16]
+	VORR V2.B16, V0.B16, V0.B16
+	VORR V3.B16, V1.B16, V1.B16
+	VST1.P [V0.B16, V1.B16], 32(R0)
+	SUB  $4, R3
+	B    loop4
+loop1:
+	CBZ  R3, done
+	MOVD.P 8(R1), R4
+	MOVD.P 8(R2), R5
+	ORR  R5, R4
+	MOVD.P R4, 8(R0)
+	SUB  $1, R3
+	B    loop1
+done:
+	RET
+
+// func xorWordsImpl(dst, a, b []uint64)
+TEXT ·xorWordsImpl(SB), NOSPLIT, $0-72
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R3
+	MOVD a_base+24(FP), R1
+	MOVD b_base+48(FP), R2
+loop4:
+	CMP  $4, R3
+	BLT  loop1
+	VLD1.P 32(R1), [V0.B16, V1.B16]
+	VLD1.P 32(R2), [V2.B16, V3.B16]
+	VEOR V2.B16, V0.B16, V0.B16
+	VEOR V3.B16, V1.B16, V1.B16
+	VST1.P [V0.B16, V1.B16], 32(R0)
+	SUB  $4, R3
+	B    loop4
+loop1:
+	CBZ  R3, done
+	MOVD.P 8(R1), R4
+	MOVD.P 8(R2), R5
+	EOR  R5, R4
+	MOVD.P R4, 8(R0)
+	SUB  $1, R3
+	B    loop1
+done:
+	RET
+
+// func andNotWordsImpl(dst, a, b []uint64)
+TEXT ·andNotWordsImpl(SB), NOSPLIT, $0-72
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R3
+	MOVD a_base+24(FP), R1
+	MOVD b_base+48(FP), R2
+loop4:
+	CMP  $4, R3
+	BLT  loop1
+	VLD1.P 32(R1), [V0.B16, V1.B16]
+	VLD1.P 32(R2), [V2.B16, V3.B16]
+	VBIC V2.B16, V0.B16, V0.B16
+	VBIC V3.B16, V1.B16, V1.B16
+	VST1.P [V0.B16, V1.B16], 32(R0)
+	SUB  $4, R3
+	B    loop4
+loop1:
+	CBZ  R3, done
+	MOVD.P 8(R1), R4
+	MOVD.P 8(R2), R5
+	BIC  R5, R4
+	MOVD.P R4, 8(R0)
+	SUB  $1, R3
+	B    loop1
+done:
+	RET
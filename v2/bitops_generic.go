@@ -0,0 +1,13 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build (!amd64 && !arm64) || purego
+
+package set
+
+func andWordsImpl(dst, a, b []uint64)    { andWordsGeneric(dst, a, b) }
+func orWordsImpl(dst, a, b []uint64)     { orWordsGeneric(dst, a, b) }
+func xorWordsImpl(dst, a, b []uint64)    { xorWordsGeneric(dst, a, b) }
+func andNotWordsImpl(dst, a, b []uint64) { andNotWordsGeneric(dst, a, b) }
@@ -0,0 +1,69 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestBitops(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	kernels := []struct {
+		name      string
+		fast, ref func(dst, a, b []uint64)
+	}{
+		{"and", andWords, andWordsGeneric},
+		{"or", orWords, orWordsGeneric},
+		{"xor", xorWords, xorWordsGeneric},
+		{"andNot", andNotWords, andNotWordsGeneric},
+	}
+	// lengths around the unrolling boundaries
+	for _, n := range []int{0, 1, 2, 3, 4, 5, 7, 8, 9, 1024} {
+		a, b := make([]uint64, n), make([]uint64, n+1)
+		for i := range a {
+			a[i], b[i] = r.Uint64(), r.Uint64()
+		}
+		for _, k := range kernels {
+			got, want := make([]uint64, n), make([]uint64, n)
+			k.fast(got, a, b)
+			k.ref(want, a, b)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s kernel failed for %d words.\n", k.name, n)
+			}
+		}
+		want := 0
+		for _, w := range a {
+			want += bits.OnesCount64(w)
+		}
+		if got := popcount(a); got != want {
+			t.Errorf("popcount failed for %d words: got %d, expected %d.\n", n, got, want)
+		}
+	}
+
+	// in-place operation
+	a := []uint64{0xF0F0, 0xFFFF, 1, 2, 3}
+	andNotWords(a, a, []uint64{0xFF00, 0x0F0F, 1, 0, 0})
+	if !slices.Equal(a, []uint64{0x00F0, 0xF0F0, 0, 2, 3}) {
+		t.Errorf("andNot kernel failed in place: got %x.\n", a)
+	}
+}
+
+func BenchmarkBitops(b *testing.B) {
+	x, y, dst := make([]uint64, 1024), make([]uint64, 1024), make([]uint64, 1024)
+	b.Run("and", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			andWords(dst, x, y)
+		}
+	})
+	b.Run("andGeneric", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			andWordsGeneric(dst, x, y)
+		}
+	})
+}
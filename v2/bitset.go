@@ -0,0 +1,252 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"math/bits"
+	"strconv"
+)
+
+// ----- BitSet definition -----
+
+// A BitSet is a set of small non-negative integers, like the node numbers of
+// a graph, backed by a bitmap which grows with the largest element. Its set
+// algebra combines 64 elements per machine word, which is much faster than a
+// map based set for dense domains. Negative numbers are ignored.
+type BitSet struct {
+	words []uint64
+}
+
+// ----- constructor -----
+
+// NewBitSet creates a new bit set and initializes it with the argument
+// values.
+func NewBitSet(e ...int) *BitSet {
+	s := &BitSet{}
+	s.Add(e...)
+	return s
+}
+
+// grow extends the bitmap to n words.
+func (s *BitSet) grow(n int) {
+	if n > len(s.words) {
+		s.words = append(s.words, make([]uint64, n-len(s.words))...)
+	}
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s *BitSet) Add(e ...int) {
+	for _, i := range e {
+		if i < 0 {
+			continue
+		}
+		s.grow(i>>6 + 1)
+		s.words[i>>6] |= 1 << (i & 63)
+	}
+}
+
+// Remove removes one or more elements from the given set.
+func (s *BitSet) Remove(e ...int) {
+	for _, i := range e {
+		if i >= 0 && i>>6 < len(s.words) {
+			s.words[i>>6] &^= 1 << (i & 63)
+		}
+	}
+}
+
+// Clear removes all elements from the given set. The bitmap is kept.
+func (s *BitSet) Clear() {
+	clear(s.words)
+}
+
+// Reset removes all elements from the given set, and replaces its bitmap
+// with a new one presized for the elements below capacityHint, which releases
+// the memory of the old bitmap.
+func (s *BitSet) Reset(capacityHint int) {
+	s.words = make([]uint64, (max(capacityHint, 0)+63)/64)
+}
+
+// UnionWith adds all elements of t to s, without allocating a new set.
+func (s *BitSet) UnionWith(t *BitSet) {
+	s.grow(len(t.words))
+	n := len(t.words)
+	orWords(s.words[:n], s.words[:n], t.words)
+}
+
+// IntersectWith removes all elements from s which are not in t, without
+// allocating a new set.
+func (s *BitSet) IntersectWith(t *BitSet) {
+	n := min(len(s.words), len(t.words))
+	andWords(s.words[:n], s.words[:n], t.words[:n])
+	clear(s.words[n:])
+}
+
+// DiffWith removes all elements of t from s, without allocating a new set.
+func (s *BitSet) DiffWith(t *BitSet) {
+	n := min(len(s.words), len(t.words))
+	andNotWords(s.words[:n], s.words[:n], t.words[:n])
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *BitSet) IsEmpty() bool {
+	for _, w := range s.words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the length of the set.
+func (s *BitSet) Len() int {
+	return popcount(s.words)
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *BitSet) Contains(e ...int) bool {
+	for _, i := range e {
+		if i < 0 || i>>6 >= len(s.words) || s.words[i>>6]&(1<<(i&63)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsSorted checks the membership of each element of a query slice. It
+// exists for parity with the other ordered sets; as the bitmap is indexed
+// directly, the query does not need to be sorted.
+func (s *BitSet) ContainsSorted(sorted []int) []bool {
+	r := make([]bool, len(sorted))
+	for i, x := range sorted {
+		r[i] = s.Contains(x)
+	}
+	return r
+}
+
+// IsEqual tests if two sets are equal.
+func (s *BitSet) IsEqual(t *BitSet) bool {
+	a, b := s.words, t.words
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	for i, w := range a {
+		if i < len(b) {
+			if w != b[i] {
+				return false
+			}
+		} else if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubsetOf returns true if the set s is a subset of the set t, e.g. if
+// all elements of s are also in t.
+func (s *BitSet) IsSubsetOf(t *BitSet) bool {
+	for i, w := range s.words {
+		if i < len(t.words) {
+			w &^= t.words[i]
+		}
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSupersetOf returns true if the set s is a superset of the set t, e.g.
+// if all elements of t are also in s.
+func (s *BitSet) IsSupersetOf(t *BitSet) bool {
+	return t.IsSubsetOf(s)
+}
+
+// ----- methods that return a new set -----
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *BitSet) Copy() *BitSet {
+	return &BitSet{words: append([]uint64(nil), s.words...)}
+}
+
+// Union returns a new set, which represents the union of two or more sets.
+// The sets themselves are not modified.
+func (s *BitSet) Union(t ...*BitSet) *BitSet {
+	r := s.Copy()
+	for _, i := range t {
+		r.UnionWith(i)
+	}
+	return r
+}
+
+// Intersect returns a new set which represents the intersection of two or
+// more sets. The sets themselves are not modified.
+func (s *BitSet) Intersect(t ...*BitSet) *BitSet {
+	r := s.Copy()
+	for _, i := range t {
+		r.IntersectWith(i)
+	}
+	return r
+}
+
+// Diff returns a new set which represents the difference of two sets.
+// The sets themselves are not modified.
+func (s *BitSet) Diff(t *BitSet) *BitSet {
+	r := s.Copy()
+	r.DiffWith(t)
+	return r
+}
+
+// SymDiff returns a new set which represents the symmetric difference of two
+// sets. The sets themselves are not modified.
+func (s *BitSet) SymDiff(t *BitSet) *BitSet {
+	r := s.Copy()
+	r.grow(len(t.words))
+	n := len(t.words)
+	xorWords(r.words[:n], r.words[:n], t.words)
+	return r
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all elements in ascending order.
+func (s *BitSet) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, w := range s.words {
+			for w != 0 {
+				if !yield(i<<6 + bits.TrailingZeros64(w)) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// List returns the set elements in ascending order in a slice.
+func (s *BitSet) List() []int {
+	l := make([]int, 0, s.Len())
+	for i := range s.All() {
+		l = append(l, i)
+	}
+	return l
+}
+
+// String returns a textual representation of the set in a string, with the
+// elements in ascending order.
+func (s *BitSet) String() string {
+	b := []byte("{ ")
+	for i := range s.All() {
+		b = append(strconv.AppendInt(b, int64(i), 10), ' ')
+	}
+	b = append(b, '}')
+	return string(b)
+}
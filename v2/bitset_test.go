@@ -0,0 +1,70 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBitSet(t *testing.T) {
+	a := NewBitSet(1, 3, 5, 64, 200, -1)
+	b := NewBitSet(3, 64, 65)
+	if a.Len() != 5 || !a.Contains(1, 200) || a.Contains(-1, 2, 1000) {
+		t.Errorf("NewBitSet failed: got %v.\n", a)
+	}
+	if r := a.Union(b); !slices.Equal(r.List(), []int{1, 3, 5, 64, 65, 200}) {
+		t.Errorf("Union failed: got %v.\n", r)
+	}
+	if r := a.Intersect(b); !r.IsEqual(NewBitSet(3, 64)) {
+		t.Errorf("Intersect failed: got %v.\n", r)
+	}
+	if r := a.Diff(b); r.String() != "{ 1 5 200 }" {
+		t.Errorf("Diff failed: got %v.\n", r)
+	}
+	if r := b.SymDiff(a); !r.IsEqual(NewBitSet(1, 5, 65, 200)) {
+		t.Errorf("SymDiff failed: got %v.\n", r)
+	}
+	if !NewBitSet(3, 64).IsSubsetOf(a) || b.IsSubsetOf(a) || !a.IsSupersetOf(NewBitSet()) {
+		t.Errorf("IsSubsetOf failed.\n")
+	}
+
+	// the in-place operations and trailing zero words
+	c := a.Copy()
+	c.IntersectWith(NewBitSet(1))
+	if !c.IsEqual(NewBitSet(1)) || !NewBitSet(1).IsEqual(c) {
+		t.Errorf("IntersectWith failed: got %v.\n", c)
+	}
+	c.Remove(1, 5000)
+	if !c.IsEmpty() || c.Len() != 0 {
+		t.Errorf("Remove failed: got %v.\n", c)
+	}
+	a.Clear()
+	if !a.IsEmpty() || !a.IsSubsetOf(b) {
+		t.Errorf("Clear failed: got %v.\n", a)
+	}
+}
+
+func BenchmarkBitSetUnion(b *testing.B) {
+	x, y := NewBitSet(), NewBitSet()
+	for i := 0; i < 100000; i += 3 {
+		x.Add(i)
+		y.Add(i + 1)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Union(y)
+	}
+}
+
+func TestBitSetContainsSorted(t *testing.T) {
+	s := NewBitSet(1, 5, 64, 200)
+	got := s.ContainsSorted([]int{-1, 1, 2, 5, 64, 200, 1000})
+	want := []bool{false, true, false, true, true, true, false}
+	if !slices.Equal(got, want) {
+		t.Errorf("ContainsSorted failed: got %v, expected %v.\n", got, want)
+	}
+}
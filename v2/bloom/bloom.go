@@ -0,0 +1,200 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package bloom provides Bloom and cuckoo filters, which test the membership of
+elements in a small, fixed amount of memory, with a bounded rate of false
+positives.
+
+A filter never reports a false negative, so it is used in front of an exact
+set, or an expensive lookup: elements for which MayContain returns false
+are certainly not in the set, and only the others need to be checked.
+
+A Filter is a classic Bloom filter, whose false positive rate is chosen at
+creation. A Cuckoo filter also supports the removal of elements, like for
+the invalidation of cache entries.
+*/
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+var (
+	// ErrIncompatible is returned by Union for filters of different sizes
+	// or numbers of hash functions.
+	ErrIncompatible = errors.New("bloom: filters have different parameters")
+
+	// ErrFormat is returned by UnmarshalBinary for malformed input.
+	ErrFormat = errors.New("bloom: malformed filter")
+)
+
+// the magic bytes of the binary encoding
+const magic = "BLM1"
+
+// ----- Filter definition -----
+
+// A Filter is a Bloom filter with m bits and k hash functions. The k bit
+// positions of an element are derived from its 64 bit hash by double
+// hashing.
+type Filter struct {
+	k    uint8
+	m    uint64 // the number of bits, a multiple of 64
+	bits []uint64
+}
+
+// New creates an empty filter for about n elements with the false positive
+// rate p, which is clamped to [1e-9, 0.5]. The filter takes about
+// 1.44 * log2(1/p) bits per element, e.g. 1.2 bytes for 1%.
+func New(n int, p float64) *Filter {
+	n = max(n, 1)
+	p = min(max(p, 1e-9), 0.5)
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	m = max((m+63)/64, 1) * 64
+	k := min(max(math.Round(float64(m)/float64(n)*math.Ln2), 1), 32)
+	return &Filter{k: uint8(k), m: m, bits: make([]uint64, m/64)}
+}
+
+// positions calls fn for the k bit positions of a hash, until fn returns
+// false.
+func (f *Filter) positions(h uint64, fn func(i uint64) bool) bool {
+	h1, h2 := h, hash.Mix64(h)|1
+	for range f.k {
+		if !fn(h1 % f.m) {
+			return false
+		}
+		h1 += h2
+	}
+	return true
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds an element, given by its binary representation.
+func (f *Filter) Add(b []byte) {
+	f.AddHash(hash.Sum64(b))
+}
+
+// AddString adds an element, given by its string representation.
+func (f *Filter) AddString(e string) {
+	f.AddHash(hash.String(e))
+}
+
+// AddHash adds an element, given by a uniformly distributed 64 bit hash.
+func (f *Filter) AddHash(h uint64) {
+	f.positions(h, func(i uint64) bool {
+		f.bits[i/64] |= 1 << (i % 64)
+		return true
+	})
+}
+
+// Union adds all elements of the filter t to f, which yields a filter of
+// the union of both sets. Both filters must have been created with the same
+// parameters.
+func (f *Filter) Union(t *Filter) error {
+	if f.k != t.k || f.m != t.m {
+		return ErrIncompatible
+	}
+	for i, w := range t.bits {
+		f.bits[i] |= w
+	}
+	return nil
+}
+
+// Reset removes all elements from the filter.
+func (f *Filter) Reset() {
+	clear(f.bits)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// MayContain checks if the filter may contain an element, given by its
+// binary representation. A false result is certain, a true result is a
+// false positive with the rate of the filter.
+func (f *Filter) MayContain(b []byte) bool {
+	return f.MayContainHash(hash.Sum64(b))
+}
+
+// MayContainString checks if the filter may contain an element, given by
+// its string representation.
+func (f *Filter) MayContainString(e string) bool {
+	return f.MayContainHash(hash.String(e))
+}
+
+// MayContainHash checks if the filter may contain an element, given by a
+// uniformly distributed 64 bit hash.
+func (f *Filter) MayContainHash(h uint64) bool {
+	return f.positions(h, func(i uint64) bool {
+		return f.bits[i/64]&(1<<(i%64)) != 0
+	})
+}
+
+// Bits returns the number of bits of the filter.
+func (f *Filter) Bits() int {
+	return int(f.m)
+}
+
+// Hashes returns the number of hash functions of the filter.
+func (f *Filter) Hashes() int {
+	return int(f.k)
+}
+
+// FalsePositiveRate returns the current false positive rate of the filter,
+// estimated from the fraction of set bits. It grows beyond the rate given
+// to New when more elements are added than planned.
+func (f *Filter) FalsePositiveRate() float64 {
+	ones := 0
+	for _, w := range f.bits {
+		ones += bits.OnesCount64(w)
+	}
+	return math.Pow(float64(ones)/float64(f.m), float64(f.k))
+}
+
+// Clone returns a copy of the filter.
+func (f *Filter) Clone() *Filter {
+	return &Filter{k: f.k, m: f.m, bits: append([]uint64(nil), f.bits...)}
+}
+
+// ----- serialization -----
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// encoding consists of the magic bytes "BLM1", the number of hash functions
+// as a byte, the number of bits as a uvarint, and the bits in little endian
+// words.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(magic)+1+binary.MaxVarintLen64+8*len(f.bits))
+	b = append(b, magic...)
+	b = append(b, f.k)
+	b = binary.AppendUvarint(b, f.m)
+	for _, w := range f.bits {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// replaces the filter with the decoded one.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < len(magic)+1 || string(data[:len(magic)]) != magic {
+		return ErrFormat
+	}
+	k := data[len(magic)]
+	m, n := binary.Uvarint(data[len(magic)+1:])
+	data = data[len(magic)+1+max(n, 0):]
+	if n <= 0 || k == 0 || m == 0 || m%64 != 0 || uint64(len(data)) != m/8 {
+		return ErrFormat
+	}
+	w := make([]uint64, m/64)
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	*f = Filter{k: k, m: m, bits: w}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package bloom
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	f := New(10000, 0.01)
+	if f.Bits() < 90000 || f.Bits() > 100000 || f.Hashes() != 7 {
+		t.Errorf("New failed: got %d bits and %d hashes.\n", f.Bits(), f.Hashes())
+	}
+	for i := 0; i < 10000; i++ {
+		f.AddString("in" + strconv.Itoa(i))
+	}
+	for i := 0; i < 10000; i++ {
+		if !f.MayContainString("in" + strconv.Itoa(i)) {
+			t.Fatalf("MayContain failed: false negative for element %d.\n", i)
+		}
+	}
+	fp := 0
+	for i := 0; i < 100000; i++ {
+		if f.MayContainString("out" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / 100000; rate > 0.015 {
+		t.Errorf("MayContain failed: false positive rate %.4f.\n", rate)
+	}
+	if r := f.FalsePositiveRate(); r < 0.005 || r > 0.015 {
+		t.Errorf("FalsePositiveRate failed: got %.4f.\n", r)
+	}
+
+	f.Add([]byte{1, 2, 3})
+	if !f.MayContain([]byte{1, 2, 3}) {
+		t.Errorf("Add failed for a binary element.\n")
+	}
+	c := f.Clone()
+	f.Reset()
+	if f.MayContainString("in0") || f.FalsePositiveRate() != 0 || !c.MayContainString("in0") {
+		t.Errorf("Reset failed.\n")
+	}
+}
+
+func TestFilterUnion(t *testing.T) {
+	a, b := New(100, 0.01), New(100, 0.01)
+	a.AddString("a")
+	b.AddString("b")
+	if err := a.Union(b); err != nil || !a.MayContainString("a") || !a.MayContainString("b") {
+		t.Errorf("Union failed: %v.\n", err)
+	}
+	if err := a.Union(New(1000, 0.01)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Union failed: got %v for different sizes.\n", err)
+	}
+}
+
+func TestFilterBinary(t *testing.T) {
+	f := New(1000, 0.001)
+	for i := 0; i < 1000; i++ {
+		f.AddString(strconv.Itoa(i))
+	}
+	b, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v.\n", err)
+	}
+	var g Filter
+	if err := g.UnmarshalBinary(b); err != nil || g.Bits() != f.Bits() || g.Hashes() != f.Hashes() {
+		t.Fatalf("UnmarshalBinary failed: %v.\n", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !g.MayContainString(strconv.Itoa(i)) {
+			t.Fatalf("UnmarshalBinary failed: element %d lost.\n", i)
+		}
+	}
+	for _, bad := range [][]byte{nil, []byte("BLM1"), b[:len(b)-1], append([]byte("XLM1"), b[4:]...)} {
+		if err := g.UnmarshalBinary(bad); !errors.Is(err, ErrFormat) {
+			t.Errorf("UnmarshalBinary failed: got %v for %d bytes.\n", err, len(bad))
+		}
+	}
+}
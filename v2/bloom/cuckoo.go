@@ -0,0 +1,264 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package bloom
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+// the magic bytes of the binary encoding of cuckoo filters
+const cuckooMagic = "CKO1"
+
+// the parameters of cuckoo filters
+const (
+	bucketSize = 4   // the fingerprints per bucket
+	maxKicks   = 500 // the relocations before an insertion fails
+)
+
+// ----- Cuckoo definition -----
+
+// A Cuckoo is a cuckoo filter, which unlike a Bloom filter supports the
+// removal of elements. It stores a 16 bit fingerprint of each element in one
+// of two buckets of 4 fingerprints, which bounds the false positive rate to
+// 8/2^16, about 0.012%, independent of the number of elements.
+//
+// An element added twice is stored twice, and needs to be removed twice.
+// Only elements which were added may be removed; removing others may remove
+// the fingerprint of a different element, which causes a false negative.
+type Cuckoo struct {
+	fp     []uint16 // the buckets, 0 for free entries
+	mask   uint64   // the number of buckets minus 1
+	count  int
+	victim uint16 // a fingerprint which did not fit, 0 for none
+	vidx   uint64 // a bucket of the victim
+	rnd    uint64 // the state of the random choice of relocated entries
+}
+
+// NewCuckoo creates an empty cuckoo filter for up to n elements. The number
+// of buckets is a power of two, with room for n elements at a load of 95%.
+func NewCuckoo(n int) *Cuckoo {
+	b := uint64(max(n, 1)*100/95/bucketSize + 1)
+	nb := uint64(1) << bits.Len64(b-1)
+	return &Cuckoo{fp: make([]uint16, nb*bucketSize), mask: nb - 1, rnd: 1}
+}
+
+// split returns the fingerprint and the first bucket of a hash. The
+// fingerprint is never 0.
+func (c *Cuckoo) split(h uint64) (uint16, uint64) {
+	f := uint16(h >> 48)
+	if f == 0 {
+		f = 1
+	}
+	return f, h & c.mask
+}
+
+// alt returns the other bucket of a fingerprint in bucket i. It is its own
+// inverse, so it can be computed from either bucket.
+func (c *Cuckoo) alt(f uint16, i uint64) uint64 {
+	return (i ^ hash.Mix64(uint64(f))) & c.mask
+}
+
+// bucket returns the entries of the i-th bucket.
+func (c *Cuckoo) bucket(i uint64) []uint16 {
+	return c.fp[i*bucketSize : (i+1)*bucketSize]
+}
+
+// insert puts a fingerprint into a free entry of bucket i.
+func (c *Cuckoo) insert(f uint16, i uint64) bool {
+	b := c.bucket(i)
+	for j, e := range b {
+		if e == 0 {
+			b[j] = f
+			return true
+		}
+	}
+	return false
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds an element, given by its binary representation. It returns false
+// if the filter is full.
+func (c *Cuckoo) Add(b []byte) bool {
+	return c.AddHash(hash.Sum64(b))
+}
+
+// AddString adds an element, given by its string representation.
+func (c *Cuckoo) AddString(e string) bool {
+	return c.AddHash(hash.String(e))
+}
+
+// AddHash adds an element, given by a uniformly distributed 64 bit hash.
+func (c *Cuckoo) AddHash(h uint64) bool {
+	if c.victim != 0 {
+		return false
+	}
+	f, i := c.split(h)
+	c.count++
+	c.place(f, i)
+	return true
+}
+
+// place puts a fingerprint into bucket i or its other bucket, relocating
+// random entries to their other buckets if both are full. The last evicted
+// fingerprint becomes the victim, so nothing is lost.
+func (c *Cuckoo) place(f uint16, i uint64) {
+	if c.insert(f, i) || c.insert(f, c.alt(f, i)) {
+		return
+	}
+	for range maxKicks {
+		c.rnd ^= c.rnd << 13
+		c.rnd ^= c.rnd >> 7
+		c.rnd ^= c.rnd << 17
+		b := c.bucket(i)
+		j := c.rnd % bucketSize
+		f, b[j] = b[j], f
+		i = c.alt(f, i)
+		if c.insert(f, i) {
+			return
+		}
+	}
+	c.victim, c.vidx = f, i
+}
+
+// Remove removes an element, given by its binary representation. It returns
+// false if the element was not found.
+func (c *Cuckoo) Remove(b []byte) bool {
+	return c.RemoveHash(hash.Sum64(b))
+}
+
+// RemoveString removes an element, given by its string representation.
+func (c *Cuckoo) RemoveString(e string) bool {
+	return c.RemoveHash(hash.String(e))
+}
+
+// RemoveHash removes an element, given by a uniformly distributed 64 bit
+// hash.
+func (c *Cuckoo) RemoveHash(h uint64) bool {
+	f, i := c.split(h)
+	for _, k := range [2]uint64{i, c.alt(f, i)} {
+		b := c.bucket(k)
+		for j, e := range b {
+			if e == f {
+				b[j] = 0
+				c.count--
+				// the victim may fit now
+				if v := c.victim; v != 0 {
+					c.victim = 0
+					c.place(v, c.vidx)
+				}
+				return true
+			}
+		}
+	}
+	if c.victim == f && (c.vidx == i || c.vidx == c.alt(f, i)) {
+		c.victim = 0
+		c.count--
+		return true
+	}
+	return false
+}
+
+// Reset removes all elements from the filter.
+func (c *Cuckoo) Reset() {
+	clear(c.fp)
+	c.count, c.victim = 0, 0
+}
+
+// ----- methods that do not modify the receiver -----
+
+// MayContain checks if the filter may contain an element, given by its
+// binary representation. A false result is certain, as far as only added
+// elements were removed.
+func (c *Cuckoo) MayContain(b []byte) bool {
+	return c.MayContainHash(hash.Sum64(b))
+}
+
+// MayContainString checks if the filter may contain an element, given by
+// its string representation.
+func (c *Cuckoo) MayContainString(e string) bool {
+	return c.MayContainHash(hash.String(e))
+}
+
+// MayContainHash checks if the filter may contain an element, given by a
+// uniformly distributed 64 bit hash.
+func (c *Cuckoo) MayContainHash(h uint64) bool {
+	f, i := c.split(h)
+	i2 := c.alt(f, i)
+	for j := range bucketSize {
+		if c.fp[i*bucketSize+uint64(j)] == f || c.fp[i2*bucketSize+uint64(j)] == f {
+			return true
+		}
+	}
+	return c.victim == f && (c.vidx == i || c.vidx == i2)
+}
+
+// Len returns the number of elements in the filter.
+func (c *Cuckoo) Len() int {
+	return c.count
+}
+
+// Cap returns the number of entries of the filter.
+func (c *Cuckoo) Cap() int {
+	return len(c.fp)
+}
+
+// Clone returns a copy of the filter.
+func (c *Cuckoo) Clone() *Cuckoo {
+	r := *c
+	r.fp = append([]uint16(nil), c.fp...)
+	return &r
+}
+
+// ----- serialization -----
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// encoding consists of the magic bytes "CKO1", the number of buckets, the
+// number of elements, the victim and its bucket as uvarints, and the
+// fingerprints as little endian 16 bit words.
+func (c *Cuckoo) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(cuckooMagic)+4*binary.MaxVarintLen64+2*len(c.fp))
+	b = append(b, cuckooMagic...)
+	b = binary.AppendUvarint(b, c.mask+1)
+	b = binary.AppendUvarint(b, uint64(c.count))
+	b = binary.AppendUvarint(b, uint64(c.victim))
+	b = binary.AppendUvarint(b, c.vidx)
+	for _, f := range c.fp {
+		b = binary.LittleEndian.AppendUint16(b, f)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// replaces the filter with the decoded one.
+func (c *Cuckoo) UnmarshalBinary(data []byte) error {
+	if len(data) < len(cuckooMagic) || string(data[:len(cuckooMagic)]) != cuckooMagic {
+		return ErrFormat
+	}
+	data = data[len(cuckooMagic):]
+	var v [4]uint64
+	for i := range v {
+		x, n := binary.Uvarint(data)
+		if n <= 0 {
+			return ErrFormat
+		}
+		v[i], data = x, data[n:]
+	}
+	nb, count, victim, vidx := v[0], v[1], v[2], v[3]
+	if nb == 0 || nb&(nb-1) != 0 || uint64(len(data)) != 2*bucketSize*nb || victim > 0xffff || vidx >= nb ||
+		count > bucketSize*nb+1 {
+		return ErrFormat
+	}
+	fp := make([]uint16, nb*bucketSize)
+	for i := range fp {
+		fp[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	*c = Cuckoo{fp: fp, mask: nb - 1, count: int(count), victim: uint16(victim), vidx: vidx, rnd: 1}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package bloom
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestCuckoo(t *testing.T) {
+	c := NewCuckoo(10000)
+	if c.Cap() < 10000 {
+		t.Errorf("NewCuckoo failed: got %d entries.\n", c.Cap())
+	}
+	for i := 0; i < 10000; i++ {
+		if !c.AddString("in" + strconv.Itoa(i)) {
+			t.Fatalf("Add failed: filter full after %d elements.\n", i)
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		if !c.MayContainString("in" + strconv.Itoa(i)) {
+			t.Fatalf("MayContain failed: false negative for element %d.\n", i)
+		}
+	}
+	fp := 0
+	for i := 0; i < 100000; i++ {
+		if c.MayContainString("out" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / 100000; rate > 8.0/(1<<16)*1.5 {
+		t.Errorf("MayContain failed: false positive rate %.5f.\n", rate)
+	}
+
+	// removed elements are gone, the others stay
+	for i := 0; i < 10000; i += 2 {
+		if !c.RemoveString("in" + strconv.Itoa(i)) {
+			t.Fatalf("Remove failed for element %d.\n", i)
+		}
+	}
+	gone := 0
+	for i := 0; i < 10000; i++ {
+		in := c.MayContainString("in" + strconv.Itoa(i))
+		if i%2 == 1 && !in {
+			t.Fatalf("Remove failed: false negative for element %d.\n", i)
+		}
+		if i%2 == 0 && !in {
+			gone++
+		}
+	}
+	if gone < 4990 || c.Len() != 5000 {
+		t.Errorf("Remove failed: %d of 5000 removed, length %d.\n", gone, c.Len())
+	}
+	if c.RemoveString("never added") {
+		t.Errorf("Remove failed for a missing element.\n")
+	}
+
+	// duplicates are counted
+	d := NewCuckoo(10)
+	d.AddString("x")
+	d.AddString("x")
+	d.RemoveString("x")
+	if !d.MayContainString("x") || d.Len() != 1 {
+		t.Errorf("Remove failed: both copies of a duplicate removed.\n")
+	}
+	e := d.Clone()
+	d.Reset()
+	if d.MayContainString("x") || d.Len() != 0 || !e.MayContainString("x") {
+		t.Errorf("Reset failed.\n")
+	}
+}
+
+func TestCuckooFull(t *testing.T) {
+	c := NewCuckoo(100)
+	n := 0
+	for c.AddHash(uint64(n) * 0x9e3779b97f4a7c15) {
+		n++
+	}
+	if n < c.Cap() || n > c.Cap()+1 || c.Len() != n {
+		t.Errorf("Add failed: %d elements in %d entries.\n", n, c.Cap())
+	}
+	// all elements are found, including the victim of the last insertion
+	for i := 0; i < n; i++ {
+		if !c.MayContainHash(uint64(i) * 0x9e3779b97f4a7c15) {
+			t.Fatalf("MayContain failed: false negative for element %d of a full filter.\n", i)
+		}
+	}
+	if !c.RemoveHash(0) || !c.AddHash(0) {
+		t.Errorf("Add failed after Remove in a full filter.\n")
+	}
+}
+
+func TestCuckooBinary(t *testing.T) {
+	c := NewCuckoo(1000)
+	for i := 0; i < 1000; i++ {
+		c.AddString(strconv.Itoa(i))
+	}
+	b, _ := c.MarshalBinary()
+	var d Cuckoo
+	if err := d.UnmarshalBinary(b); err != nil || d.Len() != 1000 || d.Cap() != c.Cap() {
+		t.Fatalf("UnmarshalBinary failed: %v.\n", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !d.MayContainString(strconv.Itoa(i)) {
+			t.Fatalf("UnmarshalBinary failed: element %d lost.\n", i)
+		}
+	}
+	if !d.RemoveString("0") || d.Len() != 999 {
+		t.Errorf("Remove failed after UnmarshalBinary.\n")
+	}
+	for _, bad := range [][]byte{nil, []byte("CKO1"), b[:len(b)-1], []byte("BLM1")} {
+		if err := d.UnmarshalBinary(bad); !errors.Is(err, ErrFormat) {
+			t.Errorf("UnmarshalBinary failed: got %v for %d bytes.\n", err, len(bad))
+		}
+	}
+}
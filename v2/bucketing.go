@@ -0,0 +1,91 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/hweidner/set/v2/internal/elemcodec"
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+// ----- deterministic bucketing -----
+
+// Bucket assigns an element to one of the given number of buckets, like the
+// variants of an A/B experiment. The assignment is a hash of the salt and
+// the element, so it is stable across runs and processes, uniform, and
+// independent for different salts, like one salt per experiment. Strings,
+// integers and floats are hashed by their encoding like for Digest, other
+// elements by their fmt representation, which is stable for values without
+// pointers. Bucket panics if buckets is not positive.
+func Bucket[T comparable](e T, salt string, buckets int) int {
+	if buckets <= 0 {
+		panic("set: non-positive number of buckets for Bucket")
+	}
+	return bucket(e, salt, uint64(buckets))
+}
+
+// bucket returns the bucket of e among n buckets.
+func bucket[T comparable](e T, salt string, n uint64) int {
+	b := make([]byte, 0, len(salt)+24)
+	b = append(append(b, salt...), 0)
+	if v, ok := elemcodec.AppendValue(b, e); ok {
+		b = v
+	} else {
+		b = fmt.Append(b, e)
+	}
+	// the high word of the product maps the hash uniformly to [0, n)
+	hi, _ := bits.Mul64(hash.Sum64(b), n)
+	return int(hi)
+}
+
+// Buckets splits the set into the given number of new sets by Bucket, so the
+// element e is in the set at index Bucket(e, salt, buckets).
+func Buckets[T comparable](s Set[T], salt string, buckets int) []Set[T] {
+	if buckets <= 0 {
+		panic("set: non-positive number of buckets for Buckets")
+	}
+	r := make([]Set[T], buckets)
+	for i := range r {
+		r[i] = Set[T]{set: make(map[T]struct{}, len(s.set)/buckets)}
+	}
+	for e := range s.set {
+		r[bucket(e, salt, uint64(buckets))].set[e] = struct{}{}
+	}
+	return r
+}
+
+// Assign splits the set into groups of the given relative weights, like
+// 90 and 10 for a control group and a treatment group. Each element is
+// assigned by its bucket among sum(weights) buckets, so the assignment is
+// stable like with Bucket, as long as the weights do not change. Groups with
+// a weight <= 0 are empty.
+func Assign[T comparable](s Set[T], salt string, weights ...int) []Set[T] {
+	var total uint64
+	bounds := make([]uint64, len(weights)) // the exclusive upper bucket of each group
+	for i, w := range weights {
+		total += uint64(max(w, 0))
+		bounds[i] = total
+	}
+	r := make([]Set[T], len(weights))
+	for i := range r {
+		r[i] = New[T]()
+	}
+	if total == 0 {
+		return r
+	}
+	for e := range s.set {
+		b := uint64(bucket(e, salt, total))
+		for i, hi := range bounds {
+			if b < hi {
+				r[i].set[e] = struct{}{}
+				break
+			}
+		}
+	}
+	return r
+}
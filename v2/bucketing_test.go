@@ -0,0 +1,75 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBucket(t *testing.T) {
+	// the assignment is stable across processes
+	if b := Bucket("user42", "exp1", 100); b != 21 {
+		t.Errorf("Bucket failed: got %d.\n", b)
+	}
+	if a, b := Bucket(7, "s", 10), Bucket(int64(7), "s", 10); a != b {
+		t.Errorf("Bucket failed: integer types differ: %d, %d.\n", a, b)
+	}
+
+	s := New[string]()
+	for i := 0; i < 10000; i++ {
+		s.Add("user" + strconv.Itoa(i))
+	}
+	b := Buckets(s, "exp1", 4)
+	n, moved := 0, 0
+	for i, bs := range b {
+		n += bs.Len()
+		if bs.Len() < 2300 || bs.Len() > 2700 {
+			t.Errorf("Buckets failed: bucket %d has %d elements.\n", i, bs.Len())
+		}
+		for e := range bs.All() {
+			if Bucket(e, "exp1", 4) != i {
+				t.Fatalf("Buckets failed: %s in bucket %d.\n", e, i)
+			}
+			if Bucket(e, "exp2", 4) != i {
+				moved++
+			}
+		}
+	}
+	// the buckets of different salts are independent
+	if n != s.Len() || moved < 7000 || moved > 8000 {
+		t.Errorf("Buckets failed: got %d elements, %d moved for another salt.\n", n, moved)
+	}
+
+	type user struct{ ID int }
+	if Bucket(user{1}, "s", 1000) != Bucket(user{1}, "s", 1000) {
+		t.Errorf("Bucket failed for structs.\n")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Bucket failed: no panic for 0 buckets.\n")
+		}
+	}()
+	Bucket(1, "s", 0)
+}
+
+func TestAssign(t *testing.T) {
+	s := New[int]()
+	for i := 0; i < 10000; i++ {
+		s.Add(i)
+	}
+	g := Assign(s, "exp", 90, 0, 10)
+	if g[0].Len() < 8800 || g[0].Len() > 9200 || !g[1].IsEmpty() || g[0].Len()+g[2].Len() != s.Len() {
+		t.Errorf("Assign failed: got groups of %d, %d, %d.\n", g[0].Len(), g[1].Len(), g[2].Len())
+	}
+	if !g[2].IsEqual(Assign(s, "exp", 90, 0, 10)[2]) {
+		t.Errorf("Assign failed: assignment not stable.\n")
+	}
+	if g := Assign(s, "exp", 0); len(g) != 1 || !g[0].IsEmpty() {
+		t.Errorf("Assign failed: got %v for zero weights.\n", g)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"time"
+)
+
+// ----- BucketSeries definition -----
+
+// A BucketSeries is a time series of sets, with one set per time bucket of a
+// fixed width, like one set of visitors per minute. Only the most recent
+// buckets are retained. Queries over several buckets, like the distinct
+// elements of the last hour, are answered by lazy unions, which do not
+// materialize the union unless asked for.
+type BucketSeries[T comparable] struct {
+	width   time.Duration
+	now     func() time.Time
+	buckets []timeBucket[T] // ring buffer, indexed by bucket number
+	newest  int64           // the newest bucket number seen
+}
+
+// a time bucket and its number; the slot is stale when the number is outdated
+type timeBucket[T comparable] struct {
+	num int64
+	set Set[T]
+}
+
+// ----- constructor -----
+
+// NewBucketSeries creates a new time series with buckets of the given width,
+// retaining the given number of buckets.
+func NewBucketSeries[T comparable](width time.Duration, retention int) *BucketSeries[T] {
+	return &BucketSeries[T]{
+		width:   width,
+		now:     time.Now,
+		buckets: make([]timeBucket[T], max(retention, 1)),
+	}
+}
+
+// bucketNum returns the number of the bucket which contains t.
+func (b *BucketSeries[T]) bucketNum(t time.Time) int64 {
+	n, w := t.UnixNano(), int64(b.width)
+	if n < 0 {
+		return (n - w + 1) / w
+	}
+	return n / w
+}
+
+// slot returns the set of bucket n, or an invalid set if the bucket is stale
+// or not retained.
+func (b *BucketSeries[T]) slot(n int64) Set[T] {
+	if n <= b.newest-int64(len(b.buckets)) || n > b.newest {
+		return Set[T]{}
+	}
+	i := n % int64(len(b.buckets))
+	if i < 0 {
+		i += int64(len(b.buckets))
+	}
+	if tb := b.buckets[i]; tb.num == n {
+		return tb.set
+	}
+	return Set[T]{}
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the current bucket.
+func (b *BucketSeries[T]) Add(e ...T) {
+	b.AddAt(b.now(), e...)
+}
+
+// AddAt adds one or more elements to the bucket which contains t. Elements
+// for buckets which are no longer retained are ignored.
+func (b *BucketSeries[T]) AddAt(t time.Time, e ...T) {
+	n := b.bucketNum(t)
+	b.newest = max(b.newest, n)
+	if n <= b.newest-int64(len(b.buckets)) {
+		return
+	}
+	i := n % int64(len(b.buckets))
+	if i < 0 {
+		i += int64(len(b.buckets))
+	}
+	if tb := &b.buckets[i]; tb.num != n || tb.set.set == nil {
+		*tb = timeBucket[T]{num: n, set: New(e...)}
+	} else {
+		tb.set.Add(e...)
+	}
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Bucket returns a copy of the set of the bucket which contains t. If the
+// bucket is not retained, the set is empty.
+func (b *BucketSeries[T]) Bucket(t time.Time) Set[T] {
+	return b.slot(b.bucketNum(t)).Copy()
+}
+
+// Last returns an iterator over the distinct elements of the last n buckets,
+// including the current one. Each element is yielded once, from the most
+// recent bucket which contains it; no union is materialized.
+func (b *BucketSeries[T]) Last(n int) iter.Seq[T] {
+	cur := b.bucketNum(b.now())
+	return func(yield func(T) bool) {
+		for i := int64(0); i < int64(n); i++ {
+			s := b.slot(cur - i)
+		next:
+			for k := range s.set {
+				for j := int64(0); j < i; j++ {
+					if _, ok := b.slot(cur - j).set[k]; ok {
+						continue next
+					}
+				}
+				if !yield(k) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Distinct returns the union of the sets of the last n buckets, including the
+// current one.
+func (b *BucketSeries[T]) Distinct(n int) Set[T] {
+	r := New[T]()
+	for k := range b.Last(n) {
+		r.set[k] = struct{}{}
+	}
+	return r
+}
+
+// CountDistinct returns the number of distinct elements in the last n
+// buckets, including the current one, without materializing their union.
+func (b *BucketSeries[T]) CountDistinct(n int) int {
+	c := 0
+	for range b.Last(n) {
+		c++
+	}
+	return c
+}
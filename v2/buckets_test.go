@@ -0,0 +1,54 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketSeries(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBucketSeries[string](time.Minute, 3)
+	b.now = func() time.Time { return clock }
+
+	b.Add("alice", "bob")
+	clock = clock.Add(time.Minute)
+	b.Add("bob", "carol")
+	clock = clock.Add(time.Minute)
+	b.Add("dave")
+
+	if n := b.CountDistinct(1); n != 1 {
+		t.Errorf("CountDistinct failed: got %d, expected 1.\n", n)
+	}
+	if r := b.Distinct(2); !r.IsEqual(New("bob", "carol", "dave")) {
+		t.Errorf("Distinct failed: got %v.\n", r)
+	}
+	if n := b.CountDistinct(10); n != 4 {
+		t.Errorf("CountDistinct failed: got %d, expected 4.\n", n)
+	}
+
+	// the oldest bucket falls out of the retention
+	clock = clock.Add(time.Minute)
+	b.Add("erin")
+	if r := b.Distinct(3); !r.IsEqual(New("bob", "carol", "dave", "erin")) {
+		t.Errorf("Distinct failed after rotation: got %v.\n", r)
+	}
+	b.AddAt(clock.Add(-10*time.Minute), "mallory")
+	if b.Distinct(10).Contains("mallory") {
+		t.Errorf("AddAt failed: element added to an expired bucket.\n")
+	}
+	if r := b.Bucket(clock.Add(-time.Minute)); !r.IsEqual(New("dave")) {
+		t.Errorf("Bucket failed: got %v.\n", r)
+	}
+
+	// a gap in time leaves stale buckets behind
+	clock = clock.Add(2 * time.Minute)
+	b.Add("frank")
+	if r := b.Distinct(3); !r.IsEqual(New("erin", "frank")) {
+		t.Errorf("Distinct failed after gap: got %v.\n", r)
+	}
+}
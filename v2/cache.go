@@ -0,0 +1,164 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// ----- Cache definition -----
+
+// A Cache maps keys to sets, like sessions to permissions or users to devices.
+// Entries expire after a time to live, and the least recently used entries are
+// evicted when the cache is full. All methods are safe for concurrent use.
+type Cache[K comparable, T comparable] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	lru     *list.List // of K, most recently used first
+	entries map[K]*cacheEntry[T]
+}
+
+// an entry of the cache
+type cacheEntry[T comparable] struct {
+	set     Set[T]
+	expires time.Time // zero if the entry does not expire
+	elem    *list.Element
+}
+
+// ----- constructor -----
+
+// NewCache creates a new cache for at most size entries, which expire after
+// the time to live ttl. A ttl of 0 means that entries do not expire.
+func NewCache[K comparable, T comparable](size int, ttl time.Duration) *Cache[K, T] {
+	size = max(size, 0)
+	return &Cache[K, T]{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		lru:     list.New(),
+		entries: make(map[K]*cacheEntry[T], size),
+	}
+}
+
+// lookup returns the live entry for the key and marks it as recently used.
+// Expired entries are removed. The caller must hold the lock.
+func (c *Cache[K, T]) lookup(key K) *cacheEntry[T] {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if !e.expires.IsZero() && !c.now().Before(e.expires) {
+		c.delete(key, e)
+		return nil
+	}
+	c.lru.MoveToFront(e.elem)
+	return e
+}
+
+// insert creates a new entry for the key, evicting the least recently used
+// entry if the cache is full. The caller must hold the lock.
+func (c *Cache[K, T]) insert(key K, s Set[T], ttl time.Duration) *cacheEntry[T] {
+	if e, ok := c.entries[key]; ok {
+		c.delete(key, e)
+	}
+	for len(c.entries) >= c.size && c.lru.Len() > 0 {
+		k := c.lru.Back().Value.(K)
+		c.delete(k, c.entries[k])
+	}
+	e := &cacheEntry[T]{set: s, elem: c.lru.PushFront(key)}
+	if ttl > 0 {
+		e.expires = c.now().Add(ttl)
+	}
+	c.entries[key] = e
+	return e
+}
+
+func (c *Cache[K, T]) delete(key K, e *cacheEntry[T]) {
+	c.lru.Remove(e.elem)
+	delete(c.entries, key)
+}
+
+// ----- methods that modify the cache -----
+
+// Put stores a copy of the set s under the given key, using the default time
+// to live of the cache.
+func (c *Cache[K, T]) Put(key K, s Set[T]) {
+	c.PutTTL(key, s, c.ttl)
+}
+
+// PutTTL stores a copy of the set s under the given key, which expires after
+// the time to live ttl. A ttl of 0 means that the entry does not expire.
+func (c *Cache[K, T]) PutTTL(key K, s Set[T], ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.insert(key, s.Copy(), ttl)
+}
+
+// AddToSet atomically adds one or more elements to the set stored under the
+// given key. If there is no such entry, a new one is created with the default
+// time to live. The expiry time of an existing entry is not changed.
+func (c *Cache[K, T]) AddToSet(key K, e ...T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ent := c.lookup(key)
+	if ent == nil {
+		ent = c.insert(key, New[T](), c.ttl)
+	}
+	ent.set.Add(e...)
+}
+
+// RemoveFromSet atomically removes one or more elements from the set stored
+// under the given key.
+func (c *Cache[K, T]) RemoveFromSet(key K, e ...T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ent := c.lookup(key); ent != nil {
+		ent.set.Remove(e...)
+	}
+}
+
+// Delete removes the entry for the given key.
+func (c *Cache[K, T]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.delete(key, e)
+	}
+}
+
+// ----- methods that query the cache -----
+
+// Get returns a copy of the set stored under the given key. The second return
+// value is false if there is no live entry for the key.
+func (c *Cache[K, T]) Get(key K) (Set[T], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.lookup(key); e != nil {
+		return e.set.Copy(), true
+	}
+	return New[T](), false
+}
+
+// Contains checks if the set stored under the given key contains one or more
+// elements. The return value is true only if all given elements are in the set.
+func (c *Cache[K, T]) Contains(key K, e ...T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ent := c.lookup(key)
+	return ent != nil && ent.set.Contains(e...)
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// which were not yet removed.
+func (c *Cache[K, T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
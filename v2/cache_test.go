@@ -0,0 +1,63 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewCache[string, string](2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.AddToSet("alice", "read", "write")
+	c.Put("bob", New("read"))
+	if !c.Contains("alice", "read", "write") || !c.Contains("bob", "read") || c.Contains("bob", "write") {
+		t.Errorf("AddToSet/Put/Contains failed.\n")
+	}
+
+	// the returned set is a copy
+	s, ok := c.Get("bob")
+	s.Add("admin")
+	if !ok || c.Contains("bob", "admin") {
+		t.Errorf("Get failed: got %v/%t.\n", s, ok)
+	}
+
+	// alice was used more recently than bob, so bob is evicted
+	c.Contains("alice", "read")
+	c.PutTTL("carol", New("read"), 0)
+	if _, ok := c.Get("bob"); ok || c.Len() != 2 {
+		t.Errorf("LRU eviction failed: bob is still in the cache.\n")
+	}
+
+	c.RemoveFromSet("alice", "write")
+	if c.Contains("alice", "write") {
+		t.Errorf("RemoveFromSet failed.\n")
+	}
+
+	// alice expires, carol has no time to live
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("alice"); ok {
+		t.Errorf("TTL expiry failed: alice is still in the cache.\n")
+	}
+	if _, ok := c.Get("carol"); !ok {
+		t.Errorf("TTL expiry failed: carol has expired.\n")
+	}
+
+	c.Delete("carol")
+	if c.Len() != 0 {
+		t.Errorf("Delete failed: cache has %d entries.\n", c.Len())
+	}
+
+	// a negative size is treated as 0
+	n := NewCache[string, string](-1, 0)
+	n.Put("alice", New("read"))
+	if n.Len() > 1 {
+		t.Errorf("NewCache failed for a negative size: cache has %d entries.\n", n.Len())
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+// ----- Complement definition -----
+
+// A Complement is a set which contains everything except the elements of a
+// finite set. Together with Set, it allows the correct composition of allow
+// lists and deny lists. Operations which are guaranteed to yield a finite set
+// return a Set, all others return a Complement.
+type Complement[T comparable] struct {
+	except Set[T]
+}
+
+// ----- constructors -----
+
+// NewComplement creates a set which contains everything except the argument
+// values.
+func NewComplement[T comparable](e ...T) Complement[T] {
+	return Complement[T]{except: New(e...)}
+}
+
+// ComplementOf creates a set which contains everything except the elements
+// of s. The set s is copied.
+func ComplementOf[T comparable](s Set[T]) Complement[T] {
+	return Complement[T]{except: s.Copy()}
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if none of the given elements is excluded.
+func (c Complement[T]) Contains(e ...T) bool {
+	return !c.except.ContainsAny(e...)
+}
+
+// Except returns a copy of the finite set of excluded elements, which is the
+// complement of c.
+func (c Complement[T]) Except() Set[T] {
+	return c.except.Copy()
+}
+
+// IsEqual tests if two complement sets are equal.
+func (c Complement[T]) IsEqual(d Complement[T]) bool {
+	return c.except.IsEqual(d.except)
+}
+
+// Union returns the union of c and the finite set s, which contains
+// everything except the excluded elements not in s.
+func (c Complement[T]) Union(s Set[T]) Complement[T] {
+	return Complement[T]{except: c.except.Diff(s)}
+}
+
+// Intersect returns the intersection of c and the finite set s, which are
+// the elements of s which are not excluded.
+func (c Complement[T]) Intersect(s Set[T]) Set[T] {
+	return s.Diff(c.except)
+}
+
+// Diff returns the difference of c and the finite set s, which contains
+// everything except the excluded elements and the elements of s.
+func (c Complement[T]) Diff(s Set[T]) Complement[T] {
+	return Complement[T]{except: c.except.Union(s)}
+}
+
+// DiffFrom returns the difference of the finite set s and c, which are the
+// elements of s which are excluded by c.
+func (c Complement[T]) DiffFrom(s Set[T]) Set[T] {
+	return s.Intersect(c.except)
+}
+
+// UnionComplement returns the union of two complement sets, which excludes
+// the elements excluded by both.
+func (c Complement[T]) UnionComplement(d Complement[T]) Complement[T] {
+	return Complement[T]{except: c.except.Intersect(d.except)}
+}
+
+// IntersectComplement returns the intersection of two complement sets, which
+// excludes the elements excluded by either.
+func (c Complement[T]) IntersectComplement(d Complement[T]) Complement[T] {
+	return Complement[T]{except: c.except.Union(d.except)}
+}
+
+// String returns a textual representation of the set in a string.
+func (c Complement[T]) String() string {
+	return "∁" + c.except.String()
+}
@@ -0,0 +1,49 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"testing"
+)
+
+func TestComplement(t *testing.T) {
+	deny := NewComplement(3, 4)
+	allow := New(1, 2, 3)
+
+	if !deny.Contains(1, 2, 5) || deny.Contains(1, 3) {
+		t.Errorf("Contains failed for %v.\n", deny)
+	}
+
+	if r := deny.Intersect(allow); !r.IsEqual(New(1, 2)) {
+		t.Errorf("Intersect failed: got %v.\n", r)
+	}
+	if r := deny.Union(allow); !r.IsEqual(NewComplement(4)) {
+		t.Errorf("Union failed: got %v.\n", r)
+	}
+	if r := deny.Diff(allow); !r.IsEqual(NewComplement(1, 2, 3, 4)) {
+		t.Errorf("Diff failed: got %v.\n", r)
+	}
+	if r := deny.DiffFrom(allow); !r.IsEqual(New(3)) {
+		t.Errorf("DiffFrom failed: got %v.\n", r)
+	}
+
+	other := ComplementOf(New(4, 5))
+	if r := deny.UnionComplement(other); !r.IsEqual(NewComplement(4)) {
+		t.Errorf("UnionComplement failed: got %v.\n", r)
+	}
+	if r := deny.IntersectComplement(other); !r.IsEqual(NewComplement(3, 4, 5)) {
+		t.Errorf("IntersectComplement failed: got %v.\n", r)
+	}
+
+	e := deny.Except()
+	e.Add(9)
+	if !deny.Contains(9) {
+		t.Errorf("Except failed: returned set is not a copy.\n")
+	}
+	if s := NewComplement(1).String(); s != "∁{ 1 }" {
+		t.Errorf("String failed: got %v.\n", s)
+	}
+}
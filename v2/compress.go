@@ -0,0 +1,171 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/hweidner/set/v2/internal/elemcodec"
+)
+
+// ErrCompressedFormat is returned by DecodeCompressed for input which does not
+// start with the header of a compressed set.
+var ErrCompressedFormat = errors.New("set: not a compressed set")
+
+// ----- compressed encoding -----
+
+// The compressed format consists of the magic bytes "SETZ", the number of
+// elements and the size of the uncompressed element stream as uvarints, and
+// the gzip compressed element stream. The elements are encoded like for
+// Digest. The header allows decoders to presize the set, and together with
+// the gzip checksum to detect truncated snapshots.
+
+// the magic bytes of the compressed format
+const compressedMagic = "SETZ"
+
+// CompressOptions holds the options of EncodeCompressed.
+type CompressOptions struct {
+	// Level is the gzip compression level from gzip.BestSpeed to
+	// gzip.BestCompression. 0 selects gzip.DefaultCompression.
+	Level int
+
+	// Sorted writes the elements in the order of their encodings, so equal
+	// sets have equal encodings. It needs memory for all encodings.
+	Sorted bool
+}
+
+// EncodeCompressed writes the set to w in the compressed format. For sets of
+// element types without a stable encoding, nothing is written and
+// ErrNoStableEncoding is returned.
+func (s Set[T]) EncodeCompressed(w io.Writer, opts CompressOptions) error {
+	level := opts.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	// the first pass checks the element type and computes the size
+	var size uint64
+	var b []byte
+	for k := range s.set {
+		var ok bool
+		if b, ok = elemcodec.AppendValue(b[:0], k); !ok {
+			var zero T
+			return fmt.Errorf("%w: %T", ErrNoStableEncoding, zero)
+		}
+		size += uint64(len(b))
+	}
+
+	hdr := append([]byte(compressedMagic), binary.AppendUvarint(nil, uint64(len(s.set)))...)
+	hdr = binary.AppendUvarint(hdr, size)
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	z, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(z)
+	if opts.Sorted {
+		enc := make([][]byte, 0, len(s.set))
+		for k := range s.set {
+			e, _ := elemcodec.AppendValue(nil, k)
+			enc = append(enc, e)
+		}
+		slices.SortFunc(enc, bytes.Compare)
+		for _, e := range enc {
+			bw.Write(e)
+		}
+	} else {
+		for k := range s.set {
+			b, _ = elemcodec.AppendValue(b[:0], k)
+			bw.Write(b)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// DecodeCompressed reads a set in the compressed format from r. Errors in the
+// element stream are returned as a *DecodeError with the position of the
+// element, and truncated or corrupted input is detected by the gzip
+// checksum and the header. For element types without a stable encoding,
+// ErrNoStableEncoding is returned.
+func DecodeCompressed[T comparable](r io.Reader) (Set[T], error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(compressedMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != compressedMagic {
+		return New[T](), ErrCompressedFormat
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return New[T](), ErrCompressedFormat
+	}
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return New[T](), ErrCompressedFormat
+	}
+	z, err := gzip.NewReader(br)
+	if err != nil {
+		return New[T](), err
+	}
+	z.Multistream(false)
+
+	// the header is not trusted for the initial size of the map
+	s := Set[T]{set: make(map[T]struct{}, min(n, 1<<16))}
+	cr := &countingReader{r: bufio.NewReader(z)}
+	for i := uint64(0); i < n; i++ {
+		e, err := elemcodec.ReadValue[T](cr, int(min(size, 1<<30)))
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		} else if errors.Is(err, elemcodec.ErrNoValueEncoding) {
+			return s, fmt.Errorf("%w: %T", ErrNoStableEncoding, e)
+		}
+		if err != nil {
+			return s, &DecodeError{Pos: int(i), Err: err}
+		}
+		s.set[e] = struct{}{}
+	}
+	// reading to the end verifies the gzip checksum
+	if _, err := cr.r.ReadByte(); err != io.EOF {
+		if err == nil {
+			err = errors.New("trailing data")
+		}
+		return s, &DecodeError{Pos: int(n), Err: err}
+	}
+	if cr.n != size {
+		return s, &DecodeError{Pos: int(n), Err: fmt.Errorf("size %d does not match the header %d", cr.n, size)}
+	}
+	return s, nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r *bufio.Reader
+	n uint64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
@@ -0,0 +1,79 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestCompressed(t *testing.T) {
+	s := New[string]()
+	for i := 0; i < 5000; i++ {
+		s.Add("host" + strconv.Itoa(i) + ".example.com")
+	}
+	var b bytes.Buffer
+	if err := s.EncodeCompressed(&b, CompressOptions{}); err != nil {
+		t.Fatalf("EncodeCompressed failed: %v.\n", err)
+	}
+	if b.Len() > 5000*5 {
+		t.Errorf("EncodeCompressed failed: %d bytes for %d elements.\n", b.Len(), s.Len())
+	}
+	r, err := DecodeCompressed[string](bytes.NewReader(b.Bytes()))
+	if err != nil || !r.IsEqual(s) {
+		t.Errorf("DecodeCompressed failed: %v.\n", err)
+	}
+
+	i := New[int64](-1, 0, 1<<40)
+	b.Reset()
+	if err := i.EncodeCompressed(&b, CompressOptions{Level: gzip.BestSpeed}); err != nil {
+		t.Fatalf("EncodeCompressed failed: %v.\n", err)
+	}
+	if r, err := DecodeCompressed[int64](&b); err != nil || !r.IsEqual(i) {
+		t.Errorf("DecodeCompressed failed: got %v, %v.\n", r, err)
+	}
+
+	// sorted encodings of equal sets are equal
+	var x, y bytes.Buffer
+	New(1, 2, 3, 4, 5).EncodeCompressed(&x, CompressOptions{Sorted: true})
+	New(5, 4, 3, 2, 1).EncodeCompressed(&y, CompressOptions{Sorted: true})
+	if !bytes.Equal(x.Bytes(), y.Bytes()) {
+		t.Errorf("EncodeCompressed failed: sorted encodings differ.\n")
+	}
+
+	// truncated and corrupted input
+	data := x.Bytes()
+	if _, err := DecodeCompressed[int](bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Errorf("DecodeCompressed failed: truncated input accepted.\n")
+	}
+	bad := bytes.Clone(data)
+	bad[len(bad)-5] ^= 0xff // the uncompressed size in the gzip trailer
+	if _, err := DecodeCompressed[int](bytes.NewReader(bad)); err == nil {
+		t.Errorf("DecodeCompressed failed: corrupted input accepted.\n")
+	}
+	if _, err := DecodeCompressed[int](bytes.NewReader([]byte("SETX\x00\x00"))); !errors.Is(err, ErrCompressedFormat) {
+		t.Errorf("DecodeCompressed failed: got %v for a wrong magic.\n", err)
+	}
+
+	// the element count must match the stream
+	var wrong bytes.Buffer
+	New(1, 2).EncodeCompressed(&wrong, CompressOptions{})
+	wrong.Bytes()[4] = 3
+	var de *DecodeError
+	if _, err := DecodeCompressed[int](&wrong); !errors.As(err, &de) || de.Pos != 2 {
+		t.Errorf("DecodeCompressed failed: got %v for a wrong count.\n", err)
+	}
+
+	if err := New(testPoint{1, 2}).EncodeCompressed(&b, CompressOptions{}); !errors.Is(err, ErrNoStableEncoding) {
+		t.Errorf("EncodeCompressed failed: got %v for a struct set.\n", err)
+	}
+	if _, err := DecodeCompressed[testPoint](bytes.NewReader(data)); !errors.Is(err, ErrNoStableEncoding) {
+		t.Errorf("DecodeCompressed failed: got %v for a struct set.\n", err)
+	}
+}
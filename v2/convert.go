@@ -0,0 +1,128 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hweidner/set/v2/hll"
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+// ----- conversions from other set encodings -----
+
+// FromBoolMap creates a new set from a map[T]bool, as used by legacy code to
+// represent sets. Keys mapped to false are treated as absent.
+func FromBoolMap[T comparable](m map[T]bool) Set[T] {
+	s := New[T]()
+	for k, v := range m {
+		if v {
+			s.set[k] = struct{}{}
+		}
+	}
+	return s
+}
+
+// FromStructMap creates a new set from the keys of a map[T]struct{}. The map
+// is copied.
+func FromStructMap[T comparable](m map[T]struct{}) Set[T] {
+	s := Set[T]{set: make(map[T]struct{}, len(m))}
+	for k := range m {
+		s.set[k] = struct{}{}
+	}
+	return s
+}
+
+// FromSyncMap creates a new set from the keys of a sync.Map. Keys which are
+// not of type T are not added; like with FromAny, the returned error joins a
+// *DecodeError wrapping ErrWrongType for each of them. Their positions count
+// the keys in the order of the Range method of sync.Map, which is undefined.
+// As with Range, concurrent modifications may or may not be reflected.
+func FromSyncMap[T comparable](m *sync.Map) (Set[T], error) {
+	s := New[T]()
+	var errs []error
+	pos := 0
+	m.Range(func(k, _ any) bool {
+		if e, ok := k.(T); ok {
+			s.set[e] = struct{}{}
+		} else {
+			errs = append(errs, &DecodeError{Pos: pos, Err: fmt.Errorf("%w: %T", ErrWrongType, k)})
+		}
+		pos++
+		return true
+	})
+	return s, errors.Join(errs...)
+}
+
+// ----- conversions to other set encodings -----
+
+// BoolMap returns the set as a new map[T]bool, where all elements map to
+// true.
+func (s Set[T]) BoolMap() map[T]bool {
+	m := make(map[T]bool, len(s.set))
+	for k := range s.set {
+		m[k] = true
+	}
+	return m
+}
+
+// StructMap returns the set as a new map[T]struct{}.
+func (s Set[T]) StructMap() map[T]struct{} {
+	m := make(map[T]struct{}, len(s.set))
+	for k := range s.set {
+		m[k] = struct{}{}
+	}
+	return m
+}
+
+// StoreInSyncMap stores all elements of the set as keys of the sync.Map m,
+// with the given value. Existing keys are overwritten.
+func (s Set[T]) StoreInSyncMap(m *sync.Map, value any) {
+	for k := range s.set {
+		m.Store(k, value)
+	}
+}
+
+// ToHLL returns a HyperLogLog sketch of the given precision with the elements
+// of the set, which can be merged with sketches of streams too large for a
+// set. Strings are added like with Sketch.AddString. Integers have stable
+// hashes as well, but other element types are hashed with a per-process
+// seed, so their sketches can only be merged within one process.
+func (s Set[T]) ToHLL(precision int) *hll.Sketch {
+	h := hll.New(precision)
+	for k := range s.set {
+		h.AddHash(hash.Of(k))
+	}
+	return h
+}
+
+// ----- inverted indexes -----
+
+// Invert returns the inverted index of a map from documents to their sets of
+// terms, i.e. a map from each term to the set of documents containing it.
+// It is built in one pass over the postings; the result map is presized for
+// the largest set of terms, which is a lower bound of the number of distinct
+// terms. Documents with empty or nil sets do not appear in the result.
+func Invert[D, T comparable](m map[D]Set[T]) map[T]Set[D] {
+	n := 0
+	for _, terms := range m {
+		n = max(n, len(terms.set))
+	}
+	r := make(map[T]Set[D], n)
+	for d, terms := range m {
+		for t := range terms.set {
+			docs, ok := r[t]
+			if !ok {
+				docs = Set[D]{set: map[D]struct{}{}}
+				r[t] = docs
+			}
+			docs.set[d] = struct{}{}
+		}
+	}
+	return r
+}
@@ -0,0 +1,96 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/hweidner/set/v2/hll"
+)
+
+func TestBoolMap(t *testing.T) {
+	s := FromBoolMap(map[string]bool{"a": true, "b": false, "c": true})
+	if !s.IsEqual(New("a", "c")) {
+		t.Errorf("FromBoolMap failed: got %v.\n", s)
+	}
+	m := s.BoolMap()
+	if len(m) != 2 || !m["a"] || m["b"] {
+		t.Errorf("BoolMap failed: got %v.\n", m)
+	}
+}
+
+func TestStructMap(t *testing.T) {
+	raw := map[int]struct{}{1: {}, 2: {}}
+	s := FromStructMap(raw)
+	raw[3] = struct{}{}
+	if !s.IsEqual(New(1, 2)) {
+		t.Errorf("FromStructMap failed: got %v, or the map is shared.\n", s)
+	}
+	m := s.StructMap()
+	m[4] = struct{}{}
+	if s.Contains(4) {
+		t.Errorf("StructMap failed: the map is shared with the set.\n")
+	}
+}
+
+func TestSyncMap(t *testing.T) {
+	var m sync.Map
+	New(1, 2, 3).StoreInSyncMap(&m, true)
+	m.Store("foreign", true)
+	s, err := FromSyncMap[int](&m)
+	var de *DecodeError
+	if !s.IsEqual(New(1, 2, 3)) || !errors.Is(err, ErrWrongType) || !errors.As(err, &de) {
+		t.Errorf("FromSyncMap failed: got %v/%v.\n", s, err)
+	}
+	if s, err := FromSyncMap[string](&m); !s.IsEqual(New("foreign")) || len(err.(interface{ Unwrap() []error }).Unwrap()) != 3 {
+		t.Errorf("FromSyncMap failed: got %v/%v.\n", s, err)
+	}
+}
+
+func TestToHLL(t *testing.T) {
+	s := New[string]()
+	for i := 0; i < 10000; i++ {
+		s.Add("user" + strconv.Itoa(i))
+	}
+	h := s.ToHLL(12)
+	if c := h.Count(); c < 9500 || c > 10500 || h.Precision() != 12 {
+		t.Errorf("ToHLL failed: got estimate %d.\n", c)
+	}
+
+	// string sketches merge with sketches of streams
+	stream := hll.New(12)
+	for i := 5000; i < 15000; i++ {
+		stream.AddString("user" + strconv.Itoa(i))
+	}
+	if err := h.Merge(stream); err != nil {
+		t.Fatalf("Merge failed: %v.\n", err)
+	}
+	if c := h.Count(); c < 14250 || c > 15750 {
+		t.Errorf("ToHLL failed: got estimate %d for the union.\n", c)
+	}
+	if c := New(1, 2, 3).ToHLL(4).Count(); c != 3 {
+		t.Errorf("ToHLL failed: got estimate %d for 3 integers.\n", c)
+	}
+}
+
+func TestInvert(t *testing.T) {
+	m := map[string]Set[string]{
+		"d1": New("go", "set"),
+		"d2": New("go", "map"),
+		"d3": New[string](),
+		"d4": {},
+	}
+	r := Invert(m)
+	if len(r) != 3 || !r["go"].IsEqual(New("d1", "d2")) || !r["set"].IsEqual(New("d1")) || !r["map"].IsEqual(New("d2")) {
+		t.Errorf("Invert failed: got %v.\n", r)
+	}
+	if r := Invert(map[int]Set[int]{}); len(r) != 0 {
+		t.Errorf("Invert failed: got %v for an empty map.\n", r)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// ----- COWSet definition -----
+
+// A COWSet is a copy-on-write set, which is safe for concurrent use. Readers
+// load the current version of the set with a single atomic operation and are
+// never blocked, while writers copy the set, modify the copy and swap it in.
+// It suits read-mostly sets which are replaced as a whole, like allowlists
+// refreshed from a file or a remote store. Modifications take O(n) time, so
+// several of them should be batched with Update.
+type COWSet[T comparable] struct {
+	mu  sync.Mutex // serializes the writers
+	cur atomic.Pointer[Set[T]]
+}
+
+// ----- constructors -----
+
+// NewCOW creates a new copy-on-write set and initializes it with the argument
+// values.
+func NewCOW[T comparable](e ...T) *COWSet[T] {
+	return NewCOWFrom(New(e...))
+}
+
+// NewCOWFrom creates a new copy-on-write set with s as its first version.
+// The set s must not be modified afterwards.
+func NewCOWFrom[T comparable](s Set[T]) *COWSet[T] {
+	c := &COWSet[T]{}
+	c.cur.Store(&s)
+	return c
+}
+
+// ----- methods that modify the receiver -----
+
+// Update calls f with a copy of the current version, and makes the copy the
+// new version when f returns.
+func (c *COWSet[T]) Update(f func(s Set[T])) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.cur.Load().Copy()
+	f(s)
+	c.cur.Store(&s)
+}
+
+// Add adds one or more elements to the given set.
+func (c *COWSet[T]) Add(e ...T) {
+	c.Update(func(s Set[T]) { s.Add(e...) })
+}
+
+// Remove removes one or more elements from the given set.
+func (c *COWSet[T]) Remove(e ...T) {
+	c.Update(func(s Set[T]) { s.Remove(e...) })
+}
+
+// Swap replaces the current version with s, and returns the previous one.
+// The set s must not be modified afterwards.
+func (c *COWSet[T]) Swap(s Set[T]) Set[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return *c.cur.Swap(&s)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Load returns the current version of the set. It stays unchanged by later
+// modifications of the COWSet, and must not be modified itself.
+func (c *COWSet[T]) Load() Set[T] {
+	return *c.cur.Load()
+}
+
+// IsEmpty tests if the set is empty.
+func (c *COWSet[T]) IsEmpty() bool {
+	return c.Load().IsEmpty()
+}
+
+// Len returns the length of the set.
+func (c *COWSet[T]) Len() int {
+	return c.Load().Len()
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (c *COWSet[T]) Contains(e ...T) bool {
+	return c.Load().Contains(e...)
+}
+
+// ContainsAny checks if a set contains at least one of the given elements.
+func (c *COWSet[T]) ContainsAny(e ...T) bool {
+	return c.Load().ContainsAny(e...)
+}
+
+// All returns an iterator to all elements of the current version, in no
+// particular order.
+func (c *COWSet[T]) All() iter.Seq[T] {
+	return c.Load().All()
+}
+
+// String returns a textual representation of the current version.
+func (c *COWSet[T]) String() string {
+	return c.Load().String()
+}
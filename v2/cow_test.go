@@ -0,0 +1,60 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCOWSet(t *testing.T) {
+	c := NewCOW(1, 2, 3)
+	v1 := c.Load()
+	c.Add(4)
+	c.Remove(1)
+	if !c.Contains(2, 3, 4) || c.Contains(1) || c.Len() != 3 || !c.ContainsAny(0, 4) {
+		t.Errorf("Add/Remove failed: got %v.\n", c)
+	}
+	if !v1.IsEqual(New(1, 2, 3)) {
+		t.Errorf("Add modified a loaded version: got %v.\n", v1)
+	}
+	c.Update(func(s Set[int]) {
+		s.Clear()
+		s.Add(7, 8)
+	})
+	if !c.Load().IsEqual(New(7, 8)) {
+		t.Errorf("Update failed: got %v.\n", c)
+	}
+	if old := c.Swap(New(9)); !old.IsEqual(New(7, 8)) || !c.Load().IsEqual(New(9)) || c.IsEmpty() {
+		t.Errorf("Swap failed: got %v, old %v.\n", c, old)
+	}
+}
+
+func TestCOWSetConcurrent(t *testing.T) {
+	c := NewCOW[int]()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				c.Add(w*100 + i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				for range c.All() {
+				}
+				c.Contains(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Len() != 400 {
+		t.Errorf("Add failed: got %d elements.\n", c.Len())
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package crdt
+
+import (
+	"encoding/json"
+
+	"github.com/hweidner/set/v2"
+)
+
+// ----- GSet definition -----
+
+// A GSet is a grow-only set, whose elements can only be added. Its merge is
+// the union of the states, so it needs no metadata besides the elements.
+type GSet[T comparable] struct {
+	elems set.Set[T]
+}
+
+// NewGSet creates a new grow-only set and initializes it with the argument
+// values.
+func NewGSet[T comparable](e ...T) *GSet[T] {
+	return &GSet[T]{elems: set.New(e...)}
+}
+
+// Add adds one or more elements to the given set.
+func (s *GSet[T]) Add(e ...T) {
+	s.elems.Add(e...)
+}
+
+// Merge adds the elements of another replica to the given set.
+func (s *GSet[T]) Merge(o *GSet[T]) {
+	for x := range o.elems.All() {
+		s.elems.Add(x)
+	}
+}
+
+// IsEmpty tests if the set is empty.
+func (s *GSet[T]) IsEmpty() bool {
+	return s.elems.IsEmpty()
+}
+
+// Len returns the number of elements of the set.
+func (s *GSet[T]) Len() int {
+	return s.elems.Len()
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *GSet[T]) Contains(e ...T) bool {
+	return s.elems.Contains(e...)
+}
+
+// Set returns the elements as a new set.
+func (s *GSet[T]) Set() set.Set[T] {
+	return s.elems.Copy()
+}
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *GSet[T]) Copy() *GSet[T] {
+	return &GSet[T]{elems: s.elems.Copy()}
+}
+
+// MarshalJSON implements the json.Marshaler interface. The state is encoded
+// as an array of the elements.
+func (s *GSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.elems.List())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It replaces the
+// state of the replica with the decoded one.
+func (s *GSet[T]) UnmarshalJSON(b []byte) error {
+	var l []T
+	if err := json.Unmarshal(b, &l); err != nil {
+		return err
+	}
+	s.elems = set.New(l...)
+	return nil
+}
+
+// ----- TwoPSet definition -----
+
+// A TwoPSet is a two-phase set, made of a grow-only set of added elements
+// and a grow-only set of removed elements, the tombstones. An element is in
+// the set if it was added and never removed; once removed, an element can
+// not be added again. A remove wins over a concurrent add.
+type TwoPSet[T comparable] struct {
+	added, removed set.Set[T]
+}
+
+// NewTwoPSet creates a new two-phase set and initializes it with the
+// argument values.
+func NewTwoPSet[T comparable](e ...T) *TwoPSet[T] {
+	return &TwoPSet[T]{added: set.New(e...), removed: set.New[T]()}
+}
+
+// Add adds one or more elements to the given set. Removed elements stay
+// removed.
+func (s *TwoPSet[T]) Add(e ...T) {
+	s.added.Add(e...)
+}
+
+// Remove removes one or more elements from the given set for good. The
+// elements need not have been added yet, so a remove which arrives before
+// the add it refers to is not lost.
+func (s *TwoPSet[T]) Remove(e ...T) {
+	s.removed.Add(e...)
+}
+
+// Merge adds the added and removed elements of another replica to the
+// given set.
+func (s *TwoPSet[T]) Merge(o *TwoPSet[T]) {
+	for x := range o.added.All() {
+		s.added.Add(x)
+	}
+	for x := range o.removed.All() {
+		s.removed.Add(x)
+	}
+}
+
+// IsEmpty tests if the set is empty.
+func (s *TwoPSet[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Len returns the number of elements of the set.
+func (s *TwoPSet[T]) Len() int {
+	n := 0
+	for x := range s.added.All() {
+		if !s.removed.Contains(x) {
+			n++
+		}
+	}
+	return n
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *TwoPSet[T]) Contains(e ...T) bool {
+	return s.added.Contains(e...) && !s.removed.ContainsAny(e...)
+}
+
+// Removed checks if an element was removed, i.e. if it can not be added
+// again.
+func (s *TwoPSet[T]) Removed(e T) bool {
+	return s.removed.Contains(e)
+}
+
+// Set returns the elements as a new set.
+func (s *TwoPSet[T]) Set() set.Set[T] {
+	return s.added.Diff(s.removed)
+}
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *TwoPSet[T]) Copy() *TwoPSet[T] {
+	return &TwoPSet[T]{added: s.added.Copy(), removed: s.removed.Copy()}
+}
+
+// the JSON encoding of a TwoPSet
+type twoPSetJSON[T comparable] struct {
+	Added   []T `json:"added"`
+	Removed []T `json:"removed"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *TwoPSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(twoPSetJSON[T]{Added: s.added.List(), Removed: s.removed.List()})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It replaces the
+// state of the replica with the decoded one.
+func (s *TwoPSet[T]) UnmarshalJSON(b []byte) error {
+	var j twoPSetJSON[T]
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	s.added, s.removed = set.New(j.Added...), set.New(j.Removed...)
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package crdt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+func TestGSet(t *testing.T) {
+	a, b := NewGSet(1, 2), NewGSet(3)
+	b.Add(4)
+	a.Merge(b)
+	b.Merge(a)
+	b.Merge(b.Copy())
+	if !a.Set().IsEqual(set.New(1, 2, 3, 4)) || !b.Set().IsEqual(a.Set()) {
+		t.Errorf("Merge failed: got %v and %v.\n", a.Set(), b.Set())
+	}
+	if !a.Contains(1, 4) || a.Contains(5) || a.Len() != 4 || a.IsEmpty() {
+		t.Errorf("Contains failed: got %v.\n", a.Set())
+	}
+
+	j, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v.\n", err)
+	}
+	c := NewGSet[int]()
+	if err := json.Unmarshal(j, c); err != nil || !c.Set().IsEqual(a.Set()) {
+		t.Errorf("UnmarshalJSON failed: got %v, %v.\n", c.Set(), err)
+	}
+}
+
+func TestTwoPSet(t *testing.T) {
+	a, b := NewTwoPSet("x", "y"), NewTwoPSet[string]()
+	b.Merge(a)
+	b.Remove("x")
+	a.Add("z")
+	a.Merge(b)
+	b.Merge(a)
+	if !a.Set().IsEqual(set.New("y", "z")) || !b.Set().IsEqual(a.Set()) || a.Len() != 2 {
+		t.Errorf("Merge failed: got %v and %v.\n", a.Set(), b.Set())
+	}
+
+	// a removed element can not be added again
+	a.Add("x")
+	if a.Contains("x") || !a.Removed("x") || !a.Contains("y", "z") {
+		t.Errorf("Add failed after Remove: got %v.\n", a.Set())
+	}
+
+	// a remove which arrives before the add wins
+	c := NewTwoPSet[string]()
+	c.Remove("w")
+	d := NewTwoPSet("w")
+	d.Merge(c)
+	if !d.IsEmpty() {
+		t.Errorf("Merge failed: got %v.\n", d.Set())
+	}
+
+	j, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v.\n", err)
+	}
+	e := NewTwoPSet[string]()
+	if err := json.Unmarshal(j, e); err != nil || !e.Set().IsEqual(a.Set()) || !e.Removed("x") {
+		t.Errorf("UnmarshalJSON failed: got %v, %v.\n", e.Set(), err)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package crdt
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"time"
+
+	"github.com/hweidner/set/v2"
+)
+
+// ----- LWWSet definition -----
+
+// A Clock returns the current time for the timestamps of an LWWSet. It need
+// not be the wall clock, but the clocks of all replicas should be roughly
+// synchronized, like a hybrid logical clock, as the latest timestamp wins.
+type Clock func() time.Time
+
+// A Stamp is the timestamp of an add or remove operation, in nanoseconds
+// since the Unix epoch, together with the replica which issued it. Equal
+// times are ordered by the replica ID, so the order is total.
+type Stamp struct {
+	Time    int64  `json:"t"`
+	Replica string `json:"r"`
+}
+
+// compare orders two stamps.
+func (a Stamp) compare(b Stamp) int {
+	return cmp.Or(cmp.Compare(a.Time, b.Time), cmp.Compare(a.Replica, b.Replica))
+}
+
+// IsZero tests if the stamp is the zero stamp of an operation which never
+// happened.
+func (a Stamp) IsZero() bool {
+	return a == Stamp{}
+}
+
+// the latest add and remove of an element
+type lwwEntry struct {
+	add, remove Stamp
+}
+
+// An LWWSet is a last-writer-wins element set. It keeps the timestamp of
+// the latest add and remove of each element, and an element is in the set
+// if its latest add is later than its latest remove. Elements can be added
+// and removed any number of times, but of concurrent operations on an
+// element, only the one with the latest timestamp counts; for an add and a
+// remove with the same stamp, the remove wins. Removed elements are kept as
+// tombstones.
+type LWWSet[T comparable] struct {
+	replica string
+	clock   Clock
+	last    int64 // the time of the latest stamp issued by the replica
+	entries map[T]lwwEntry
+}
+
+// ----- constructor -----
+
+// NewLWWSet creates a new, empty replica of a last-writer-wins set. The
+// replica ID must be unique among all replicas of the set. A nil clock
+// selects time.Now.
+func NewLWWSet[T comparable](replica string, clock Clock) *LWWSet[T] {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &LWWSet[T]{replica: replica, clock: clock, entries: map[T]lwwEntry{}}
+}
+
+// now returns a new stamp of the replica at the current time of the clock.
+// The stamps returned by now increase strictly, even if the clock goes
+// backwards.
+func (s *LWWSet[T]) now() Stamp {
+	s.last = max(s.clock().UnixNano(), s.last+1)
+	return Stamp{s.last, s.replica}
+}
+
+// at returns the stamp of the replica at the given time.
+func (s *LWWSet[T]) at(t time.Time) Stamp {
+	st := Stamp{t.UnixNano(), s.replica}
+	s.last = max(s.last, st.Time)
+	return st
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the replica, at the current time of the
+// clock.
+func (s *LWWSet[T]) Add(e ...T) {
+	s.add(s.now(), e)
+}
+
+// AddAt adds one or more elements to the replica at the given time, like
+// the time of an offline change of a client.
+func (s *LWWSet[T]) AddAt(t time.Time, e ...T) {
+	s.add(s.at(t), e)
+}
+
+// add records an add of the elements with the stamp.
+func (s *LWWSet[T]) add(st Stamp, e []T) {
+	for _, x := range e {
+		if ent := s.entries[x]; st.compare(ent.add) > 0 {
+			ent.add = st
+			s.entries[x] = ent
+		}
+	}
+}
+
+// Remove removes one or more elements from the replica, at the current time
+// of the clock.
+func (s *LWWSet[T]) Remove(e ...T) {
+	s.remove(s.now(), e)
+}
+
+// RemoveAt removes one or more elements from the replica at the given time.
+// Of an add and a remove at the same time, the remove wins.
+func (s *LWWSet[T]) RemoveAt(t time.Time, e ...T) {
+	s.remove(s.at(t), e)
+}
+
+// remove records a remove of the elements with the stamp.
+func (s *LWWSet[T]) remove(st Stamp, e []T) {
+	for _, x := range e {
+		if ent := s.entries[x]; st.compare(ent.remove) > 0 {
+			ent.remove = st
+			s.entries[x] = ent
+		}
+	}
+}
+
+// Merge merges the state of another replica into the replica, keeping the
+// latest add and remove of each element.
+func (s *LWWSet[T]) Merge(o *LWWSet[T]) {
+	for x, oe := range o.entries {
+		ent := s.entries[x]
+		if oe.add.compare(ent.add) > 0 {
+			ent.add = oe.add
+		}
+		if oe.remove.compare(ent.remove) > 0 {
+			ent.remove = oe.remove
+		}
+		s.entries[x] = ent
+	}
+}
+
+// Compact removes the tombstones of the elements removed before the given
+// time. It must only be called when all replicas have merged the removes,
+// as an add older than a dropped tombstone would reappear.
+func (s *LWWSet[T]) Compact(before time.Time) {
+	b := before.UnixNano()
+	for x, ent := range s.entries {
+		if !ent.live() && ent.remove.Time < b {
+			delete(s.entries, x)
+		}
+	}
+}
+
+// ----- methods that do not modify the receiver -----
+
+// live checks if the latest operation on the element is an add.
+func (e lwwEntry) live() bool {
+	return e.add.compare(e.remove) > 0
+}
+
+// Replica returns the replica ID of the set.
+func (s *LWWSet[T]) Replica() string {
+	return s.replica
+}
+
+// IsEmpty tests if the set is empty.
+func (s *LWWSet[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Len returns the number of elements of the set.
+func (s *LWWSet[T]) Len() int {
+	n := 0
+	for _, ent := range s.entries {
+		if ent.live() {
+			n++
+		}
+	}
+	return n
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *LWWSet[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if !s.entries[x].live() {
+			return false
+		}
+	}
+	return true
+}
+
+// Stamps returns the stamps of the latest add and remove of an element.
+// The stamp of an operation which never happened is zero.
+func (s *LWWSet[T]) Stamps(e T) (add, remove Stamp) {
+	ent := s.entries[e]
+	return ent.add, ent.remove
+}
+
+// Set returns the elements as a new set.
+func (s *LWWSet[T]) Set() set.Set[T] {
+	r := set.New[T]()
+	for x, ent := range s.entries {
+		if ent.live() {
+			r.Add(x)
+		}
+	}
+	return r
+}
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *LWWSet[T]) Copy() *LWWSet[T] {
+	r := *s
+	r.entries = maps.Clone(s.entries)
+	return &r
+}
+
+// ----- serialization -----
+
+// the JSON encoding of an LWWSet
+type lwwSetJSON[T comparable] struct {
+	Replica string            `json:"replica"`
+	Entries []lwwEntryJSON[T] `json:"entries"`
+}
+
+// the JSON encoding of an element with its stamps
+type lwwEntryJSON[T comparable] struct {
+	Elem   T     `json:"e"`
+	Add    Stamp `json:"a"`
+	Remove Stamp `json:"r"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, for the exchange of
+// states between replicas. The clock is not encoded.
+func (s *LWWSet[T]) MarshalJSON() ([]byte, error) {
+	j := lwwSetJSON[T]{Replica: s.replica, Entries: []lwwEntryJSON[T]{}}
+	for x, ent := range s.entries {
+		j.Entries = append(j.Entries, lwwEntryJSON[T]{x, ent.add, ent.remove})
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It replaces the
+// state of the replica with the decoded one, but keeps its clock.
+func (s *LWWSet[T]) UnmarshalJSON(b []byte) error {
+	var j lwwSetJSON[T]
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	r := NewLWWSet[T](j.Replica, s.clock)
+	for _, e := range j.Entries {
+		r.entries[e.Elem] = lwwEntry{e.Add, e.Remove}
+		for _, st := range []Stamp{e.Add, e.Remove} {
+			if st.Replica == r.replica {
+				r.last = max(r.last, st.Time)
+			}
+		}
+	}
+	*s = *r
+	return nil
+}
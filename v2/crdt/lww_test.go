@@ -0,0 +1,83 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package crdt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hweidner/set/v2"
+)
+
+// manualClock is a clock which is set by the test.
+type manualClock struct {
+	t time.Time
+}
+
+func (c *manualClock) now() time.Time {
+	return c.t
+}
+
+func TestLWWSet(t *testing.T) {
+	c := &manualClock{time.Unix(1000, 0)}
+	a, b := NewLWWSet[string]("a", c.now), NewLWWSet[string]("b", c.now)
+	a.Add("x", "y")
+	b.Merge(a)
+	c.t = c.t.Add(time.Second)
+	b.Remove("x")
+	c.t = c.t.Add(time.Second)
+	a.Add("z")
+	a.Merge(b)
+	b.Merge(a)
+	if !a.Set().IsEqual(set.New("y", "z")) || !b.Set().IsEqual(a.Set()) || a.Len() != 2 {
+		t.Errorf("Merge failed: got %v and %v.\n", a.Set(), b.Set())
+	}
+
+	// the later add wins over an earlier remove, in any order of merges
+	a.AddAt(time.Unix(1005, 0), "x")
+	b.RemoveAt(time.Unix(1004, 0), "x")
+	a.Merge(b)
+	b.Merge(a)
+	if !a.Contains("x") || !b.Contains("x") {
+		t.Errorf("Merge failed: got %v and %v.\n", a.Set(), b.Set())
+	}
+
+	// a remove wins over an add with the same time
+	a.AddAt(time.Unix(1010, 0), "w")
+	a.RemoveAt(time.Unix(1010, 0), "w")
+	if a.Contains("w") {
+		t.Errorf("RemoveAt failed: got %v.\n", a.Set())
+	}
+
+	// the stamps of a replica increase, even with a stopped clock
+	a.Add("v")
+	a.Remove("v")
+	if add, rem := a.Stamps("v"); a.Contains("v") || rem.compare(add) <= 0 {
+		t.Errorf("Remove failed: got %v with stamps %v, %v.\n", a.Set(), add, rem)
+	}
+
+	j, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v.\n", err)
+	}
+	d := NewLWWSet[string]("", c.now)
+	if err := json.Unmarshal(j, d); err != nil || !d.Set().IsEqual(a.Set()) || d.Replica() != "a" {
+		t.Errorf("UnmarshalJSON failed: got %v, %v.\n", d.Set(), err)
+	}
+	d.Add("w")
+	if !d.Contains("w") {
+		t.Errorf("Add failed after UnmarshalJSON: got %v.\n", d.Set())
+	}
+
+	a.Compact(time.Unix(2000, 0))
+	if _, rem := a.Stamps("w"); !rem.IsZero() || !a.Set().IsEqual(d.Set().Diff(set.New("w"))) {
+		t.Errorf("Compact failed: got %v.\n", a.Set())
+	}
+	if e := a.Copy(); !e.Set().IsEqual(a.Set()) {
+		t.Errorf("Copy failed: got %v.\n", e.Set())
+	}
+}
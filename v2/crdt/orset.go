@@ -0,0 +1,308 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package crdt provides sets which are conflict-free replicated data types
+(CRDTs). Each replica of such a set is modified locally, without
+coordination, and the replicas converge to the same state when they merge
+the states, or the deltas, of each other in any order, any number of times.
+
+An ORSet is an observed-remove set, where elements can be added and
+removed any number of times. An add wins over a concurrent remove of the
+same element.
+
+The GSet and TwoPSet are much cheaper, since they keep no metadata per
+add. A GSet is grow-only, like a set of seen message IDs. A TwoPSet keeps
+the removed elements as tombstones, so an element can be removed only
+once, and not be added again.
+
+An LWWSet is a last-writer-wins element set, where each add and remove
+carries a timestamp of a pluggable clock, and the latest operation on an
+element wins, like for the sync between mobile clients and a server.
+*/
+package crdt
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"github.com/hweidner/set/v2"
+)
+
+// ----- causal context -----
+
+// A Dot identifies an add operation by the replica and its sequence number.
+type Dot struct {
+	Replica string `json:"r"`
+	Seq     uint64 `json:"s"`
+}
+
+// a causalContext is the set of the dots which a state has seen, as a
+// version vector of contiguous sequence numbers and a cloud of the others
+type causalContext struct {
+	vv    map[string]uint64
+	cloud map[Dot]struct{}
+}
+
+func newContext() causalContext {
+	return causalContext{vv: map[string]uint64{}, cloud: map[Dot]struct{}{}}
+}
+
+// contains checks if the context has seen the dot.
+func (c causalContext) contains(d Dot) bool {
+	if d.Seq <= c.vv[d.Replica] {
+		return true
+	}
+	_, ok := c.cloud[d]
+	return ok
+}
+
+// add adds a dot to the context.
+func (c causalContext) add(d Dot) {
+	if !c.contains(d) {
+		c.cloud[d] = struct{}{}
+	}
+}
+
+// join adds the dots of o to the context.
+func (c causalContext) join(o causalContext) {
+	for r, s := range o.vv {
+		c.vv[r] = max(c.vv[r], s)
+	}
+	for d := range o.cloud {
+		c.add(d)
+	}
+}
+
+// compact moves the dots of the cloud which continue the version vector
+// into it.
+func (c causalContext) compact() {
+	for changed := true; changed; {
+		changed = false
+		for d := range c.cloud {
+			switch s := c.vv[d.Replica]; {
+			case d.Seq == s+1:
+				c.vv[d.Replica] = d.Seq
+				changed = true
+				fallthrough
+			case d.Seq <= s:
+				delete(c.cloud, d)
+			}
+		}
+	}
+}
+
+// ----- ORSet definition -----
+
+// An ORSet is an observed-remove set. Each add of an element is tagged with
+// a new dot of the replica, and a remove removes the dots which the replica
+// has observed, so a concurrent add with a new dot survives the merge. The
+// state keeps no tombstones of removed elements, only a causal context of
+// the dots seen, which is compact as long as the replicas exchange their
+// deltas without gaps.
+type ORSet[T comparable] struct {
+	replica string
+	entries map[T]map[Dot]struct{}
+	ctx     causalContext
+	delta   *ORSet[T] // the changes since the last call of Delta, or nil
+}
+
+// ----- constructor -----
+
+// NewORSet creates a new, empty replica of an observed-remove set. The
+// replica ID must be unique among all replicas of the set.
+func NewORSet[T comparable](replica string) *ORSet[T] {
+	return &ORSet[T]{replica: replica, entries: map[T]map[Dot]struct{}{}, ctx: newContext()}
+}
+
+// pending returns the delta of the changes since the last call of Delta.
+func (s *ORSet[T]) pending() *ORSet[T] {
+	if s.delta == nil {
+		s.delta = NewORSet[T]("")
+	}
+	return s.delta
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the replica.
+func (s *ORSet[T]) Add(e ...T) {
+	d := s.pending()
+	for _, x := range e {
+		dot := Dot{s.replica, s.ctx.vv[s.replica] + 1}
+		// the new dot replaces the observed dots of the element
+		for old := range s.entries[x] {
+			d.ctx.add(old)
+		}
+		s.entries[x] = map[Dot]struct{}{dot: {}}
+		s.ctx.add(dot)
+		s.ctx.compact()
+		d.entries[x] = map[Dot]struct{}{dot: {}}
+		d.ctx.add(dot)
+	}
+	d.ctx.compact()
+}
+
+// Remove removes one or more elements from the replica.
+func (s *ORSet[T]) Remove(e ...T) {
+	d := s.pending()
+	for _, x := range e {
+		for old := range s.entries[x] {
+			d.ctx.add(old)
+		}
+		delete(s.entries, x)
+		delete(d.entries, x)
+	}
+	d.ctx.compact()
+}
+
+// Merge merges the state or a delta of another replica into the replica.
+func (s *ORSet[T]) Merge(o *ORSet[T]) {
+	s.merge(o)
+	// merged changes are passed on with the next delta
+	if s.delta != nil {
+		s.delta.merge(o)
+	}
+}
+
+// merge joins the state o into s.
+func (s *ORSet[T]) merge(o *ORSet[T]) {
+	for x, dots := range s.entries {
+		od := o.entries[x]
+		for d := range dots {
+			// a dot seen by o, but not in its entry, was removed there
+			if _, ok := od[d]; !ok && o.ctx.contains(d) {
+				delete(dots, d)
+			}
+		}
+		if len(dots) == 0 {
+			delete(s.entries, x)
+		}
+	}
+	for x, od := range o.entries {
+		dots := s.entries[x]
+		for d := range od {
+			if _, ok := dots[d]; !ok && !s.ctx.contains(d) {
+				if dots == nil {
+					dots = map[Dot]struct{}{}
+					s.entries[x] = dots
+				}
+				dots[d] = struct{}{}
+			}
+		}
+	}
+	s.ctx.join(o.ctx)
+	s.ctx.compact()
+}
+
+// Delta returns the changes of the replica since the last call of Delta,
+// including the merged changes of other replicas, as a state which can be
+// merged into the other replicas. It is much smaller than the full state,
+// but the deltas must be delivered to all replicas, or the full state must
+// be merged later. It returns nil if nothing changed.
+func (s *ORSet[T]) Delta() *ORSet[T] {
+	d := s.delta
+	s.delta = nil
+	return d
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Replica returns the replica ID.
+func (s *ORSet[T]) Replica() string {
+	return s.replica
+}
+
+// IsEmpty tests if the set is empty.
+func (s *ORSet[T]) IsEmpty() bool {
+	return len(s.entries) == 0
+}
+
+// Len returns the number of elements.
+func (s *ORSet[T]) Len() int {
+	return len(s.entries)
+}
+
+// Contains checks if the replica contains one or more elements. The return
+// value is true only if all given elements are in the set.
+func (s *ORSet[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if _, ok := s.entries[x]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Set returns the elements as a new set.
+func (s *ORSet[T]) Set() set.Set[T] {
+	r := set.New[T]()
+	for x := range s.entries {
+		r.Add(x)
+	}
+	return r
+}
+
+// Copy returns a copy of the replica with the same ID, without pending
+// deltas.
+func (s *ORSet[T]) Copy() *ORSet[T] {
+	r := NewORSet[T](s.replica)
+	for x, dots := range s.entries {
+		r.entries[x] = maps.Clone(dots)
+	}
+	r.ctx.join(s.ctx)
+	return r
+}
+
+// ----- serialization -----
+
+// the JSON representation of an ORSet
+type orSetJSON[T comparable] struct {
+	Replica string            `json:"replica,omitempty"`
+	Entries []orSetEntry[T]   `json:"entries"`
+	Context map[string]uint64 `json:"context"`
+	Cloud   []Dot             `json:"cloud,omitempty"`
+}
+
+type orSetEntry[T comparable] struct {
+	Elem T     `json:"elem"`
+	Dots []Dot `json:"dots"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, for the exchange of
+// states and deltas between replicas.
+func (s *ORSet[T]) MarshalJSON() ([]byte, error) {
+	j := orSetJSON[T]{Replica: s.replica, Entries: []orSetEntry[T]{}, Context: s.ctx.vv}
+	for x, dots := range s.entries {
+		j.Entries = append(j.Entries, orSetEntry[T]{x, slices.Collect(maps.Keys(dots))})
+	}
+	j.Cloud = slices.Collect(maps.Keys(s.ctx.cloud))
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It replaces the
+// state of the replica with the decoded one.
+func (s *ORSet[T]) UnmarshalJSON(b []byte) error {
+	var j orSetJSON[T]
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	r := NewORSet[T](j.Replica)
+	for _, e := range j.Entries {
+		dots := make(map[Dot]struct{}, len(e.Dots))
+		for _, d := range e.Dots {
+			dots[d] = struct{}{}
+		}
+		r.entries[e.Elem] = dots
+	}
+	maps.Copy(r.ctx.vv, j.Context)
+	for _, d := range j.Cloud {
+		r.ctx.add(d)
+	}
+	r.ctx.compact()
+	*s = *r
+	return nil
+}
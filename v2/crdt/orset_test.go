@@ -0,0 +1,128 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package crdt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+func TestORSet(t *testing.T) {
+	a, b := NewORSet[string]("a"), NewORSet[string]("b")
+	a.Add("x", "y")
+	b.Merge(a)
+	if !b.Contains("x", "y") || b.Len() != 2 {
+		t.Errorf("Merge failed: got %v.\n", b.Set())
+	}
+
+	// a concurrent add wins over a remove
+	a.Remove("x", "y")
+	b.Add("x")
+	a.Merge(b)
+	b.Merge(a)
+	if !a.Set().IsEqual(set.New("x")) || !b.Set().IsEqual(set.New("x")) {
+		t.Errorf("Merge failed: got %v and %v.\n", a.Set(), b.Set())
+	}
+
+	// merges are idempotent
+	a.Merge(b)
+	a.Merge(a.Copy())
+	if !a.Set().IsEqual(set.New("x")) {
+		t.Errorf("Merge failed: got %v after repeated merges.\n", a.Set())
+	}
+
+	// an element can be added again after a remove
+	b.Remove("x")
+	a.Merge(b)
+	a.Add("x")
+	b.Merge(a)
+	if !b.Contains("x") || a.Replica() != "a" {
+		t.Errorf("Add failed after Remove: got %v.\n", b.Set())
+	}
+	if len(a.ctx.cloud) != 0 || len(b.ctx.cloud) != 0 {
+		t.Errorf("Merge failed: contexts not compacted: %v, %v.\n", a.ctx, b.ctx)
+	}
+}
+
+func TestORSetConverge(t *testing.T) {
+	r := []*ORSet[int]{NewORSet[int]("r0"), NewORSet[int]("r1"), NewORSet[int]("r2")}
+	for round := 0; round < 20; round++ {
+		for i, s := range r {
+			s.Add(round*3 + i)
+			if round%3 == i {
+				s.Remove(round*3 - 3)
+			}
+		}
+		// merge in a different order each round, with some replicas skipped
+		for i := range r {
+			j := (i + round) % len(r)
+			if round%4 != 0 || i != 0 {
+				r[j].Merge(r[(j+1)%len(r)])
+			}
+		}
+	}
+	for i := range 2 * len(r) {
+		r[i%len(r)].Merge(r[(i+1)%len(r)])
+	}
+	if !r[0].Set().IsEqual(r[1].Set()) || !r[1].Set().IsEqual(r[2].Set()) {
+		t.Errorf("Merge failed: replicas diverged: %v, %v, %v.\n", r[0].Set(), r[1].Set(), r[2].Set())
+	}
+}
+
+func TestORSetDelta(t *testing.T) {
+	a, b := NewORSet[string]("a"), NewORSet[string]("b")
+	a.Add("x", "y", "z")
+	b.Merge(a.Delta())
+	if a.Delta() != nil || !b.Contains("x", "y", "z") {
+		t.Errorf("Delta failed: got %v.\n", b.Set())
+	}
+	a.Remove("y")
+	a.Add("w")
+	d := a.Delta()
+	if d.Len() != 1 || !d.Contains("w") {
+		t.Errorf("Delta failed: got %v for a remove and an add.\n", d.Set())
+	}
+	b.Merge(d)
+	if !b.Set().IsEqual(set.New("x", "z", "w")) {
+		t.Errorf("Merge failed for a delta: got %v.\n", b.Set())
+	}
+
+	// the delta of an element added and removed again removes it elsewhere
+	a.Add("v")
+	b.Merge(a.Copy())
+	a.Add("v")
+	a.Remove("v")
+	b.Merge(a.Delta())
+	if b.Contains("v") {
+		t.Errorf("Merge failed: removed element kept: %v.\n", b.Set())
+	}
+}
+
+func TestORSetJSON(t *testing.T) {
+	a := NewORSet[string]("a")
+	a.Add("x", "y")
+	a.Remove("x")
+	b, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v.\n", err)
+	}
+	var c ORSet[string]
+	if err := json.Unmarshal(b, &c); err != nil || !c.Set().IsEqual(set.New("y")) || c.Replica() != "a" {
+		t.Fatalf("UnmarshalJSON failed: %v, %v.\n", c.Set(), err)
+	}
+	// the decoded state still knows the removed dot
+	o := NewORSet[string]("o")
+	o.Merge(a.Copy())
+	o.Merge(&c)
+	if !o.Set().IsEqual(set.New("y")) {
+		t.Errorf("Merge failed for a decoded state: got %v.\n", o.Set())
+	}
+	if err := json.Unmarshal([]byte(`{"entries":1}`), &c); err == nil {
+		t.Errorf("UnmarshalJSON failed: malformed input accepted.\n")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package dedup indexes the contents of the files of a file system by their
+SHA-256 digests, and answers whether a content is already present, like for
+backup and sync tools which skip files already stored at the destination.
+
+An Index is built by scanning a file system with several goroutines. It
+caches the digest of each file with its size and modification time, so a
+rescan only reads new and modified files.
+*/
+package dedup
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hweidner/set/v2"
+)
+
+// A Digest is the SHA-256 digest of a content.
+type Digest [sha256.Size]byte
+
+// Sum returns the digest of the content read from r.
+func Sum(r io.Reader) (Digest, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return Digest{}, err
+	}
+	return Digest(h.Sum(nil)), nil
+}
+
+// ----- Index definition -----
+
+// An Index holds the digests of the regular files of a file system. It is
+// safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	files    map[string]file     // the files by path
+	byDigest map[Digest][]string // the sorted paths of each digest
+}
+
+// a file of the index, with the size and modification time of its digest
+type file struct {
+	size   int64
+	mtime  time.Time
+	digest Digest
+}
+
+// NewIndex creates a new, empty index.
+func NewIndex() *Index {
+	return &Index{files: map[string]file{}, byDigest: map[Digest][]string{}}
+}
+
+// Scan replaces the contents of the index with the regular files of fsys,
+// read by the given number of goroutines; values below 1 select 1. Files
+// whose size and modification time did not change since the last scan are
+// not read again. On errors, the index keeps its previous contents, and the
+// error joins the errors of all files which could not be read.
+func (x *Index) Scan(ctx context.Context, fsys fs.FS, workers int) error {
+	x.mu.RLock()
+	cache := x.files
+	x.mu.RUnlock()
+
+	type job struct {
+		path string
+		info fs.FileInfo
+	}
+	jobs := make(chan job)
+	var mu sync.Mutex
+	files := map[string]file{}
+	var errs []error
+	fail := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				f := file{size: j.info.Size(), mtime: j.info.ModTime()}
+				if c, ok := cache[j.path]; ok && c.size == f.size && c.mtime.Equal(f.mtime) {
+					f.digest = c.digest
+				} else {
+					d, err := sumFile(fsys, j.path)
+					if err != nil {
+						fail(err)
+						continue
+					}
+					f.digest = d
+				}
+				mu.Lock()
+				files[j.path] = f
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			fail(err)
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			fail(err)
+			return nil
+		}
+		jobs <- job{path, info}
+		return nil
+	})
+	close(jobs)
+	wg.Wait()
+	if err = errors.Join(append([]error{err}, errs...)...); err != nil {
+		return err
+	}
+
+	byDigest := map[Digest][]string{}
+	for p, f := range files {
+		byDigest[f.digest] = append(byDigest[f.digest], p)
+	}
+	for _, l := range byDigest {
+		slices.Sort(l)
+	}
+	x.mu.Lock()
+	x.files, x.byDigest = files, byDigest
+	x.mu.Unlock()
+	return nil
+}
+
+// sumFile returns the digest of a file.
+func sumFile(fsys fs.FS, path string) (Digest, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return Digest{}, err
+	}
+	defer f.Close()
+	d, err := Sum(f)
+	if err != nil {
+		return Digest{}, &fs.PathError{Op: "read", Path: path, Err: err}
+	}
+	return d, nil
+}
+
+// ----- queries -----
+
+// Has checks if a file with the digest is in the index.
+func (x *Index) Has(d Digest) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	_, ok := x.byDigest[d]
+	return ok
+}
+
+// HasContent checks if a file with the content read from r is in the index.
+// It also returns the digest of the content.
+func (x *Index) HasContent(r io.Reader) (bool, Digest, error) {
+	d, err := Sum(r)
+	if err != nil {
+		return false, d, err
+	}
+	return x.Has(d), d, nil
+}
+
+// Paths returns the sorted paths of the files with the digest.
+func (x *Index) Paths(d Digest) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return slices.Clone(x.byDigest[d])
+}
+
+// Digest returns the digest of the file with the path. The second return
+// value is false if the file is not in the index.
+func (x *Index) Digest(path string) (Digest, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	f, ok := x.files[path]
+	return f.digest, ok
+}
+
+// Len returns the number of files in the index.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.files)
+}
+
+// Digests returns the distinct digests of the index as a new set.
+func (x *Index) Digests() set.Set[Digest] {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	s := set.New[Digest]()
+	for d := range x.byDigest {
+		s.Add(d)
+	}
+	return s
+}
+
+// Duplicates returns the paths of the contents which are stored in more
+// than one file.
+func (x *Index) Duplicates() [][]string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	var r [][]string
+	for _, l := range x.byDigest {
+		if len(l) > 1 {
+			r = append(r, slices.Clone(l))
+		}
+	}
+	slices.SortFunc(r, func(a, b []string) int { return cmp.Compare(a[0], b[0]) })
+	return r
+}
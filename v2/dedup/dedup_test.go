@@ -0,0 +1,119 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package dedup
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// countingFS counts the files opened from a file system.
+type countingFS struct {
+	fs.FS
+	opens atomic.Int64
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	f, err := c.FS.Open(name)
+	if err == nil {
+		if st, err := f.Stat(); err == nil && st.Mode().IsRegular() {
+			c.opens.Add(1)
+		}
+	}
+	return f, err
+}
+
+func TestIndex(t *testing.T) {
+	mt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := fstest.MapFS{
+		"a.txt":     {Data: []byte("hello"), ModTime: mt},
+		"b/c.txt":   {Data: []byte("world"), ModTime: mt},
+		"b/d/e.txt": {Data: []byte("hello"), ModTime: mt},
+	}
+	c := &countingFS{FS: m}
+	x := NewIndex()
+	if err := x.Scan(context.Background(), c, 4); err != nil {
+		t.Fatalf("Scan failed: %v.\n", err)
+	}
+	if x.Len() != 3 || x.Digests().Len() != 2 || c.opens.Load() != 3 {
+		t.Errorf("Scan failed: got %d files, %d digests, %d reads.\n", x.Len(), x.Digests().Len(), c.opens.Load())
+	}
+	ok, d, err := x.HasContent(strings.NewReader("hello"))
+	if !ok || err != nil || !slices.Equal(x.Paths(d), []string{"a.txt", "b/d/e.txt"}) {
+		t.Errorf("HasContent failed: got %v, %v, %v.\n", ok, x.Paths(d), err)
+	}
+	if ok, _, _ := x.HasContent(strings.NewReader("other")); ok {
+		t.Errorf("HasContent failed: got true for a new content.\n")
+	}
+	if dup := x.Duplicates(); len(dup) != 1 || dup[0][0] != "a.txt" {
+		t.Errorf("Duplicates failed: got %v.\n", dup)
+	}
+
+	// a rescan only reads new and modified files, and drops removed files
+	m["b/c.txt"] = &fstest.MapFile{Data: []byte("other"), ModTime: mt.Add(time.Second)}
+	m["f.txt"] = &fstest.MapFile{Data: []byte("new"), ModTime: mt}
+	delete(m, "b/d/e.txt")
+	c.opens.Store(0)
+	if err := x.Scan(context.Background(), c, 1); err != nil {
+		t.Fatalf("Scan failed: %v.\n", err)
+	}
+	if c.opens.Load() != 2 || x.Len() != 3 {
+		t.Errorf("Scan failed: got %d reads and %d files on rescan.\n", c.opens.Load(), x.Len())
+	}
+	d, _ = Sum(strings.NewReader("other"))
+	if !x.Has(d) || !slices.Equal(x.Paths(d), []string{"b/c.txt"}) {
+		t.Errorf("Scan failed: modified file not indexed.\n")
+	}
+	if _, ok := x.Digest("b/d/e.txt"); ok {
+		t.Errorf("Scan failed: removed file still indexed.\n")
+	}
+	if len(x.Duplicates()) != 0 {
+		t.Errorf("Duplicates failed: got %v.\n", x.Duplicates())
+	}
+}
+
+// failingFS fails to open one file.
+type failingFS struct {
+	fs.FS
+	name string
+}
+
+var errBroken = errors.New("broken")
+
+func (f failingFS) Open(name string) (fs.File, error) {
+	if name == f.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errBroken}
+	}
+	return f.FS.Open(name)
+}
+
+func TestIndexErrors(t *testing.T) {
+	m := fstest.MapFS{"a": {Data: []byte("a")}, "b": {Data: []byte("b")}}
+	x := NewIndex()
+	if err := x.Scan(context.Background(), m, 2); err != nil {
+		t.Fatalf("Scan failed: %v.\n", err)
+	}
+	m["c"] = &fstest.MapFile{Data: []byte("c")}
+	if err := x.Scan(context.Background(), failingFS{m, "c"}, 2); !errors.Is(err, errBroken) {
+		t.Errorf("Scan failed: got %v.\n", err)
+	}
+	if x.Len() != 2 {
+		t.Errorf("Scan failed: index changed after an error, got %d files.\n", x.Len())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := x.Scan(ctx, m, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Scan failed: got %v after cancel.\n", err)
+	}
+}
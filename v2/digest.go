@@ -0,0 +1,88 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+
+	"github.com/hweidner/set/v2/internal/elemcodec"
+	ihash "github.com/hweidner/set/v2/internal/hash"
+)
+
+// ErrNoStableEncoding is returned by Digest and the encoders of sets whose
+// element type has no encoding which is stable across processes, like
+// structs and pointers.
+var ErrNoStableEncoding = errors.New("set: element type has no stable encoding")
+
+// ----- canonical digest -----
+
+// Digest writes a canonical encoding of the elements of the set into h, so
+// equal sets have equal digests, independent of the order of insertion and
+// the process. Together with a cryptographic hash like SHA-256, the digest is
+// a fingerprint of the set for content addressed storage or for detecting
+// changes of membership.
+//
+// The encoding is the number of elements as a uvarint, followed by the
+// self-delimiting element encodings in ascending byte order. Strings are
+// encoded with their length, integers as varints and floats by their bits,
+// with -0.0 encoded like 0.0. The element type itself is not encoded, so sets
+// of different integer types with the same elements have the same digest.
+// For other element types, nothing is written and ErrNoStableEncoding is returned.
+func (s Set[T]) Digest(h hash.Hash) error {
+	enc := make([][]byte, 0, len(s.set))
+	for k := range s.set {
+		b, ok := elemcodec.AppendValue(nil, k)
+		if !ok {
+			var zero T
+			return fmt.Errorf("%w: %T", ErrNoStableEncoding, zero)
+		}
+		enc = append(enc, b)
+	}
+	slices.SortFunc(enc, bytes.Compare)
+
+	buf := binary.AppendUvarint(make([]byte, 0, 4096), uint64(len(enc)))
+	for _, b := range enc {
+		if len(buf)+len(b) > cap(buf) {
+			if _, err := h.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+		buf = append(buf, b...)
+	}
+	_, err := h.Write(buf)
+	return err
+}
+
+// ----- order-independent hash -----
+
+// Hash returns a 64 bit hash of the elements of the set, independent of the
+// order of insertion, so two replicas can cheaply check if their sets are
+// likely equal before computing a full difference. It combines the hashes
+// of the elements by addition, in O(n) time without sorting. Elements with
+// a stable encoding, like for Digest, are hashed by that encoding, so their
+// hashes are equal in all processes; other elements are hashed with a
+// per-process seed. Equal sets have equal hashes; unequal sets have equal
+// hashes only with a probability of about 2^-64.
+func (s Set[T]) Hash() uint64 {
+	var sum uint64
+	var buf []byte
+	for k := range s.set {
+		b, ok := elemcodec.AppendValue(buf[:0], k)
+		if ok {
+			sum += ihash.Sum64(b)
+			buf = b
+		} else {
+			sum += ihash.Mix64(ihash.Of(k))
+		}
+	}
+	return ihash.Mix64(sum ^ uint64(len(s.set)))
+}
@@ -0,0 +1,83 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+)
+
+// sum returns the hex encoded SHA-256 digest of s.
+func sum[T comparable](t *testing.T, s Set[T]) string {
+	t.Helper()
+	h := sha256.New()
+	if err := s.Digest(h); err != nil {
+		t.Fatalf("Digest failed: %v.\n", err)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestDigest(t *testing.T) {
+	a := New("x", "y", "z")
+	b := New("z", "x")
+	b.Add("y")
+	if sum(t, a) != sum(t, b) {
+		t.Errorf("Digest failed: equal sets have different digests.\n")
+	}
+	if sum(t, a) == sum(t, New("x", "y")) || sum(t, New("ab", "c")) == sum(t, New("a", "bc")) {
+		t.Errorf("Digest failed: different sets have equal digests.\n")
+	}
+	if sum(t, New[string]()) == sum(t, New("")) {
+		t.Errorf("Digest failed: the empty set and {\"\"} have equal digests.\n")
+	}
+	if sum(t, New(0.0, 1)) != sum(t, New(math.Copysign(0, -1), 1)) {
+		t.Errorf("Digest failed: -0.0 and 0.0 have different digests.\n")
+	}
+
+	// the digest is stable across processes: SHA-256 of 03 02 04 06
+	if got := sum(t, New(1, 2, 3)); got != "2909d853b0d05030cb806643398525e99f581241ea9299818cdc7ed0b191dabe" {
+		t.Errorf("Digest failed: got %s.\n", got)
+	}
+
+	// large sets are written in chunks
+	l := New[int]()
+	for i := 0; i < 10000; i++ {
+		l.Add(i)
+	}
+	if sum(t, l) != sum(t, l.Copy()) {
+		t.Errorf("Digest failed for a large set.\n")
+	}
+
+	if err := New(testPoint{1, 2}).Digest(sha256.New()); !errors.Is(err, ErrNoStableEncoding) {
+		t.Errorf("Digest failed: got %v for a struct set.\n", err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	a := New("x", "y", "z")
+	b := New("z", "x")
+	b.Add("y")
+	if a.Hash() != b.Hash() {
+		t.Errorf("Hash failed: equal sets have different hashes.\n")
+	}
+	if a.Hash() == New("x", "y").Hash() || New[string]().Hash() == New("").Hash() {
+		t.Errorf("Hash failed: different sets have equal hashes.\n")
+	}
+	if New(0.0, 1).Hash() != New(math.Copysign(0, -1), 1).Hash() {
+		t.Errorf("Hash failed: -0.0 and 0.0 have different hashes.\n")
+	}
+	if New(testPoint{1, 2}, testPoint{3, 4}).Hash() != New(testPoint{3, 4}, testPoint{1, 2}).Hash() {
+		t.Errorf("Hash failed: equal struct sets have different hashes.\n")
+	}
+
+	// the hash is stable across processes and integer types
+	if got := New(1, 2, 3).Hash(); got != 0xa4721c3ed644300 || got != New[int64](3, 2, 1).Hash() {
+		t.Errorf("Hash failed: got %x.\n", got)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "maps"
+
+// ----- DisjointSet definition -----
+
+// A DisjointSet is a partition of elements into disjoint sets, also known as
+// union-find structure, like for connected components or clustering. Each
+// set is identified by one of its elements, its representative. Union and
+// Find take nearly constant amortized time, by union by size and path
+// halving.
+type DisjointSet[T comparable] struct {
+	parent map[T]T   // the parent of each element, roots are their own parent
+	size   map[T]int // the size of the set of each root
+}
+
+// ----- constructor -----
+
+// NewDisjoint creates a new disjoint set, with each of the argument
+// elements in a set of its own.
+func NewDisjoint[T comparable](e ...T) *DisjointSet[T] {
+	d := &DisjointSet[T]{parent: make(map[T]T, len(e)), size: make(map[T]int, len(e))}
+	d.Add(e...)
+	return d
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements, each in a set of its own. Elements which
+// are already present keep their sets.
+func (d *DisjointSet[T]) Add(e ...T) {
+	for _, x := range e {
+		if _, ok := d.parent[x]; !ok {
+			d.parent[x] = x
+			d.size[x] = 1
+		}
+	}
+}
+
+// Union merges the sets of a and b, adding them first if they are not
+// present. It returns false if they were already in the same set.
+func (d *DisjointSet[T]) Union(a, b T) bool {
+	d.Add(a, b)
+	ra, rb := d.root(a), d.root(b)
+	if ra == rb {
+		return false
+	}
+	// the smaller set is attached to the larger one
+	if d.size[ra] < d.size[rb] {
+		ra, rb = rb, ra
+	}
+	d.parent[rb] = ra
+	d.size[ra] += d.size[rb]
+	delete(d.size, rb)
+	return true
+}
+
+// Clear removes all elements.
+func (d *DisjointSet[T]) Clear() {
+	clear(d.parent)
+	clear(d.size)
+}
+
+// root returns the representative of a present element. It halves the path
+// to the root, as far as it is walked.
+func (d *DisjointSet[T]) root(x T) T {
+	for {
+		p := d.parent[x]
+		if p == x {
+			return x
+		}
+		g := d.parent[p]
+		d.parent[x] = g
+		x = g
+	}
+}
+
+// Find returns the representative of the set of x. The second return value
+// is false if x is not present. Find compresses the path to the
+// representative, so it modifies the internal structure, but not the
+// partition.
+func (d *DisjointSet[T]) Find(x T) (T, bool) {
+	if _, ok := d.parent[x]; !ok {
+		return x, false
+	}
+	return d.root(x), true
+}
+
+// ----- methods that do not modify the partition -----
+
+// Contains checks if one or more elements are present. The return value is
+// true only if all given elements are present.
+func (d *DisjointSet[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if _, ok := d.parent[x]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// SameSet checks if a and b are present and in the same set.
+func (d *DisjointSet[T]) SameSet(a, b T) bool {
+	ra, ok := d.Find(a)
+	if !ok {
+		return false
+	}
+	rb, ok := d.Find(b)
+	return ok && ra == rb
+}
+
+// Len returns the number of elements.
+func (d *DisjointSet[T]) Len() int {
+	return len(d.parent)
+}
+
+// NumSets returns the number of sets.
+func (d *DisjointSet[T]) NumSets() int {
+	return len(d.size)
+}
+
+// SizeOf returns the size of the set of x, 0 if x is not present.
+func (d *DisjointSet[T]) SizeOf(x T) int {
+	r, ok := d.Find(x)
+	if !ok {
+		return 0
+	}
+	return d.size[r]
+}
+
+// Copy returns a copy of the disjoint set.
+func (d *DisjointSet[T]) Copy() *DisjointSet[T] {
+	return &DisjointSet[T]{parent: maps.Clone(d.parent), size: maps.Clone(d.size)}
+}
+
+// ----- methods that return other data types -----
+
+// SetOf returns the elements of the set of x as a new set, which is empty if
+// x is not present. It takes O(n) time for n elements.
+func (d *DisjointSet[T]) SetOf(x T) Set[T] {
+	r, ok := d.Find(x)
+	if !ok {
+		return New[T]()
+	}
+	s := Set[T]{set: make(map[T]struct{}, d.size[r])}
+	for e := range d.parent {
+		if d.root(e) == r {
+			s.set[e] = struct{}{}
+		}
+	}
+	return s
+}
+
+// Sets returns the partition as a slice of new sets, in no particular order.
+func (d *DisjointSet[T]) Sets() []Set[T] {
+	idx := make(map[T]int, len(d.size))
+	r := make([]Set[T], 0, len(d.size))
+	for e := range d.parent {
+		root := d.root(e)
+		i, ok := idx[root]
+		if !ok {
+			i = len(r)
+			idx[root] = i
+			r = append(r, Set[T]{set: make(map[T]struct{}, d.size[root])})
+		}
+		r[i].set[e] = struct{}{}
+	}
+	return r
+}
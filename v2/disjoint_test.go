@@ -0,0 +1,64 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDisjointSet(t *testing.T) {
+	d := NewDisjoint(1, 2, 3, 4, 5)
+	if d.NumSets() != 5 || d.Len() != 5 || d.SameSet(1, 2) {
+		t.Errorf("NewDisjoint failed.\n")
+	}
+	if !d.Union(1, 2) || !d.Union(3, 4) || !d.Union(2, 4) || d.Union(1, 3) {
+		t.Errorf("Union failed.\n")
+	}
+	if !d.SameSet(1, 4) || d.SameSet(1, 5) || d.SameSet(1, 6) || d.NumSets() != 2 || d.SizeOf(3) != 4 {
+		t.Errorf("Union failed: got %d sets.\n", d.NumSets())
+	}
+	r1, _ := d.Find(1)
+	r4, _ := d.Find(4)
+	if r1 != r4 {
+		t.Errorf("Find failed: got representatives %d and %d.\n", r1, r4)
+	}
+	if _, ok := d.Find(6); ok || d.SizeOf(6) != 0 || d.Contains(5, 6) {
+		t.Errorf("Find failed for a missing element.\n")
+	}
+
+	// Union adds missing elements
+	d.Union(6, 7)
+	if !d.Contains(6, 7) || !d.SameSet(6, 7) || d.Len() != 7 || d.NumSets() != 3 {
+		t.Errorf("Union failed for new elements.\n")
+	}
+	if !d.SetOf(3).IsEqual(New(1, 2, 3, 4)) || !d.SetOf(5).IsEqual(New(5)) || !d.SetOf(8).IsEmpty() {
+		t.Errorf("SetOf failed: got %v.\n", d.SetOf(3))
+	}
+
+	sets := d.Sets()
+	slices.SortFunc(sets, func(a, b Set[int]) int { return a.Len() - b.Len() })
+	if len(sets) != 3 || !sets[0].IsEqual(New(5)) || !sets[1].IsEqual(New(6, 7)) || !sets[2].IsEqual(New(1, 2, 3, 4)) {
+		t.Errorf("Sets failed: got %v.\n", sets)
+	}
+
+	c := d.Copy()
+	d.Clear()
+	if d.Len() != 0 || d.NumSets() != 0 || !c.SameSet(1, 3) {
+		t.Errorf("Clear failed.\n")
+	}
+}
+
+func TestDisjointSetChain(t *testing.T) {
+	// a long chain of unions stays shallow
+	d := NewDisjoint[int]()
+	for i := 1; i < 10000; i++ {
+		d.Union(i-1, i)
+	}
+	if d.NumSets() != 1 || d.SizeOf(0) != 10000 || !d.SameSet(0, 9999) {
+		t.Errorf("Union failed for a chain: got %d sets.\n", d.NumSets())
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrInvalidDomain is the cause of a *DecodeError for a malformed domain
+	// name.
+	ErrInvalidDomain = errors.New("set: invalid domain name")
+
+	// ErrPublicSuffix is the cause of a *DecodeError for an entry which is a
+	// public suffix, like "co.uk", and would match unrelated domains.
+	ErrPublicSuffix = errors.New("set: domain is a public suffix")
+)
+
+// ----- DomainSet definition -----
+
+// A DomainSet is a set of domain names for blocklists and allowlists, which
+// matches hostnames by their suffix. An entry "example.com" matches the domain
+// itself and all of its subdomains, like "a.b.example.com"; a wildcard entry
+// "*.example.com" matches only the subdomains. Since DNS names are not case
+// sensitive, entries and hostnames are lower cased, and a trailing dot is
+// ignored. Internationalized names must be given in their ASCII form
+// (punycode).
+//
+// The entries are stored in a trie of their labels in reverse order, so a
+// lookup takes one step per label of the hostname.
+type DomainSet struct {
+	root   *domainNode
+	count  int
+	suffix func(domain string) (string, bool)
+}
+
+// a node of the trie, for the domain of the labels on its path
+type domainNode struct {
+	next map[string]*domainNode
+	self bool // the domain is an entry
+	wild bool // the wildcard of the domain is an entry
+}
+
+// ----- constructor -----
+
+// NewDomainSet creates a new domain set and initializes it with the argument
+// entries. Malformed entries are ignored; use Add to detect them.
+func NewDomainSet(e ...string) *DomainSet {
+	s := &DomainSet{root: &domainNode{}}
+	s.Add(e...)
+	return s
+}
+
+// SetPublicSuffixList sets the function which returns the public suffix of
+// a domain, and whether it is managed by ICANN. It has the signature of
+// PublicSuffix of the golang.org/x/net/publicsuffix package. Once set, Add
+// rejects entries which are public suffixes, like "com" or "*.co.uk", since
+// they would match the domains of unrelated owners.
+func (s *DomainSet) SetPublicSuffixList(f func(domain string) (publicSuffix string, icann bool)) {
+	s.suffix = f
+}
+
+// parseDomain returns the labels of a domain in reverse order, and whether
+// it is a wildcard.
+func parseDomain(d string) (labels []string, wild bool, ok bool) {
+	d = strings.TrimSuffix(strings.ToLower(d), ".")
+	if rest, found := strings.CutPrefix(d, "*."); found {
+		d, wild = rest, true
+	}
+	if d == "" || len(d) > 253 {
+		return nil, false, false
+	}
+	labels = strings.Split(d, ".")
+	for _, l := range labels {
+		if !validLabel(l) {
+			return nil, false, false
+		}
+	}
+	slices.Reverse(labels)
+	return labels, wild, true
+}
+
+// validLabel checks if l is a valid label of a hostname. Underscores are
+// allowed, as they are common in service names.
+func validLabel(l string) bool {
+	if l == "" || len(l) > 63 || l[0] == '-' || l[len(l)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(l); i++ {
+		if c := l[i]; !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// joinDomain returns the domain of labels in reverse order.
+func joinDomain(labels []string) string {
+	l := slices.Clone(labels)
+	slices.Reverse(l)
+	return strings.Join(l, ".")
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more entries to the given set. Malformed entries, and
+// public suffixes if a public suffix list is set, are not added; the returned
+// error joins a *DecodeError wrapping ErrInvalidDomain or ErrPublicSuffix for
+// each of them. The other entries are added nevertheless.
+func (s *DomainSet) Add(e ...string) error {
+	var errs []error
+	for pos, d := range e {
+		if err := s.add(d); err != nil {
+			errs = append(errs, &DecodeError{Pos: pos, Input: d, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add adds a single entry to the set.
+func (s *DomainSet) add(d string) error {
+	labels, wild, ok := parseDomain(d)
+	if !ok {
+		return ErrInvalidDomain
+	}
+	// the wildcard of a public suffix matches unrelated domains as well
+	if s.suffix != nil {
+		name := joinDomain(labels)
+		if ps, _ := s.suffix(name); ps == name {
+			return ErrPublicSuffix
+		}
+	}
+	n := s.root
+	for _, l := range labels {
+		c := n.next[l]
+		if c == nil {
+			if n.next == nil {
+				n.next = map[string]*domainNode{}
+			}
+			c = &domainNode{}
+			n.next[l] = c
+		}
+		n = c
+	}
+	if wild && !n.wild {
+		n.wild = true
+		s.count++
+	} else if !wild && !n.self {
+		n.self = true
+		s.count++
+	}
+	return nil
+}
+
+// Remove removes one or more entries from the given set. An entry only
+// removes the same entry; removing "example.com" keeps "*.example.com".
+func (s *DomainSet) Remove(e ...string) {
+	for _, d := range e {
+		labels, wild, ok := parseDomain(d)
+		if !ok {
+			continue
+		}
+		path := []*domainNode{s.root}
+		for _, l := range labels {
+			n := path[len(path)-1].next[l]
+			if n == nil {
+				break
+			}
+			path = append(path, n)
+		}
+		if len(path) != len(labels)+1 {
+			continue
+		}
+		n := path[len(path)-1]
+		if wild && n.wild {
+			n.wild = false
+			s.count--
+		} else if !wild && n.self {
+			n.self = false
+			s.count--
+		}
+		// prune the nodes which neither hold entries nor lead to them
+		for i := len(path) - 1; i > 0; i-- {
+			if n := path[i]; n.self || n.wild || len(n.next) > 0 {
+				break
+			}
+			delete(path[i-1].next, labels[i-1])
+		}
+	}
+}
+
+// Clear removes all entries from the given set.
+func (s *DomainSet) Clear() {
+	s.root = &domainNode{}
+	s.count = 0
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *DomainSet) IsEmpty() bool {
+	return s.count == 0
+}
+
+// Len returns the number of entries of the set.
+func (s *DomainSet) Len() int {
+	return s.count
+}
+
+// Contains checks if one or more hostnames are matched by entries of the
+// set. The return value is true only if all given hostnames are matched.
+func (s *DomainSet) Contains(h ...string) bool {
+	for _, i := range h {
+		if _, ok := s.Match(i); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns the most specific entry which matches the hostname. The
+// second return value is false if no entry matches it.
+func (s *DomainSet) Match(h string) (string, bool) {
+	labels, wild, ok := parseDomain(h)
+	if !ok || wild {
+		return "", false
+	}
+	match, n := "", s.root
+	for i, l := range labels {
+		// the wildcard of a parent matches all proper subdomains
+		if n.wild {
+			match = "*." + joinDomain(labels[:i])
+		}
+		if n = n.next[l]; n == nil {
+			break
+		}
+		if n.self {
+			match = joinDomain(labels[:i+1])
+		}
+	}
+	return match, match != ""
+}
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *DomainSet) Copy() *DomainSet {
+	return &DomainSet{root: s.root.copy(), count: s.count, suffix: s.suffix}
+}
+
+// copy returns a deep copy of the node.
+func (n *domainNode) copy() *domainNode {
+	c := &domainNode{self: n.self, wild: n.wild}
+	if n.next != nil {
+		c.next = make(map[string]*domainNode, len(n.next))
+		for l, m := range n.next {
+			c.next[l] = m.copy()
+		}
+	}
+	return c
+}
+
+// ----- methods that return other data types -----
+
+// List returns the entries of the set in a slice, sorted by their labels in
+// reverse order, so subdomains follow their parent domains.
+func (s *DomainSet) List() []string {
+	l := make([]string, 0, s.count)
+	var walk func(n *domainNode, labels []string)
+	walk = func(n *domainNode, labels []string) {
+		if n.self {
+			l = append(l, joinDomain(labels))
+		}
+		if n.wild {
+			l = append(l, "*."+joinDomain(labels))
+		}
+		for _, k := range slices.Sorted(maps.Keys(n.next)) {
+			walk(n.next[k], append(labels, k))
+		}
+	}
+	walk(s.root, nil)
+	return l
+}
+
+// String returns a textual representation of the set in a string, with the
+// entries in the order of List.
+func (s *DomainSet) String() string {
+	var b strings.Builder
+	b.WriteString("{ ")
+	for _, d := range s.List() {
+		b.WriteString(d)
+		b.WriteByte(' ')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,87 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDomainSet(t *testing.T) {
+	s := NewDomainSet("Example.COM.", "*.ads.net", "tracker.io", "a.tracker.io")
+	if s.Len() != 4 {
+		t.Errorf("NewDomainSet failed: got %v.\n", s)
+	}
+	for _, tt := range []struct {
+		host  string
+		match string
+	}{
+		{"example.com", "example.com"},
+		{"a.b.EXAMPLE.com.", "example.com"},
+		{"notexample.com", ""},
+		{"com", ""},
+		{"ads.net", ""},
+		{"x.ads.net", "*.ads.net"},
+		{"y.x.ads.net", "*.ads.net"},
+		{"b.a.tracker.io", "a.tracker.io"},
+		{"b.tracker.io", "tracker.io"},
+		{"bad..host", ""},
+	} {
+		if m, ok := s.Match(tt.host); m != tt.match || ok != (tt.match != "") {
+			t.Errorf("Match failed for %s: got %q, expected %q.\n", tt.host, m, tt.match)
+		}
+	}
+	if !s.Contains("example.com", "x.ads.net") || s.Contains("example.com", "ads.net") {
+		t.Errorf("Contains failed.\n")
+	}
+	want := []string{"example.com", "tracker.io", "a.tracker.io", "*.ads.net"}
+	if l := s.List(); !slices.Equal(l, want) {
+		t.Errorf("List failed: got %v, expected %v.\n", l, want)
+	}
+
+	c := s.Copy()
+	s.Remove("tracker.io", "*.example.com", "ads.net", "missing.org")
+	if s.Len() != 3 || s.Contains("b.tracker.io") || !s.Contains("b.a.tracker.io", "example.com") {
+		t.Errorf("Remove failed: got %v.\n", s)
+	}
+	s.Remove("a.tracker.io")
+	if _, ok := s.root.next["io"]; ok {
+		t.Errorf("Remove failed: empty nodes not pruned.\n")
+	}
+	if c.Len() != 4 || !c.Contains("b.tracker.io") {
+		t.Errorf("Copy failed: got %v.\n", c)
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.Contains("example.com") || s.String() != "{ }" {
+		t.Errorf("Clear failed: got %v.\n", s)
+	}
+}
+
+func TestDomainSetErrors(t *testing.T) {
+	s := NewDomainSet()
+	// a small stand-in for golang.org/x/net/publicsuffix
+	s.SetPublicSuffixList(func(d string) (string, bool) {
+		for _, ps := range []string{"co.uk", "com", "uk"} {
+			if d == ps || strings.HasSuffix(d, "."+ps) {
+				return ps, true
+			}
+		}
+		return d[strings.LastIndexByte(d, '.')+1:], false
+	})
+	err := s.Add("example.co.uk", "co.uk", "-bad-.com", "*.com", "*.example.com", strings.Repeat("a", 64)+".com")
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Pos != 1 || !errors.Is(err, ErrPublicSuffix) || !errors.Is(err, ErrInvalidDomain) {
+		t.Errorf("Add failed: got %v.\n", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 4 {
+		t.Errorf("Add failed: %d errors, expected 4.\n", n)
+	}
+	if s.Len() != 2 || !s.Contains("www.example.co.uk", "a.example.com") || s.Contains("other.co.uk") {
+		t.Errorf("Add failed: got %v.\n", s)
+	}
+}
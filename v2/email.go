@@ -0,0 +1,85 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidEmail is the cause of a *ValidationError for a malformed email
+// address.
+var ErrInvalidEmail = errors.New("set: invalid email address")
+
+// ----- sets of email addresses -----
+
+// EmailOptions controls the normalization of email addresses. The domain is
+// always lower cased, as domains are not case sensitive.
+type EmailOptions struct {
+	// LowerLocal lower cases the local part. It is case sensitive by the
+	// standard, but not for practically all mail providers.
+	LowerLocal bool
+
+	// FoldPlus removes subaddresses, the part of the local part from the
+	// first '+', so "user+news@example.com" becomes "user@example.com".
+	FoldPlus bool
+
+	// FoldGmail applies the rules of Gmail to its addresses: the local part
+	// is lower cased, dots and subaddresses are removed, and googlemail.com
+	// becomes gmail.com.
+	FoldGmail bool
+}
+
+// EmailCanonicalizer returns a function which normalizes email addresses
+// with the given options, for use with WithCanonicalizer. Surrounding white
+// space is removed, and strings without '@' are only lower cased.
+func EmailCanonicalizer(o EmailOptions) func(string) string {
+	return func(a string) string {
+		a = strings.TrimSpace(a)
+		i := strings.LastIndexByte(a, '@')
+		if i < 0 {
+			return strings.ToLower(a)
+		}
+		local, domain := a[:i], strings.TrimSuffix(strings.ToLower(a[i+1:]), ".")
+		gmail := o.FoldGmail && (domain == "gmail.com" || domain == "googlemail.com")
+		if o.LowerLocal || gmail {
+			local = strings.ToLower(local)
+		}
+		if o.FoldPlus || gmail {
+			if j := strings.IndexByte(local, '+'); j > 0 {
+				local = local[:j]
+			}
+		}
+		if gmail {
+			local, domain = strings.ReplaceAll(local, ".", ""), "gmail.com"
+		}
+		return local + "@" + domain
+	}
+}
+
+// validEmail checks the syntax of a normalized email address: a local part of
+// up to 64 printable characters without white space, and a valid domain.
+func validEmail(a string) error {
+	i := strings.LastIndexByte(a, '@')
+	if i <= 0 || i > 64 || strings.ContainsFunc(a[:i], func(r rune) bool { return r <= ' ' || r == 0x7f }) {
+		return ErrInvalidEmail
+	}
+	if _, wild, ok := parseDomain(a[i+1:]); !ok || wild || !strings.Contains(a[i+1:], ".") {
+		return ErrInvalidEmail
+	}
+	return nil
+}
+
+// NewEmailSet creates a new set of email addresses, like a suppression list,
+// and initializes it with the argument addresses. Addresses are normalized
+// with the given options when they are added and looked up, so Contains
+// finds the variants of an address. Malformed addresses are not added; use
+// TryAdd to detect them.
+func NewEmailSet(o EmailOptions, e ...string) Set[string] {
+	s := NewWith(WithCanonicalizer(EmailCanonicalizer(o)), WithValidator(validEmail))
+	s.Add(e...)
+	return s
+}
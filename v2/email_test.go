@@ -0,0 +1,53 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmailCanonicalizer(t *testing.T) {
+	tests := []struct {
+		opts EmailOptions
+		in   string
+		want string
+	}{
+		{EmailOptions{}, " John.Doe+x@Example.COM ", "John.Doe+x@example.com"},
+		{EmailOptions{LowerLocal: true}, "John.Doe+x@Example.COM", "john.doe+x@example.com"},
+		{EmailOptions{FoldPlus: true}, "John+x+y@example.com", "John@example.com"},
+		{EmailOptions{FoldPlus: true}, "+x@example.com", "+x@example.com"},
+		{EmailOptions{FoldGmail: true}, "John.Doe+news@GoogleMail.com", "johndoe@gmail.com"},
+		{EmailOptions{FoldGmail: true}, "John.Doe+news@example.com", "John.Doe+news@example.com"},
+		{EmailOptions{}, "Not An Address", "not an address"},
+		{EmailOptions{}, `"a@b"@example.com.`, `"a@b"@example.com`},
+	}
+	for _, tt := range tests {
+		if got := EmailCanonicalizer(tt.opts)(tt.in); got != tt.want {
+			t.Errorf("EmailCanonicalizer(%+v) failed for %q: got %q, expected %q.\n", tt.opts, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEmailSet(t *testing.T) {
+	s := NewEmailSet(EmailOptions{LowerLocal: true, FoldGmail: true}, "Jane.Doe@gmail.com", "bob@Example.org", "bad")
+	if s.Len() != 2 || !s.Contains("janedoe+promo@googlemail.com", "BOB@example.org") || s.Contains("bob+x@example.org") {
+		t.Errorf("NewEmailSet failed: got %v.\n", s)
+	}
+	s.Remove("JANE.DOE@gmail.com")
+	if s.Contains("janedoe@gmail.com") {
+		t.Errorf("Remove failed: got %v.\n", s)
+	}
+
+	err := s.TryAdd("a@b.com", "@example.com", "a b@example.com", "a@localhost", "a@-x-.com")
+	var ve *ValidationError[string]
+	if !errors.As(err, &ve) || !errors.Is(err, ErrInvalidEmail) || !s.Contains("a@b.com") || s.Len() != 2 {
+		t.Errorf("TryAdd failed: got %v, set %v.\n", err, s)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 4 {
+		t.Errorf("TryAdd failed: %d errors, expected 4.\n", n)
+	}
+}
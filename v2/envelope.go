@@ -0,0 +1,148 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// ----- verified envelopes of serialized sets -----
+
+// Serialized sets, like the output of MarshalBinary, can be sealed in an
+// envelope which detects corruption, truncation or tampering when they are
+// distributed, e.g. blocklists sent to edge nodes. There are three kinds of
+// envelopes: with a CRC-32C checksum against accidental corruption, with an
+// HMAC-SHA256 for parties sharing a secret key, and with an Ed25519 signature
+// which is verified with a public key.
+//
+// An envelope consists of the magic bytes "SEV1", the kind byte, the length
+// of the payload as a uvarint, the payload, and the checksum, MAC or
+// signature over all preceding bytes. Each Open function only accepts its
+// own kind of envelope, so a signature cannot be stripped by relabeling a
+// signed envelope as checksummed. The payload returned by the Open functions
+// shares its storage with the envelope.
+
+var (
+	// ErrEnvelope is returned for data which is not a complete envelope of
+	// the expected kind.
+	ErrEnvelope = errors.New("set: malformed or truncated envelope")
+
+	// ErrChecksum is returned for an envelope whose checksum does not match.
+	ErrChecksum = errors.New("set: envelope checksum mismatch")
+
+	// ErrSignature is returned for an envelope whose MAC or signature is not
+	// valid for the key.
+	ErrSignature = errors.New("set: invalid envelope signature")
+)
+
+// the magic bytes of an envelope
+const envelopeMagic = "SEV1"
+
+// the kinds of envelopes
+const (
+	envelopeChecksum byte = iota
+	envelopeHMAC
+	envelopeEd25519
+)
+
+// the CRC-32C table
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// envelopeHeader returns the header of an envelope for the payload.
+func envelopeHeader(kind byte, payload []byte, trailer int) []byte {
+	b := make([]byte, 0, len(envelopeMagic)+1+binary.MaxVarintLen64+len(payload)+trailer)
+	b = append(b, envelopeMagic...)
+	b = append(b, kind)
+	b = binary.AppendUvarint(b, uint64(len(payload)))
+	return append(b, payload...)
+}
+
+// openEnvelope checks the framing of an envelope of the given kind, and
+// returns the signed part, the payload and the trailer.
+func openEnvelope(data []byte, kind byte, trailer int) (signed, payload, tail []byte, err error) {
+	if len(data) < len(envelopeMagic)+1 || string(data[:len(envelopeMagic)]) != envelopeMagic ||
+		data[len(envelopeMagic)] != kind {
+		return nil, nil, nil, ErrEnvelope
+	}
+	hdr := len(envelopeMagic) + 1
+	n, k := binary.Uvarint(data[hdr:])
+	if k <= 0 || n > uint64(len(data)) {
+		return nil, nil, nil, ErrEnvelope
+	}
+	end := hdr + k + int(n)
+	if len(data) != end+trailer {
+		return nil, nil, nil, ErrEnvelope
+	}
+	return data[:end], data[hdr+k : end], data[end:], nil
+}
+
+// SealChecksum returns the payload in an envelope with a CRC-32C checksum.
+func SealChecksum(payload []byte) []byte {
+	b := envelopeHeader(envelopeChecksum, payload, 4)
+	return binary.BigEndian.AppendUint32(b, crc32.Checksum(b, castagnoli))
+}
+
+// OpenChecksum verifies an envelope created by SealChecksum and returns its
+// payload.
+func OpenChecksum(data []byte) ([]byte, error) {
+	signed, payload, tail, err := openEnvelope(data, envelopeChecksum, 4)
+	if err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(signed, castagnoli) != binary.BigEndian.Uint32(tail) {
+		return nil, ErrChecksum
+	}
+	return payload, nil
+}
+
+// SealHMAC returns the payload in an envelope with an HMAC-SHA256 for the
+// secret key.
+func SealHMAC(payload, key []byte) []byte {
+	b := envelopeHeader(envelopeHMAC, payload, sha256.Size)
+	m := hmac.New(sha256.New, key)
+	m.Write(b)
+	return m.Sum(b)
+}
+
+// OpenHMAC verifies an envelope created by SealHMAC with the same key and
+// returns its payload.
+func OpenHMAC(data, key []byte) ([]byte, error) {
+	signed, payload, tail, err := openEnvelope(data, envelopeHMAC, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	m := hmac.New(sha256.New, key)
+	m.Write(signed)
+	if !hmac.Equal(m.Sum(nil), tail) {
+		return nil, ErrSignature
+	}
+	return payload, nil
+}
+
+// SealEd25519 returns the payload in an envelope signed with the Ed25519
+// private key.
+func SealEd25519(payload []byte, key ed25519.PrivateKey) []byte {
+	b := envelopeHeader(envelopeEd25519, payload, ed25519.SignatureSize)
+	return append(b, ed25519.Sign(key, b)...)
+}
+
+// OpenEd25519 verifies an envelope created by SealEd25519 with the public key
+// of the signer and returns its payload.
+func OpenEd25519(data []byte, key ed25519.PublicKey) ([]byte, error) {
+	signed, payload, tail, err := openEnvelope(data, envelopeEd25519, ed25519.SignatureSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != ed25519.PublicKeySize || !ed25519.Verify(key, signed, tail) {
+		return nil, ErrSignature
+	}
+	return payload, nil
+}
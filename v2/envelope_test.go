@@ -0,0 +1,81 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	a := NewAllocator[uint16](0, 1000)
+	a.Reserve(5, 10)
+	a.Reserve(500, 500)
+	payload, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v.\n", err)
+	}
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	key, otherKey := []byte("secret"), []byte("guess")
+
+	kinds := []struct {
+		name     string
+		sealed   []byte
+		open     func([]byte) ([]byte, error)
+		openBad  func([]byte) ([]byte, error) // with the wrong key
+		errFlip  error                        // the error for a modified payload
+		openLike func([]byte) ([]byte, error) // of another kind
+	}{
+		{"checksum", SealChecksum(payload), OpenChecksum, OpenChecksum, ErrChecksum,
+			func(b []byte) ([]byte, error) { return OpenHMAC(b, key) }},
+		{"hmac", SealHMAC(payload, key),
+			func(b []byte) ([]byte, error) { return OpenHMAC(b, key) },
+			func(b []byte) ([]byte, error) { return OpenHMAC(b, otherKey) }, ErrSignature, OpenChecksum},
+		{"ed25519", SealEd25519(payload, priv),
+			func(b []byte) ([]byte, error) { return OpenEd25519(b, pub) },
+			func(b []byte) ([]byte, error) { return OpenEd25519(b, otherPub) }, ErrSignature, OpenChecksum},
+	}
+	for _, k := range kinds {
+		got, err := k.open(k.sealed)
+		if err != nil || string(got) != string(payload) {
+			t.Errorf("%s: Open failed: got %v/%v.\n", k.name, got, err)
+			continue
+		}
+		b := NewAllocator[uint16](0, 1000)
+		if err := b.UnmarshalBinary(got); err != nil || !b.IsAllocated(500) {
+			t.Errorf("%s: UnmarshalBinary of the payload failed: %v.\n", k.name, err)
+		}
+
+		if k.name != "checksum" {
+			if _, err := k.openBad(k.sealed); !errors.Is(err, ErrSignature) {
+				t.Errorf("%s: Open with the wrong key failed: got %v.\n", k.name, err)
+			}
+		}
+		flipped := append([]byte(nil), k.sealed...)
+		flipped[8] ^= 1
+		if _, err := k.open(flipped); !errors.Is(err, k.errFlip) {
+			t.Errorf("%s: Open of a modified envelope failed: got %v.\n", k.name, err)
+		}
+		if _, err := k.open(k.sealed[:len(k.sealed)-1]); !errors.Is(err, ErrEnvelope) {
+			t.Errorf("%s: Open of a truncated envelope failed: got %v.\n", k.name, err)
+		}
+		if _, err := k.openLike(k.sealed); !errors.Is(err, ErrEnvelope) {
+			t.Errorf("%s: Open as another kind failed: got %v.\n", k.name, err)
+		}
+	}
+
+	// relabeling a signed envelope as checksummed does not strip the signature
+	relabeled := SealHMAC(payload, key)
+	relabeled[4] = envelopeChecksum
+	if _, err := OpenChecksum(relabeled); err == nil {
+		t.Errorf("OpenChecksum failed: relabeled HMAC envelope accepted.\n")
+	}
+	if _, err := OpenChecksum([]byte("SEV")); !errors.Is(err, ErrEnvelope) {
+		t.Errorf("OpenChecksum failed: got %v for a short input.\n", err)
+	}
+}
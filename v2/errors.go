@@ -0,0 +1,61 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ----- errors of loaders, converters and strict constructors -----
+
+var (
+	// ErrDuplicate matches the *DuplicateError of the strict constructors
+	// and loaders with errors.Is.
+	ErrDuplicate = errors.New("set: duplicate element")
+
+	// ErrWrongType is the error of a *DecodeError for an input value which
+	// is not of the element type of the set.
+	ErrWrongType = errors.New("set: element of wrong type")
+)
+
+// A DecodeError is returned when an element of a bulk input cannot be
+// decoded or converted. Pos is the position of the element in the input,
+// counting from 0.
+type DecodeError struct {
+	Pos   int    // the position of the element
+	Input string // the offending input, if it is textual
+	Err   error  // the cause
+}
+
+func (e *DecodeError) Error() string {
+	if e.Input == "" {
+		return fmt.Sprintf("set: cannot decode element %d: %v", e.Pos, e.Err)
+	}
+	return fmt.Sprintf("set: cannot decode element %d %q: %v", e.Pos, e.Input, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// FromAny creates a new set from values of an unknown type, like the
+// elements of a decoded JSON array or the columns of a database row. Values
+// which are not of type T are not added; the returned error joins a
+// *DecodeError wrapping ErrWrongType for each of them.
+func FromAny[T comparable](v ...any) (Set[T], error) {
+	s := New[T]()
+	var errs []error
+	for i, x := range v {
+		e, ok := x.(T)
+		if !ok {
+			errs = append(errs, &DecodeError{Pos: i, Err: fmt.Errorf("%w: %T", ErrWrongType, x)})
+			continue
+		}
+		s.set[e] = struct{}{}
+	}
+	return s, errors.Join(errs...)
+}
@@ -0,0 +1,37 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	s, err := FromAny[string]("a", 1, "b", 2.5)
+	var de *DecodeError
+	if !errors.Is(err, ErrWrongType) || !errors.As(err, &de) || de.Pos != 1 {
+		t.Errorf("FromAny failed: expected a DecodeError at position 1, got %v.\n", err)
+	}
+	if !s.IsEqual(New("a", "b")) {
+		t.Errorf("FromAny failed: got %v.\n", s)
+	}
+
+	_, err = NewStrict(1, 2, 1)
+	if !errors.Is(err, ErrDuplicate) {
+		t.Errorf("NewStrict failed: expected ErrDuplicate, got %v.\n", err)
+	}
+
+	_, err = ParsePortSet("80,http,443")
+	if !errors.As(err, &de) || de.Pos != 1 || de.Input != "http" {
+		t.Errorf("ParsePortSet failed: expected a DecodeError at position 1, got %v.\n", err)
+	}
+
+	err = NewHWAddrSet().AddString("00:1a:2b:00:00:01", "bogus")
+	if !errors.As(err, &de) || de.Pos != 1 {
+		t.Errorf("AddString failed: expected a DecodeError at position 1, got %v.\n", err)
+	}
+}
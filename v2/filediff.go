@@ -0,0 +1,74 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ErrUnsorted is the cause of a *DecodeError for a line of a file which is
+// not in ascending order.
+var ErrUnsorted = errors.New("set: input not sorted")
+
+// ----- difference against a sorted file -----
+
+// DiffAgainstFile compares a set of strings with a text file of one element
+// per line, sorted in ascending byte order like the output of "LC_ALL=C
+// sort", e.g. a large nightly export. It returns the lines of the file which
+// are not in s as added, and the elements of s which are not in the file as
+// removed. The file is streamed and never held in memory; only the sorted
+// elements of s and the result are. Empty lines are skipped, and line
+// endings may be "\n" or "\r\n". A line out of order is reported as a
+// *DecodeError wrapping ErrUnsorted, with the line number as its position.
+func DiffAgainstFile(s Set[string], path string) (added, removed Set[string], err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Set[string]{}, Set[string]{}, err
+	}
+	defer f.Close()
+	return DiffAgainstReader(s, f)
+}
+
+// DiffAgainstReader is like DiffAgainstFile, but reads the sorted lines from
+// r.
+func DiffAgainstReader(s Set[string], r io.Reader) (added, removed Set[string], err error) {
+	elems := slices.Sorted(s.All())
+	added, removed = New[string](), New[string]()
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	var prev string
+	for pos := 0; sc.Scan(); pos++ {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" || line == prev {
+			continue
+		}
+		if line < prev {
+			return Set[string]{}, Set[string]{}, &DecodeError{Pos: pos, Input: line, Err: ErrUnsorted}
+		}
+		prev = line
+		for len(elems) > 0 && elems[0] < line {
+			removed.set[elems[0]] = struct{}{}
+			elems = elems[1:]
+		}
+		if len(elems) > 0 && elems[0] == line {
+			elems = elems[1:]
+		} else {
+			added.set[line] = struct{}{}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return Set[string]{}, Set[string]{}, err
+	}
+	for _, e := range elems {
+		removed.set[e] = struct{}{}
+	}
+	return added, removed, nil
+}
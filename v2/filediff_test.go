@@ -0,0 +1,41 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffAgainstFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.txt")
+	if err := os.WriteFile(path, []byte("apple\nbanana\r\nbanana\n\ncherry\nfig\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := New("banana", "date", "fig", "zucchini")
+	added, removed, err := DiffAgainstFile(s, path)
+	if err != nil || !added.IsEqual(New("apple", "cherry")) || !removed.IsEqual(New("date", "zucchini")) {
+		t.Errorf("DiffAgainstFile failed: got %v, %v, %v.\n", added, removed, err)
+	}
+
+	added, removed, err = DiffAgainstReader(New[string](), strings.NewReader(""))
+	if err != nil || !added.IsEmpty() || !removed.IsEmpty() {
+		t.Errorf("DiffAgainstReader failed on empty input: got %v, %v, %v.\n", added, removed, err)
+	}
+
+	_, _, err = DiffAgainstReader(s, strings.NewReader("a\nc\nb\n"))
+	var de *DecodeError
+	if !errors.Is(err, ErrUnsorted) || !errors.As(err, &de) || de.Pos != 2 || de.Input != "b" {
+		t.Errorf("DiffAgainstReader failed: got %v for unsorted input.\n", err)
+	}
+
+	if _, _, err := DiffAgainstFile(s, filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("DiffAgainstFile failed: got %v for a missing file.\n", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ----- Flag definition -----
+
+// A Flag is a command line flag which populates a set, for use with
+// flag.Var. It implements the flag.Value and flag.Getter interfaces. The
+// flag may be repeated, and each value may hold several elements separated
+// by the separator of the set, so "-tag=a -tag=b,c" yields the set of a, b
+// and c. Elements are parsed like by UnmarshalText.
+//
+// The elements of the set before the first use of the flag are its
+// default, which is replaced by the given elements, and shown in the usage
+// message.
+type Flag[T comparable] struct {
+	s       *Set[T]
+	opt     FlagOptions[T]
+	changed bool // if the flag was used, and the default replaced
+}
+
+// FlagOptions holds the options of a Flag.
+type FlagOptions[T comparable] struct {
+	// Allowed is the universe of the allowed elements. If it is nil, all
+	// elements are allowed which the set accepts.
+	Allowed *Universe[T]
+
+	// RejectDuplicates makes an element which is given more than once an
+	// error, as a *DuplicateError.
+	RejectDuplicates bool
+}
+
+// ----- constructor -----
+
+// NewFlag creates a new flag which populates the set s. A nil set of s is
+// initialized when the flag is used.
+func NewFlag[T comparable](s *Set[T], o FlagOptions[T]) *Flag[T] {
+	return &Flag[T]{s: s, opt: o}
+}
+
+// ----- methods that modify the receiver -----
+
+// Set implements the flag.Value interface. It adds the elements of the value
+// to the set, or fails without adding any of them if an element cannot be
+// parsed, is not allowed, or is a rejected duplicate. Empty elements are
+// skipped.
+func (f *Flag[T]) Set(v string) error {
+	var l []T
+	var errs []error
+	for i, t := range strings.Split(v, f.s.separator()) {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		e, err := parseText[T](t)
+		if errors.Is(err, ErrWrongType) {
+			return err
+		}
+		if err != nil {
+			return &DecodeError{Pos: i, Input: t, Err: err}
+		}
+		if f.opt.Allowed != nil && !f.opt.Allowed.Contains(e) {
+			errs = append(errs, &ValidationError[T]{Elem: e, Err: ErrForeignElement})
+			continue
+		}
+		l = append(l, e)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// the default is only replaced if the first value is valid
+	target := *f.s
+	if !f.changed || target.set == nil {
+		target = Set[T]{set: make(map[T]struct{}, len(l)), cfg: f.s.cfg}
+	}
+	add := target.TryAdd
+	if f.opt.RejectDuplicates {
+		add = target.AddStrict
+	}
+	if err := add(l...); err != nil {
+		return err
+	}
+	*f.s = target
+	f.changed = true
+	return nil
+}
+
+// ----- methods that do not modify the receiver -----
+
+// String implements the flag.Value interface. It returns the elements of the
+// set in ascending order of their text, joined by the separator of the set,
+// or the empty string for the zero Flag.
+func (f *Flag[T]) String() string {
+	if f == nil || f.s == nil {
+		return ""
+	}
+	l := make([]string, 0, len(f.s.set))
+	for k := range f.s.set {
+		t, err := formatText(k)
+		if err != nil {
+			t = fmt.Sprint(k)
+		}
+		l = append(l, t)
+	}
+	slices.Sort(l)
+	return strings.Join(l, f.s.separator())
+}
+
+// Get implements the flag.Getter interface. It returns the set.
+func (f *Flag[T]) Get() any {
+	return *f.s
+}
+
+// Changed tells if the flag was used on the command line, so the set no
+// longer holds the default.
+func (f *Flag[T]) Changed() bool {
+	return f.changed
+}
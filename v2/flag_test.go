@@ -0,0 +1,88 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFlag(t *testing.T) {
+	tags := New("default")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	f := NewFlag(&tags, FlagOptions[string]{})
+	fs.Var(f, "tag", "tags")
+	if f.String() != "default" || f.Changed() {
+		t.Errorf("Flag failed for the default: got %q.\n", f.String())
+	}
+	if err := fs.Parse([]string{"-tag=a", "-tag", "b, c", "-tag=a,"}); err != nil {
+		t.Fatalf("Parse failed: got %v.\n", err)
+	}
+	if !tags.IsEqual(New("a", "b", "c")) || !f.Changed() {
+		t.Errorf("Flag failed: got %v.\n", tags)
+	}
+	if g := fs.Lookup("tag").Value.(flag.Getter).Get().(Set[string]); !g.IsEqual(tags) {
+		t.Errorf("Get failed: got %v.\n", g)
+	}
+	if f.String() != "a,b,c" {
+		t.Errorf("String failed: got %q.\n", f.String())
+	}
+
+	// the zero Flag, as used by flag.PrintDefaults
+	if s := new(Flag[string]).String(); s != "" {
+		t.Errorf("String failed for the zero Flag: got %q.\n", s)
+	}
+	var sb strings.Builder
+	fs.SetOutput(&sb)
+	fs.PrintDefaults()
+	if !strings.Contains(sb.String(), "(default default)") {
+		t.Errorf("PrintDefaults failed: got %q.\n", sb.String())
+	}
+}
+
+func TestFlagOptions(t *testing.T) {
+	// a nil set, with duplicates rejected
+	var ports Set[int]
+	f := NewFlag(&ports, FlagOptions[int]{RejectDuplicates: true})
+	if err := f.Set("80,443"); err != nil || !ports.IsEqual(New(80, 443)) {
+		t.Errorf("Set failed: got %v, %v.\n", ports, err)
+	}
+	if err := f.Set("8080,80"); !errors.Is(err, ErrDuplicate) || ports.Len() != 2 {
+		t.Errorf("Set failed for a duplicate: got %v, %v.\n", ports, err)
+	}
+	if err := f.Set("80x"); err == nil {
+		t.Errorf("Set failed: no error for an invalid element.\n")
+	}
+	var de *DecodeError
+	if err := f.Set("1,2,x"); !errors.As(err, &de) || de.Pos != 2 || ports.Len() != 2 {
+		t.Errorf("Set failed: got %v.\n", err)
+	}
+
+	// allowed values
+	levels := NewUniverse("levels", "debug", "info", "warn", "error")
+	s := New("info")
+	f2 := NewFlag(&s, FlagOptions[string]{Allowed: levels})
+	if err := f2.Set("trace,warn"); !errors.Is(err, ErrForeignElement) || !s.IsEqual(New("info")) || f2.Changed() {
+		t.Errorf("Set failed for a foreign element: got %v, %v.\n", s, err)
+	}
+	if err := f2.Set("warn;error"); !errors.Is(err, ErrForeignElement) {
+		t.Errorf("Set failed for the wrong separator: got %v.\n", err)
+	}
+	if err := f2.Set("warn,error"); err != nil || !s.IsEqual(New("warn", "error")) {
+		t.Errorf("Set failed: got %v, %v.\n", s, err)
+	}
+
+	// the separator and configuration of the set
+	u := NewWith(WithSeparator[string](" "), WithCanonicalizer(strings.ToLower))
+	f3 := NewFlag(&u, FlagOptions[string]{})
+	if err := f3.Set("A  b"); err != nil || !u.IsEqual(New("a", "b")) || f3.String() != "a b" {
+		t.Errorf("Set failed with a configured set: got %v, %v.\n", u, err)
+	}
+}
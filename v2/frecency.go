@@ -0,0 +1,157 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+)
+
+// ----- FrecencySet definition -----
+
+// A FrecencySet is a set where each element carries a score combining the
+// frequency and recency of its use, like the entries of an autocomplete or
+// "most recently used" list. Each Touch adds 1 to the score of an element,
+// and scores decay exponentially with the given half-life. Elements whose
+// score decayed below a minimum are pruned automatically.
+type FrecencySet[T comparable] struct {
+	halfLife time.Duration
+	minScore float64
+	now      func() time.Time
+	entries  map[T]*frecencyEntry
+	touches  int // touches since the last pruning
+}
+
+// the score of an element at the time of its last update
+type frecencyEntry struct {
+	score   float64
+	updated time.Time
+}
+
+// Scored is an element together with its score.
+type Scored[T comparable] struct {
+	Elem  T
+	Score float64
+}
+
+// ----- constructor -----
+
+// NewFrecency creates a new frecency set with the given half-life of scores.
+// Elements with a score below minScore are pruned. Like time.NewTicker, it
+// panics if the half-life is not positive, which would make all scores NaN.
+func NewFrecency[T comparable](halfLife time.Duration, minScore float64) *FrecencySet[T] {
+	if halfLife <= 0 {
+		panic("set: non-positive half-life for NewFrecency")
+	}
+	return &FrecencySet[T]{
+		halfLife: halfLife,
+		minScore: minScore,
+		now:      time.Now,
+		entries:  map[T]*frecencyEntry{},
+	}
+}
+
+// decayed returns the score of the entry at time t.
+func (s *FrecencySet[T]) decayed(e *frecencyEntry, t time.Time) float64 {
+	return e.score * math.Exp2(-float64(t.Sub(e.updated))/float64(s.halfLife))
+}
+
+// ----- methods that modify the receiver -----
+
+// Touch records a use of one or more elements, adding them to the set if
+// necessary.
+func (s *FrecencySet[T]) Touch(e ...T) {
+	t := s.now()
+	for _, i := range e {
+		if ent, ok := s.entries[i]; ok {
+			ent.score = s.decayed(ent, t) + 1
+			ent.updated = t
+		} else {
+			s.entries[i] = &frecencyEntry{score: 1, updated: t}
+		}
+	}
+	// pruning after every len(entries) touches keeps the amortized cost constant
+	if s.touches += len(e); s.touches >= len(s.entries) {
+		s.Prune()
+	}
+}
+
+// Remove removes one or more elements from the given set.
+func (s *FrecencySet[T]) Remove(e ...T) {
+	for _, i := range e {
+		delete(s.entries, i)
+	}
+}
+
+// Prune removes all elements whose score decayed below the minimum score.
+func (s *FrecencySet[T]) Prune() {
+	t := s.now()
+	for k, e := range s.entries {
+		if s.decayed(e, t) < s.minScore {
+			delete(s.entries, k)
+		}
+	}
+	s.touches = 0
+}
+
+// Clear removes all elements from the given set.
+func (s *FrecencySet[T]) Clear() {
+	clear(s.entries)
+	s.touches = 0
+}
+
+// Reset removes all elements from the given set, and replaces its map with a
+// new one presized for capacityHint elements.
+func (s *FrecencySet[T]) Reset(capacityHint int) {
+	s.entries = make(map[T]*frecencyEntry, max(capacityHint, 0))
+	s.touches = 0
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Len returns the number of elements in the set, including decayed elements
+// which were not yet pruned.
+func (s *FrecencySet[T]) Len() int {
+	return len(s.entries)
+}
+
+// Contains checks if a set contains one or more elements with a score of at
+// least the minimum score.
+func (s *FrecencySet[T]) Contains(e ...T) bool {
+	t := s.now()
+	for _, i := range e {
+		ent, ok := s.entries[i]
+		if !ok || s.decayed(ent, t) < s.minScore {
+			return false
+		}
+	}
+	return true
+}
+
+// Score returns the current score of an element, or 0 if it is not in the set.
+func (s *FrecencySet[T]) Score(e T) float64 {
+	if ent, ok := s.entries[e]; ok {
+		return s.decayed(ent, s.now())
+	}
+	return 0
+}
+
+// Top returns up to k elements with the highest current scores, in
+// descending order of score. Decayed elements are not returned. For k <= 0,
+// the result is empty.
+func (s *FrecencySet[T]) Top(k int) []Scored[T] {
+	t := s.now()
+	r := make([]Scored[T], 0, len(s.entries))
+	for e, ent := range s.entries {
+		if sc := s.decayed(ent, t); sc >= s.minScore {
+			r = append(r, Scored[T]{e, sc})
+		}
+	}
+	slices.SortFunc(r, func(a, b Scored[T]) int { return cmp.Compare(b.Score, a.Score) })
+	return r[:max(0, min(k, len(r)))]
+}
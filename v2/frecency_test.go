@@ -0,0 +1,74 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFrecencySet(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewFrecency[string](time.Hour, 0.2)
+	s.now = func() time.Time { return now }
+
+	s.Touch("vim", "vim", "emacs")
+	now = now.Add(time.Hour)
+	s.Touch("nano")
+
+	if sc := s.Score("vim"); math.Abs(sc-1) > 1e-9 {
+		t.Errorf("Score failed: vim has score %v, expected 1.\n", sc)
+	}
+	top := s.Top(2)
+	if len(top) != 2 || top[0].Elem != "vim" && top[0].Elem != "nano" || top[1].Elem == "emacs" {
+		t.Errorf("Top failed: got %v.\n", top)
+	}
+
+	// after two more hours, emacs has score 0.125 and is pruned
+	now = now.Add(2 * time.Hour)
+	if s.Contains("emacs") || !s.Contains("vim") {
+		t.Errorf("Contains failed: decayed element still contained.\n")
+	}
+	s.Prune()
+	if s.Len() != 2 {
+		t.Errorf("Prune failed: got %d elements, expected 2.\n", s.Len())
+	}
+	if top := s.Top(5); len(top) != 2 || top[0].Elem != "nano" && top[0].Elem != "vim" {
+		t.Errorf("Top failed: got %v.\n", top)
+	}
+
+	s.Remove("vim")
+	if s.Score("vim") != 0 || s.Len() != 1 {
+		t.Errorf("Remove failed.\n")
+	}
+	s.Clear()
+	if s.Len() != 0 {
+		t.Errorf("Clear failed.\n")
+	}
+}
+
+func TestFrecencyLimits(t *testing.T) {
+	s := NewFrecency[string](time.Hour, 0.1)
+	s.Touch("a", "b")
+	if top := s.Top(-1); len(top) != 0 {
+		t.Errorf("Top failed for negative k: got %v.\n", top)
+	}
+	if top := s.Top(0); len(top) != 0 {
+		t.Errorf("Top failed for k = 0: got %v.\n", top)
+	}
+
+	for _, hl := range []time.Duration{0, -time.Second} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewFrecency failed: half-life %v accepted.\n", hl)
+				}
+			}()
+			NewFrecency[string](hl, 0.1)
+		}()
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"weak"
+
+	"github.com/hweidner/set/v2/internal/elemcodec"
+)
+
+// ----- Frozen definition -----
+
+// A Frozen set is an immutable, comparable representation of a set. Equal
+// sets have equal frozen representations, so frozen sets can be compared
+// with ==, used as map keys and stored in other sets, like
+// Set[Frozen[string]] for a set of sets. The zero value is the empty set.
+//
+// Elements of kind string, bool, integer and floating point are stored by
+// value, like structs and arrays of them. Pointers and channels are stored by
+// their address, and the frozen set keeps them alive, so frozen sets of them
+// are only meaningful within the process.
+type Frozen[T comparable] struct {
+	key  string          // the sorted element encodings
+	n    int             // the number of elements
+	refs *elemcodec.Refs // the values referenced by key, shared by equal frozen sets
+}
+
+// sharedRefs holds the references of the live frozen sets by key, so equal
+// frozen sets share them and compare equal. Its entries are removed when
+// their frozen sets are garbage collected.
+var sharedRefs = struct {
+	sync.Mutex
+	m map[string]weak.Pointer[elemcodec.Refs]
+}{m: map[string]weak.Pointer[elemcodec.Refs]{}}
+
+// shareRefs returns the references of the live frozen sets with the given
+// key, or registers refs for them.
+func shareRefs(key string, refs *elemcodec.Refs) *elemcodec.Refs {
+	sharedRefs.Lock()
+	defer sharedRefs.Unlock()
+	if r := sharedRefs.m[key].Value(); r != nil {
+		return r
+	}
+	sharedRefs.m[key] = weak.Make(refs)
+	runtime.AddCleanup(refs, func(key string) {
+		sharedRefs.Lock()
+		defer sharedRefs.Unlock()
+		if sharedRefs.m[key].Value() == nil {
+			delete(sharedRefs.m, key)
+		}
+	}, key)
+	return refs
+}
+
+// Frozen returns the frozen representation of the set.
+func (s Set[T]) Frozen() Frozen[T] {
+	enc := make([]string, 0, len(s.set))
+	var b []byte
+	refs := &elemcodec.Refs{}
+	for k := range s.set {
+		b = elemcodec.Append(b[:0], k, refs)
+		enc = append(enc, string(b))
+	}
+	slices.Sort(enc)
+	f := Frozen[T]{key: strings.Join(enc, ""), n: len(enc)}
+	if refs.Len() > 0 {
+		f.refs = shareRefs(f.key, refs)
+	}
+	return f
+}
+
+// Len returns the length of the frozen set.
+func (f Frozen[T]) Len() int {
+	return f.n
+}
+
+// IsEmpty tests if the frozen set is empty.
+func (f Frozen[T]) IsEmpty() bool {
+	return f.n == 0
+}
+
+// All returns an iterator to all elements of the frozen set, in the order of
+// their encodings.
+func (f Frozen[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		b := []byte(f.key)
+		for len(b) > 0 {
+			var e T
+			e, b = elemcodec.Decode[T](b, f.refs)
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// Contains checks if the frozen set contains one or more elements. It takes
+// O(n) steps; use Thaw for repeated lookups.
+func (f Frozen[T]) Contains(e ...T) bool {
+	return f.Thaw().Contains(e...)
+}
+
+// Thaw returns the elements of the frozen set as a new, mutable set.
+func (f Frozen[T]) Thaw() Set[T] {
+	s := Set[T]{set: make(map[T]struct{}, f.n)}
+	for e := range f.All() {
+		s.set[e] = struct{}{}
+	}
+	return s
+}
+
+// String returns a textual representation of the frozen set, like the one of
+// Set.
+func (f Frozen[T]) String() string {
+	return f.Thaw().String()
+}
@@ -0,0 +1,77 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFrozen(t *testing.T) {
+	a := New("x", "y", "z").Frozen()
+	b := New("z", "x", "y").Frozen()
+	if a != b || a == New("x", "y").Frozen() {
+		t.Errorf("Frozen failed: equality does not match the set contents.\n")
+	}
+	if (Frozen[string]{}) != New[string]().Frozen() {
+		t.Errorf("Frozen failed: zero value is not the empty set.\n")
+	}
+
+	// a set of sets
+	sets := New(a, b, New("x").Frozen())
+	if sets.Len() != 2 || !sets.Contains(New("x").Frozen()) {
+		t.Errorf("Frozen failed: got set of sets %v.\n", sets)
+	}
+	if !a.Thaw().IsEqual(New("x", "y", "z")) || a.Len() != 3 || !a.Contains("y") || a.IsEmpty() {
+		t.Errorf("Thaw failed: got %v.\n", a.Thaw())
+	}
+
+	// structs are stored by value
+	type point struct{ x, y int }
+	p := New(point{1, 2}, point{3, 4})
+	if p.Frozen() != Collect(p.All()).Frozen() || !p.Frozen().Thaw().IsEqual(p) {
+		t.Errorf("Frozen failed for struct elements: got %v.\n", p.Frozen())
+	}
+	if s := New(1, 2).Frozen().String(); s != "{ 1 2 }" && s != "{ 2 1 }" {
+		t.Errorf("String failed: got %q.\n", s)
+	}
+}
+
+func TestFrozenPointers(t *testing.T) {
+	x, y := 1, 2
+	a := New(&x, &y).Frozen()
+	if a != New(&y, &x).Frozen() || a == New(&x).Frozen() {
+		t.Errorf("Frozen failed: equality does not match the set contents.\n")
+	}
+	if s := a.Thaw(); !s.IsEqual(New(&x, &y)) {
+		t.Errorf("Thaw failed: got %v.\n", s)
+	}
+
+	// the references are released with the frozen sets
+	sharedRefs.Lock()
+	n := len(sharedRefs.m)
+	sharedRefs.Unlock()
+	for range 10 {
+		New(new(int)).Frozen()
+	}
+	for range 100 {
+		runtime.GC()
+		sharedRefs.Lock()
+		m := len(sharedRefs.m)
+		sharedRefs.Unlock()
+		if m <= n {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	sharedRefs.Lock()
+	defer sharedRefs.Unlock()
+	if len(sharedRefs.m) > n {
+		t.Errorf("Frozen failed: %d references of dropped frozen sets are kept.\n", len(sharedRefs.m)-n)
+	}
+	runtime.KeepAlive(a)
+}
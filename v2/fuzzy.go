@@ -0,0 +1,192 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ----- FuzzySet definition -----
+
+// A FuzzySet is a set of strings with an additional BK-tree index, which
+// allows typo tolerant lookups of the elements nearest to a query string in
+// terms of the Levenshtein edit distance.
+//
+// Removed elements stay in the index, but are never returned by lookups.
+// When they make up half of the index, it is rebuilt from the remaining
+// elements.
+type FuzzySet struct {
+	set  Set[string]
+	tree *bkTree
+}
+
+// a BK-tree, see https://en.wikipedia.org/wiki/BK-tree
+type bkTree struct {
+	root *bkNode
+	size int // the number of nodes, including removed elements
+}
+
+// a node of the BK-tree. The children are indexed by their distance to the node.
+type bkNode struct {
+	word     string
+	children map[int]*bkNode
+}
+
+// ----- constructor -----
+
+// NewFuzzy creates a new fuzzy set and initializes it with the argument values.
+func NewFuzzy(e ...string) FuzzySet {
+	s := FuzzySet{set: New[string](), tree: &bkTree{}}
+	s.Add(e...)
+	return s
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s FuzzySet) Add(e ...string) {
+	for _, i := range e {
+		if s.set.Contains(i) {
+			continue
+		}
+		s.set.Add(i)
+		s.tree.insert(i)
+	}
+}
+
+// Remove removes one or more elements from the given set.
+func (s FuzzySet) Remove(e ...string) {
+	s.set.Remove(e...)
+	if s.tree.size >= minFuzzyRebuild && s.tree.size >= 2*s.set.Len() {
+		s.rebuild()
+	}
+}
+
+// minFuzzyRebuild is the index size below which removed elements are kept in
+// the index, since rebuilding small indexes is not worth the effort.
+const minFuzzyRebuild = 64
+
+// rebuild replaces the index with a new one of the elements of the set.
+func (s FuzzySet) rebuild() {
+	*s.tree = bkTree{}
+	for i := range s.set.set {
+		s.tree.insert(i)
+	}
+}
+
+// Clear removes all elements from the given set, including the index.
+func (s FuzzySet) Clear() {
+	s.set.Clear()
+	*s.tree = bkTree{}
+}
+
+func (t *bkTree) insert(w string) {
+	if t.root == nil {
+		t.root = &bkNode{word: w}
+		t.size++
+		return
+	}
+	n := t.root
+	for {
+		d := Levenshtein(n.word, w)
+		if d == 0 {
+			return // re-added element, which is still in the tree
+		}
+		c, ok := n.children[d]
+		if !ok {
+			if n.children == nil {
+				n.children = map[int]*bkNode{}
+			}
+			n.children[d] = &bkNode{word: w}
+			t.size++
+			return
+		}
+		n = c
+	}
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Len returns the length of the set.
+func (s FuzzySet) Len() int {
+	return s.set.Len()
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s FuzzySet) Contains(e ...string) bool {
+	return s.set.Contains(e...)
+}
+
+// Nearest returns all elements with an edit distance to q of at most maxDist.
+// The elements are ordered by increasing distance, and lexically for equal
+// distances.
+func (s FuzzySet) Nearest(q string, maxDist int) []string {
+	type match struct {
+		word string
+		dist int
+	}
+	var r []match
+	stack := []*bkNode{}
+	if s.tree.root != nil {
+		stack = append(stack, s.tree.root)
+	}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d := Levenshtein(n.word, q)
+		if d <= maxDist && s.set.Contains(n.word) {
+			r = append(r, match{n.word, d})
+		}
+		// by the triangle inequality, only children within [d-maxDist, d+maxDist]
+		// can contain matches
+		for cd, c := range n.children {
+			if cd >= d-maxDist && cd <= d+maxDist {
+				stack = append(stack, c)
+			}
+		}
+	}
+	slices.SortFunc(r, func(a, b match) int {
+		return cmp.Or(cmp.Compare(a.dist, b.dist), cmp.Compare(a.word, b.word))
+	})
+	l := make([]string, len(r))
+	for i, m := range r {
+		l[i] = m.word
+	}
+	return l
+}
+
+// Set returns a copy of the elements of the fuzzy set as a plain set, so
+// modifications of the returned set do not bypass the index.
+func (s FuzzySet) Set() Set[string] {
+	return s.set.Copy()
+}
+
+// ----- edit distance -----
+
+// Levenshtein returns the edit distance between a and b, e.g. the minimal
+// number of rune insertions, deletions and substitutions to transform a into b.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,79 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0}, {"abc", "", 3}, {"kitten", "sitting", 3}, {"flaw", "lawn", 2}, {"über", "uber", 1},
+	}
+	for _, i := range tests {
+		if d := Levenshtein(i.a, i.b); d != i.want {
+			t.Errorf("Levenshtein failed: distance of %q and %q is %d, expected %d.\n", i.a, i.b, d, i.want)
+		}
+	}
+}
+
+func TestFuzzySet(t *testing.T) {
+	s := NewFuzzy("apple", "apply", "ample", "maple", "banana", "apple")
+	if s.Len() != 5 || !s.Contains("maple") {
+		t.Errorf("NewFuzzy failed: got %v.\n", s.Set())
+	}
+
+	str := fmt.Sprint(s.Nearest("appel", 2))
+	if str != "[apple apply]" {
+		t.Errorf("Nearest failed: got %v.\n", str)
+	}
+
+	s.Remove("apple")
+	str = fmt.Sprint(s.Nearest("apple", 1))
+	if str != "[ample apply]" {
+		t.Errorf("Remove/Nearest failed: got %v.\n", str)
+	}
+
+	s.Add("apple")
+	if n := s.Nearest("apple", 0); len(n) != 1 || n[0] != "apple" {
+		t.Errorf("Add/Nearest failed: got %v.\n", n)
+	}
+
+	s.Clear()
+	if s.Len() != 0 || len(s.Nearest("apple", 10)) != 0 {
+		t.Errorf("Clear failed: got %v.\n", s.Set())
+	}
+}
+
+func TestFuzzySetRebuild(t *testing.T) {
+	s := NewFuzzy()
+	for i := 0; i < 100; i++ {
+		s.Add(fmt.Sprintf("word%03d", i))
+	}
+	for i := 0; i < 49; i++ {
+		s.Remove(fmt.Sprintf("word%03d", i))
+	}
+	if s.tree.size != 100 {
+		t.Errorf("Remove failed: index rebuilt with %d nodes above half of the elements.\n", s.tree.size)
+	}
+	s.Remove("word049", "word050")
+	if s.tree.size != 49 || s.Len() != 49 {
+		t.Errorf("Remove failed: index has %d nodes for %d elements.\n", s.tree.size, s.Len())
+	}
+	if n := s.Nearest("word050", 1); len(n) != 13 || n[0] != "word051" {
+		t.Errorf("Nearest failed after rebuild: got %v.\n", n)
+	}
+
+	p := s.Set()
+	p.Add("injected")
+	if s.Contains("injected") {
+		t.Errorf("Set failed: the returned set is shared with the fuzzy set.\n")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "iter"
+
+// ----- GenerationSet definition -----
+
+// A GenerationSet is a set which is cleared in O(1) time, like the visited
+// set of a graph algorithm which is reset for each of millions of searches.
+// Each element is stored with the generation in which it was added, and
+// Clear starts a new generation, so elements of older generations are no
+// longer in the set. Their entries are reused when they are added again.
+//
+// The map keeps the entries of all elements ever added, until the
+// generation counter wraps around after 2^32 clears, or Reset is called.
+// For small integer elements, SparseSet has an O(1) Clear as well, without
+// hashing.
+type GenerationSet[T comparable] struct {
+	gen   uint32
+	stamp map[T]uint32 // the generation of each element
+	len   int          // the number of elements of the current generation
+}
+
+// ----- constructor -----
+
+// NewGeneration creates a new generation set and initializes it with the
+// argument values.
+func NewGeneration[T comparable](e ...T) *GenerationSet[T] {
+	s := &GenerationSet[T]{gen: 1, stamp: make(map[T]uint32, len(e))}
+	s.Add(e...)
+	return s
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s *GenerationSet[T]) Add(e ...T) {
+	for _, x := range e {
+		s.Visit(x)
+	}
+}
+
+// Visit adds an element to the given set, and reports whether it was new,
+// like for marking the nodes of a graph as visited.
+func (s *GenerationSet[T]) Visit(e T) bool {
+	if s.stamp[e] == s.gen {
+		return false
+	}
+	s.stamp[e] = s.gen
+	s.len++
+	return true
+}
+
+// Remove removes one or more elements from the given set.
+func (s *GenerationSet[T]) Remove(e ...T) {
+	for _, x := range e {
+		if s.stamp[x] == s.gen {
+			s.stamp[x] = 0
+			s.len--
+		}
+	}
+}
+
+// Clear removes all elements from the given set in O(1) time, by starting a
+// new generation. When the generation counter wraps around, the map is
+// cleared.
+func (s *GenerationSet[T]) Clear() {
+	s.len = 0
+	if s.gen++; s.gen == 0 {
+		clear(s.stamp)
+		s.gen = 1
+	}
+}
+
+// Reset removes all elements from the given set, and replaces its map with
+// a new one presized for capacityHint elements, which releases the entries
+// of the elements of older generations.
+func (s *GenerationSet[T]) Reset(capacityHint int) {
+	s.gen, s.len = 1, 0
+	s.stamp = make(map[T]uint32, max(capacityHint, 0))
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *GenerationSet[T]) IsEmpty() bool {
+	return s.len == 0
+}
+
+// Len returns the length of the set.
+func (s *GenerationSet[T]) Len() int {
+	return s.len
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *GenerationSet[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if s.stamp[x] != s.gen {
+			return false
+		}
+	}
+	return true
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all elements of the set, in an undefined
+// order. It visits the entries of older generations as well, so it takes
+// time proportional to all elements ever added since the last Reset.
+func (s *GenerationSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for x, g := range s.stamp {
+			if g == s.gen && !yield(x) {
+				return
+			}
+		}
+	}
+}
+
+// Set returns the elements as a new set.
+func (s *GenerationSet[T]) Set() Set[T] {
+	r := Set[T]{set: make(map[T]struct{}, s.len)}
+	for x := range s.All() {
+		r.set[x] = struct{}{}
+	}
+	return r
+}
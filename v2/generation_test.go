@@ -0,0 +1,52 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "testing"
+
+func TestGenerationSet(t *testing.T) {
+	s := NewGeneration(1, 2, 3)
+	if !s.Contains(1, 2, 3) || s.Len() != 3 || s.Visit(2) || !s.Visit(4) {
+		t.Errorf("Visit failed: got %v.\n", s.Set())
+	}
+	s.Remove(1, 5)
+	if s.Contains(1) || s.Len() != 3 || !s.Set().IsEqual(New(2, 3, 4)) {
+		t.Errorf("Remove failed: got %v.\n", s.Set())
+	}
+
+	s.Clear()
+	if !s.IsEmpty() || s.Contains(2) || s.Set().Len() != 0 {
+		t.Errorf("Clear failed: got %v.\n", s.Set())
+	}
+	s.Add(3, 7)
+	if !s.Set().IsEqual(New(3, 7)) || s.Len() != 2 {
+		t.Errorf("Add failed after Clear: got %v.\n", s.Set())
+	}
+
+	// the map is cleared when the generation counter wraps around
+	s.gen = ^uint32(0)
+	s.stamp[9] = s.gen
+	s.Clear()
+	if s.gen != 1 || len(s.stamp) != 0 || s.Contains(9) {
+		t.Errorf("Clear failed at the wrap around: generation %d with %d entries.\n", s.gen, len(s.stamp))
+	}
+
+	s.Add(1)
+	s.Reset(10)
+	if !s.IsEmpty() || len(s.stamp) != 0 {
+		t.Errorf("Reset failed.\n")
+	}
+}
+
+func BenchmarkGenerationClear(b *testing.B) {
+	s := NewGeneration[int]()
+	for i := 0; i < b.N; i++ {
+		for v := 0; v < 100; v++ {
+			s.Visit(v)
+		}
+		s.Clear()
+	}
+}
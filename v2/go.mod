@@ -1,3 +1,3 @@
 module github.com/hweidner/set/v2
 
-go 1.23
+go 1.24
@@ -0,0 +1,95 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"context"
+	"sync"
+)
+
+// ----- Guard definition -----
+
+// A Guard admits at most one goroutine per key at a time, so concurrent work
+// on the same key is serialized or deduplicated. The set of active keys is
+// released when the admitted goroutines finish. All methods are safe for
+// concurrent use. The zero value is not usable; use NewGuard.
+type Guard[T comparable] struct {
+	mu     sync.Mutex
+	active map[T]chan struct{} // closed when the key is released
+}
+
+// ----- constructor -----
+
+// NewGuard creates a new guard without active keys.
+func NewGuard[T comparable]() *Guard[T] {
+	return &Guard[T]{active: map[T]chan struct{}{}}
+}
+
+// ----- methods -----
+
+// Acquire waits until the key is not active, then marks it as active and
+// returns a function which releases it. If the context is cancelled before
+// the key becomes available, the context error is returned.
+func (g *Guard[T]) Acquire(ctx context.Context, key T) (release func(), err error) {
+	for {
+		release, wait := g.try(key)
+		if release != nil {
+			return release, nil
+		}
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// TryAcquire marks the key as active and returns a function which releases
+// it. If the key is already active, it returns false without waiting.
+func (g *Guard[T]) TryAcquire(key T) (release func(), ok bool) {
+	release, _ = g.try(key)
+	return release, release != nil
+}
+
+// try acquires the key, or returns a channel which is closed when the
+// current holder releases it.
+func (g *Guard[T]) try(key T) (release func(), wait <-chan struct{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ch, busy := g.active[key]; busy {
+		return nil, ch
+	}
+	ch := make(chan struct{})
+	g.active[key] = ch
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			delete(g.active, key)
+			g.mu.Unlock()
+			close(ch)
+		})
+	}, nil
+}
+
+// IsActive checks if the key is currently held by a goroutine.
+func (g *Guard[T]) IsActive(key T) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.active[key]
+	return ok
+}
+
+// Active returns a snapshot of the currently active keys.
+func (g *Guard[T]) Active() Set[T] {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r := Set[T]{set: make(map[T]struct{}, len(g.active))}
+	for k := range g.active {
+		r.set[k] = struct{}{}
+	}
+	return r
+}
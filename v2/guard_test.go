@@ -0,0 +1,67 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGuard(t *testing.T) {
+	g := NewGuard[string]()
+
+	release, ok := g.TryAcquire("job1")
+	if !ok || !g.IsActive("job1") {
+		t.Fatalf("TryAcquire failed on an inactive key.\n")
+	}
+	if _, ok := g.TryAcquire("job1"); ok {
+		t.Errorf("TryAcquire failed: acquired an active key.\n")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.Acquire(ctx, "job1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire failed: expected deadline error, got %v.\n", err)
+	}
+
+	release()
+	release() // releasing twice is harmless
+	if g.IsActive("job1") || !g.Active().IsEmpty() {
+		t.Errorf("release failed: key is still active.\n")
+	}
+}
+
+func TestGuardConcurrent(t *testing.T) {
+	g := NewGuard[int]()
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := g.Acquire(context.Background(), 42)
+			if err != nil {
+				t.Errorf("Acquire failed: %v.\n", err)
+				return
+			}
+			n := running.Add(1)
+			if n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			release()
+		}()
+	}
+	wg.Wait()
+	if maxRunning.Load() != 1 {
+		t.Errorf("Acquire failed: %d goroutines held the same key.\n", maxRunning.Load())
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package hamt provides an immutable, persistent set backed by a hash array
+mapped trie (HAMT).
+
+Add and Remove return new sets, which share all unchanged parts of the trie
+with the original. Deriving a set takes O(log n) time and space instead of a
+copy of the whole set, so snapshots are cheap, and sets can be read by any
+number of goroutines without locking.
+*/
+package hamt
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+
+	"github.com/hweidner/set/v2"
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+// the number of hash bits consumed per trie level
+const (
+	levelBits = 5
+	levelMask = 1<<levelBits - 1
+)
+
+// ----- ImmutableSet definition -----
+
+// An ImmutableSet is an immutable set. Its zero value is an empty set, ready
+// to use.
+type ImmutableSet[T comparable] struct {
+	root *node[T]
+	size int
+}
+
+// a trie node; entries holds one entry per bit set in bitmap
+type node[T comparable] struct {
+	bitmap  uint32
+	entries []entry[T]
+}
+
+// an entry is either a child node, or a leaf with the elements of one hash
+type entry[T comparable] struct {
+	child *node[T]
+	hash  uint64
+	elems []T // more than one on hash collisions
+}
+
+// ----- constructors -----
+
+// New creates a new immutable set with the argument values.
+func New[T comparable](e ...T) ImmutableSet[T] {
+	return ImmutableSet[T]{}.Add(e...)
+}
+
+// FromSet creates a new immutable set with the elements of s.
+func FromSet[T comparable](s set.Set[T]) ImmutableSet[T] {
+	var r ImmutableSet[T]
+	for e := range s.All() {
+		r = r.Add(e)
+	}
+	return r
+}
+
+// ----- methods that return a new set -----
+
+// Add returns a new set with the elements of s and the arguments. The set s
+// is not modified.
+func (s ImmutableSet[T]) Add(e ...T) ImmutableSet[T] {
+	for _, i := range e {
+		root, added := s.root.insert(hash.Of(i), i, 0)
+		if added {
+			s = ImmutableSet[T]{root: root, size: s.size + 1}
+		}
+	}
+	return s
+}
+
+// Remove returns a new set with the elements of s except the arguments. The
+// set s is not modified.
+func (s ImmutableSet[T]) Remove(e ...T) ImmutableSet[T] {
+	for _, i := range e {
+		root, removed := s.root.remove(hash.Of(i), i, 0)
+		if removed {
+			s = ImmutableSet[T]{root: root, size: s.size - 1}
+		}
+	}
+	return s
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s ImmutableSet[T]) IsEmpty() bool {
+	return s.size == 0
+}
+
+// Len returns the length of the set.
+func (s ImmutableSet[T]) Len() int {
+	return s.size
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s ImmutableSet[T]) Contains(e ...T) bool {
+	for _, i := range e {
+		if !s.root.contains(hash.Of(i), i) {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns an iterator to all elements in the set in an undefined order.
+func (s ImmutableSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.root.all(yield)
+	}
+}
+
+// ToSet returns the elements of the immutable set as a new, mutable set.
+func (s ImmutableSet[T]) ToSet() set.Set[T] {
+	return set.Collect(s.All())
+}
+
+// String returns a textual representation of the set in a string, in the
+// format of set.Set.
+func (s ImmutableSet[T]) String() string {
+	return s.ToSet().String()
+}
+
+// ----- trie operations -----
+
+// index returns the bit and the position of the hash in a node of the level
+// starting at the given shift.
+func (n *node[T]) index(h uint64, shift uint) (bit uint32, pos int) {
+	bit = 1 << ((h >> shift) & levelMask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// contains checks if the trie contains e with the hash h.
+func (n *node[T]) contains(h uint64, e T) bool {
+	for shift := uint(0); n != nil; shift += levelBits {
+		bit, pos := n.index(h, shift)
+		if n.bitmap&bit == 0 {
+			return false
+		}
+		ent := &n.entries[pos]
+		if ent.child == nil {
+			return ent.hash == h && slices.Index(ent.elems, e) >= 0
+		}
+		n = ent.child
+	}
+	return false
+}
+
+// insert returns a copy of the trie with e added, and whether e was added.
+// If e is already in the trie, the trie itself is returned.
+func (n *node[T]) insert(h uint64, e T, shift uint) (*node[T], bool) {
+	if n == nil {
+		n = &node[T]{}
+	}
+	bit, pos := n.index(h, shift)
+	if n.bitmap&bit == 0 {
+		c := &node[T]{bitmap: n.bitmap | bit, entries: make([]entry[T], len(n.entries)+1)}
+		copy(c.entries, n.entries[:pos])
+		c.entries[pos] = entry[T]{hash: h, elems: []T{e}}
+		copy(c.entries[pos+1:], n.entries[pos:])
+		return c, true
+	}
+
+	ent := n.entries[pos]
+	switch {
+	case ent.child != nil:
+		child, added := ent.child.insert(h, e, shift+levelBits)
+		if !added {
+			return n, false
+		}
+		ent = entry[T]{child: child}
+	case ent.hash == h:
+		if slices.Index(ent.elems, e) >= 0 {
+			return n, false
+		}
+		ent.elems = append(ent.elems[:len(ent.elems):len(ent.elems)], e)
+	default:
+		// split the leaf into a child node with both hashes
+		child := &node[T]{}
+		child.insertLeaf(ent, shift+levelBits)
+		child, _ = child.insert(h, e, shift+levelBits)
+		ent = entry[T]{child: child}
+	}
+	c := &node[T]{bitmap: n.bitmap, entries: append([]entry[T](nil), n.entries...)}
+	c.entries[pos] = ent
+	return c, true
+}
+
+// insertLeaf inserts an existing leaf into a new, empty node in place. It is
+// only used when splitting leaves.
+func (n *node[T]) insertLeaf(leaf entry[T], shift uint) {
+	bit, pos := n.index(leaf.hash, shift)
+	n.bitmap |= bit
+	n.entries = append(n.entries, entry[T]{})
+	copy(n.entries[pos+1:], n.entries[pos:])
+	n.entries[pos] = leaf
+}
+
+// remove returns a copy of the trie without e, and whether e was removed. If
+// e is not in the trie, the trie itself is returned. Empty nodes are removed,
+// and nodes with a single leaf are collapsed into their parent.
+func (n *node[T]) remove(h uint64, e T, shift uint) (*node[T], bool) {
+	if n == nil {
+		return nil, false
+	}
+	bit, pos := n.index(h, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	ent := n.entries[pos]
+	if ent.child != nil {
+		child, removed := ent.child.remove(h, e, shift+levelBits)
+		if !removed {
+			return n, false
+		}
+		switch {
+		case child == nil:
+			return n.without(bit, pos), true
+		case len(child.entries) == 1 && child.entries[0].child == nil:
+			ent = child.entries[0] // collapse the single leaf
+		default:
+			ent = entry[T]{child: child}
+		}
+	} else {
+		i := slices.Index(ent.elems, e)
+		if ent.hash != h || i < 0 {
+			return n, false
+		}
+		if len(ent.elems) == 1 {
+			return n.without(bit, pos), true
+		}
+		elems := make([]T, 0, len(ent.elems)-1)
+		ent.elems = append(append(elems, ent.elems[:i]...), ent.elems[i+1:]...)
+	}
+	c := &node[T]{bitmap: n.bitmap, entries: append([]entry[T](nil), n.entries...)}
+	c.entries[pos] = ent
+	return c, true
+}
+
+// without returns a copy of the node without the entry at pos, or nil if the
+// node would be empty.
+func (n *node[T]) without(bit uint32, pos int) *node[T] {
+	if len(n.entries) == 1 {
+		return nil
+	}
+	c := &node[T]{bitmap: n.bitmap &^ bit, entries: make([]entry[T], 0, len(n.entries)-1)}
+	c.entries = append(append(c.entries, n.entries[:pos]...), n.entries[pos+1:]...)
+	return c
+}
+
+// all yields all elements of the trie. It returns false if the iteration
+// was stopped.
+func (n *node[T]) all(yield func(T) bool) bool {
+	if n == nil {
+		return true
+	}
+	for _, ent := range n.entries {
+		if ent.child != nil {
+			if !ent.child.all(yield) {
+				return false
+			}
+			continue
+		}
+		for _, e := range ent.elems {
+			if !yield(e) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package hamt
+
+import (
+	"iter"
+	"math"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+	"github.com/hweidner/set/v2/settest"
+)
+
+func TestImmutableSet(t *testing.T) {
+	var empty ImmutableSet[int]
+	a := empty.Add(1, 2, 3)
+	b := a.Add(4).Remove(1)
+	if !empty.IsEmpty() || a.Len() != 3 || !a.Contains(1, 2, 3) || a.Contains(4) {
+		t.Errorf("Add failed: the original set was modified: %v.\n", a)
+	}
+	if b.Len() != 3 || !b.Contains(2, 3, 4) || b.Contains(1) {
+		t.Errorf("Remove failed: got %v.\n", b)
+	}
+	if a.Add(1) != a || a.Remove(99) != a {
+		t.Errorf("Add/Remove failed: no-op returned a new set.\n")
+	}
+	if s := New("x").String(); s != "{ x }" {
+		t.Errorf("String failed: got %q.\n", s)
+	}
+}
+
+func TestImmutableSetLarge(t *testing.T) {
+	const n = 20000
+	s := New[int]()
+	ref := set.New[int]()
+	for i := 0; i < n; i++ {
+		s = s.Add(i * 7)
+		ref.Add(i * 7)
+	}
+	if s.Len() != n || !s.ToSet().IsEqual(ref) || !FromSet(ref).ToSet().IsEqual(ref) {
+		t.Fatalf("Add failed on %d elements: got length %d.\n", n, s.Len())
+	}
+	snap := s
+	for i := 0; i < n; i += 2 {
+		s = s.Remove(i * 7)
+	}
+	if s.Len() != n/2 || s.Contains(0) || !s.Contains(7) || snap.Len() != n || !snap.Contains(0) {
+		t.Errorf("Remove failed: got length %d, snapshot length %d.\n", s.Len(), snap.Len())
+	}
+	for i := 1; i < n; i += 2 {
+		s = s.Remove(i * 7)
+	}
+	if s.Len() != 0 || s.root != nil {
+		t.Errorf("Remove failed: trie not empty after removing all elements.\n")
+	}
+}
+
+func TestImmutableSetCollisions(t *testing.T) {
+	// different elements with the same hash share a leaf
+	c := node[string]{}
+	n, _ := c.insert(42, "a", 0)
+	n, _ = n.insert(42, "b", 0)
+	n, _ = n.insert(42|1<<40, "c", 0)
+	if !n.contains(42, "a") || !n.contains(42, "b") || !n.contains(42|1<<40, "c") || n.contains(42, "c") {
+		t.Errorf("insert failed on hash collisions.\n")
+	}
+	n, _ = n.remove(42, "a", 0)
+	n, _ = n.remove(42|1<<40, "c", 0)
+	if n.contains(42, "a") || !n.contains(42, "b") || len(n.entries) != 1 || n.entries[0].child != nil {
+		t.Errorf("remove failed on hash collisions.\n")
+	}
+}
+
+func TestImmutableSetNegativeZero(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	if !New(0.0).Contains(negZero) || New(0.0, negZero).Len() != 1 {
+		t.Errorf("Contains failed: 0.0 and -0.0 are different elements.\n")
+	}
+	type p struct{ f float64 }
+	if !New(p{0}).Contains(p{negZero}) || !New(p{1}).Remove(p{1}).IsEmpty() {
+		t.Errorf("Contains failed for structs with -0.0 fields.\n")
+	}
+}
+
+// mutable adapts an immutable set to the settest.Target interface.
+type mutable struct{ s *ImmutableSet[int] }
+
+func (m mutable) Add(e ...int)           { *m.s = m.s.Add(e...) }
+func (m mutable) Remove(e ...int)        { *m.s = m.s.Remove(e...) }
+func (m mutable) Contains(e ...int) bool { return m.s.Contains(e...) }
+func (m mutable) Len() int               { return m.s.Len() }
+func (m mutable) All() iter.Seq[int]     { return m.s.All() }
+func (m mutable) Clear()                 { *m.s = ImmutableSet[int]{} }
+
+func TestImmutableSetCheck(t *testing.T) {
+	settest.CheckBackend(t, mutable{&ImmutableSet[int]{}})
+}
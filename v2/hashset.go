@@ -0,0 +1,224 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"iter"
+)
+
+// ----- HashSet definition -----
+
+// A HashSet is a set of elements of any type, including types which are not
+// comparable, like slices, maps, protobuf messages or structs containing
+// them. Elements are identified by the user supplied functions hash and
+// equal: equal elements must have equal hashes, and the hash should spread
+// the elements uniformly, like one built with hash/maphash.
+//
+//	seed := maphash.MakeSeed()
+//	s := set.NewHash(func(b []byte) uint64 { return maphash.Bytes(seed, b) }, bytes.Equal)
+//
+// The elements are kept in buckets by hash, and lookups take O(1) time plus
+// one call of equal for each element with the same hash. Elements are stored
+// as they are, so an element which is modified while in the set, like
+// through the backing array of a slice, must be removed and added again.
+//
+// A HashSet is not an Interface, which requires comparable elements.
+type HashSet[T any] struct {
+	hash    func(T) uint64
+	equal   func(a, b T) bool
+	buckets map[uint64][]T
+	len     int
+}
+
+// ----- constructor -----
+
+// NewHash creates a new hash set with the given hash and equality functions,
+// and initializes it with the argument values.
+func NewHash[T any](hash func(T) uint64, equal func(a, b T) bool, e ...T) *HashSet[T] {
+	s := &HashSet[T]{hash: hash, equal: equal, buckets: make(map[uint64][]T, len(e))}
+	s.Add(e...)
+	return s
+}
+
+// find returns the hash of x, and the position of x in its bucket, or -1.
+func (s *HashSet[T]) find(x T) (uint64, int) {
+	h := s.hash(x)
+	for i, y := range s.buckets[h] {
+		if s.equal(x, y) {
+			return h, i
+		}
+	}
+	return h, -1
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set. An element equal to one
+// already in the set is not added; the set keeps the old one.
+func (s *HashSet[T]) Add(e ...T) {
+	for _, x := range e {
+		if h, i := s.find(x); i < 0 {
+			s.buckets[h] = append(s.buckets[h], x)
+			s.len++
+		}
+	}
+}
+
+// Remove removes one or more elements from the given set.
+func (s *HashSet[T]) Remove(e ...T) {
+	for _, x := range e {
+		h, i := s.find(x)
+		if i < 0 {
+			continue
+		}
+		b := s.buckets[h]
+		if len(b) == 1 {
+			delete(s.buckets, h)
+		} else {
+			b[i] = b[len(b)-1]
+			var zero T
+			b[len(b)-1] = zero // release the reference
+			s.buckets[h] = b[:len(b)-1]
+		}
+		s.len--
+	}
+}
+
+// Clear removes all elements from the given set.
+func (s *HashSet[T]) Clear() {
+	clear(s.buckets)
+	s.len = 0
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *HashSet[T]) IsEmpty() bool {
+	return s.len == 0
+}
+
+// Len returns the length of the set.
+func (s *HashSet[T]) Len() int {
+	return s.len
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *HashSet[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if _, i := s.find(x); i < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEqual tests if two hash sets have the same elements. Both sets must use
+// the same hash and equality functions.
+func (s *HashSet[T]) IsEqual(t *HashSet[T]) bool {
+	return s.len == t.len && s.IsSubsetOf(t)
+}
+
+// IsSubsetOf tests if s is a subset of t.
+func (s *HashSet[T]) IsSubsetOf(t *HashSet[T]) bool {
+	if s.len > t.len {
+		return false
+	}
+	for x := range s.All() {
+		if !t.Contains(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// ----- methods that return a new set -----
+
+// Copy returns a copy of the set, with the same hash and equality functions.
+// The elements themselves are not copied.
+func (s *HashSet[T]) Copy() *HashSet[T] {
+	r := &HashSet[T]{hash: s.hash, equal: s.equal, buckets: make(map[uint64][]T, len(s.buckets)), len: s.len}
+	for h, b := range s.buckets {
+		r.buckets[h] = append([]T(nil), b...)
+	}
+	return r
+}
+
+// Union returns a new set with the elements of s and the sets t. All sets
+// must use the same hash and equality functions.
+func (s *HashSet[T]) Union(t ...*HashSet[T]) *HashSet[T] {
+	r := s.Copy()
+	for _, i := range t {
+		for x := range i.All() {
+			r.Add(x)
+		}
+	}
+	return r
+}
+
+// Intersect returns a new set with the elements of s which are in all sets t.
+func (s *HashSet[T]) Intersect(t ...*HashSet[T]) *HashSet[T] {
+	r := NewHash(s.hash, s.equal)
+outer:
+	for x := range s.All() {
+		for _, i := range t {
+			if !i.Contains(x) {
+				continue outer
+			}
+		}
+		r.Add(x)
+	}
+	return r
+}
+
+// Diff returns a new set with the elements of s which are not in t.
+func (s *HashSet[T]) Diff(t *HashSet[T]) *HashSet[T] {
+	r := NewHash(s.hash, s.equal)
+	for x := range s.All() {
+		if !t.Contains(x) {
+			r.Add(x)
+		}
+	}
+	return r
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all elements of the set, in no particular
+// order.
+func (s *HashSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, b := range s.buckets {
+			for _, x := range b {
+				if !yield(x) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// List returns the set elements in a slice, in no particular order.
+func (s *HashSet[T]) List() []T {
+	l := make([]T, 0, s.len)
+	for x := range s.All() {
+		l = append(l, x)
+	}
+	return l
+}
+
+// String returns a string representation of the set, in no particular
+// order.
+func (s *HashSet[T]) String() string {
+	b := make([]byte, 0, 2+8*s.len)
+	b = append(b, "{ "...)
+	for x := range s.All() {
+		b = append(fmt.Append(b, x), ' ')
+	}
+	b = append(b, '}')
+	return string(b)
+}
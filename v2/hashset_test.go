@@ -0,0 +1,85 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"bytes"
+	"hash/maphash"
+	"slices"
+	"testing"
+)
+
+func TestHashSet(t *testing.T) {
+	seed := maphash.MakeSeed()
+	s := NewHash(func(b []byte) uint64 { return maphash.Bytes(seed, b) }, bytes.Equal,
+		[]byte("a"), []byte("b"), []byte("a"))
+	if s.Len() != 2 || !s.Contains([]byte("a"), []byte("b")) || s.Contains([]byte("c")) {
+		t.Errorf("NewHash failed: got %v.\n", s)
+	}
+	s.Add([]byte("c"))
+	s.Remove([]byte("a"), []byte("x"))
+	if s.Len() != 2 || s.Contains([]byte("a")) || s.IsEmpty() {
+		t.Errorf("Add or Remove failed: got %v.\n", s)
+	}
+	if c := s.Copy(); !c.IsEqual(s) || s.String() == "" {
+		t.Errorf("Copy failed: got %v.\n", c)
+	}
+	if c, ok := ConfigOf(s); !ok || c.Backend != HashBackend || s.Backend().String() != "hash" {
+		t.Errorf("Config failed: got %+v.\n", c)
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.Len() != 0 {
+		t.Errorf("Clear failed: got %v.\n", s)
+	}
+}
+
+func TestHashSetCollisions(t *testing.T) {
+	// a poor hash puts all slices of the same length in one bucket
+	hash := func(l []int) uint64 { return uint64(len(l)) }
+	newSet := func(e ...[]int) *HashSet[[]int] { return NewHash(hash, slices.Equal[[]int], e...) }
+
+	a := newSet([]int{1, 2}, []int{2, 1}, []int{3, 4}, []int{5})
+	b := newSet([]int{2, 1}, []int{5}, []int{6})
+	if a.Len() != 4 || !a.Contains([]int{3, 4}, []int{2, 1}) || a.Contains([]int{4, 3}) {
+		t.Errorf("Contains failed: got %v.\n", a)
+	}
+	if u := a.Union(b); u.Len() != 5 || !u.Contains([]int{6}) {
+		t.Errorf("Union failed: got %v.\n", u)
+	}
+	if i := a.Intersect(b); !i.IsEqual(newSet([]int{2, 1}, []int{5})) {
+		t.Errorf("Intersect failed: got %v.\n", i)
+	}
+	if d := a.Diff(b); !d.IsEqual(newSet([]int{1, 2}, []int{3, 4})) {
+		t.Errorf("Diff failed: got %v.\n", d)
+	}
+	if a.IsSubsetOf(b) || !newSet([]int{5}).IsSubsetOf(a) {
+		t.Errorf("IsSubsetOf failed.\n")
+	}
+
+	// removal from the middle of a bucket
+	a.Remove([]int{1, 2})
+	if a.Len() != 3 || a.Contains([]int{1, 2}) || !a.Contains([]int{2, 1}, []int{3, 4}) {
+		t.Errorf("Remove failed: got %v.\n", a)
+	}
+	if l := a.List(); len(l) != 3 {
+		t.Errorf("List failed: got %v.\n", l)
+	}
+	n := 0
+	for range a.All() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("All failed for a break: got %d.\n", n)
+	}
+
+	// a copy is independent of the original
+	c := a.Copy()
+	c.Remove([]int{2, 1})
+	if !a.Contains([]int{2, 1}) {
+		t.Errorf("Copy failed: the original was modified.\n")
+	}
+}
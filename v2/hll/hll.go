@@ -0,0 +1,145 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package hll provides HyperLogLog sketches, which estimate the number of distinct
+elements of very large streams in a small, fixed amount of memory.
+
+A Sketch counts the distinct elements of a whole stream; a Sliding sketch
+counts the distinct elements of a sliding time window, like the last hour.
+*/
+package hll
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+// the bounds of the precision
+const (
+	MinPrecision = 4
+	MaxPrecision = 16
+)
+
+// ErrPrecision is returned by Merge for sketches of different precisions.
+var ErrPrecision = errors.New("hll: sketches have different precisions")
+
+// ----- Sketch definition -----
+
+// A Sketch is a HyperLogLog sketch with 2^p registers. Its standard error is
+// about 1.04/sqrt(2^p), e.g. 1.6% for the precision 12, which takes 4 KiB.
+type Sketch struct {
+	p   uint8
+	reg []uint8
+}
+
+// New creates an empty sketch with the given precision, which is clamped to
+// [MinPrecision, MaxPrecision].
+func New(precision int) *Sketch {
+	p := clamp(precision)
+	return &Sketch{p: p, reg: make([]uint8, 1<<p)}
+}
+
+// clamp limits the precision to the supported range.
+func clamp(precision int) uint8 {
+	return uint8(min(max(precision, MinPrecision), MaxPrecision))
+}
+
+// split splits a hash into the register index and the rank, which is the
+// position of the first set bit of the remaining hash bits.
+func split(h uint64, p uint8) (idx uint64, rank uint8) {
+	idx = h >> (64 - p)
+	rank = uint8(bits.LeadingZeros64(h<<p|1<<(p-1))) + 1
+	return idx, rank
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds an element, given by its binary representation.
+func (s *Sketch) Add(b []byte) {
+	s.AddHash(hash.Sum64(b))
+}
+
+// AddString adds an element, given by its string representation.
+func (s *Sketch) AddString(e string) {
+	s.AddHash(hash.String(e))
+}
+
+// AddHash adds an element, given by a uniformly distributed 64 bit hash.
+func (s *Sketch) AddHash(h uint64) {
+	idx, rank := split(h, s.p)
+	s.reg[idx] = max(s.reg[idx], rank)
+}
+
+// Merge adds all elements of the sketch t to s, which yields a sketch of the
+// union of both streams. Both sketches must have the same precision.
+func (s *Sketch) Merge(t *Sketch) error {
+	if s.p != t.p {
+		return ErrPrecision
+	}
+	for i, r := range t.reg {
+		s.reg[i] = max(s.reg[i], r)
+	}
+	return nil
+}
+
+// Reset removes all elements from the sketch.
+func (s *Sketch) Reset() {
+	clear(s.reg)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Precision returns the precision of the sketch.
+func (s *Sketch) Precision() int {
+	return int(s.p)
+}
+
+// Estimate returns the estimated number of distinct elements.
+func (s *Sketch) Estimate() uint64 {
+	return estimate(s.reg)
+}
+
+// Count returns the estimated number of distinct elements, like Estimate.
+func (s *Sketch) Count() uint64 {
+	return s.Estimate()
+}
+
+// Clone returns a copy of the sketch.
+func (s *Sketch) Clone() *Sketch {
+	return &Sketch{p: s.p, reg: append([]uint8(nil), s.reg...)}
+}
+
+// estimate returns the HyperLogLog estimate for the given registers, with
+// linear counting for small cardinalities.
+func estimate(reg []uint8) uint64 {
+	m := float64(len(reg))
+	sum, zeros := 0.0, 0
+	for _, r := range reg {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	var alpha float64
+	switch len(reg) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	e := alpha * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(e + 0.5)
+}
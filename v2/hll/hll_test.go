@@ -0,0 +1,48 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package hll
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// within checks if the estimate is within the relative error of want.
+func within(got, want uint64, rel float64) bool {
+	return math.Abs(float64(got)-float64(want)) <= rel*float64(want)
+}
+
+func TestSketch(t *testing.T) {
+	s := New(12)
+	for i := 0; i < 100000; i++ {
+		s.AddString(strconv.Itoa(i % 50000))
+	}
+	if c := s.Estimate(); !within(c, 50000, 0.05) || c != s.Count() {
+		t.Errorf("Estimate failed: got %d, expected about 50000.\n", c)
+	}
+
+	small := New(12)
+	for i := 0; i < 100; i++ {
+		small.Add([]byte(strconv.Itoa(i)))
+	}
+	if c := small.Count(); !within(c, 100, 0.05) {
+		t.Errorf("Count failed for small cardinality: got %d.\n", c)
+	}
+
+	u := s.Clone()
+	if err := u.Merge(small); err != nil || u.Count() != s.Count() {
+		t.Errorf("Merge failed: subset changed the count: %d vs %d.\n", u.Count(), s.Count())
+	}
+	if err := u.Merge(New(10)); !errors.Is(err, ErrPrecision) {
+		t.Errorf("Merge failed: expected ErrPrecision, got %v.\n", err)
+	}
+	u.Reset()
+	if u.Count() != 0 || New(99).Precision() != MaxPrecision {
+		t.Errorf("Reset/New failed.\n")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package hll
+
+import (
+	"time"
+
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+// ----- Sliding definition -----
+
+// A Sliding sketch estimates the number of distinct elements seen within a
+// sliding time window, like "distinct visitors in the last hour", without
+// keeping exact sets per time bucket. It implements the sliding HyperLogLog
+// of Chabchoub and Hébrail: each register keeps the list of its possible
+// future maxima, which is short in practice. Counts can be queried for any
+// window up to the window given to NewSliding.
+type Sliding struct {
+	p      uint8
+	window time.Duration
+	now    func() time.Time
+	reg    [][]sample
+}
+
+// a sample of a register: a rank observed at a time
+type sample struct {
+	t    int64 // unix nanoseconds
+	rank uint8
+}
+
+// NewSliding creates an empty sliding sketch with the given precision, which
+// is clamped to [MinPrecision, MaxPrecision], and the maximum window.
+func NewSliding(precision int, window time.Duration) *Sliding {
+	p := clamp(precision)
+	return &Sliding{p: p, window: window, now: time.Now, reg: make([][]sample, 1<<p)}
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds an element, given by its binary representation, at the current
+// time.
+func (s *Sliding) Add(b []byte) {
+	s.AddHashAt(s.now(), hash.Sum64(b))
+}
+
+// AddString adds an element, given by its string representation, at the
+// current time.
+func (s *Sliding) AddString(e string) {
+	s.AddHashAt(s.now(), hash.String(e))
+}
+
+// AddHashAt adds an element, given by a uniformly distributed 64 bit hash,
+// at the time t. The times of consecutive calls must not decrease.
+func (s *Sliding) AddHashAt(t time.Time, h uint64) {
+	idx, rank := split(h, s.p)
+	ts := t.UnixNano()
+	list := s.reg[idx]
+
+	// drop the samples outside of the window and those which can never be
+	// a maximum again, since the new sample is younger and not smaller
+	cutoff := ts - int64(s.window)
+	i := 0
+	for i < len(list) && list[i].t <= cutoff {
+		i++
+	}
+	list = list[i:]
+	j := len(list)
+	for j > 0 && list[j-1].rank <= rank {
+		j--
+	}
+	s.reg[idx] = append(list[:j], sample{ts, rank})
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Count returns the estimated number of distinct elements in the full window.
+func (s *Sliding) Count() uint64 {
+	return s.CountWithin(s.window)
+}
+
+// CountWithin returns the estimated number of distinct elements added in the
+// last duration d, which is limited to the window of the sketch.
+func (s *Sliding) CountWithin(d time.Duration) uint64 {
+	return estimate(s.registers(min(d, s.window)))
+}
+
+// Sketch returns a snapshot of the last duration d as a plain sketch, which
+// can be merged with other sketches of the same precision.
+func (s *Sliding) Sketch(d time.Duration) *Sketch {
+	return &Sketch{p: s.p, reg: s.registers(min(d, s.window))}
+}
+
+// registers returns the register values of the last duration d. The samples
+// of a register are ordered by ascending time and descending rank, so the
+// first sample within the window is its maximum.
+func (s *Sliding) registers(d time.Duration) []uint8 {
+	cutoff := s.now().UnixNano() - int64(d)
+	reg := make([]uint8, len(s.reg))
+	for i, list := range s.reg {
+		for _, smp := range list {
+			if smp.t > cutoff {
+				reg[i] = smp.rank
+				break
+			}
+		}
+	}
+	return reg
+}
@@ -0,0 +1,58 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package hll
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSliding(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewSliding(12, time.Hour)
+	s.now = func() time.Time { return clock }
+
+	// 1000 new visitors per minute, plus the same 500 regulars every minute
+	for m := 0; m < 120; m++ {
+		if m > 0 {
+			clock = clock.Add(time.Minute)
+		}
+		for i := 0; i < 1000; i++ {
+			s.AddString(strconv.Itoa(m*1000 + i))
+		}
+		for i := 0; i < 500; i++ {
+			s.AddString("regular" + strconv.Itoa(i))
+		}
+	}
+
+	if c := s.Count(); !within(c, 60*1000+500, 0.05) {
+		t.Errorf("Count failed: got %d, expected about 60500.\n", c)
+	}
+	if c := s.CountWithin(10 * time.Minute); !within(c, 10*1000+500, 0.05) {
+		t.Errorf("CountWithin failed: got %d, expected about 10500.\n", c)
+	}
+	if c := s.CountWithin(24 * time.Hour); c != s.Count() {
+		t.Errorf("CountWithin failed: window not limited: %d.\n", c)
+	}
+	if c := s.Sketch(time.Hour).Count(); c != s.Count() {
+		t.Errorf("Sketch failed: got %d, expected %d.\n", c, s.Count())
+	}
+
+	// the register lists stay short
+	longest := 0
+	for _, l := range s.reg {
+		longest = max(longest, len(l))
+	}
+	if longest > 32 {
+		t.Errorf("AddHashAt failed: register list of %d samples.\n", longest)
+	}
+
+	clock = clock.Add(2 * time.Hour)
+	if c := s.Count(); c != 0 {
+		t.Errorf("Count failed after the window passed: got %d.\n", c)
+	}
+}
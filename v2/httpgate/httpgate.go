@@ -0,0 +1,131 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package httpgate provides an HTTP middleware, which admits or rejects requests
+by the membership of a key in a set, like the client IP in a blocklist, or an
+API key in an allowlist.
+
+The key is extracted from the request by a function, like RemoteIP or Header.
+The set is anything with a Contains method, like set.Set, set.COWSet or
+set.IPSet. For sets which are reloaded at runtime, use a set.COWSet, which is
+refreshed by the snapsync package, or a Live set for other set types.
+*/
+package httpgate
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"sync/atomic"
+)
+
+// Members is the interface of the sets of a gate.
+type Members[T comparable] interface {
+	Contains(e ...T) bool
+}
+
+// Mode is the mode of a gate.
+type Mode int
+
+const (
+	// Allow admits only the requests with keys in the set.
+	Allow Mode = iota
+
+	// Deny rejects the requests with keys in the set.
+	Deny
+)
+
+// Config is the configuration of a gate.
+type Config[T comparable] struct {
+	// Key extracts the key of a request. It returns false for requests
+	// without a key, which are rejected in Allow mode and admitted in Deny
+	// mode.
+	Key func(r *http.Request) (T, bool)
+
+	// Set is the set of keys.
+	Set Members[T]
+
+	// Mode selects if the set is an allowlist or a denylist.
+	Mode Mode
+
+	// Status is the HTTP status of rejected requests; 0 selects
+	// http.StatusForbidden.
+	Status int
+}
+
+// New returns a middleware, which passes the admitted requests to the next
+// handler, and rejects all others with the status of the configuration.
+func New[T comparable](c Config[T]) func(http.Handler) http.Handler {
+	status := c.Status
+	if status == 0 {
+		status = http.StatusForbidden
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k, ok := c.Key(r)
+			if ok {
+				ok = c.Set.Contains(k)
+			}
+			if ok != (c.Mode == Allow) {
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ----- key extractors -----
+
+// RemoteIP returns the IP address of the client from the remote address of
+// the connection, with IPv4-mapped addresses unmapped. Behind a proxy, it
+// is the address of the proxy.
+func RemoteIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	a, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return a.Unmap().WithZone(""), true
+}
+
+// Header returns a key extractor for the value of a request header, like an
+// API key. Requests without the header or with an empty value have no key.
+func Header(name string) func(r *http.Request) (string, bool) {
+	return func(r *http.Request) (string, bool) {
+		v := r.Header.Get(name)
+		return v, v != ""
+	}
+}
+
+// ----- live sets -----
+
+// A Live set holds a set which is replaced atomically, for set types without
+// their own copy-on-write variant, like set.IPSet. The replaced set must not
+// be modified after Store.
+type Live[T comparable] struct {
+	p atomic.Pointer[Members[T]]
+}
+
+// NewLive creates a live set with m as its first version.
+func NewLive[T comparable](m Members[T]) *Live[T] {
+	l := &Live[T]{}
+	l.Store(m)
+	return l
+}
+
+// Store replaces the set.
+func (l *Live[T]) Store(m Members[T]) {
+	l.p.Store(&m)
+}
+
+// Contains checks if the current set contains one or more elements.
+func (l *Live[T]) Contains(e ...T) bool {
+	return (*l.p.Load()).Contains(e...)
+}
@@ -0,0 +1,76 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package httpgate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+// ok is the handler behind the gates.
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+})
+
+// status returns the status of a request through the handler h.
+func status(h http.Handler, r *http.Request) int {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestAllow(t *testing.T) {
+	keys := set.NewCOW("k1", "k2")
+	h := New(Config[string]{Key: Header("X-API-Key"), Set: keys, Status: http.StatusUnauthorized})(ok)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if s := status(h, r); s != http.StatusUnauthorized {
+		t.Errorf("Allow failed: got %d for a request without key.\n", s)
+	}
+	r.Header.Set("X-API-Key", "k1")
+	if s := status(h, r); s != http.StatusNoContent {
+		t.Errorf("Allow failed: got %d for a known key.\n", s)
+	}
+	r.Header.Set("X-API-Key", "k3")
+	if s := status(h, r); s != http.StatusUnauthorized {
+		t.Errorf("Allow failed: got %d for an unknown key.\n", s)
+	}
+
+	// reloading the set takes effect immediately
+	keys.Swap(set.New("k3"))
+	if s := status(h, r); s != http.StatusNoContent {
+		t.Errorf("Allow failed: got %d after a reload.\n", s)
+	}
+}
+
+func TestDeny(t *testing.T) {
+	block := NewLive[netip.Addr](set.NewIPSet(netip.MustParsePrefix("10.0.0.0/8")))
+	h := New(Config[netip.Addr]{Key: RemoteIP, Set: block, Mode: Deny})(ok)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, tt := range []struct {
+		addr string
+		want int
+	}{
+		{"10.1.2.3:1234", http.StatusForbidden},
+		{"[::ffff:10.1.2.3]:1234", http.StatusForbidden},
+		{"192.0.2.1:1234", http.StatusNoContent},
+		{"garbage", http.StatusNoContent},
+	} {
+		r.RemoteAddr = tt.addr
+		if s := status(h, r); s != tt.want {
+			t.Errorf("Deny failed: got %d for %s, expected %d.\n", s, tt.addr, tt.want)
+		}
+	}
+
+	block.Store(set.NewIPSet(netip.MustParsePrefix("192.0.2.0/24")))
+	r.RemoteAddr = "192.0.2.1:1234"
+	if s := status(h, r); s != http.StatusForbidden {
+		t.Errorf("Deny failed: got %d after a reload.\n", s)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"iter"
+	"net"
+	"slices"
+)
+
+// ErrUnsupportedHWAddr is the cause of a *DecodeError for a hardware address
+// which is neither EUI-48 nor EUI-64.
+var ErrUnsupportedHWAddr = errors.New("set: unsupported hardware address")
+
+// ----- HWAddrSet definition -----
+
+// An HWAddrSet is a set of EUI-48 and EUI-64 hardware addresses (MAC
+// addresses). The addresses are stored as integers, grouped by their
+// organizationally unique identifier (OUI, the first three bytes), so all
+// addresses of a vendor prefix can be queried efficiently.
+type HWAddrSet struct {
+	byOUI map[uint32]Set[hwKey]
+	count *int
+}
+
+// the compact representation of a hardware address
+type hwKey struct {
+	addr uint64
+	size uint8 // 6 or 8 bytes
+}
+
+// ----- constructor -----
+
+// NewHWAddrSet creates a new, empty hardware address set.
+func NewHWAddrSet() HWAddrSet {
+	return HWAddrSet{byOUI: map[uint32]Set[hwKey]{}, count: new(int)}
+}
+
+// makeHWKey converts a hardware address into its compact representation.
+func makeHWKey(a net.HardwareAddr) (hwKey, uint32, error) {
+	if len(a) != 6 && len(a) != 8 {
+		return hwKey{}, 0, ErrUnsupportedHWAddr
+	}
+	k := hwKey{size: uint8(len(a))}
+	for _, b := range a {
+		k.addr = k.addr<<8 | uint64(b)
+	}
+	return k, uint32(a[0])<<16 | uint32(a[1])<<8 | uint32(a[2]), nil
+}
+
+// hardwareAddr converts the compact representation back into an address.
+func (k hwKey) hardwareAddr() net.HardwareAddr {
+	a := make(net.HardwareAddr, k.size)
+	for i, v := len(a)-1, k.addr; i >= 0; i, v = i-1, v>>8 {
+		a[i] = byte(v)
+	}
+	return a
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more addresses to the given set. Addresses which are
+// neither EUI-48 nor EUI-64 are not added; the returned error joins a
+// *DecodeError wrapping ErrUnsupportedHWAddr for each of them. The other
+// addresses are added nevertheless.
+func (s HWAddrSet) Add(a ...net.HardwareAddr) error {
+	var errs []error
+	for pos, i := range a {
+		if err := s.add(i); err != nil {
+			errs = append(errs, &DecodeError{Pos: pos, Input: i.String(), Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add adds a single address to the set.
+func (s HWAddrSet) add(a net.HardwareAddr) error {
+	k, oui, err := makeHWKey(a)
+	if err != nil {
+		return err
+	}
+	g, ok := s.byOUI[oui]
+	if !ok {
+		g = New[hwKey]()
+		s.byOUI[oui] = g
+	}
+	if !g.Contains(k) {
+		g.Add(k)
+		*s.count++
+	}
+	return nil
+}
+
+// AddString parses one or more addresses with net.ParseMAC and adds them to
+// the given set. Invalid or unsupported addresses are not added; the
+// returned error joins a *DecodeError for each of them. The other addresses
+// are added nevertheless.
+func (s HWAddrSet) AddString(a ...string) error {
+	var errs []error
+	for pos, i := range a {
+		h, err := net.ParseMAC(i)
+		if err == nil {
+			err = s.add(h)
+		}
+		if err != nil {
+			errs = append(errs, &DecodeError{Pos: pos, Input: i, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Remove removes one or more addresses from the given set.
+func (s HWAddrSet) Remove(a ...net.HardwareAddr) {
+	for _, i := range a {
+		k, oui, err := makeHWKey(i)
+		if err != nil {
+			continue
+		}
+		if g, ok := s.byOUI[oui]; ok && g.Contains(k) {
+			if g.Remove(k); g.IsEmpty() {
+				delete(s.byOUI, oui)
+			}
+			*s.count--
+		}
+	}
+}
+
+// Clear removes all addresses from the given set.
+func (s HWAddrSet) Clear() {
+	clear(s.byOUI)
+	*s.count = 0
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Len returns the number of addresses in the set.
+func (s HWAddrSet) Len() int {
+	return *s.count
+}
+
+// Contains checks if a set contains one or more addresses. The return value
+// is true only if all given addresses are in the set.
+func (s HWAddrSet) Contains(a ...net.HardwareAddr) bool {
+	for _, i := range a {
+		k, oui, err := makeHWKey(i)
+		if err != nil || !s.byOUI[oui].Contains(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsOUI checks if the set contains any address with the given
+// organizationally unique identifier.
+func (s HWAddrSet) ContainsOUI(oui [3]byte) bool {
+	_, ok := s.byOUI[uint32(oui[0])<<16|uint32(oui[1])<<8|uint32(oui[2])]
+	return ok
+}
+
+// WithOUI returns all addresses with the given organizationally unique
+// identifier in ascending order.
+func (s HWAddrSet) WithOUI(oui [3]byte) []net.HardwareAddr {
+	g := s.byOUI[uint32(oui[0])<<16|uint32(oui[1])<<8|uint32(oui[2])]
+	keys := g.List()
+	slices.SortFunc(keys, compareHWKeys)
+	r := make([]net.HardwareAddr, len(keys))
+	for i, k := range keys {
+		r[i] = k.hardwareAddr()
+	}
+	return r
+}
+
+// OUIs returns the number of distinct vendor prefixes in the set.
+func (s HWAddrSet) OUIs() int {
+	return len(s.byOUI)
+}
+
+func compareHWKeys(a, b hwKey) int {
+	if a.size != b.size {
+		return int(a.size) - int(b.size)
+	}
+	switch {
+	case a.addr < b.addr:
+		return -1
+	case a.addr > b.addr:
+		return 1
+	}
+	return 0
+}
+
+// ----- iterators -----
+
+// All returns an iterator to all addresses in the set in an undefined order.
+func (s HWAddrSet) All() iter.Seq[net.HardwareAddr] {
+	return func(yield func(net.HardwareAddr) bool) {
+		for _, g := range s.byOUI {
+			for k := range g.set {
+				if !yield(k.hardwareAddr()) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestHWAddrSet(t *testing.T) {
+	s := NewHWAddrSet()
+	err := s.AddString("00:1a:2b:00:00:02", "00:1a:2b:00:00:01", "00-1A-2B-00-00-01", "ac:de:48:00:11:22",
+		"02:00:5e:10:00:00:00:01")
+	if err != nil {
+		t.Fatalf("AddString failed: %v.\n", err)
+	}
+	if s.Len() != 4 || s.OUIs() != 3 {
+		t.Errorf("Len/OUIs failed: got %d/%d, expected 4/3.\n", s.Len(), s.OUIs())
+	}
+	if err := s.AddString("xx:yy"); err == nil {
+		t.Errorf("AddString failed: expected an error for an invalid address.\n")
+	}
+	// all errors are reported
+	err = s.AddString("xx:yy", "00:1a:2b:00:00:03", "zz")
+	if j, ok := err.(interface{ Unwrap() []error }); !ok || len(j.Unwrap()) != 2 || s.Len() != 5 {
+		t.Errorf("AddString failed: got %v, %d elements.\n", err, s.Len())
+	}
+	s.Remove(net.HardwareAddr{0x00, 0x1a, 0x2b, 0, 0, 3})
+	err = s.Add(net.HardwareAddr{0x00, 0x1a, 0x2b, 0, 0, 1}, net.HardwareAddr{1, 2, 3})
+	var de *DecodeError
+	if !errors.Is(err, ErrUnsupportedHWAddr) || !errors.As(err, &de) || de.Pos != 1 || de.Input != "01:02:03" {
+		t.Errorf("Add failed: got %v for a 3 byte address.\n", err)
+	}
+	if err := s.AddString("01:02:03:04:05:06:07:08:09:0a:0b:0c:0d:0e:0f:10:11:12:13:14"); !errors.Is(err, ErrUnsupportedHWAddr) {
+		t.Errorf("AddString failed: got %v for a 20 byte address.\n", err)
+	}
+
+	a, _ := net.ParseMAC("ac:de:48:00:11:22")
+	if !s.Contains(a) || !s.ContainsOUI([3]byte{0xac, 0xde, 0x48}) || s.ContainsOUI([3]byte{1, 2, 3}) {
+		t.Errorf("Contains/ContainsOUI failed.\n")
+	}
+
+	str := fmt.Sprint(s.WithOUI([3]byte{0x00, 0x1a, 0x2b}))
+	if str != "[00:1a:2b:00:00:01 00:1a:2b:00:00:02]" {
+		t.Errorf("WithOUI failed: got %v.\n", str)
+	}
+
+	n := 0
+	for range s.All() {
+		n++
+	}
+	if n != 4 {
+		t.Errorf("All failed: got %d addresses.\n", n)
+	}
+
+	s.Remove(a, a)
+	if s.Len() != 3 || s.ContainsOUI([3]byte{0xac, 0xde, 0x48}) {
+		t.Errorf("Remove failed: got %d addresses.\n", s.Len())
+	}
+	s.Clear()
+	if s.Len() != 0 || s.OUIs() != 0 {
+		t.Errorf("Clear failed.\n")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+
+	"github.com/hweidner/set/v2/hll"
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+// ErrNotExact is returned by the methods of a HybridSet which need the
+// elements, after the set was turned into a sketch.
+var ErrNotExact = errors.New("set: hybrid set is no longer exact")
+
+// ----- HybridSet definition -----
+
+// A HybridSet is a set for flows which mostly count distinct elements, but
+// occasionally need the elements, like the distinct visitors of pages, most
+// of which have few visitors. It holds an exact set while its cardinality is
+// at most a threshold, and turns into a HyperLogLog sketch of fixed size
+// once the threshold is exceeded, after which Count is an estimate. A sketch
+// cannot recover its elements, so the change is one way; a set whose
+// elements will be needed is pinned as exact by RequireExact.
+type HybridSet[T comparable] struct {
+	threshold int
+	precision int
+	pinned    bool
+	exact     Set[T]      // the elements, while the set is exact
+	sketch    *hll.Sketch // the sketch, once the set is not exact
+}
+
+// ----- constructor -----
+
+// NewHybrid creates a new, exact hybrid set, which turns into a sketch of the
+// given precision when it exceeds threshold elements. Strings and integers
+// are hashed stably like with ToHLL, so the sketches can be merged across
+// processes. The precision is clamped like by hll.New.
+func NewHybrid[T comparable](threshold, precision int) *HybridSet[T] {
+	return &HybridSet[T]{threshold: threshold, precision: precision, exact: New[T]()}
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s *HybridSet[T]) Add(e ...T) {
+	if s.sketch != nil {
+		for _, x := range e {
+			s.sketch.AddHash(hash.Of(x))
+		}
+		return
+	}
+	s.exact.Add(e...)
+	s.degrade()
+}
+
+// degrade turns the set into a sketch, if it exceeds the threshold and is
+// not pinned.
+func (s *HybridSet[T]) degrade() {
+	if !s.pinned && s.exact.Len() > s.threshold {
+		s.sketch = s.exact.ToHLL(s.precision)
+		s.exact = Set[T]{}
+	}
+}
+
+// RequireExact pins the set as exact, so it keeps its elements beyond the
+// threshold. It returns ErrNotExact if the set is already a sketch.
+func (s *HybridSet[T]) RequireExact() error {
+	if s.sketch != nil {
+		return ErrNotExact
+	}
+	s.pinned = true
+	return nil
+}
+
+// Merge adds all elements of t to s. If either set is a sketch, s becomes a
+// sketch as well, unless it is pinned, in which case ErrNotExact is returned
+// and s is not modified. Sketches of different precisions cannot be merged.
+func (s *HybridSet[T]) Merge(t *HybridSet[T]) error {
+	if t.sketch == nil {
+		s.Add(t.exact.List()...)
+		return nil
+	}
+	if s.pinned {
+		return ErrNotExact
+	}
+	if s.sketch == nil {
+		s.sketch = s.exact.ToHLL(s.precision)
+		s.exact = Set[T]{}
+	}
+	return s.sketch.Merge(t.sketch)
+}
+
+// Clear removes all elements from the given set, which becomes exact again.
+func (s *HybridSet[T]) Clear() {
+	s.exact, s.sketch = New[T](), nil
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsExact tests if the set holds its elements.
+func (s *HybridSet[T]) IsExact() bool {
+	return s.sketch == nil
+}
+
+// Count returns the number of distinct elements, which is exact as long as
+// the set is, and an estimate afterwards.
+func (s *HybridSet[T]) Count() uint64 {
+	if s.sketch != nil {
+		return s.sketch.Estimate()
+	}
+	return uint64(s.exact.Len())
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set. It returns ErrNotExact
+// if the set is a sketch.
+func (s *HybridSet[T]) Contains(e ...T) (bool, error) {
+	if s.sketch != nil {
+		return false, ErrNotExact
+	}
+	return s.exact.Contains(e...), nil
+}
+
+// Set returns the elements as a new set. It returns ErrNotExact if the set
+// is a sketch.
+func (s *HybridSet[T]) Set() (Set[T], error) {
+	if s.sketch != nil {
+		return Set[T]{}, ErrNotExact
+	}
+	return s.exact.Copy(), nil
+}
+
+// Sketch returns a HyperLogLog sketch of the elements, as a copy.
+func (s *HybridSet[T]) Sketch() *hll.Sketch {
+	if s.sketch != nil {
+		return s.sketch.Clone()
+	}
+	return s.exact.ToHLL(s.precision)
+}
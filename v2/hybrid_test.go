@@ -0,0 +1,70 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHybrid(t *testing.T) {
+	s := NewHybrid[int](100, 12)
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+	if ok, err := s.Contains(5, 99); !ok || err != nil || !s.IsExact() || s.Count() != 100 {
+		t.Errorf("Contains failed: got %v, %v with %d elements.\n", ok, err, s.Count())
+	}
+	if e, err := s.Set(); err != nil || e.Len() != 100 {
+		t.Errorf("Set failed: got %v, %v.\n", e, err)
+	}
+
+	// beyond the threshold, the set turns into a sketch
+	for i := 100; i < 10000; i++ {
+		s.Add(i)
+	}
+	if s.IsExact() || s.Count() < 9500 || s.Count() > 10500 {
+		t.Errorf("Add failed: exact %v with %d elements.\n", s.IsExact(), s.Count())
+	}
+	if _, err := s.Contains(5); !errors.Is(err, ErrNotExact) {
+		t.Errorf("Contains failed: got %v for a sketch.\n", err)
+	}
+	if _, err := s.Set(); !errors.Is(err, ErrNotExact) {
+		t.Errorf("Set failed: got %v for a sketch.\n", err)
+	}
+	if err := s.RequireExact(); !errors.Is(err, ErrNotExact) {
+		t.Errorf("RequireExact failed: got %v for a sketch.\n", err)
+	}
+
+	// a pinned set stays exact
+	p := NewHybrid[int](10, 12)
+	if err := p.RequireExact(); err != nil {
+		t.Fatalf("RequireExact failed: %v.\n", err)
+	}
+	for i := 0; i < 50; i++ {
+		p.Add(i)
+	}
+	if !p.IsExact() || p.Count() != 50 {
+		t.Errorf("RequireExact failed: exact %v with %d elements.\n", p.IsExact(), p.Count())
+	}
+	if err := p.Merge(s); !errors.Is(err, ErrNotExact) || p.Count() != 50 {
+		t.Errorf("Merge failed: got %v for a pinned set.\n", err)
+	}
+
+	// merging a sketch into an exact set yields a sketch
+	q := NewHybrid[int](1000, 12)
+	q.Add(20000, 20001)
+	if err := q.Merge(s); err != nil || q.IsExact() || q.Count() < s.Count() {
+		t.Errorf("Merge failed: got %v, %d elements.\n", err, q.Count())
+	}
+	if q.Sketch().Count() != q.Count() || p.Sketch().Count() < 45 {
+		t.Errorf("Sketch failed.\n")
+	}
+	q.Clear()
+	if !q.IsExact() || q.Count() != 0 {
+		t.Errorf("Clear failed: exact %v with %d elements.\n", q.IsExact(), q.Count())
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package iblt provides invertible Bloom lookup tables (IBLTs) for the
+reconciliation of sets, as a building block of anti-entropy protocols.
+
+Each side encodes its set into a Sketch of a fixed number of cells, and
+sends it to the other side. The receiver subtracts its own sketch, and
+decodes the symmetric difference of the sets from the result, without any
+membership list being transferred. The size of a sketch depends only on
+the size of the difference it can decode, not on the size of the sets;
+with about twice as many cells as different elements, decoding succeeds
+with high probability. If it fails, the sides retry with larger sketches,
+or fall back to a full transfer.
+
+The sketches hold 64 bit keys. Key derives them from the elements of a set,
+and Reconcile decodes the difference between a local set and the sketch of
+a remote set in one step.
+*/
+package iblt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/hweidner/set/v2"
+	"github.com/hweidner/set/v2/internal/elemcodec"
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+var (
+	// ErrIncompatible is returned by Subtract and Reconcile for sketches
+	// with different numbers of cells.
+	ErrIncompatible = errors.New("iblt: sketches have different sizes")
+
+	// ErrUndecodable is returned by Decode and Reconcile if the difference
+	// is too large for the size of the sketch.
+	ErrUndecodable = errors.New("iblt: difference too large to decode")
+
+	// ErrFormat is returned by UnmarshalBinary for malformed input.
+	ErrFormat = errors.New("iblt: malformed sketch")
+
+	// ErrNoStableKey is returned by Key and the functions on sets for
+	// element types without an encoding which is stable across processes.
+	ErrNoStableKey = errors.New("iblt: element type has no stable key")
+)
+
+// the magic bytes of the binary encoding
+const magic = "IBL1"
+
+// the number of cells of each key, one in each third of the table
+const hashes = 3
+
+// ----- Sketch definition -----
+
+// A Sketch is an invertible Bloom lookup table of 64 bit keys. Each key is
+// added to three cells, which hold the number of keys, the XOR of the keys
+// and the XOR of their checksums. A cell with a count of ±1 and a matching
+// checksum holds a single key, which is peeled off the table to decode it.
+type Sketch struct {
+	cells []cell
+}
+
+// a cell of a sketch
+type cell struct {
+	count    int64
+	keySum   uint64
+	checkSum uint64
+}
+
+// New creates an empty sketch with at least the given number of cells,
+// rounded up to a multiple of 3. A cell takes 24 bytes, and a sketch decodes
+// a difference of up to about half of its cells.
+func New(cells int) *Sketch {
+	n := max((cells+hashes-1)/hashes, 1) * hashes
+	return &Sketch{cells: make([]cell, n)}
+}
+
+// check returns the checksum of a key.
+func check(key uint64) uint64 {
+	return hash.Mix64(key ^ 0x9e3779b97f4a7c15)
+}
+
+// index returns the i-th cell of a key.
+func (s *Sketch) index(key uint64, i int) int {
+	n := uint64(len(s.cells) / hashes)
+	return i*int(n) + int(hash.Mix64(key+uint64(i+1)*0xbf58476d1ce4e5b9)%n)
+}
+
+// update adds d to the counts of the cells of a key.
+func (s *Sketch) update(key uint64, d int64) {
+	c := check(key)
+	for i := range hashes {
+		p := &s.cells[s.index(key, i)]
+		p.count += d
+		p.keySum ^= key
+		p.checkSum ^= c
+	}
+}
+
+// ----- methods that modify the receiver -----
+
+// Insert adds one or more keys to the sketch.
+func (s *Sketch) Insert(key ...uint64) {
+	for _, k := range key {
+		s.update(k, 1)
+	}
+}
+
+// Delete removes one or more keys from the sketch. The keys need not have
+// been inserted; a sketch with more deletes than inserts of a key decodes it
+// as removed.
+func (s *Sketch) Delete(key ...uint64) {
+	for _, k := range key {
+		s.update(k, -1)
+	}
+}
+
+// Subtract subtracts the sketch o from s, so s becomes the sketch of the
+// difference. The keys which were only inserted into s decode as added, and
+// the keys which were only inserted into o decode as removed.
+func (s *Sketch) Subtract(o *Sketch) error {
+	if len(s.cells) != len(o.cells) {
+		return ErrIncompatible
+	}
+	for i, c := range o.cells {
+		p := &s.cells[i]
+		p.count -= c.count
+		p.keySum ^= c.keySum
+		p.checkSum ^= c.checkSum
+	}
+	return nil
+}
+
+// Reset removes all keys from the sketch.
+func (s *Sketch) Reset() {
+	clear(s.cells)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Cells returns the number of cells of the sketch.
+func (s *Sketch) Cells() int {
+	return len(s.cells)
+}
+
+// Clone returns a copy of the sketch.
+func (s *Sketch) Clone() *Sketch {
+	return &Sketch{cells: slices.Clone(s.cells)}
+}
+
+// pure checks if the cell holds a single key.
+func (c cell) pure() bool {
+	return (c.count == 1 || c.count == -1) && c.checkSum == check(c.keySum)
+}
+
+// Decode lists the keys of the sketch by peeling, in ascending order: the
+// keys with a positive count as added, and with a negative count as
+// removed, like after Subtract. The sketch is not modified. If the sketch
+// holds too many keys for its size, the keys decoded so far are returned
+// with ErrUndecodable.
+func (s *Sketch) Decode() (added, removed []uint64, err error) {
+	t := s.Clone()
+	queue := make([]int, 0, len(t.cells))
+	for i, c := range t.cells {
+		if c.pure() {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		c := t.cells[i]
+		if !c.pure() {
+			continue
+		}
+		if len(added)+len(removed) == len(t.cells) {
+			// a checksum collision made a cell look pure
+			return added, removed, ErrUndecodable
+		}
+		if c.count > 0 {
+			added = append(added, c.keySum)
+		} else {
+			removed = append(removed, c.keySum)
+		}
+		t.update(c.keySum, -c.count)
+		for j := range hashes {
+			if k := t.index(c.keySum, j); t.cells[k].pure() {
+				queue = append(queue, k)
+			}
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	for _, c := range t.cells {
+		if c != (cell{}) {
+			return added, removed, ErrUndecodable
+		}
+	}
+	return added, removed, nil
+}
+
+// ----- serialization -----
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// encoding is "IBL1", the number of cells as a uvarint, and for each cell
+// its count as a varint and its key and checksum sums as 8 bytes.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(magic)+binary.MaxVarintLen64+len(s.cells)*17)
+	b = append(b, magic...)
+	b = binary.AppendUvarint(b, uint64(len(s.cells)))
+	for _, c := range s.cells {
+		b = binary.AppendVarint(b, c.count)
+		b = binary.LittleEndian.AppendUint64(b, c.keySum)
+		b = binary.LittleEndian.AppendUint64(b, c.checkSum)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < len(magic) || string(data[:len(magic)]) != magic {
+		return ErrFormat
+	}
+	data = data[len(magic):]
+	n, k := binary.Uvarint(data)
+	// each cell takes at least 17 bytes
+	if k <= 0 || n == 0 || n%hashes != 0 || n > uint64(len(data))/17 {
+		return ErrFormat
+	}
+	data = data[k:]
+	cells := make([]cell, n)
+	for i := range cells {
+		c, k := binary.Varint(data)
+		if k <= 0 || len(data) < k+16 {
+			return ErrFormat
+		}
+		cells[i] = cell{c, binary.LittleEndian.Uint64(data[k:]), binary.LittleEndian.Uint64(data[k+8:])}
+		data = data[k+16:]
+	}
+	if len(data) != 0 {
+		return ErrFormat
+	}
+	s.cells = cells
+	return nil
+}
+
+// ----- sets -----
+
+// Key returns the key of an element, which is the hash of its stable
+// encoding, so it is equal in all processes. Strings, integers and floats
+// have a stable encoding; for other element types, ErrNoStableKey is
+// returned.
+func Key[T comparable](e T) (uint64, error) {
+	b, ok := elemcodec.AppendValue(nil, e)
+	if !ok {
+		return 0, fmt.Errorf("%w: %T", ErrNoStableKey, e)
+	}
+	return hash.Sum64(b), nil
+}
+
+// keys returns the elements of a set by their keys.
+func keys[T comparable](s set.Set[T]) (map[uint64]T, error) {
+	m := make(map[uint64]T, s.Len())
+	for e := range s.All() {
+		k, err := Key(e)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = e
+	}
+	return m, nil
+}
+
+// FromSet returns a sketch with the given number of cells and the keys of
+// the elements of a set.
+func FromSet[T comparable](s set.Set[T], cells int) (*Sketch, error) {
+	t := New(cells)
+	for e := range s.All() {
+		k, err := Key(e)
+		if err != nil {
+			return nil, err
+		}
+		t.Insert(k)
+	}
+	return t, nil
+}
+
+// A Diff is the difference between a local set and a remote set, as seen
+// by the local side. The elements of Local are only in the local set, so
+// they are sent to the remote side; the keys of Remote are those of the
+// elements only in the remote set, which the remote side resolves with
+// Resolve.
+type Diff[T comparable] struct {
+	Local  []T
+	Remote []uint64
+}
+
+// Reconcile decodes the difference between a local set and the sketch of a
+// remote set. The local set is encoded into a sketch of the same size as the
+// remote sketch.
+func Reconcile[T comparable](local set.Set[T], remote *Sketch) (Diff[T], error) {
+	m, err := keys(local)
+	if err != nil {
+		return Diff[T]{}, err
+	}
+	t := New(remote.Cells())
+	for k := range m {
+		t.Insert(k)
+	}
+	if err := t.Subtract(remote); err != nil {
+		return Diff[T]{}, err
+	}
+	added, removed, err := t.Decode()
+	if err != nil {
+		return Diff[T]{}, err
+	}
+	d := Diff[T]{Local: make([]T, 0, len(added)), Remote: removed}
+	for _, k := range added {
+		d.Local = append(d.Local, m[k])
+	}
+	return d, nil
+}
+
+// Resolve returns the elements of a set with the given keys, like the keys
+// requested by the other side of a reconciliation. Keys of elements which
+// are not in the set are ignored.
+func Resolve[T comparable](s set.Set[T], key ...uint64) ([]T, error) {
+	m, err := keys(s)
+	if err != nil {
+		return nil, err
+	}
+	var r []T
+	for _, k := range key {
+		if e, ok := m[k]; ok {
+			r = append(r, e)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package iblt
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+func TestSketch(t *testing.T) {
+	a, b := New(100), New(100)
+	for k := uint64(0); k < 10000; k++ {
+		a.Insert(k)
+		b.Insert(k)
+	}
+	a.Insert(20000, 20001, 20002)
+	b.Insert(30000, 30001)
+	if err := a.Subtract(b); err != nil {
+		t.Fatalf("Subtract failed: %v.\n", err)
+	}
+	added, removed, err := a.Decode()
+	if err != nil || !slices.Equal(added, []uint64{20000, 20001, 20002}) || !slices.Equal(removed, []uint64{30000, 30001}) {
+		t.Errorf("Decode failed: got %v, %v, %v.\n", added, removed, err)
+	}
+
+	// a difference larger than the sketch cannot be decoded
+	c := New(30)
+	for k := uint64(0); k < 100; k++ {
+		c.Insert(k)
+	}
+	if _, _, err := c.Decode(); !errors.Is(err, ErrUndecodable) {
+		t.Errorf("Decode failed: got %v for an overloaded sketch.\n", err)
+	}
+	if err := c.Subtract(New(60)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Subtract failed: got %v.\n", err)
+	}
+
+	c.Reset()
+	if added, removed, err := c.Decode(); err != nil || len(added)+len(removed) != 0 {
+		t.Errorf("Reset failed: got %v, %v, %v.\n", added, removed, err)
+	}
+	if New(1).Cells() != 3 || New(100).Cells() != 102 {
+		t.Errorf("New failed: got %d and %d cells.\n", New(1).Cells(), New(100).Cells())
+	}
+}
+
+func TestSketchBinary(t *testing.T) {
+	a := New(30)
+	a.Insert(1, 2, 3)
+	a.Delete(4)
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v.\n", err)
+	}
+	b := new(Sketch)
+	if err := b.UnmarshalBinary(data); err != nil || !slices.Equal(a.cells, b.cells) {
+		t.Errorf("UnmarshalBinary failed: %v.\n", err)
+	}
+	for _, d := range [][]byte{nil, []byte("IBL0"), data[:len(data)-1], append(data, 0)} {
+		if err := b.UnmarshalBinary(d); !errors.Is(err, ErrFormat) {
+			t.Errorf("UnmarshalBinary failed: got %v for %q.\n", err, d)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	local, remote := set.New[string](), set.New[string]()
+	for i := 0; i < 5000; i++ {
+		local.Add("id" + strconv.Itoa(i))
+		remote.Add("id" + strconv.Itoa(i))
+	}
+	local.Add("only-local-1", "only-local-2")
+	remote.Add("only-remote")
+
+	rs, err := FromSet(remote, 20)
+	if err != nil {
+		t.Fatalf("FromSet failed: %v.\n", err)
+	}
+	d, err := Reconcile(local, rs)
+	if err != nil || !set.New(d.Local...).IsEqual(set.New("only-local-1", "only-local-2")) || len(d.Remote) != 1 {
+		t.Fatalf("Reconcile failed: got %v, %v.\n", d, err)
+	}
+
+	// the remote side resolves the requested keys
+	r, err := Resolve(remote, d.Remote...)
+	if err != nil || !slices.Equal(r, []string{"only-remote"}) {
+		t.Errorf("Resolve failed: got %v, %v.\n", r, err)
+	}
+
+	type point struct{ x, y int }
+	if _, err := FromSet(set.New(point{1, 2}), 10); !errors.Is(err, ErrNoStableKey) {
+		t.Errorf("FromSet failed: got %v for structs.\n", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ----- reporting loaders -----
+
+// AddAllReport adds one or more elements to the given set, like Add, and
+// returns the number of elements which were added, and the number of
+// duplicates, which were already in the set or repeated in the arguments.
+// Invalid elements of a set with a validator are silently rejected and
+// counted by neither; use Ingest to count them.
+func (s Set[T]) AddAllReport(e ...T) (added, duplicates int) {
+	if s.set == nil {
+		s.misuse("AddAllReport", errUninitialized)
+		return 0, 0
+	}
+	st, _ := s.Ingest(func(yield func(T) bool) {
+		for _, i := range e {
+			if !yield(i) {
+				return
+			}
+		}
+	})
+	return st.New, st.Duplicate
+}
+
+// IngestStats are the statistics of a batch of elements added by Ingest.
+type IngestStats struct {
+	New       int // the number of elements added to the set
+	Duplicate int // the number of elements already in the set, or repeated in the batch
+	Invalid   int // the number of elements rejected by the universe or validator
+}
+
+// Total returns the number of elements of the batch.
+func (st IngestStats) Total() int {
+	return st.New + st.Duplicate + st.Invalid
+}
+
+// Add adds the statistics of another batch, like for the totals of a load
+// job.
+func (st *IngestStats) Add(o IngestStats) {
+	st.New += o.New
+	st.Duplicate += o.Duplicate
+	st.Invalid += o.Invalid
+}
+
+func (st IngestStats) String() string {
+	return fmt.Sprintf("%d new, %d duplicate, %d invalid", st.New, st.Duplicate, st.Invalid)
+}
+
+// Ingest adds the values of the iterator to the given set, like TryAdd, and
+// returns the statistics of the batch. Duplicates are counted after
+// canonicalization. The returned error joins a *ValidationError for each
+// invalid element.
+func (s Set[T]) Ingest(seq iter.Seq[T]) (IngestStats, error) {
+	var st IngestStats
+	if s.set == nil {
+		return st, s.misuse("Ingest", errUninitialized)
+	}
+	var errs []error
+	for i := range seq {
+		i, err := s.admit(i)
+		if err != nil {
+			st.Invalid++
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := s.set[i]; ok {
+			st.Duplicate++
+			continue
+		}
+		s.set[i] = struct{}{}
+		st.New++
+	}
+	return st, errors.Join(errs...)
+}
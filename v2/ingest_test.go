@@ -0,0 +1,65 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAddAllReport(t *testing.T) {
+	s := New(1, 2)
+	if a, d := s.AddAllReport(2, 3, 4, 3); a != 2 || d != 2 || !s.IsEqual(New(1, 2, 3, 4)) {
+		t.Errorf("AddAllReport failed: got %d, %d, %v.\n", a, d, s)
+	}
+	if a, d := s.AddAllReport(); a != 0 || d != 0 {
+		t.Errorf("AddAllReport failed for no elements: got %d, %d.\n", a, d)
+	}
+}
+
+func TestIngest(t *testing.T) {
+	errOdd := errors.New("odd")
+	s := NewWith(WithValidator(func(i int) error {
+		if i%2 != 0 {
+			return errOdd
+		}
+		return nil
+	}))
+	s.Add(2)
+	st, err := s.Ingest(slices.Values([]int{1, 2, 4, 6, 4, 7}))
+	if st != (IngestStats{New: 2, Duplicate: 2, Invalid: 2}) || st.Total() != 6 || !errors.Is(err, errOdd) {
+		t.Errorf("Ingest failed: got %v, %v.\n", st, err)
+	}
+	if !s.IsEqual(New(2, 4, 6)) {
+		t.Errorf("Ingest failed: got %v.\n", s)
+	}
+	var total IngestStats
+	total.Add(st)
+	total.Add(IngestStats{New: 1})
+	if total.String() != "3 new, 2 duplicate, 2 invalid" {
+		t.Errorf("IngestStats failed: got %v.\n", total)
+	}
+
+	// duplicates after canonicalization
+	c := NewWith(WithCanonicalizer(strings.ToLower))
+	if st, err := c.Ingest(slices.Values([]string{"A", "a", "b"})); err != nil || st.New != 2 || st.Duplicate != 1 {
+		t.Errorf("Ingest failed with a canonicalizer: got %v, %v.\n", st, err)
+	}
+	if a, d := c.AddAllReport("B", "c"); a != 1 || d != 1 {
+		t.Errorf("AddAllReport failed with a canonicalizer: got %d, %d.\n", a, d)
+	}
+
+	// the zero value of Set
+	SetMisusePolicy(RecordMisuse)
+	defer SetMisusePolicy(PanicOnMisuse)
+	var z Set[int]
+	var me *MisuseError
+	if _, err := z.Ingest(slices.Values([]int{1})); !errors.As(err, &me) || me.Op != "Ingest" {
+		t.Errorf("Ingest failed: expected a MisuseError, got %v.\n", err)
+	}
+}
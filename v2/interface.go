@@ -0,0 +1,120 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "iter"
+
+// ----- Interface definition -----
+
+// Interface is the common method set of the mutable set types of the
+// package, like Set, BitSet, SortedSet and SyncSet, so libraries can accept
+// any set without committing to a representation. The set algebra on
+// Interface values is provided by the functions UnionWith, IntersectWith
+// and DiffWith, which modify their first argument, and IsEqualTo and
+// IsSubset.
+//
+// Set implements Interface with a value receiver, so a Set is used as it
+// is; the other types are used by pointer.
+//
+// Some types implement Interface only for the elements of their domain:
+// BitSet ignores negative numbers, and SparseSet the numbers out of its
+// range, so for other elements, Contains is false after Add. Check them
+// with settest.InDomain.
+type Interface[T comparable] interface {
+	// Add adds one or more elements.
+	Add(e ...T)
+
+	// Remove removes one or more elements.
+	Remove(e ...T)
+
+	// Clear removes all elements.
+	Clear()
+
+	// Contains checks if all given elements are in the set.
+	Contains(e ...T) bool
+
+	// Len returns the number of elements.
+	Len() int
+
+	// All returns an iterator over all elements.
+	All() iter.Seq[T]
+}
+
+// the set types implementing Interface
+var (
+	_ Interface[int]    = Set[int]{}
+	_ Interface[int]    = (*BitSet)(nil)
+	_ Interface[int]    = (*SortedSet[int])(nil)
+	_ Interface[int]    = (*SyncSet[int])(nil)
+	_ Interface[int]    = (*ShardedSet[int])(nil)
+	_ Interface[int]    = (*OrderedSet[int])(nil)
+	_ Interface[int]    = (*SparseSet[int])(nil)
+	_ Interface[int]    = (*GenerationSet[int])(nil)
+	_ Interface[uint32] = (*RoaringSet)(nil)
+	_ Interface[uint16] = (*PortSet)(nil)
+	_ Interface[string] = PathSet{}
+)
+
+// ----- algebra of Interface values -----
+
+// UnionWith adds the elements of the sets t to s.
+func UnionWith[T comparable](s Interface[T], t ...Interface[T]) {
+	for _, i := range t {
+		for e := range i.All() {
+			s.Add(e)
+		}
+	}
+}
+
+// IntersectWith removes the elements from s which are not in all of the sets
+// t.
+func IntersectWith[T comparable](s Interface[T], t ...Interface[T]) {
+	var drop []T
+	for e := range s.All() {
+		for _, i := range t {
+			if !i.Contains(e) {
+				drop = append(drop, e)
+				break
+			}
+		}
+	}
+	s.Remove(drop...)
+}
+
+// DiffWith removes the elements of t from s.
+func DiffWith[T comparable](s, t Interface[T]) {
+	if t.Len() <= s.Len() {
+		for e := range t.All() {
+			s.Remove(e)
+		}
+		return
+	}
+	var drop []T
+	for e := range s.All() {
+		if t.Contains(e) {
+			drop = append(drop, e)
+		}
+	}
+	s.Remove(drop...)
+}
+
+// IsSubset tests if all elements of s are in t.
+func IsSubset[T comparable](s, t Interface[T]) bool {
+	if s.Len() > t.Len() {
+		return false
+	}
+	for e := range s.All() {
+		if !t.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEqualTo tests if s and t have the same elements.
+func IsEqualTo[T comparable](s, t Interface[T]) bool {
+	return s.Len() == t.Len() && IsSubset(s, t)
+}
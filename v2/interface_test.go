@@ -0,0 +1,44 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInterface(t *testing.T) {
+	// the algebra across different representations
+	for _, s := range []Interface[int]{New[int](), NewBitSet(), NewSorted[int](), NewSync[int]()} {
+		s.Add(1, 2, 3, 4)
+		UnionWith(s, NewBitSet(5), NewSorted(6))
+		if got := slices.Sorted(s.All()); !slices.Equal(got, []int{1, 2, 3, 4, 5, 6}) {
+			t.Errorf("UnionWith failed for %T: got %v.\n", s, got)
+		}
+		IntersectWith(s, New(1, 2, 3, 4, 5), NewBitSet(2, 3, 4, 5, 6))
+		if !IsEqualTo(s, New(2, 3, 4, 5)) {
+			t.Errorf("IntersectWith failed for %T: got %v.\n", s, s)
+		}
+		DiffWith(s, NewSorted(5, 7))
+		DiffWith(s, New(0, 1, 2, 7, 8, 9))
+		if !IsEqualTo(s, NewBitSet(3, 4)) {
+			t.Errorf("DiffWith failed for %T: got %v.\n", s, s)
+		}
+		if !IsSubset(s, New(3, 4, 5)) || IsSubset(s, NewSorted(3)) || IsSubset(New(3, 9), s) {
+			t.Errorf("IsSubset failed for %T.\n", s)
+		}
+		if IsEqualTo(s, NewSync(3, 5)) {
+			t.Errorf("IsEqualTo failed for %T.\n", s)
+		}
+	}
+
+	// an intersection with no sets is the identity
+	s := New(1, 2)
+	IntersectWith[int](s)
+	if s.Len() != 2 {
+		t.Errorf("IntersectWith failed: got %v.\n", s)
+	}
+}
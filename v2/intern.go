@@ -0,0 +1,126 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ----- Interner definition -----
+
+// An Interner is a registry of shared frozen sets, for many objects which
+// reference few distinct sets, like the permission combinations of millions
+// of users. Intern returns the same frozen set for all equal sets, so the
+// objects share the memory of a single encoding, and can compare their sets
+// with ==. The registry is keyed by the hash of the sets, see Set.Hash, and
+// only encodes a set which it does not know yet. All methods are safe for
+// concurrent use.
+//
+// The interned sets are never released, except by Clear.
+type Interner[T comparable] struct {
+	mu     sync.RWMutex
+	byHash map[uint64][]Frozen[T]
+	len    int
+	bytes  int
+
+	hits, misses atomic.Int64
+}
+
+// InternStats are the statistics of an Interner.
+type InternStats struct {
+	Sets   int   // the number of distinct interned sets
+	Bytes  int   // the total size of their encodings
+	Hits   int64 // the number of calls of Intern which returned a known set
+	Misses int64 // the number of calls of Intern which added a new set
+}
+
+// ----- constructor -----
+
+// NewInterner creates a new, empty interner.
+func NewInterner[T comparable]() *Interner[T] {
+	return &Interner[T]{byHash: map[uint64][]Frozen[T]{}}
+}
+
+// find returns the interned set equal to s among the candidates.
+func find[T comparable](candidates []Frozen[T], s Set[T]) (Frozen[T], bool) {
+outer:
+	for _, f := range candidates {
+		if f.n != len(s.set) {
+			continue
+		}
+		for e := range f.All() {
+			if _, ok := s.set[e]; !ok {
+				continue outer
+			}
+		}
+		return f, true
+	}
+	return Frozen[T]{}, false
+}
+
+// ----- methods that modify the receiver -----
+
+// Intern returns the shared frozen set equal to s, and adds the frozen
+// representation of s to the registry if there is none.
+func (in *Interner[T]) Intern(s Set[T]) Frozen[T] {
+	h := s.Hash()
+	in.mu.RLock()
+	f, ok := find(in.byHash[h], s)
+	in.mu.RUnlock()
+	if ok {
+		in.hits.Add(1)
+		return f
+	}
+
+	// the set is encoded without holding the lock
+	nf := s.Frozen()
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if f, ok := find(in.byHash[h], s); ok {
+		in.hits.Add(1)
+		return f
+	}
+	in.byHash[h] = append(in.byHash[h], nf)
+	in.len++
+	in.bytes += len(nf.key)
+	in.misses.Add(1)
+	return nf
+}
+
+// Clear removes all sets from the registry. The frozen sets returned before
+// remain valid, but are no longer shared with the sets interned afterwards.
+func (in *Interner[T]) Clear() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	clear(in.byHash)
+	in.len, in.bytes = 0, 0
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Lookup returns the shared frozen set equal to s, without adding it. The
+// second return value is false if s was not interned.
+func (in *Interner[T]) Lookup(s Set[T]) (Frozen[T], bool) {
+	h := s.Hash()
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	return find(in.byHash[h], s)
+}
+
+// Len returns the number of distinct interned sets.
+func (in *Interner[T]) Len() int {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	return in.len
+}
+
+// Stats returns the statistics of the interner.
+func (in *Interner[T]) Stats() InternStats {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	return InternStats{Sets: in.len, Bytes: in.bytes, Hits: in.hits.Load(), Misses: in.misses.Load()}
+}
@@ -0,0 +1,69 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestInterner(t *testing.T) {
+	in := NewInterner[string]()
+	a := in.Intern(New("read", "write"))
+	b := in.Intern(New("write", "read"))
+	c := in.Intern(New("read"))
+	if a != b || a == c || !b.Thaw().IsEqual(New("read", "write")) {
+		t.Errorf("Intern failed: got %v, %v, %v.\n", a, b, c)
+	}
+	// equal sets share the memory of their encoding
+	if unsafe.StringData(a.key) != unsafe.StringData(b.key) {
+		t.Errorf("Intern failed: the encodings are not shared.\n")
+	}
+	if st := in.Stats(); st.Sets != 2 || st.Hits != 1 || st.Misses != 2 || st.Bytes != len(a.key)+len(c.key) {
+		t.Errorf("Stats failed: got %+v.\n", st)
+	}
+	if f, ok := in.Lookup(New("read")); !ok || f != c {
+		t.Errorf("Lookup failed: got %v, %v.\n", f, ok)
+	}
+	if _, ok := in.Lookup(New("admin")); ok || in.Len() != 2 {
+		t.Errorf("Lookup failed: added a set.\n")
+	}
+	if e := in.Intern(New[string]()); !e.IsEmpty() || e != (Frozen[string]{}) {
+		t.Errorf("Intern failed for the empty set: got %v.\n", e)
+	}
+	in.Clear()
+	if in.Len() != 0 || in.Stats().Bytes != 0 {
+		t.Errorf("Clear failed: got %+v.\n", in.Stats())
+	}
+	if d := in.Intern(New("read")); d != c {
+		t.Errorf("Intern failed after Clear: got %v.\n", d)
+	}
+}
+
+func TestInternerConcurrent(t *testing.T) {
+	in := NewInterner[int]()
+	var wg sync.WaitGroup
+	res := make([]Frozen[int], 8)
+	for i := range res {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := range 100 {
+				res[i] = in.Intern(New(j%10, j%10+1))
+			}
+		}(i)
+	}
+	wg.Wait()
+	if st := in.Stats(); st.Sets != 10 || st.Hits+st.Misses != 800 || st.Misses != 10 {
+		t.Errorf("Intern failed: got %+v.\n", st)
+	}
+	for _, f := range res[1:] {
+		if unsafe.StringData(f.key) != unsafe.StringData(res[0].key) {
+			t.Errorf("Intern failed: the encodings are not shared.\n")
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+// Package elemcodec provides a reversible binary encoding of comparable
+// values, where equal values have equal encodings.
+//
+// Values of kind string, bool, integer and floating point, including named
+// types, are encoded by value. Structs and arrays are encoded field by field,
+// and interfaces by their dynamic type and value. Pointers and channels are
+// encoded by their address, and kept alive by the Refs of the encoding, so
+// such encodings are only valid within the process, and only together with
+// their Refs.
+package elemcodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Refs holds the values referenced by encodings, like pointers, which are
+// encoded by their address. It keeps them alive as long as it is reachable.
+// The zero value is an empty Refs.
+type Refs struct {
+	m map[uintptr]unsafe.Pointer
+}
+
+// Len returns the number of referenced values.
+func (r *Refs) Len() int {
+	return len(r.m)
+}
+
+// the dynamic types of the encoded interface values, by their number in the
+// encoding. The table only grows with the number of distinct types.
+var (
+	typesMu sync.Mutex
+	typeIDs = map[reflect.Type]uint64{}
+	types   []reflect.Type
+)
+
+// Append appends the encoding of e to b, and adds the values referenced by
+// it to refs, which must not be nil. The encoding is self-delimiting, so encodings can be
+// concatenated.
+func Append[T comparable](b []byte, e T, refs *Refs) []byte {
+	return appendAny(b, reflect.ValueOf(&e).Elem(), refs)
+}
+
+func appendAny(b []byte, v reflect.Value, refs *Refs) []byte {
+	if r, ok := appendBasic(b, v); ok {
+		return r
+	}
+	switch v.Kind() {
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		b = appendFloat(b, real(c))
+		return appendFloat(b, imag(c))
+	case reflect.Array:
+		for i := range v.Len() {
+			b = appendAny(b, v.Index(i), refs)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).Name != "_" { // blank fields are ignored by ==
+				b = appendAny(b, v.Field(i), refs)
+			}
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return append(b, 0)
+		}
+		d := v.Elem()
+		typesMu.Lock()
+		id, ok := typeIDs[d.Type()]
+		if !ok {
+			id = uint64(len(types))
+			typeIDs[d.Type()] = id
+			types = append(types, d.Type())
+		}
+		typesMu.Unlock()
+		b = binary.AppendUvarint(b, id+1)
+		return appendAny(b, d, refs)
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		p := v.UnsafePointer()
+		if p != nil {
+			if refs.m == nil {
+				refs.m = map[uintptr]unsafe.Pointer{}
+			}
+			refs.m[uintptr(p)] = p
+		}
+		return binary.AppendUvarint(b, uint64(uintptr(p)))
+	}
+	return b
+}
+
+// AppendValue appends the encoding of e to b, like Append, if e is encoded by
+// value. Such encodings are stable across processes. For all other values,
+// it returns b unchanged and false.
+func AppendValue[T comparable](b []byte, e T) ([]byte, bool) {
+	return appendBasic(b, reflect.ValueOf(&e).Elem())
+}
+
+func appendBasic(b []byte, v reflect.Value) ([]byte, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		b = binary.AppendUvarint(b, uint64(v.Len()))
+		return append(b, v.String()...), true
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, 1), true
+		}
+		return append(b, 0), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(b, v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(b, v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return appendFloat(b, v.Float()), true
+	}
+	return b, false
+}
+
+func appendFloat(b []byte, f float64) []byte {
+	if f == 0 {
+		f = 0 // -0 and 0 are equal
+	}
+	return binary.BigEndian.AppendUint64(b, math.Float64bits(f))
+}
+
+// Decode decodes a value from the start of b, with the values referenced by
+// its encoding in refs, and returns it together with the rest of b. It
+// panics on malformed input, since encodings are only produced by Append.
+func Decode[T comparable](b []byte, refs *Refs) (T, []byte) {
+	var e T
+	b = decodeAny(b, reflect.ValueOf(&e).Elem(), refs)
+	return e, b
+}
+
+// decodeAny decodes a value into v, which must be addressable.
+func decodeAny(b []byte, v reflect.Value, refs *Refs) []byte {
+	// unexported fields are set through their address
+	v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		n, k := binary.Uvarint(b)
+		v.SetString(string(b[k : k+int(n)]))
+		return b[k+int(n):]
+	case reflect.Bool:
+		v.SetBool(b[0] == 1)
+		return b[1:]
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, k := binary.Varint(b)
+		v.SetInt(x)
+		return b[k:]
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, k := binary.Uvarint(b)
+		v.SetUint(x)
+		return b[k:]
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(math.Float64frombits(binary.BigEndian.Uint64(b)))
+		return b[8:]
+	case reflect.Complex64, reflect.Complex128:
+		re := math.Float64frombits(binary.BigEndian.Uint64(b))
+		im := math.Float64frombits(binary.BigEndian.Uint64(b[8:]))
+		v.SetComplex(complex(re, im))
+		return b[16:]
+	case reflect.Array:
+		for i := range v.Len() {
+			b = decodeAny(b, v.Index(i), refs)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).Name != "_" {
+				b = decodeAny(b, v.Field(i), refs)
+			}
+		}
+	case reflect.Interface:
+		id, k := binary.Uvarint(b)
+		b = b[k:]
+		if id == 0 {
+			return b
+		}
+		typesMu.Lock()
+		t := types[id-1]
+		typesMu.Unlock()
+		d := reflect.New(t).Elem()
+		b = decodeAny(b, d, refs)
+		v.Set(d)
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		p, k := binary.Uvarint(b)
+		if p != 0 {
+			*(*unsafe.Pointer)(unsafe.Pointer(v.UnsafeAddr())) = refs.m[uintptr(p)]
+		}
+		return b[k:]
+	}
+	return b
+}
+
+// ErrNoValueEncoding is returned by ReadValue for types which are not
+// encoded by value.
+var ErrNoValueEncoding = errors.New("type has no value encoding")
+
+// A Reader is the input of ReadValue.
+type Reader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// ReadValue reads a value encoded by AppendValue from r. Unlike Decode, it
+// checks its input, and returns an error for malformed or truncated
+// encodings. Strings longer than maxLen bytes are rejected.
+func ReadValue[T comparable](r Reader, maxLen int) (T, error) {
+	var e T
+	v := reflect.ValueOf(&e).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return e, err
+		}
+		if n > uint64(maxLen) {
+			return e, errors.New("string too long")
+		}
+		// long strings are read in chunks, so a corrupted length does not
+		// allocate its size up front
+		var b strings.Builder
+		if _, err := io.CopyN(&b, r, int64(n)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return e, err
+		}
+		v.SetString(b.String())
+	case reflect.Bool:
+		c, err := r.ReadByte()
+		if err != nil {
+			return e, err
+		}
+		if c > 1 {
+			return e, errors.New("invalid bool")
+		}
+		v.SetBool(c == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := binary.ReadVarint(r)
+		if err != nil {
+			return e, err
+		}
+		if v.OverflowInt(x) {
+			return e, errors.New("integer overflow")
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, err := binary.ReadUvarint(r)
+		if err != nil {
+			return e, err
+		}
+		if v.OverflowUint(x) {
+			return e, errors.New("integer overflow")
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return e, err
+		}
+		v.SetFloat(math.Float64frombits(binary.BigEndian.Uint64(b[:])))
+	default:
+		return e, ErrNoValueEncoding
+	}
+	return e, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package elemcodec
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+)
+
+type id string
+
+type point struct{ x, y int }
+
+// enc returns the encoding of e.
+func enc[T comparable](e T) string {
+	return string(Append(nil, e, &Refs{}))
+}
+
+// roundtrip encodes and decodes e, and checks the result.
+func roundtrip[T comparable](t *testing.T, e T) {
+	t.Helper()
+	var refs Refs
+	b := Append([]byte{0xff}, e, &refs)
+	got, rest := Decode[T](b[1:], &refs)
+	if got != e || len(rest) != 0 {
+		t.Errorf("roundtrip failed for %v: got %v, rest %v.\n", e, got, rest)
+	}
+}
+
+func TestCodec(t *testing.T) {
+	roundtrip(t, "hello")
+	roundtrip(t, id("named"))
+	roundtrip(t, -42)
+	roundtrip(t, int8(-128))
+	roundtrip(t, uint64(math.MaxUint64))
+	roundtrip(t, true)
+	roundtrip(t, 2.5)
+	roundtrip(t, float32(-1.25))
+	roundtrip(t, point{1, 2})
+	roundtrip(t, [2]string{"a", "b"})
+	roundtrip(t, complex(1, -2))
+	x := 1
+	roundtrip(t, &x)
+	roundtrip(t, (*int)(nil))
+	roundtrip(t, make(chan int))
+	roundtrip(t, any(nil))
+	roundtrip(t, any(point{5, 6}))
+	roundtrip(t, struct {
+		a any
+		p *int
+		_ int
+		c any
+	}{a: "x", p: &x, c: id("y")})
+
+	if enc(math.Copysign(0, -1)) != enc(0.0) || enc(complex(math.Copysign(0, -1), 1)) != enc(complex(0, 1)) {
+		t.Errorf("Append failed: -0 and 0 have different encodings.\n")
+	}
+	if enc(point{3, 4}) != enc(point{3, 4}) || enc(any(point{3, 4})) != enc(any(point{3, 4})) {
+		t.Errorf("Append failed: equal structs have different encodings.\n")
+	}
+	if enc(any(1)) == enc(any(int8(1))) || enc(point{3, 4}) == enc(point{4, 3}) {
+		t.Errorf("Append failed: different values have equal encodings.\n")
+	}
+	y := 1
+	if enc(&x) == enc(&y) {
+		t.Errorf("Append failed: different pointers have equal encodings.\n")
+	}
+	var refs Refs
+	Append(nil, [2]*int{&x, &x}, &refs)
+	if refs.Len() != 1 {
+		t.Errorf("Append failed: got %d references, expected 1.\n", refs.Len())
+	}
+
+	// value encodings are available for basic kinds only
+	if b, ok := AppendValue(nil, id("x")); !ok || string(b) != enc("x") {
+		t.Errorf("AppendValue failed for a named string: got %v/%t.\n", b, ok)
+	}
+	if b, ok := AppendValue([]byte{1}, point{1, 2}); ok || len(b) != 1 {
+		t.Errorf("AppendValue failed for a struct: got %v/%t.\n", b, ok)
+	}
+
+	// concatenated encodings
+	b := Append(Append(nil, "ab", &refs), "c", &refs)
+	s1, b := Decode[string](b, nil)
+	s2, b := Decode[string](b, nil)
+	if s1 != "ab" || s2 != "c" || len(b) != 0 {
+		t.Errorf("Decode failed on concatenated encodings: got %q and %q.\n", s1, s2)
+	}
+}
+
+func TestReadValue(t *testing.T) {
+	b, _ := AppendValue(nil, "hello")
+	b, _ = AppendValue(b, "")
+	r := bytes.NewReader(b)
+	if s, err := ReadValue[string](r, 10); s != "hello" || err != nil {
+		t.Errorf("ReadValue failed: got %q/%v.\n", s, err)
+	}
+	if s, err := ReadValue[string](r, 10); s != "" || err != nil {
+		t.Errorf("ReadValue failed: got %q/%v.\n", s, err)
+	}
+	if _, err := ReadValue[string](r, 10); err != io.EOF {
+		t.Errorf("ReadValue failed at the end: got %v.\n", err)
+	}
+
+	b, _ = AppendValue(nil, id("much too long"))
+	if _, err := ReadValue[id](bytes.NewReader(b), 4); err == nil {
+		t.Errorf("ReadValue failed: string longer than the limit accepted.\n")
+	}
+	if _, err := ReadValue[string](bytes.NewReader(b[:5]), 100); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadValue failed for a truncated string: got %v.\n", err)
+	}
+	b, _ = AppendValue(nil, 300)
+	if _, err := ReadValue[int8](bytes.NewReader(b), 0); err == nil {
+		t.Errorf("ReadValue failed: int8 overflow accepted.\n")
+	}
+	b, _ = AppendValue(nil, -1.5)
+	if f, err := ReadValue[float32](bytes.NewReader(b), 0); f != -1.5 || err != nil {
+		t.Errorf("ReadValue failed: got %v/%v.\n", f, err)
+	}
+	if _, err := ReadValue[point](bytes.NewReader(b), 0); err != ErrNoValueEncoding {
+		t.Errorf("ReadValue failed for a struct: got %v.\n", err)
+	}
+}
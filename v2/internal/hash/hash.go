@@ -0,0 +1,85 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+// Package hash provides the stable 64 bit hash functions shared by the
+// probabilistic data structures of the set module.
+package hash
+
+import "hash/maphash"
+
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+// Sum64 returns the hash of b. It is FNV-1a followed by the finalizer of
+// MurmurHash3, which spreads the entropy over all bits.
+func Sum64(b []byte) uint64 {
+	h := uint64(offset64)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= prime64
+	}
+	return Mix64(h)
+}
+
+// String returns the hash of s, like Sum64.
+func String(s string) uint64 {
+	h := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime64
+	}
+	return Mix64(h)
+}
+
+// Mix64 is the 64 bit finalizer of MurmurHash3.
+func Mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// seed is the seed of Of for values without a stable hash
+var seed = maphash.MakeSeed()
+
+// Of returns the hash of a comparable value, such that values which are
+// equal with == have equal hashes; in particular, 0.0 and -0.0 hash alike.
+// Strings and integers of the predeclared types are hashed directly, so their
+// hashes are stable across processes. All other values, including floats and
+// structs, are hashed with maphash.Comparable and a per-process seed. NaN
+// values, which are never equal to anything, get random hashes.
+func Of[T comparable](e T) uint64 {
+	switch v := any(e).(type) {
+	case string:
+		return String(v)
+	case int:
+		return Mix64(uint64(v))
+	case int64:
+		return Mix64(uint64(v))
+	case int32:
+		return Mix64(uint64(v))
+	case uint:
+		return Mix64(uint64(v))
+	case uint64:
+		return Mix64(v)
+	case uint32:
+		return Mix64(uint64(v))
+	case int16:
+		return Mix64(uint64(v))
+	case uint16:
+		return Mix64(uint64(v))
+	case int8:
+		return Mix64(uint64(v))
+	case uint8:
+		return Mix64(uint64(v))
+	case uintptr:
+		return Mix64(uint64(v))
+	}
+	return maphash.Comparable(seed, e)
+}
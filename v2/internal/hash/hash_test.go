@@ -0,0 +1,54 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package hash
+
+import (
+	"math"
+	"testing"
+)
+
+type point2 struct{ f float64 }
+
+func TestHash(t *testing.T) {
+	if Sum64([]byte("set")) != String("set") || String("set") != Of("set") {
+		t.Errorf("hash failed: byte, string and generic hashes differ.\n")
+	}
+	if String("a") == String("b") || Of(1) == Of(2) {
+		t.Errorf("hash failed: collision on trivial input.\n")
+	}
+	type point struct{ x, y int }
+	if Of(point{1, 2}) != Of(point{1, 2}) || Of(point{1, 2}) == Of(point{2, 1}) {
+		t.Errorf("hash failed: struct hashes are not stable.\n")
+	}
+	// equal values hash alike, even if their representations differ
+	negZero := math.Copysign(0, -1)
+	if Of(0.0) != Of(negZero) || Of(point2{0}) != Of(point2{negZero}) {
+		t.Errorf("hash failed: 0.0 and -0.0 hash differently.\n")
+	}
+	if Of(float32(1.5)) != Of(float32(1.5)) || Of([2]float64{1, 2}) != Of([2]float64{1, 2}) {
+		t.Errorf("hash failed: float hashes are not stable.\n")
+	}
+	// integers of all sizes are hashed without a seed, alike for equal values
+	if Of(int8(-1)) != Mix64(^uint64(0)) || Of(int16(-1)) != Of(int64(-1)) || Of(uint8(7)) != Of(uint16(7)) {
+		t.Errorf("hash failed: small integer hashes are not stable.\n")
+	}
+
+	// the finalizer changes about half of the bits for adjacent inputs
+	diff := Mix64(1) ^ Mix64(2)
+	n := 0
+	for ; diff != 0; diff &= diff - 1 {
+		n++
+	}
+	if n < 16 || n > 48 {
+		t.Errorf("Mix64 failed: %d differing bits.\n", n)
+	}
+}
+
+func BenchmarkOf(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = Of(uint16(i))
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+// Package textcodec provides the text encoding of comparable values, which
+// is shared by the text formats of the set module, like command line flags,
+// database columns and the members of remote sets.
+//
+// Values are formatted with their MarshalText method if they have one, and
+// with fmt otherwise. They are parsed by their UnmarshalText method if they
+// have one, or else by their kind: strings are taken as they are, and
+// booleans and numbers are parsed with strconv.
+package textcodec
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ErrNoTextEncoding is returned by Parse for types which cannot be parsed
+// from text.
+var ErrNoTextEncoding = errors.New("type has no text encoding")
+
+// Format returns the text of a value.
+func Format[T comparable](e T) (string, error) {
+	if m, ok := any(e).(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	return fmt.Sprint(e), nil
+}
+
+// Parse parses a value from its text.
+func Parse[T comparable](t string) (T, error) {
+	var e T
+	if u, ok := any(&e).(encoding.TextUnmarshaler); ok {
+		return e, u.UnmarshalText([]byte(t))
+	}
+	v := reflect.ValueOf(&e).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(t)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(t)
+		if err != nil {
+			return e, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(t, 0, v.Type().Bits())
+		if err != nil {
+			return e, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(t, 0, v.Type().Bits())
+		if err != nil {
+			return e, err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(t, v.Type().Bits())
+		if err != nil {
+			return e, err
+		}
+		v.SetFloat(f)
+	default:
+		return e, fmt.Errorf("%w: %T", ErrNoTextEncoding, e)
+	}
+	return e, nil
+}
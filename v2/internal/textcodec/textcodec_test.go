@@ -0,0 +1,45 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package textcodec
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+type level int8
+
+func TestCodec(t *testing.T) {
+	if s, err := Format(level(-3)); err != nil || s != "-3" {
+		t.Errorf("Format failed: got %q, %v.\n", s, err)
+	}
+	if l, err := Parse[level]("0x10"); err != nil || l != 16 {
+		t.Errorf("Parse failed: got %v, %v.\n", l, err)
+	}
+	if _, err := Parse[level]("300"); err == nil {
+		t.Errorf("Parse failed: no error for an overflow.\n")
+	}
+	if b, err := Parse[bool]("true"); err != nil || !b {
+		t.Errorf("Parse failed: got %v, %v.\n", b, err)
+	}
+	if f, err := Parse[float32]("1.5"); err != nil || f != 1.5 {
+		t.Errorf("Parse failed: got %v, %v.\n", f, err)
+	}
+
+	// values with text methods
+	a := netip.MustParseAddr("::1")
+	if s, err := Format(a); err != nil || s != "::1" {
+		t.Errorf("Format failed: got %q, %v.\n", s, err)
+	}
+	if b, err := Parse[netip.Addr]("::1"); err != nil || b != a {
+		t.Errorf("Parse failed: got %v, %v.\n", b, err)
+	}
+
+	if _, err := Parse[struct{ x int }]("1"); !errors.Is(err, ErrNoTextEncoding) {
+		t.Errorf("Parse failed: got %v.\n", err)
+	}
+}
@@ -0,0 +1,404 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"unsafe"
+)
+
+// ----- IntervalSet definition -----
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// A Range is a closed interval [Lo, Hi] of integers.
+type Range[T Integer] struct {
+	Lo, Hi T
+}
+
+// An IntervalSet is a set of integers stored as a sorted list of disjoint,
+// non-adjacent ranges. Every IntervalSet has a finite universe, which defaults
+// to the full range of the type T. Elements outside of the universe are
+// ignored, and the complement of a set is taken within its universe.
+//
+// Since ranges are coalesced with their adjacent ranges, the elements must be
+// discrete. Timestamps can be stored by their Unix time, e.g. in
+// nanoseconds.
+type IntervalSet[T Integer] struct {
+	ranges   []Range[T]
+	universe Range[T]
+}
+
+// ----- constructors -----
+
+// NewIntervalSet creates a new interval set, whose universe is the full range
+// of the type T, and initializes it with the argument values.
+func NewIntervalSet[T Integer](e ...T) *IntervalSet[T] {
+	lo, hi := typeBounds[T]()
+	return NewIntervalSetIn(lo, hi, e...)
+}
+
+// NewIntervalSetIn creates a new interval set with the universe [lo, hi] and
+// initializes it with the argument values.
+func NewIntervalSetIn[T Integer](lo, hi T, e ...T) *IntervalSet[T] {
+	s := &IntervalSet[T]{universe: Range[T]{lo, hi}}
+	s.Add(e...)
+	return s
+}
+
+// typeBounds returns the smallest and largest value of the integer type T.
+func typeBounds[T Integer]() (lo, hi T) {
+	var zero T
+	if ^zero > zero {
+		return zero, ^zero // unsigned
+	}
+	hi = T(1)<<(unsafe.Sizeof(zero)*8-1) - 1
+	return ^hi, hi
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s *IntervalSet[T]) Add(e ...T) {
+	for _, i := range e {
+		s.AddRange(i, i)
+	}
+}
+
+// AddRange adds all elements of the closed interval [lo, hi] to the given set.
+// Overlapping and adjacent ranges are coalesced.
+func (s *IntervalSet[T]) AddRange(lo, hi T) {
+	lo, hi = max(lo, s.universe.Lo), min(hi, s.universe.Hi)
+	if lo > hi {
+		return
+	}
+	// ranges [i, j) overlap or touch the new range
+	i, _ := slices.BinarySearchFunc(s.ranges, lo, func(r Range[T], lo T) int {
+		if r.Hi < lo && r.Hi+1 < lo {
+			return -1
+		}
+		return 1
+	})
+	j := i
+	for j < len(s.ranges) && (s.ranges[j].Lo <= hi || s.ranges[j].Lo-1 <= hi) {
+		j++
+	}
+	if i < j {
+		lo, hi = min(lo, s.ranges[i].Lo), max(hi, s.ranges[j-1].Hi)
+	}
+	s.ranges = slices.Replace(s.ranges, i, j, Range[T]{lo, hi})
+}
+
+// Remove removes one or more elements from the given set.
+func (s *IntervalSet[T]) Remove(e ...T) {
+	for _, i := range e {
+		s.RemoveRange(i, i)
+	}
+}
+
+// RemoveRange removes all elements of the closed interval [lo, hi] from the
+// given set.
+func (s *IntervalSet[T]) RemoveRange(lo, hi T) {
+	if lo > hi {
+		return
+	}
+	r := s.ranges[:0:0]
+	for _, i := range s.ranges {
+		if i.Hi < lo || i.Lo > hi {
+			r = append(r, i)
+			continue
+		}
+		if i.Lo < lo {
+			r = append(r, Range[T]{i.Lo, lo - 1})
+		}
+		if i.Hi > hi {
+			r = append(r, Range[T]{hi + 1, i.Hi})
+		}
+	}
+	s.ranges = r
+}
+
+// Clear removes all elements from the given set.
+func (s *IntervalSet[T]) Clear() {
+	s.ranges = s.ranges[:0]
+}
+
+// Reset removes all elements from the given set, and replaces its storage
+// with a new one presized for capacityHint disjoint ranges.
+func (s *IntervalSet[T]) Reset(capacityHint int) {
+	s.ranges = make([]Range[T], 0, max(capacityHint, 0))
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *IntervalSet[T]) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Count returns the number of elements in the set. If the set contains all
+// 2^64 values of a 64 bit type, math.MaxUint64 is returned.
+func (s *IntervalSet[T]) Count() uint64 {
+	var n uint64
+	for _, i := range s.ranges {
+		d := uint64(i.Hi) - uint64(i.Lo)
+		if n+d+1 < n || d == math.MaxUint64 {
+			return math.MaxUint64
+		}
+		n += d + 1
+	}
+	return n
+}
+
+// NumRanges returns the number of disjoint ranges stored in the set.
+func (s *IntervalSet[T]) NumRanges() int {
+	return len(s.ranges)
+}
+
+// Universe returns the universe of the set.
+func (s *IntervalSet[T]) Universe() Range[T] {
+	return s.universe
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *IntervalSet[T]) Contains(e ...T) bool {
+	for _, i := range e {
+		if !s.contains(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *IntervalSet[T]) contains(x T) bool {
+	i, _ := slices.BinarySearchFunc(s.ranges, x, func(r Range[T], x T) int {
+		if r.Hi < x {
+			return -1
+		}
+		return 1
+	})
+	return i < len(s.ranges) && s.ranges[i].Lo <= x
+}
+
+// ContainsSorted checks the membership of each element of a query slice,
+// which must be sorted in ascending order. The ranges of the set and the
+// query are scanned in a single merge pass, which is much faster than
+// individual lookups for large queries. The result is undefined if the
+// query is not sorted.
+func (s *IntervalSet[T]) ContainsSorted(sorted []T) []bool {
+	r := make([]bool, len(sorted))
+	j := 0
+	for i, x := range sorted {
+		for j < len(s.ranges) && s.ranges[j].Hi < x {
+			j++
+		}
+		if j == len(s.ranges) {
+			break
+		}
+		r[i] = s.ranges[j].Lo <= x
+	}
+	return r
+}
+
+// IsEqual tests if two sets are equal. The universes are not compared.
+func (s *IntervalSet[T]) IsEqual(t *IntervalSet[T]) bool {
+	return slices.Equal(s.ranges, t.ranges)
+}
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *IntervalSet[T]) Copy() *IntervalSet[T] {
+	return &IntervalSet[T]{ranges: slices.Clone(s.ranges), universe: s.universe}
+}
+
+// Complement returns a new set which contains all elements of the universe
+// which are not in s. The set s is not modified.
+func (s *IntervalSet[T]) Complement() *IntervalSet[T] {
+	r := &IntervalSet[T]{universe: s.universe}
+	next, done := s.universe.Lo, false
+	for _, i := range s.ranges {
+		if i.Lo > next {
+			r.ranges = append(r.ranges, Range[T]{next, i.Lo - 1})
+		}
+		if i.Hi == s.universe.Hi {
+			done = true
+			break
+		}
+		next = i.Hi + 1
+	}
+	if !done {
+		r.ranges = append(r.ranges, Range[T]{next, s.universe.Hi})
+	}
+	return r
+}
+
+// ----- set operations -----
+
+// The set operations merge the sorted range lists of the sets in a single
+// pass, which takes O(n+m) steps for n and m ranges, independent of the
+// number of elements. The results have the universe of s; ranges of other
+// sets outside of it are clipped.
+
+// Union returns a new set with the elements which are in s or in any of the
+// argument sets.
+func (s *IntervalSet[T]) Union(t ...*IntervalSet[T]) *IntervalSet[T] {
+	r := s.Copy()
+	for _, u := range t {
+		r.ranges = unionRanges(r.ranges, u.clip(s.universe))
+	}
+	return r
+}
+
+// Intersect returns a new set with the elements which are in s and in all of
+// the argument sets.
+func (s *IntervalSet[T]) Intersect(t ...*IntervalSet[T]) *IntervalSet[T] {
+	r := s.Copy()
+	for _, u := range t {
+		r.ranges = intersectRanges(r.ranges, u.ranges)
+	}
+	return r
+}
+
+// Diff returns a new set with the elements of s which are not in t.
+func (s *IntervalSet[T]) Diff(t *IntervalSet[T]) *IntervalSet[T] {
+	return &IntervalSet[T]{ranges: diffRanges(s.ranges, t.ranges), universe: s.universe}
+}
+
+// SymDiff returns a new set with the elements which are either in s or in t,
+// but not in both.
+func (s *IntervalSet[T]) SymDiff(t *IntervalSet[T]) *IntervalSet[T] {
+	u := t.clip(s.universe)
+	r := unionRanges(diffRanges(s.ranges, u), diffRanges(u, s.ranges))
+	return &IntervalSet[T]{ranges: r, universe: s.universe}
+}
+
+// IsSubsetOf returns true if the set s is a subset of the set t.
+func (s *IntervalSet[T]) IsSubsetOf(t *IntervalSet[T]) bool {
+	return len(diffRanges(s.ranges, t.ranges)) == 0
+}
+
+// clip returns the ranges of s clipped to the universe u.
+func (s *IntervalSet[T]) clip(u Range[T]) []Range[T] {
+	if s.universe == u {
+		return s.ranges
+	}
+	var r []Range[T]
+	for _, i := range s.ranges {
+		if lo, hi := max(i.Lo, u.Lo), min(i.Hi, u.Hi); lo <= hi {
+			r = append(r, Range[T]{lo, hi})
+		}
+	}
+	return r
+}
+
+// unionRanges merges two sorted lists of disjoint ranges, coalescing
+// overlapping and adjacent ranges.
+func unionRanges[T Integer](a, b []Range[T]) []Range[T] {
+	r := make([]Range[T], 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		var n Range[T]
+		if len(b) == 0 || len(a) > 0 && a[0].Lo <= b[0].Lo {
+			n, a = a[0], a[1:]
+		} else {
+			n, b = b[0], b[1:]
+		}
+		if l := len(r) - 1; l >= 0 && (n.Lo <= r[l].Hi || r[l].Hi+1 == n.Lo) {
+			r[l].Hi = max(r[l].Hi, n.Hi)
+			continue
+		}
+		r = append(r, n)
+	}
+	return r
+}
+
+// intersectRanges intersects two sorted lists of disjoint ranges.
+func intersectRanges[T Integer](a, b []Range[T]) []Range[T] {
+	var r []Range[T]
+	for len(a) > 0 && len(b) > 0 {
+		if lo, hi := max(a[0].Lo, b[0].Lo), min(a[0].Hi, b[0].Hi); lo <= hi {
+			r = append(r, Range[T]{lo, hi})
+		}
+		if a[0].Hi < b[0].Hi {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return r
+}
+
+// diffRanges removes the ranges of b from the ranges of a, both sorted lists
+// of disjoint ranges.
+func diffRanges[T Integer](a, b []Range[T]) []Range[T] {
+	var r []Range[T]
+	for _, i := range a {
+		for len(b) > 0 && b[0].Hi < i.Lo {
+			b = b[1:]
+		}
+		// the ranges b[k] with b[k].Lo <= i.Hi overlap i
+		lo, covered := i.Lo, false
+		for k := 0; k < len(b) && b[k].Lo <= i.Hi; k++ {
+			if b[k].Lo > lo {
+				r = append(r, Range[T]{lo, b[k].Lo - 1})
+			}
+			if b[k].Hi >= i.Hi {
+				covered = true
+				break
+			}
+			lo = b[k].Hi + 1
+		}
+		if !covered {
+			r = append(r, Range[T]{lo, i.Hi})
+		}
+	}
+	return r
+}
+
+// ----- iterators -----
+
+// All returns an iterator to all elements in the set in ascending order.
+func (s *IntervalSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, r := range s.ranges {
+			for x := r.Lo; ; x++ {
+				if !yield(x) {
+					return
+				}
+				if x == r.Hi {
+					break
+				}
+			}
+		}
+	}
+}
+
+// ----- methods that return other data types -----
+
+// Ranges returns the disjoint ranges of the set in ascending order.
+func (s *IntervalSet[T]) Ranges() []Range[T] {
+	return slices.Clone(s.ranges)
+}
+
+// String returns a textual representation of the set in a string.
+func (s *IntervalSet[T]) String() string {
+	str := "{ "
+	for _, i := range s.ranges {
+		if i.Lo == i.Hi {
+			str += fmt.Sprint(i.Lo) + " "
+		} else {
+			str += fmt.Sprintf("%v..%v ", i.Lo, i.Hi)
+		}
+	}
+	str += "}"
+	return str
+}
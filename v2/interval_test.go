@@ -0,0 +1,137 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestIntervalSet(t *testing.T) {
+	s := NewIntervalSet[int](1, 2, 3, 10)
+	s.AddRange(5, 8)
+	s.AddRange(4, 4) // coalesces 1..3, 4 and 5..8
+	if str := s.String(); str != "{ 1..8 10 }" {
+		t.Errorf("AddRange failed: got %v.\n", str)
+	}
+	if !s.Contains(1, 8, 10) || s.Contains(9) || s.Contains(0) {
+		t.Errorf("Contains failed for %v.\n", s)
+	}
+	if s.Count() != 9 || s.NumRanges() != 2 {
+		t.Errorf("Count/NumRanges failed: got %d/%d.\n", s.Count(), s.NumRanges())
+	}
+
+	s.RemoveRange(3, 5)
+	s.Remove(10)
+	if str := s.String(); str != "{ 1..2 6..8 }" {
+		t.Errorf("RemoveRange failed: got %v.\n", str)
+	}
+	if l := slices.Collect(s.All()); !slices.Equal(l, []int{1, 2, 6, 7, 8}) {
+		t.Errorf("All failed: got %v.\n", l)
+	}
+
+	c := s.Copy()
+	c.Clear()
+	if !c.IsEmpty() || s.IsEmpty() {
+		t.Errorf("Copy/Clear failed: got %v and %v.\n", s, c)
+	}
+}
+
+func TestIntervalComplement(t *testing.T) {
+	ports := NewIntervalSet[uint16]()
+	ports.AddRange(0, 1023)
+	ports.Add(8080)
+	c := ports.Complement()
+	if str := c.String(); str != "{ 1024..8079 8081..65535 }" {
+		t.Errorf("Complement failed: got %v.\n", str)
+	}
+	if !c.Complement().IsEqual(ports) {
+		t.Errorf("Complement failed: double complement is %v.\n", c.Complement())
+	}
+	if full := NewIntervalSet[uint16]().Complement(); full.Count() != 65536 {
+		t.Errorf("Complement failed: complement of empty set has %d elements.\n", full.Count())
+	}
+
+	s := NewIntervalSet[int8](-128, 127)
+	if str := s.Complement().String(); str != "{ -127..126 }" {
+		t.Errorf("Complement failed for signed type: got %v.\n", str)
+	}
+
+	u := NewIntervalSetIn[int](1, 10, 0, 5, 11)
+	if str := u.Complement().String(); str != "{ 1..4 6..10 }" {
+		t.Errorf("Complement failed for bounded universe: got %v.\n", str)
+	}
+
+	all := NewIntervalSet[uint64]()
+	all.AddRange(0, math.MaxUint64)
+	if all.Count() != math.MaxUint64 || !all.Complement().IsEmpty() {
+		t.Errorf("Count failed for full universe: got %d.\n", all.Count())
+	}
+}
+
+func TestIntervalContainsSorted(t *testing.T) {
+	s := NewIntervalSet[int](3, 10)
+	s.AddRange(5, 7)
+	got := s.ContainsSorted([]int{1, 3, 4, 5, 5, 7, 8, 10, 11})
+	want := []bool{false, true, false, true, true, true, false, true, false}
+	if !slices.Equal(got, want) {
+		t.Errorf("ContainsSorted failed: got %v, expected %v.\n", got, want)
+	}
+	if got := NewIntervalSet[int]().ContainsSorted([]int{1}); got[0] {
+		t.Errorf("ContainsSorted failed on empty set.\n")
+	}
+}
+
+func TestIntervalSetAlgebra(t *testing.T) {
+	a := NewIntervalSet[int]()
+	a.AddRange(1, 10)
+	a.AddRange(20, 30)
+	b := NewIntervalSet[int](11, 15)
+	b.AddRange(25, 40)
+
+	tests := []struct {
+		name string
+		got  *IntervalSet[int]
+		want string
+	}{
+		{"Union", a.Union(b), "{ 1..11 15 20..40 }"},
+		{"Intersect", a.Intersect(b), "{ 25..30 }"},
+		{"Diff", a.Diff(b), "{ 1..10 20..24 }"},
+		{"Diff", b.Diff(a), "{ 11 15 31..40 }"},
+		{"SymDiff", a.SymDiff(b), "{ 1..11 15 20..24 31..40 }"},
+		{"Union", a.Union(), "{ 1..10 20..30 }"},
+		{"Intersect", a.Intersect(b, NewIntervalSet(28)), "{ 28 }"},
+	}
+	for _, i := range tests {
+		if str := i.got.String(); str != i.want {
+			t.Errorf("%s failed: got %v, expected %v.\n", i.name, str, i.want)
+		}
+	}
+	if !NewIntervalSet(2, 5, 25).IsSubsetOf(a) || b.IsSubsetOf(a) {
+		t.Errorf("IsSubsetOf failed.\n")
+	}
+
+	// single element ranges and the bounds of the type
+	c := NewIntervalSet[uint8](0, 5, 255)
+	d := NewIntervalSet[uint8](5)
+	d.AddRange(250, 255)
+	if str := c.Diff(d).String(); str != "{ 0 }" {
+		t.Errorf("Diff failed: got %v.\n", str)
+	}
+	if str := c.Union(d).String(); str != "{ 0 5 250..255 }" {
+		t.Errorf("Union failed: got %v.\n", str)
+	}
+
+	// ranges outside of the universe of s are clipped
+	u := NewIntervalSetIn[int](0, 9, 1)
+	if r := u.Union(b); r.String() != "{ 1 }" || r.Universe() != u.Universe() {
+		t.Errorf("Union failed: got %v.\n", r)
+	}
+	if r := u.SymDiff(NewIntervalSet(1, 2, 100)); r.String() != "{ 2 }" {
+		t.Errorf("SymDiff failed: got %v.\n", r)
+	}
+}
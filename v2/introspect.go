@@ -0,0 +1,163 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "fmt"
+
+// ----- introspection -----
+
+// A BackendKind is the in-memory representation of the elements of a set,
+// as reported by its Backend method. It is unrelated to the Backend of a
+// TieredSet.
+type BackendKind uint8
+
+const (
+	UnknownBackend  BackendKind = iota // a set which does not report its backend
+	MapBackend                         // a Go map, like Set and SyncSet
+	BitmapBackend                      // a bitmap of integers, like BitSet
+	SkipListBackend                    // a skip list in the order of the elements, like SortedSet
+	ShardedBackend                     // Go maps in shards with locks of their own, like ShardedSet
+	FrozenBackend                      // an immutable encoding, like Frozen
+	HashBackend                        // buckets of user supplied hashes, like HashSet
+	numBackends
+)
+
+var backendNames = [...]string{"unknown", "map", "bitmap", "skiplist", "sharded", "frozen", "hash"}
+
+func (b BackendKind) String() string {
+	if b < numBackends {
+		return backendNames[b]
+	}
+	return fmt.Sprintf("BackendKind(%d)", b)
+}
+
+// A Config describes the effective configuration of a set, so frameworks
+// receiving a set through an interface can make informed decisions, like
+// skipping defensive copies of immutable sets, or the locking of thread
+// safe ones. It is a snapshot; ConfigOf returns it for any set type which
+// reports it.
+type Config struct {
+	Backend BackendKind
+
+	// Capacity is the number of elements, or the range of integer
+	// elements for bitmaps, which the set holds without growing. It is 0
+	// if it is unknown, like for Go maps.
+	Capacity int
+
+	// Shards is the number of shards of a sharded set, or 0.
+	Shards int
+
+	ThreadSafe    bool // if the set can be used concurrently
+	Immutable     bool // if the set cannot be modified
+	Validator     bool // if the set has a validator, see WithValidator
+	Canonicalizer bool // if the set has a canonicalizer, see WithCanonicalizer
+	Universe      bool // if the set is constrained to a universe
+	SortedJSON    bool // if the set has an order for MarshalJSON, see WithSortedJSON
+
+	// Separator is the separator of the text encoding, see WithSeparator,
+	// or "" for sets without a text encoding.
+	Separator string
+
+	Shrink       float64      // the shrink fraction, see WithShrink, or 0
+	MisusePolicy MisusePolicy // the effective misuse policy of sets based on Set
+}
+
+// ConfigOf returns the configuration of a set of the package, or of any
+// other type with a method Config() Config. The second return value is false
+// if s does not report its configuration.
+func ConfigOf(s any) (Config, bool) {
+	if c, ok := s.(interface{ Config() Config }); ok {
+		return c.Config(), true
+	}
+	return Config{}, false
+}
+
+// Backend returns MapBackend.
+func (s Set[T]) Backend() BackendKind {
+	return MapBackend
+}
+
+// Config returns the effective configuration of the set.
+func (s Set[T]) Config() Config {
+	c := Config{Backend: MapBackend, Separator: s.separator(), MisusePolicy: s.policy()}
+	if s.cfg != nil {
+		c.Validator = s.cfg.validate != nil
+		c.Canonicalizer = s.cfg.canon != nil
+		c.Universe = s.cfg.universe != nil
+		c.SortedJSON = s.cfg.order != nil
+		c.Shrink = s.cfg.shrink
+	}
+	return c
+}
+
+// Backend returns MapBackend.
+func (s *SyncSet[T]) Backend() BackendKind {
+	return MapBackend
+}
+
+// Config returns the effective configuration of the set, which is thread
+// safe.
+func (s *SyncSet[T]) Config() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := s.set.Config()
+	c.ThreadSafe = true
+	return c
+}
+
+// Backend returns ShardedBackend.
+func (s *ShardedSet[T]) Backend() BackendKind {
+	return ShardedBackend
+}
+
+// Config returns the configuration of the set, which is thread safe.
+func (s *ShardedSet[T]) Config() Config {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	return Config{Backend: ShardedBackend, Shards: len(s.shards), ThreadSafe: true}
+}
+
+// Backend returns BitmapBackend.
+func (s *BitSet) Backend() BackendKind {
+	return BitmapBackend
+}
+
+// Config returns the configuration of the set. Its capacity is the range of
+// the elements 0 to Capacity-1 which it holds without growing.
+func (s *BitSet) Config() Config {
+	return Config{Backend: BitmapBackend, Capacity: 64 * len(s.words)}
+}
+
+// Backend returns SkipListBackend.
+func (s *SortedSet[T]) Backend() BackendKind {
+	return SkipListBackend
+}
+
+// Config returns the configuration of the set.
+func (s *SortedSet[T]) Config() Config {
+	return Config{Backend: SkipListBackend}
+}
+
+// Backend returns FrozenBackend.
+func (f Frozen[T]) Backend() BackendKind {
+	return FrozenBackend
+}
+
+// Config returns the configuration of the frozen set, which is immutable,
+// and thus thread safe. Its capacity is its length.
+func (f Frozen[T]) Config() Config {
+	return Config{Backend: FrozenBackend, Capacity: f.n, ThreadSafe: true, Immutable: true}
+}
+
+// Backend returns HashBackend.
+func (s *HashSet[T]) Backend() BackendKind {
+	return HashBackend
+}
+
+// Config returns the configuration of the set.
+func (s *HashSet[T]) Config() Config {
+	return Config{Backend: HashBackend}
+}
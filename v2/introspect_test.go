@@ -0,0 +1,52 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	if c := New(1).Config(); c != (Config{Backend: MapBackend, Separator: ",", MisusePolicy: PanicOnMisuse}) {
+		t.Errorf("Config failed for a plain set: got %+v.\n", c)
+	}
+	s := NewWith(WithCanonicalizer(strings.ToLower), WithSeparator[string](";"), WithMisusePolicy[string](RecordMisuse))
+	if c := s.Config(); !c.Canonicalizer || c.Validator || c.Separator != ";" || c.MisusePolicy != RecordMisuse {
+		t.Errorf("Config failed for a configured set: got %+v.\n", c)
+	}
+	u, _ := NewUniverse("days", "mon", "tue").New()
+	if c := u.Config(); !c.Universe {
+		t.Errorf("Config failed for a universe set: got %+v.\n", c)
+	}
+	y := NewSyncWith(WithShrink[int](0.25), WithSortedJSON[int]())
+	if c := y.Config(); !c.ThreadSafe || c.Shrink != 0.25 || !c.SortedJSON || y.Backend() != MapBackend {
+		t.Errorf("Config failed for a sync set: got %+v.\n", c)
+	}
+
+	// the other backends, as seen through Interface
+	for _, tc := range []struct {
+		s    Interface[int]
+		want Config
+	}{
+		{NewBitSet(100), Config{Backend: BitmapBackend, Capacity: 128}},
+		{NewSorted(1), Config{Backend: SkipListBackend}},
+		{NewSharded[int](4), Config{Backend: ShardedBackend, Shards: 4, ThreadSafe: true}},
+	} {
+		if c, ok := ConfigOf(tc.s); !ok || c != tc.want {
+			t.Errorf("ConfigOf failed for %T: got %+v, %v.\n", tc.s, c, ok)
+		}
+	}
+	if c, ok := ConfigOf(New(1, 2).Frozen()); !ok || !c.Immutable || c.Capacity != 2 || c.Backend.String() != "frozen" {
+		t.Errorf("ConfigOf failed for a frozen set: got %+v, %v.\n", c, ok)
+	}
+	if _, ok := ConfigOf(NewOrdered[int]()); ok {
+		t.Errorf("ConfigOf failed: got a configuration of an ordered set.\n")
+	}
+	if b := BackendKind(42).String(); b != "BackendKind(42)" {
+		t.Errorf("String failed: got %q.\n", b)
+	}
+}
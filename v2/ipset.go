@@ -0,0 +1,376 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// ----- IPSet definition -----
+
+// An IPSet is a set of IPv4 and IPv6 addresses, built from CIDR prefixes
+// like 10.0.0.0/8. Its set semantics is on the address space, so 10.0.0.0/8
+// contains 10.1.0.0/16, and the union of 10.0.0.0/9 and 10.128.0.0/9 is
+// equal to 10.0.0.0/8.
+//
+// Besides the addresses, the set keeps the prefixes it was built from, which
+// may overlap like the entries of a routing table. They answer longest
+// prefix queries. The set operations derive the prefixes of their result
+// from the prefixes of their operands; where a prefix is only partially in
+// the result, it is replaced by the minimal prefixes covering its remaining
+// addresses.
+//
+// IPv4-mapped IPv6 addresses are treated as IPv4 addresses, and zones are
+// ignored.
+type IPSet struct {
+	ranges   []ipRange // sorted, disjoint and non-adjacent
+	prefixes map[netip.Prefix]struct{}
+}
+
+// a closed range of addresses of the same family
+type ipRange struct {
+	lo, hi netip.Addr
+}
+
+// ----- constructor -----
+
+// NewIPSet creates a new IP set and initializes it with the argument
+// prefixes. Invalid prefixes are ignored.
+func NewIPSet(p ...netip.Prefix) *IPSet {
+	s := &IPSet{prefixes: map[netip.Prefix]struct{}{}}
+	s.Add(p...)
+	return s
+}
+
+// canonPrefix returns the masked form of p, with IPv4-mapped addresses
+// unmapped. The second return value is false for invalid prefixes.
+func canonPrefix(p netip.Prefix) (netip.Prefix, bool) {
+	if !p.IsValid() {
+		return p, false
+	}
+	if a := p.Addr(); a.Is4In6() {
+		if p.Bits() < 96 {
+			return p, false
+		}
+		p = netip.PrefixFrom(a.Unmap(), p.Bits()-96)
+	}
+	return p.Masked(), true
+}
+
+// canonAddr returns a without zone, with IPv4-mapped addresses unmapped.
+func canonAddr(a netip.Addr) netip.Addr {
+	return a.Unmap().WithZone("")
+}
+
+// prefixRange returns the range of addresses of the masked prefix p.
+func prefixRange(p netip.Prefix) ipRange {
+	lo := p.Addr()
+	b := lo.As16()
+	first := 16 - lo.BitLen()/8 // the first byte of the address in b
+	for i := first*8 + p.Bits(); i < 128; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	hi := netip.AddrFrom16(b)
+	if lo.Is4() {
+		hi = hi.Unmap()
+	}
+	return ipRange{lo, hi}
+}
+
+// rangePrefixes returns the minimal list of prefixes covering r.
+func rangePrefixes(r ipRange) []netip.Prefix {
+	var l []netip.Prefix
+	for lo := r.lo; ; {
+		// the shortest prefix starting at lo, which does not exceed r
+		var p netip.Prefix
+		for bits := 0; bits <= lo.BitLen(); bits++ {
+			p = netip.PrefixFrom(lo, bits)
+			if p.Masked().Addr() == lo && prefixRange(p).hi.Compare(r.hi) <= 0 {
+				break
+			}
+		}
+		l = append(l, p)
+		hi := prefixRange(p).hi
+		if hi == r.hi {
+			return l
+		}
+		lo = hi.Next()
+	}
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds the addresses of one or more prefixes to the given set.
+func (s *IPSet) Add(p ...netip.Prefix) {
+	var r []ipRange
+	for _, i := range p {
+		if i, ok := canonPrefix(i); ok {
+			s.prefixes[i] = struct{}{}
+			r = append(r, prefixRange(i))
+		}
+	}
+	slices.SortFunc(r, func(a, b ipRange) int { return a.lo.Compare(b.lo) })
+	s.ranges = ipUnion(s.ranges, ipUnion(nil, r))
+}
+
+// Remove removes the addresses of one or more prefixes from the given set.
+func (s *IPSet) Remove(p ...netip.Prefix) {
+	*s = *s.Diff(NewIPSet(p...))
+}
+
+// Clear removes all addresses and prefixes from the given set.
+func (s *IPSet) Clear() {
+	s.ranges = s.ranges[:0]
+	clear(s.prefixes)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *IPSet) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Contains checks if a set contains one or more addresses. The return value
+// is true only if all given addresses are in the set.
+func (s *IPSet) Contains(a ...netip.Addr) bool {
+	for _, i := range a {
+		if !i.IsValid() {
+			return false
+		}
+		i = canonAddr(i)
+		if !s.covers(ipRange{i, i}) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsPrefix checks if a set contains all addresses of one or more
+// prefixes.
+func (s *IPSet) ContainsPrefix(p ...netip.Prefix) bool {
+	for _, i := range p {
+		i, ok := canonPrefix(i)
+		if !ok || !s.covers(prefixRange(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// covers checks if the range r is in the set.
+func (s *IPSet) covers(r ipRange) bool {
+	i, _ := slices.BinarySearchFunc(s.ranges, r.lo, func(x ipRange, a netip.Addr) int {
+		return x.hi.Compare(a)
+	})
+	return i < len(s.ranges) && s.ranges[i].lo.Compare(r.lo) <= 0 && s.ranges[i].hi.Compare(r.hi) >= 0
+}
+
+// LongestPrefix returns the most specific prefix of the set which contains
+// the address, as a router does for its routing table. The second return
+// value is false if no prefix contains the address.
+func (s *IPSet) LongestPrefix(a netip.Addr) (netip.Prefix, bool) {
+	if !a.IsValid() {
+		return netip.Prefix{}, false
+	}
+	a = canonAddr(a)
+	for bits := a.BitLen(); bits >= 0; bits-- {
+		p, _ := a.Prefix(bits)
+		if _, ok := s.prefixes[p]; ok {
+			return p, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// IsEqual tests if two sets contain the same addresses. Their prefixes may
+// differ.
+func (s *IPSet) IsEqual(t *IPSet) bool {
+	return slices.Equal(s.ranges, t.ranges)
+}
+
+// IsSubsetOf returns true if all addresses of s are in t.
+func (s *IPSet) IsSubsetOf(t *IPSet) bool {
+	return len(ipDiff(s.ranges, t.ranges)) == 0
+}
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *IPSet) Copy() *IPSet {
+	return &IPSet{ranges: slices.Clone(s.ranges), prefixes: maps.Clone(s.prefixes)}
+}
+
+// ----- methods that return a new set -----
+
+// Union returns a new set with the addresses and prefixes of s and all of the
+// argument sets.
+func (s *IPSet) Union(t ...*IPSet) *IPSet {
+	r := s.Copy()
+	for _, u := range t {
+		r.ranges = ipUnion(r.ranges, u.ranges)
+		for p := range u.prefixes {
+			r.prefixes[p] = struct{}{}
+		}
+	}
+	return r
+}
+
+// Intersect returns a new set with the addresses which are in s and in all of
+// the argument sets. As overlapping prefixes are nested, the prefixes of the
+// result are those prefixes of each operand which are fully covered by the
+// other operand.
+func (s *IPSet) Intersect(t ...*IPSet) *IPSet {
+	r := s.Copy()
+	for _, u := range t {
+		n := &IPSet{ranges: ipIntersect(r.ranges, u.ranges), prefixes: map[netip.Prefix]struct{}{}}
+		for p := range r.prefixes {
+			if u.covers(prefixRange(p)) {
+				n.prefixes[p] = struct{}{}
+			}
+		}
+		for p := range u.prefixes {
+			if r.covers(prefixRange(p)) {
+				n.prefixes[p] = struct{}{}
+			}
+		}
+		r = n
+	}
+	return r
+}
+
+// Diff returns a new set with the addresses of s which are not in t. Prefixes
+// of s which are partially in t are replaced by the minimal prefixes of their
+// remaining addresses.
+func (s *IPSet) Diff(t *IPSet) *IPSet {
+	r := &IPSet{ranges: ipDiff(s.ranges, t.ranges), prefixes: map[netip.Prefix]struct{}{}}
+	for p := range s.prefixes {
+		for _, i := range ipDiff([]ipRange{prefixRange(p)}, t.ranges) {
+			for _, q := range rangePrefixes(i) {
+				r.prefixes[q] = struct{}{}
+			}
+		}
+	}
+	return r
+}
+
+// ----- methods that return other data types -----
+
+// Prefixes returns the prefixes of the set in ascending order, with shorter
+// prefixes before the longer prefixes they contain.
+func (s *IPSet) Prefixes() []netip.Prefix {
+	l := make([]netip.Prefix, 0, len(s.prefixes))
+	for p := range s.prefixes {
+		l = append(l, p)
+	}
+	slices.SortFunc(l, comparePrefix)
+	return l
+}
+
+// MinimalPrefixes returns the minimal list of prefixes covering the addresses
+// of the set, in ascending order.
+func (s *IPSet) MinimalPrefixes() []netip.Prefix {
+	var l []netip.Prefix
+	for _, r := range s.ranges {
+		l = append(l, rangePrefixes(r)...)
+	}
+	return l
+}
+
+// comparePrefix orders prefixes by address, then by length.
+func comparePrefix(a, b netip.Prefix) int {
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+	return a.Bits() - b.Bits()
+}
+
+// String returns a textual representation of the set in a string, with its
+// minimal prefixes.
+func (s *IPSet) String() string {
+	var b strings.Builder
+	b.WriteString("{ ")
+	for _, p := range s.MinimalPrefixes() {
+		b.WriteString(p.String())
+		b.WriteByte(' ')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// ----- algorithms on address ranges -----
+
+// ipUnion merges two sorted lists of disjoint ranges, coalescing overlapping
+// and adjacent ranges. It works like unionRanges on integers.
+func ipUnion(a, b []ipRange) []ipRange {
+	r := make([]ipRange, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		var n ipRange
+		if len(b) == 0 || len(a) > 0 && a[0].lo.Compare(b[0].lo) <= 0 {
+			n, a = a[0], a[1:]
+		} else {
+			n, b = b[0], b[1:]
+		}
+		if l := len(r) - 1; l >= 0 && (n.lo.Compare(r[l].hi) <= 0 || r[l].hi.Next() == n.lo) {
+			if n.hi.Compare(r[l].hi) > 0 {
+				r[l].hi = n.hi
+			}
+			continue
+		}
+		r = append(r, n)
+	}
+	return r
+}
+
+// ipIntersect intersects two sorted lists of disjoint ranges.
+func ipIntersect(a, b []ipRange) []ipRange {
+	var r []ipRange
+	for len(a) > 0 && len(b) > 0 {
+		lo, hi := a[0].lo, a[0].hi
+		if b[0].lo.Compare(lo) > 0 {
+			lo = b[0].lo
+		}
+		if b[0].hi.Compare(hi) < 0 {
+			hi = b[0].hi
+		}
+		if lo.Compare(hi) <= 0 {
+			r = append(r, ipRange{lo, hi})
+		}
+		if a[0].hi.Compare(b[0].hi) < 0 {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return r
+}
+
+// ipDiff removes the ranges of b from the ranges of a, both sorted lists of
+// disjoint ranges.
+func ipDiff(a, b []ipRange) []ipRange {
+	var r []ipRange
+	for _, i := range a {
+		for len(b) > 0 && b[0].hi.Compare(i.lo) < 0 {
+			b = b[1:]
+		}
+		lo, covered := i.lo, false
+		for k := 0; k < len(b) && b[k].lo.Compare(i.hi) <= 0; k++ {
+			if b[k].lo.Compare(lo) > 0 {
+				r = append(r, ipRange{lo, b[k].lo.Prev()})
+			}
+			if b[k].hi.Compare(i.hi) >= 0 {
+				covered = true
+				break
+			}
+			lo = b[k].hi.Next()
+		}
+		if !covered {
+			r = append(r, ipRange{lo, i.hi})
+		}
+	}
+	return r
+}
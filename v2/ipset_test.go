@@ -0,0 +1,113 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+)
+
+// prefixes parses a list of prefixes.
+func prefixes(p ...string) []netip.Prefix {
+	l := make([]netip.Prefix, len(p))
+	for i, s := range p {
+		l[i] = netip.MustParsePrefix(s)
+	}
+	return l
+}
+
+func TestIPSet(t *testing.T) {
+	s := NewIPSet(prefixes("10.0.0.0/9", "10.128.0.0/9", "10.1.0.0/16", "192.168.1.7/24", "2001:db8::/32")...)
+	if str := s.String(); str != "{ 10.0.0.0/8 192.168.1.0/24 2001:db8::/32 }" {
+		t.Errorf("NewIPSet failed: got %v.\n", str)
+	}
+	if fmt.Sprint(s.Prefixes()) != "[10.0.0.0/9 10.1.0.0/16 10.128.0.0/9 192.168.1.0/24 2001:db8::/32]" {
+		t.Errorf("Prefixes failed: got %v.\n", s.Prefixes())
+	}
+
+	a := netip.MustParseAddr
+	if !s.Contains(a("10.200.1.1"), a("192.168.1.255"), a("2001:db8::1"), a("::ffff:10.0.0.1")) {
+		t.Errorf("Contains failed.\n")
+	}
+	if s.Contains(a("11.0.0.0")) || s.Contains(a("2001:db9::")) || s.Contains(netip.Addr{}) {
+		t.Errorf("Contains failed: foreign address found.\n")
+	}
+	if !s.ContainsPrefix(prefixes("10.64.0.0/10", "10.0.0.0/8")...) || s.ContainsPrefix(prefixes("10.0.0.0/7")...) {
+		t.Errorf("ContainsPrefix failed.\n")
+	}
+
+	// longest prefix match
+	tests := []struct{ addr, want string }{
+		{"10.1.2.3", "10.1.0.0/16"},
+		{"10.2.2.3", "10.0.0.0/9"},
+		{"10.200.0.1", "10.128.0.0/9"},
+		{"2001:db8:1::", "2001:db8::/32"},
+		{"11.0.0.1", "invalid Prefix"},
+	}
+	for _, i := range tests {
+		if p, _ := s.LongestPrefix(a(i.addr)); p.String() != i.want {
+			t.Errorf("LongestPrefix failed for %s: got %v, expected %s.\n", i.addr, p, i.want)
+		}
+	}
+
+	// removing a part of a prefix splits it
+	s.Remove(prefixes("10.1.0.0/16", "192.168.1.128/25")...)
+	if s.Contains(a("10.1.0.1")) || !s.Contains(a("10.2.0.1"), a("192.168.1.1")) {
+		t.Errorf("Remove failed: got %v.\n", s)
+	}
+	if p, _ := s.LongestPrefix(a("10.0.0.1")); p.String() != "10.0.0.0/16" {
+		t.Errorf("LongestPrefix failed after Remove: got %v.\n", p)
+	}
+	if p, _ := s.LongestPrefix(a("192.168.1.1")); p.String() != "192.168.1.0/25" {
+		t.Errorf("LongestPrefix failed after Remove: got %v.\n", p)
+	}
+
+	s.Clear()
+	if !s.IsEmpty() || len(s.Prefixes()) != 0 {
+		t.Errorf("Clear failed: got %v.\n", s)
+	}
+}
+
+func TestIPSetAlgebra(t *testing.T) {
+	a := NewIPSet(prefixes("10.0.0.0/8", "192.168.0.0/16")...)
+	b := NewIPSet(prefixes("10.1.0.0/16", "172.16.0.0/12", "192.168.0.0/16")...)
+
+	tests := []struct {
+		name      string
+		got       *IPSet
+		want      string
+		wantPrefs string
+	}{
+		{"Union", a.Union(b), "{ 10.0.0.0/8 172.16.0.0/12 192.168.0.0/16 }",
+			"[10.0.0.0/8 10.1.0.0/16 172.16.0.0/12 192.168.0.0/16]"},
+		{"Intersect", a.Intersect(b), "{ 10.1.0.0/16 192.168.0.0/16 }",
+			"[10.1.0.0/16 192.168.0.0/16]"},
+		{"Diff", b.Diff(a), "{ 172.16.0.0/12 }", "[172.16.0.0/12]"},
+		{"Diff", a.Diff(NewIPSet(prefixes("10.0.0.0/9")...)), "{ 10.128.0.0/9 192.168.0.0/16 }",
+			"[10.128.0.0/9 192.168.0.0/16]"},
+	}
+	for _, i := range tests {
+		if str := i.got.String(); str != i.want {
+			t.Errorf("%s failed: got %v, expected %v.\n", i.name, str, i.want)
+		}
+		if p := fmt.Sprint(i.got.Prefixes()); p != i.wantPrefs {
+			t.Errorf("%s failed: got prefixes %v, expected %v.\n", i.name, p, i.wantPrefs)
+		}
+	}
+	if !NewIPSet(prefixes("10.1.2.0/24")...).IsSubsetOf(a) || b.IsSubsetOf(a) {
+		t.Errorf("IsSubsetOf failed.\n")
+	}
+	if !a.IsEqual(NewIPSet(prefixes("10.0.0.0/9", "10.128.0.0/9", "192.168.0.0/16")...)) {
+		t.Errorf("IsEqual failed.\n")
+	}
+
+	// an uneven rest of a range, and the end of the address space
+	c := NewIPSet(prefixes("0.0.0.0/0")...).Diff(NewIPSet(prefixes("0.0.0.0/32", "255.255.255.255/32")...))
+	if n := len(c.MinimalPrefixes()); n != 62 || c.Contains(netip.MustParseAddr("255.255.255.255")) {
+		t.Errorf("Diff failed: got %d prefixes.\n", n)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+)
+
+// errNotArray is the error of DecodeJSON for input which is not an array.
+var errNotArray = errors.New("set: JSON input is not an array")
+
+// ----- JSON encoding -----
+
+// WithSortedJSON makes MarshalJSON write the elements of the set in
+// ascending order, so the output is deterministic, like for golden files or
+// for signing. It is only available for ordered element types.
+func WithSortedJSON[T cmp.Ordered]() Option[T] {
+	return func(c *config[T]) {
+		c.order = cmp.Compare[T]
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is encoded as
+// a JSON array of its elements, in an undefined order unless the set was
+// created with WithSortedJSON.
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	l := s.List()
+	if s.cfg != nil && s.cfg.order != nil {
+		slices.SortFunc(l, s.cfg.order)
+	}
+	return json.Marshal(l)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It replaces the
+// elements of the set with those of a JSON array, and initializes the zero
+// value of Set. The elements are added like with TryAdd; the elements
+// rejected by the validator of the set are not added, and returned as error.
+// A JSON null leaves the set unchanged.
+func (s *Set[T]) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var l []T
+	if err := json.Unmarshal(b, &l); err != nil {
+		return err
+	}
+	if s.set == nil {
+		s.set = make(map[T]struct{}, len(l))
+	} else {
+		clear(s.set)
+	}
+	return s.TryAdd(l...)
+}
+
+// DecodeJSON reads a JSON array from r and returns a new set of its
+// elements. Unlike UnmarshalJSON, it decodes the array element by element,
+// so neither the input nor a slice of all elements is buffered, like for a
+// membership file of gigabytes. A JSON null yields an empty set. An element
+// which cannot be decoded is reported as a *DecodeError with its position;
+// the input after the array is not read.
+func DecodeJSON[T comparable](r io.Reader) (Set[T], error) {
+	s := New[T]()
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return Set[T]{}, err
+	}
+	if tok == nil {
+		return s, nil
+	}
+	if tok != json.Delim('[') {
+		return Set[T]{}, errNotArray
+	}
+	for pos := 0; dec.More(); pos++ {
+		var e T
+		if err := dec.Decode(&e); err != nil {
+			return Set[T]{}, &DecodeError{Pos: pos, Err: err}
+		}
+		s.set[e] = struct{}{}
+	}
+	if _, err := dec.Token(); err != nil {
+		return Set[T]{}, err
+	}
+	return s, nil
+}
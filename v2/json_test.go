@@ -0,0 +1,80 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	type model struct {
+		Tags Set[string] `json:"tags"`
+	}
+	in := model{Tags: New("b", "a", "c")}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v.\n", err)
+	}
+	var out model
+	if err := json.Unmarshal(b, &out); err != nil || !out.Tags.IsEqual(in.Tags) {
+		t.Errorf("UnmarshalJSON failed: got %v, %v.\n", out.Tags, err)
+	}
+
+	// decoding replaces the elements
+	if err := json.Unmarshal([]byte(`{"tags":["x"]}`), &out); err != nil || !out.Tags.IsEqual(New("x")) {
+		t.Errorf("UnmarshalJSON failed: got %v, %v.\n", out.Tags, err)
+	}
+	if err := json.Unmarshal([]byte(`{"tags":null}`), &out); err != nil || !out.Tags.IsEqual(New("x")) {
+		t.Errorf("UnmarshalJSON failed for null: got %v, %v.\n", out.Tags, err)
+	}
+	if err := json.Unmarshal([]byte(`{"tags":[1]}`), &out); err == nil {
+		t.Errorf("UnmarshalJSON failed: no error for a wrong element type.\n")
+	}
+
+	s := NewWith(WithSortedJSON[int]())
+	s.Add(3, 1, 2)
+	if b, err := json.Marshal(s); err != nil || string(b) != "[1,2,3]" {
+		t.Errorf("MarshalJSON failed: got %s, %v.\n", b, err)
+	}
+	if b, err := json.Marshal(Set[int]{}); err != nil || string(b) != "[]" {
+		t.Errorf("MarshalJSON failed for the zero set: got %s, %v.\n", b, err)
+	}
+
+	// the validator of the set applies
+	errOdd := errors.New("odd")
+	v := NewWith(WithValidator(func(i int) error {
+		if i%2 != 0 {
+			return errOdd
+		}
+		return nil
+	}))
+	if err := json.Unmarshal([]byte("[1,2,4]"), &v); !errors.Is(err, errOdd) || !v.IsEqual(New(2, 4)) {
+		t.Errorf("UnmarshalJSON failed: got %v, %v.\n", v, err)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	s, err := DecodeJSON[int](strings.NewReader(" [3, 1, 2, 3] "))
+	if err != nil || !s.IsEqual(New(1, 2, 3)) {
+		t.Errorf("DecodeJSON failed: got %v, %v.\n", s, err)
+	}
+	if s, err := DecodeJSON[string](strings.NewReader("null")); err != nil || !s.IsEmpty() {
+		t.Errorf("DecodeJSON failed for null: got %v, %v.\n", s, err)
+	}
+
+	var de *DecodeError
+	if _, err := DecodeJSON[int](strings.NewReader(`[1, "x", 3]`)); !errors.As(err, &de) || de.Pos != 1 {
+		t.Errorf("DecodeJSON failed: got %v for a wrong element.\n", err)
+	}
+	for _, in := range []string{`{"a":1}`, `[1, 2`, ``} {
+		if _, err := DecodeJSON[int](strings.NewReader(in)); err == nil {
+			t.Errorf("DecodeJSON failed: no error for %q.\n", in)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLeaseHeld is returned when an element is already held by a live lease.
+	ErrLeaseHeld = errors.New("set: element is held by another lease")
+
+	// ErrLeaseExpired is returned when a lease was revoked or has lapsed.
+	ErrLeaseExpired = errors.New("set: lease expired")
+)
+
+// ----- LeaseSet definition -----
+
+// A LeaseSet is a set where membership is bound to a lease, like the
+// registration of a worker or the ownership of a lock. The holder of a lease
+// must renew it periodically with KeepAlive, otherwise the element leaves the
+// set when the lease lapses. All methods are safe for concurrent use.
+type LeaseSet[T comparable] struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	leases map[T]*Lease[T]
+}
+
+// A Lease is the handle for the membership of an element in a LeaseSet.
+type Lease[T comparable] struct {
+	set     *LeaseSet[T]
+	elem    T
+	ttl     time.Duration
+	expires time.Time // protected by the lock of the set
+}
+
+// ----- constructor -----
+
+// NewLeaseSet creates a new, empty lease set.
+func NewLeaseSet[T comparable]() *LeaseSet[T] {
+	return &LeaseSet[T]{now: time.Now, leases: map[T]*Lease[T]{}}
+}
+
+// live returns the live lease of an element and removes a lapsed one. The
+// caller must hold the lock.
+func (s *LeaseSet[T]) live(e T) *Lease[T] {
+	l, ok := s.leases[e]
+	if !ok {
+		return nil
+	}
+	if !s.now().Before(l.expires) {
+		delete(s.leases, e)
+		return nil
+	}
+	return l
+}
+
+// ----- methods of the set -----
+
+// AddWithLease adds an element to the set for the time to live ttl, and
+// returns the lease handle. If the element is already held by a live lease,
+// ErrLeaseHeld is returned.
+func (s *LeaseSet[T]) AddWithLease(e T, ttl time.Duration) (*Lease[T], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.live(e) != nil {
+		return nil, ErrLeaseHeld
+	}
+	l := &Lease[T]{set: s, elem: e, ttl: ttl, expires: s.now().Add(ttl)}
+	s.leases[e] = l
+	return l, nil
+}
+
+// Contains checks if a set contains one or more elements with live leases.
+// The return value is true only if all given elements are in the set.
+func (s *LeaseSet[T]) Contains(e ...T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, i := range e {
+		if s.live(i) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Members returns a snapshot of all elements with live leases.
+func (s *LeaseSet[T]) Members() Set[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := New[T]()
+	for k := range s.leases {
+		if s.live(k) != nil {
+			r.set[k] = struct{}{}
+		}
+	}
+	return r
+}
+
+// Len returns the number of elements with live leases.
+func (s *LeaseSet[T]) Len() int {
+	return s.Members().Len()
+}
+
+// ----- methods of leases -----
+
+// Elem returns the element of the lease.
+func (l *Lease[T]) Elem() T {
+	return l.elem
+}
+
+// KeepAlive renews the lease for another time to live. If the lease was
+// revoked or has lapsed, ErrLeaseExpired is returned and the element must be
+// added again.
+func (l *Lease[T]) KeepAlive() error {
+	s := l.set
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.live(l.elem) != l {
+		return ErrLeaseExpired
+	}
+	l.expires = s.now().Add(l.ttl)
+	return nil
+}
+
+// Revoke ends the lease and removes its element from the set.
+func (l *Lease[T]) Revoke() {
+	s := l.set
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.leases[l.elem] == l {
+		delete(s.leases, l.elem)
+	}
+}
+
+// Expires returns the time when the lease lapses, unless it is renewed.
+func (l *Lease[T]) Expires() time.Time {
+	l.set.mu.Lock()
+	defer l.set.mu.Unlock()
+	return l.expires
+}
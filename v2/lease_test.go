@@ -0,0 +1,55 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLeaseSet(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewLeaseSet[string]()
+	s.now = func() time.Time { return now }
+
+	w1, err := s.AddWithLease("worker1", 10*time.Second)
+	if err != nil || !s.Contains("worker1") {
+		t.Fatalf("AddWithLease failed: %v.\n", err)
+	}
+	if _, err := s.AddWithLease("worker1", time.Second); !errors.Is(err, ErrLeaseHeld) {
+		t.Errorf("AddWithLease failed: expected ErrLeaseHeld, got %v.\n", err)
+	}
+	w2, _ := s.AddWithLease("worker2", 10*time.Second)
+
+	now = now.Add(8 * time.Second)
+	if err := w1.KeepAlive(); err != nil || !w1.Expires().Equal(now.Add(10*time.Second)) {
+		t.Errorf("KeepAlive failed: %v.\n", err)
+	}
+
+	now = now.Add(5 * time.Second)
+	if !s.Contains("worker1") || s.Contains("worker2") || s.Len() != 1 {
+		t.Errorf("Lease expiry failed: members are %v.\n", s.Members())
+	}
+	if err := w2.KeepAlive(); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("KeepAlive failed: expected ErrLeaseExpired, got %v.\n", err)
+	}
+
+	// a new lease for the lapsed element is not affected by the old handle
+	w2b, err := s.AddWithLease("worker2", time.Minute)
+	if err != nil {
+		t.Errorf("AddWithLease failed after expiry: %v.\n", err)
+	}
+	w2.Revoke()
+	if !s.Contains("worker2") || w2b.Elem() != "worker2" {
+		t.Errorf("Revoke failed: old lease revoked the new one.\n")
+	}
+
+	w1.Revoke()
+	if s.Contains("worker1") || !s.Members().IsEqual(New("worker2")) {
+		t.Errorf("Revoke failed: members are %v.\n", s.Members())
+	}
+}
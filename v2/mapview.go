@@ -0,0 +1,60 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"maps"
+)
+
+// ----- map views -----
+
+// AsMap returns the map which backs the set, for APIs which demand a raw
+// map. It is a live view, not a copy: changes of the set are visible in the
+// map and vice versa. Elements added directly to the map bypass the
+// canonicalizer, validator and universe of the set. Use StructMap for a copy,
+// or View for a read-only view.
+func (s Set[T]) AsMap() map[T]struct{} {
+	return s.set
+}
+
+// A MapView is a read-only, live view of the map which backs a set. It
+// reflects later changes of the set, but cannot modify it.
+type MapView[T comparable] struct {
+	m map[T]struct{}
+}
+
+// View returns a read-only, live view of the map which backs the set.
+func (s Set[T]) View() MapView[T] {
+	return MapView[T]{m: s.set}
+}
+
+// Lookup returns the value stored for the key and whether it is present,
+// like the two-value form of a map index expression.
+func (v MapView[T]) Lookup(k T) (struct{}, bool) {
+	_, ok := v.m[k]
+	return struct{}{}, ok
+}
+
+// Len returns the number of entries of the map.
+func (v MapView[T]) Len() int {
+	return len(v.m)
+}
+
+// All returns an iterator over the key-value pairs of the map, like maps.All.
+func (v MapView[T]) All() iter.Seq2[T, struct{}] {
+	return maps.All(v.m)
+}
+
+// Keys returns an iterator over the keys of the map, like maps.Keys.
+func (v MapView[T]) Keys() iter.Seq[T] {
+	return maps.Keys(v.m)
+}
+
+// Clone returns a copy of the map, like maps.Clone.
+func (v MapView[T]) Clone() map[T]struct{} {
+	return maps.Clone(v.m)
+}
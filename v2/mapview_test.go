@@ -0,0 +1,46 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestAsMap(t *testing.T) {
+	s := New(1, 2)
+	m := s.AsMap()
+	m[3] = struct{}{}
+	if !s.Contains(3) {
+		t.Errorf("AsMap failed: map is not a live view.\n")
+	}
+	s.Remove(1)
+	if _, ok := m[1]; ok {
+		t.Errorf("AsMap failed: removal not visible in the map.\n")
+	}
+}
+
+func TestMapView(t *testing.T) {
+	s := New("a", "b")
+	v := s.View()
+	s.Add("c")
+	if _, ok := v.Lookup("c"); !ok || v.Len() != 3 {
+		t.Errorf("View failed: addition not visible in the view.\n")
+	}
+	if n := len(maps.Collect(v.All())); n != 3 {
+		t.Errorf("All failed: got %d entries.\n", n)
+	}
+	c := v.Clone()
+	delete(c, "a")
+	if !s.Contains("a") || len(maps.Collect(maps.All(c))) != 2 {
+		t.Errorf("Clone failed: the copy is shared with the set.\n")
+	}
+	for k := range v.Keys() {
+		if !s.Contains(k) {
+			t.Errorf("Keys failed: unexpected key %v.\n", k)
+		}
+	}
+}
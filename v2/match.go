@@ -0,0 +1,94 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ----- filters on string sets -----
+
+// MatchGlob returns a new set of all elements of s which match the shell
+// pattern, using the syntax of path.Match. The only possible returned error
+// is path.ErrBadPattern. The set s is not modified.
+func MatchGlob(s Set[string], pattern string) (Set[string], error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return New[string](), err
+	}
+	r := New[string]()
+	for k := range s.set {
+		if ok, _ := path.Match(pattern, k); ok {
+			r.set[k] = struct{}{}
+		}
+	}
+	return r, nil
+}
+
+// MatchRegexp returns a new set of all elements of s which match the regular
+// expression. The set s is not modified.
+func MatchRegexp(s Set[string], re *regexp.Regexp) Set[string] {
+	r := New[string]()
+	for k := range s.set {
+		if re.MatchString(k) {
+			r.set[k] = struct{}{}
+		}
+	}
+	return r
+}
+
+// ----- filters on path sets -----
+
+// MatchGlob returns a new set of all paths which match the shell pattern,
+// using the syntax of path.Match. The pattern is matched segment by segment,
+// so only subtrees of the trie matching the pattern are visited. The only
+// possible returned error is path.ErrBadPattern.
+func (s PathSet) MatchGlob(pattern string) (Set[string], error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return New[string](), err
+	}
+	r := New[string]()
+	var walk func(n *pathNode, pat, seg []string)
+	walk = func(n *pathNode, pat, seg []string) {
+		if len(pat) == 0 {
+			if n.member {
+				r.set[strings.Join(seg, "/")] = struct{}{}
+			}
+			return
+		}
+		for k, c := range n.children {
+			if ok, _ := path.Match(pat[0], k); ok {
+				walk(c, pat[1:], append(seg, k))
+			}
+		}
+	}
+	walk(s.root, splitPath(pattern), nil)
+	return r, nil
+}
+
+// MatchRegexp returns a new set of all paths which match the regular
+// expression. If the expression has a literal prefix, only the matching part
+// of the trie is visited.
+func (s PathSet) MatchRegexp(re *regexp.Regexp) Set[string] {
+	r := New[string]()
+	prefix, _ := re.LiteralPrefix()
+	if !strings.HasPrefix(re.String(), "^") {
+		prefix = ""
+	}
+	// descend along the complete segments of the literal prefix
+	seg := splitPath(prefix[:strings.LastIndexByte(prefix, '/')+1])
+	n := s.find(seg)
+	if n == nil {
+		return r
+	}
+	for k := range walkPaths(n, seg) {
+		if re.MatchString(k) {
+			r.set[k] = struct{}{}
+		}
+	}
+	return r
+}
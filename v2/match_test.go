@@ -0,0 +1,49 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	s := New("app-web", "app-db", "db-main", "web")
+
+	g, err := MatchGlob(s, "app-*")
+	if err != nil || !g.IsEqual(New("app-web", "app-db")) {
+		t.Errorf("MatchGlob failed: got %v/%v.\n", g, err)
+	}
+	if _, err := MatchGlob(s, "[app"); err == nil {
+		t.Errorf("MatchGlob failed: expected an error for a bad pattern.\n")
+	}
+
+	r := MatchRegexp(s, regexp.MustCompile("db"))
+	if !r.IsEqual(New("app-db", "db-main")) {
+		t.Errorf("MatchRegexp failed: got %v.\n", r)
+	}
+}
+
+func TestPathSetMatch(t *testing.T) {
+	p := NewPathSet("srv/app-web/log", "srv/app-db/log", "srv/app-db/data", "etc/app-web/log")
+
+	g, err := p.MatchGlob("srv/app-*/log")
+	if err != nil || !g.IsEqual(New("srv/app-web/log", "srv/app-db/log")) {
+		t.Errorf("PathSet.MatchGlob failed: got %v/%v.\n", g, err)
+	}
+	if _, err := p.MatchGlob("srv/[/log"); err == nil {
+		t.Errorf("PathSet.MatchGlob failed: expected an error for a bad pattern.\n")
+	}
+
+	r := p.MatchRegexp(regexp.MustCompile("^srv/app-db/.*"))
+	if !r.IsEqual(New("srv/app-db/log", "srv/app-db/data")) {
+		t.Errorf("PathSet.MatchRegexp failed: got %v.\n", r)
+	}
+	r = p.MatchRegexp(regexp.MustCompile("web/log$"))
+	if !r.IsEqual(New("srv/app-web/log", "etc/app-web/log")) {
+		t.Errorf("PathSet.MatchRegexp failed: got %v.\n", r)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ----- misuse policy -----
+
+// A MisusePolicy controls how a set reacts to misuse, like adding elements
+// to an uninitialized set (the zero value of Set).
+type MisusePolicy int32
+
+const (
+	// PanicOnMisuse panics with a *MisuseError. This is the default.
+	PanicOnMisuse MisusePolicy = iota
+
+	// RecordMisuse turns the offending operation into a no-op. The
+	// *MisuseError is returned by the methods which return errors, like
+	// TryAdd, and is recorded for the Err method of configured sets.
+	RecordMisuse
+)
+
+// the package wide default policy
+var defaultPolicy atomic.Int32
+
+// SetMisusePolicy sets the package wide default policy, which applies to all
+// sets without a policy of their own.
+func SetMisusePolicy(p MisusePolicy) {
+	defaultPolicy.Store(int32(p))
+}
+
+// WithMisusePolicy sets the misuse policy of a set, which overrides the
+// package wide default.
+func WithMisusePolicy[T comparable](p MisusePolicy) Option[T] {
+	return func(c *config[T]) {
+		c.policy = &p
+	}
+}
+
+// A MisuseError describes a misuse of a set.
+type MisuseError struct {
+	Op     string // the method which was misused
+	Reason string
+}
+
+func (e *MisuseError) Error() string {
+	return fmt.Sprintf("set: misuse of %s: %s", e.Op, e.Reason)
+}
+
+// errUninitialized is the reason for operations on the zero value of Set.
+const errUninitialized = "set is not initialized, use New"
+
+// policy returns the effective misuse policy of the set.
+func (s Set[T]) policy() MisusePolicy {
+	if s.cfg != nil && s.cfg.policy != nil {
+		return *s.cfg.policy
+	}
+	return MisusePolicy(defaultPolicy.Load())
+}
+
+// misuse reports a misuse of the set according to its policy. It panics or
+// returns the error.
+func (s Set[T]) misuse(op, reason string) error {
+	err := &MisuseError{Op: op, Reason: reason}
+	if s.policy() == PanicOnMisuse {
+		panic(err)
+	}
+	if s.cfg != nil {
+		s.cfg.err.CompareAndSwap(nil, err)
+	}
+	return err
+}
+
+// Err returns the first misuse error recorded for the set or any set sharing
+// its configuration under the RecordMisuse policy. For an uninitialized set,
+// it always returns a *MisuseError.
+func (s Set[T]) Err() error {
+	if s.set == nil {
+		return &MisuseError{Op: "Err", Reason: errUninitialized}
+	}
+	if s.cfg != nil {
+		if err := s.cfg.err.Load(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestMisusePanic(t *testing.T) {
+	defer func() {
+		r := recover()
+		if err, ok := r.(*MisuseError); !ok || err.Op != "Add" {
+			t.Errorf("Add failed: expected a MisuseError panic, got %v.\n", r)
+		}
+	}()
+	var s Set[int]
+	s.Add(1)
+}
+
+func TestMisuseRecord(t *testing.T) {
+	SetMisusePolicy(RecordMisuse)
+	defer SetMisusePolicy(PanicOnMisuse)
+
+	var s Set[int]
+	s.Add(1)
+	var me *MisuseError
+	if err := s.TryAdd(1); !errors.As(err, &me) || me.Op != "TryAdd" {
+		t.Errorf("TryAdd failed: expected a MisuseError, got %v.\n", err)
+	}
+	if err := s.AddStrict(1); err == nil {
+		t.Errorf("AddStrict failed: expected a MisuseError.\n")
+	}
+	if s.Err() == nil || s.Contains(1) || s.Len() != 0 {
+		t.Errorf("Err failed: uninitialized set reports no error.\n")
+	}
+	if New[int]().Err() != nil {
+		t.Errorf("Err failed: initialized set reports an error.\n")
+	}
+}
+
+func TestMisusePerSet(t *testing.T) {
+	// the per-set policy overrides the default
+	s := NewWith(WithMisusePolicy[int](RecordMisuse))
+	s.set = nil
+	s.Add(1)
+	if s.Err() == nil {
+		t.Errorf("Err failed: misuse not reported.\n")
+	}
+
+	c := NewWith(WithMisusePolicy[int](RecordMisuse))
+	uninit := Set[int]{cfg: c.cfg}
+	uninit.Add(1)
+	if err := c.Err(); err == nil {
+		t.Errorf("Err failed: misuse not recorded in shared configuration.\n")
+	}
+}
+
+func TestMisuseConcurrent(t *testing.T) {
+	s := NewWith(WithMisusePolicy[int](RecordMisuse))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.misuse("Test", "concurrent")
+				s.Copy().Err()
+			}
+		}()
+	}
+	wg.Wait()
+	var me *MisuseError
+	if !errors.As(s.Err(), &me) || me.Op != "Test" {
+		t.Errorf("Err failed: got %v.\n", s.Err())
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"cmp"
+	"container/heap"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// ----- Multiset definition -----
+
+// A Multiset, or bag, is a collection of elements where each element can
+// occur several times. It stores the multiplicity of each element, and
+// provides the set operations with the standard multiset semantics: the union
+// takes the maximum of the multiplicities, the intersection their minimum,
+// and the difference subtracts them down to zero. Sum adds them.
+type Multiset[T comparable] struct {
+	m   map[T]int // the multiplicities, all positive
+	len int       // the sum of the multiplicities
+}
+
+// A Counted is an element of a multiset with its multiplicity.
+type Counted[T comparable] struct {
+	Elem  T
+	Count int
+}
+
+// ----- constructor -----
+
+// NewMultiset creates a new multiset and initializes it with the argument
+// values, each occurrence counting once.
+func NewMultiset[T comparable](e ...T) *Multiset[T] {
+	s := &Multiset[T]{m: make(map[T]int, len(e))}
+	for _, x := range e {
+		s.Add(x, 1)
+	}
+	return s
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds n occurrences of an element to the given multiset. Non-positive
+// n are ignored.
+func (s *Multiset[T]) Add(e T, n int) {
+	if n > 0 {
+		s.m[e] += n
+		s.len += n
+	}
+}
+
+// Remove removes up to n occurrences of an element from the given multiset.
+// Non-positive n are ignored.
+func (s *Multiset[T]) Remove(e T, n int) {
+	if n > 0 {
+		s.SetCount(e, s.m[e]-n)
+	}
+}
+
+// RemoveAll removes all occurrences of one or more elements from the given
+// multiset.
+func (s *Multiset[T]) RemoveAll(e ...T) {
+	for _, x := range e {
+		s.SetCount(x, 0)
+	}
+}
+
+// SetCount sets the multiplicity of an element. Non-positive n remove the
+// element.
+func (s *Multiset[T]) SetCount(e T, n int) {
+	s.len -= s.m[e]
+	if n <= 0 {
+		delete(s.m, e)
+		return
+	}
+	s.m[e] = n
+	s.len += n
+}
+
+// Clear removes all elements from the given multiset.
+func (s *Multiset[T]) Clear() {
+	clear(s.m)
+	s.len = 0
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Count returns the multiplicity of an element, 0 if it is not in the
+// multiset.
+func (s *Multiset[T]) Count(e T) int {
+	return s.m[e]
+}
+
+// IsEmpty tests if the multiset is empty.
+func (s *Multiset[T]) IsEmpty() bool {
+	return len(s.m) == 0
+}
+
+// Len returns the number of elements, counting each occurrence.
+func (s *Multiset[T]) Len() int {
+	return s.len
+}
+
+// Distinct returns the number of distinct elements.
+func (s *Multiset[T]) Distinct() int {
+	return len(s.m)
+}
+
+// Contains checks if a multiset contains one or more elements. The return
+// value is true only if all given elements occur at least once.
+func (s *Multiset[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if _, ok := s.m[x]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEqual tests if two multisets contain the same elements with the same
+// multiplicities.
+func (s *Multiset[T]) IsEqual(t *Multiset[T]) bool {
+	return s.len == t.len && maps.Equal(s.m, t.m)
+}
+
+// IsSubsetOf returns true if each element of s occurs in t at least as often
+// as in s.
+func (s *Multiset[T]) IsSubsetOf(t *Multiset[T]) bool {
+	if s.len > t.len {
+		return false
+	}
+	for k, n := range s.m {
+		if t.m[k] < n {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy returns a copy of a multiset. The multiset s is not modified.
+func (s *Multiset[T]) Copy() *Multiset[T] {
+	return &Multiset[T]{m: maps.Clone(s.m), len: s.len}
+}
+
+// ----- methods that return a new multiset -----
+
+// Union returns a new multiset with the elements of s and all of the argument
+// multisets, each with its maximum multiplicity.
+func (s *Multiset[T]) Union(t ...*Multiset[T]) *Multiset[T] {
+	r := s.Copy()
+	for _, u := range t {
+		for k, n := range u.m {
+			if n > r.m[k] {
+				r.SetCount(k, n)
+			}
+		}
+	}
+	return r
+}
+
+// Sum returns a new multiset with the elements of s and all of the argument
+// multisets, with the multiplicities added.
+func (s *Multiset[T]) Sum(t ...*Multiset[T]) *Multiset[T] {
+	r := s.Copy()
+	for _, u := range t {
+		for k, n := range u.m {
+			r.Add(k, n)
+		}
+	}
+	return r
+}
+
+// Intersect returns a new multiset with the elements which are in s and in
+// all of the argument multisets, each with its minimum multiplicity.
+func (s *Multiset[T]) Intersect(t ...*Multiset[T]) *Multiset[T] {
+	r := s.Copy()
+	for _, u := range t {
+		for k, n := range r.m {
+			if m := u.m[k]; m < n {
+				r.SetCount(k, m)
+			}
+		}
+	}
+	return r
+}
+
+// Diff returns a new multiset where the occurrences of the elements of t are
+// removed from s. Multiplicities do not drop below zero.
+func (s *Multiset[T]) Diff(t *Multiset[T]) *Multiset[T] {
+	r := s.Copy()
+	for k, n := range t.m {
+		r.Remove(k, n)
+	}
+	return r
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all distinct elements and their multiplicities,
+// in no particular order.
+func (s *Multiset[T]) All() iter.Seq2[T, int] {
+	return maps.All(s.m)
+}
+
+// TopN returns up to k elements with the highest multiplicities, in
+// descending order of multiplicity, like the most_common method of a Python
+// Counter. The order of elements with equal multiplicities is unspecified.
+// For k <= 0, the result is empty. It takes O(n log k) time for n distinct
+// elements.
+func (s *Multiset[T]) TopN(k int) []Counted[T] {
+	if k <= 0 {
+		return []Counted[T]{}
+	}
+	if k >= len(s.m) {
+		return s.MostCommon()
+	}
+	// a min-heap of the k highest multiplicities seen so far
+	h := make(countHeap[T], 0, k)
+	for e, n := range s.m {
+		if len(h) < k {
+			heap.Push(&h, Counted[T]{e, n})
+		} else if n > h[0].Count {
+			h[0] = Counted[T]{e, n}
+			heap.Fix(&h, 0)
+		}
+	}
+	r := make([]Counted[T], len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		r[i] = heap.Pop(&h).(Counted[T])
+	}
+	return r
+}
+
+// MostCommon returns all distinct elements with their multiplicities, in
+// descending order of multiplicity.
+func (s *Multiset[T]) MostCommon() []Counted[T] {
+	r := make([]Counted[T], 0, len(s.m))
+	for e, n := range s.m {
+		r = append(r, Counted[T]{e, n})
+	}
+	slices.SortFunc(r, func(a, b Counted[T]) int { return cmp.Compare(b.Count, a.Count) })
+	return r
+}
+
+// countHeap is a min-heap of counted elements for TopN.
+type countHeap[T comparable] []Counted[T]
+
+func (h countHeap[T]) Len() int           { return len(h) }
+func (h countHeap[T]) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h countHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *countHeap[T]) Push(x any)        { *h = append(*h, x.(Counted[T])) }
+func (h *countHeap[T]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Map returns the elements and their multiplicities in a new map.
+func (s *Multiset[T]) Map() map[T]int {
+	return maps.Clone(s.m)
+}
+
+// Set returns the distinct elements as a new set.
+func (s *Multiset[T]) Set() Set[T] {
+	r := Set[T]{set: make(map[T]struct{}, len(s.m))}
+	for k := range s.m {
+		r.set[k] = struct{}{}
+	}
+	return r
+}
+
+// String returns a textual representation of the multiset in a string, with
+// the multiplicity after each element, like { a:2 b:1 }.
+func (s *Multiset[T]) String() string {
+	b := make([]byte, 0, 2+10*len(s.m))
+	b = append(b, "{ "...)
+	for k, n := range s.m {
+		b = fmt.Appendf(b, "%v:%d ", k, n)
+	}
+	b = append(b, '}')
+	return string(b)
+}
@@ -0,0 +1,99 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestMultiset(t *testing.T) {
+	s := NewMultiset("a", "b", "a", "c", "a")
+	if s.Count("a") != 3 || s.Count("b") != 1 || s.Count("x") != 0 || s.Len() != 5 || s.Distinct() != 3 {
+		t.Errorf("NewMultiset failed: got %v.\n", s)
+	}
+	s.Add("b", 2)
+	s.Add("x", 0)
+	s.Remove("a", 1)
+	s.Remove("c", 5)
+	if !maps.Equal(s.Map(), map[string]int{"a": 2, "b": 3}) || s.Len() != 5 || s.Contains("c", "x") {
+		t.Errorf("Add/Remove failed: got %v.\n", s)
+	}
+	s.SetCount("d", 4)
+	s.RemoveAll("b")
+	if !maps.Equal(s.Map(), map[string]int{"a": 2, "d": 4}) || s.Len() != 6 {
+		t.Errorf("SetCount/RemoveAll failed: got %v.\n", s)
+	}
+	if !s.Set().IsEqual(New("a", "d")) {
+		t.Errorf("Set failed: got %v.\n", s.Set())
+	}
+
+	c := s.Copy()
+	s.Clear()
+	if !s.IsEmpty() || s.Len() != 0 || c.Count("d") != 4 {
+		t.Errorf("Clear failed: got %v, copy %v.\n", s, c)
+	}
+}
+
+func TestMultisetAlgebra(t *testing.T) {
+	a := NewMultiset(1, 1, 2, 3, 3, 3)
+	b := NewMultiset(1, 2, 2, 4)
+	tests := []struct {
+		name string
+		got  *Multiset[int]
+		want map[int]int
+	}{
+		{"Union", a.Union(b), map[int]int{1: 2, 2: 2, 3: 3, 4: 1}},
+		{"Sum", a.Sum(b), map[int]int{1: 3, 2: 3, 3: 3, 4: 1}},
+		{"Intersect", a.Intersect(b), map[int]int{1: 1, 2: 1}},
+		{"Diff", a.Diff(b), map[int]int{1: 1, 3: 3}},
+		{"Diff", b.Diff(a), map[int]int{2: 1, 4: 1}},
+	}
+	for _, tt := range tests {
+		n := 0
+		for _, c := range tt.want {
+			n += c
+		}
+		if !maps.Equal(tt.got.Map(), tt.want) || tt.got.Len() != n {
+			t.Errorf("%s failed: got %v, expected %v.\n", tt.name, tt.got, tt.want)
+		}
+	}
+	if !maps.Equal(a.Map(), map[int]int{1: 2, 2: 1, 3: 3}) {
+		t.Errorf("set operations modified their receiver: got %v.\n", a)
+	}
+
+	if !a.Intersect(b).IsSubsetOf(a) || a.IsSubsetOf(a.Intersect(b)) || !NewMultiset(1).IsSubsetOf(a) ||
+		NewMultiset(2, 2).IsSubsetOf(a) {
+		t.Errorf("IsSubsetOf failed.\n")
+	}
+	if !a.IsEqual(NewMultiset(3, 2, 3, 1, 3, 1)) || a.IsEqual(a.Union(b)) {
+		t.Errorf("IsEqual failed.\n")
+	}
+	if s := NewMultiset("x", "x").String(); s != "{ x:2 }" {
+		t.Errorf("String failed: got %s.\n", s)
+	}
+}
+
+func TestMultisetTopN(t *testing.T) {
+	s := NewMultiset[string]()
+	for i, w := range []string{"a", "b", "c", "d", "e", "f"} {
+		s.Add(w, 10*(i+1))
+	}
+	want := []Counted[string]{{"f", 60}, {"e", 50}, {"d", 40}}
+	if got := s.TopN(3); !slices.Equal(got, want) {
+		t.Errorf("TopN failed: got %v, expected %v.\n", got, want)
+	}
+	if got := s.MostCommon(); len(got) != 6 || got[0] != (Counted[string]{"f", 60}) || got[5] != (Counted[string]{"a", 10}) {
+		t.Errorf("MostCommon failed: got %v.\n", got)
+	}
+	if got := s.TopN(10); len(got) != 6 || got[0].Elem != "f" {
+		t.Errorf("TopN failed for k > Distinct: got %v.\n", got)
+	}
+	if len(s.TopN(0)) != 0 || len(s.TopN(-1)) != 0 || len(NewMultiset[int]().TopN(2)) != 0 {
+		t.Errorf("TopN failed for k <= 0 or an empty multiset.\n")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ----- per-set configuration -----
+
+// config holds the optional configuration of a set. It is shared by all sets
+// derived from the configured set, like copies, unions or intersections.
+type config[T comparable] struct {
+	validate  func(T) error
+	canon     func(T) T
+	order     func(a, b T) int            // the order of MarshalJSON, nil for none
+	separator string                      // the separator of MarshalText, "" for the default
+	universe  *Universe[T]                // nil for sets without a universe
+	shrink    float64                     // the shrink fraction, 0 for never
+	policy    *MisusePolicy               // nil for the package wide default
+	err       atomic.Pointer[MisuseError] // the first recorded misuse
+}
+
+// An Option configures a set created by NewWith.
+type Option[T comparable] func(*config[T])
+
+// NewWith creates a new, empty set with the given options. WithShrink is
+// rejected as a misuse, since a plain set cannot rebuild its map; use
+// NewSyncWith instead.
+func NewWith[T comparable](opts ...Option[T]) Set[T] {
+	s := newWith(opts)
+	if s.cfg.shrink != 0 {
+		s.cfg.shrink = 0
+		s.misuse("NewWith", errShrinkUnowned)
+	}
+	return s
+}
+
+// newWith creates a new, empty set with the given options, including
+// WithShrink.
+func newWith[T comparable](opts []Option[T]) Set[T] {
+	cfg := &config[T]{}
+	for _, o := range opts {
+		o(cfg)
+	}
+	return Set[T]{set: map[T]struct{}{}, cfg: cfg}
+}
+
+// WithValidator sets a function which validates elements when they are
+// added to the set. Elements for which it returns an error are rejected.
+func WithValidator[T comparable](f func(T) error) Option[T] {
+	return func(c *config[T]) {
+		c.validate = f
+	}
+}
+
+// WithCanonicalizer sets a function which maps elements to their canonical
+// form, like lower casing or trimming strings. It is applied to the arguments
+// of Add, TryAdd, Remove, Contains and ContainsAny, before validation, and to
+// the elements of the other sets in Union, Intersect, Diff and SymDiff.
+func WithCanonicalizer[T comparable](f func(T) T) Option[T] {
+	return func(c *config[T]) {
+		c.canon = f
+	}
+}
+
+// canon returns the canonical form of an element.
+func (s Set[T]) canon(e T) T {
+	if s.cfg != nil && s.cfg.canon != nil {
+		return s.cfg.canon(e)
+	}
+	return e
+}
+
+// canonical returns t with its elements in the canonical form of s, for the
+// operations combining s with sets of another configuration. It is t itself
+// if s has no canonicalizer, or if t shares the configuration of s.
+func (s Set[T]) canonical(t Set[T]) Set[T] {
+	if s.cfg == nil || s.cfg.canon == nil || t.cfg == s.cfg {
+		return t
+	}
+	r := Set[T]{set: make(map[T]struct{}, len(t.set))}
+	for k := range t.set {
+		r.set[s.cfg.canon(k)] = struct{}{}
+	}
+	return r
+}
+
+// ----- validation -----
+
+// A ValidationError is returned when an element is rejected by the validator
+// of a set.
+type ValidationError[T comparable] struct {
+	Elem T     // the rejected element
+	Err  error // the error returned by the validator
+}
+
+func (e *ValidationError[T]) Error() string {
+	return fmt.Sprintf("set: invalid element %v: %v", e.Elem, e.Err)
+}
+
+func (e *ValidationError[T]) Unwrap() error {
+	return e.Err
+}
+
+// TryAdd adds one or more elements to the given set, like Add. The elements
+// rejected by the validator of the set, or foreign to its universe, are not
+// added; the returned error joins a *ValidationError for each of them.
+func (s Set[T]) TryAdd(e ...T) error {
+	if s.set == nil {
+		return s.misuse("TryAdd", errUninitialized)
+	}
+	var errs []error
+	for _, i := range e {
+		i, err := s.admit(i)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s.set[i] = struct{}{}
+	}
+	return errors.Join(errs...)
+}
+
+// admit returns the canonical form of an element to be added, or a
+// *ValidationError if it is foreign to the universe of the set or rejected
+// by its validator. It is the insert path of all configuration aware
+// methods.
+func (s Set[T]) admit(i T) (T, error) {
+	i = s.canon(i)
+	return i, s.check(i)
+}
+
+// check returns a *ValidationError if the canonical element i is foreign to
+// the universe of the set or rejected by its validator.
+func (s Set[T]) check(i T) error {
+	if u := s.universe(); u != nil && !u.elems.Contains(i) {
+		return &ValidationError[T]{Elem: i, Err: ErrForeignElement}
+	}
+	if s.cfg != nil && s.cfg.validate != nil {
+		if err := s.cfg.validate(i); err != nil {
+			return &ValidationError[T]{Elem: i, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errNoID = errors.New("not an identifier")
+
+func validID(s string) error {
+	if s == "" || strings.ContainsAny(s, " /") {
+		return errNoID
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	s := NewWith(WithValidator(validID))
+
+	s.Add("alice", "bad id")
+	if !s.Contains("alice") || s.Contains("bad id") {
+		t.Errorf("Add failed: got %v.\n", s)
+	}
+
+	err := s.TryAdd("bob", "", "a/b")
+	if err == nil || !errors.Is(err, errNoID) || !s.Contains("bob") || s.Len() != 2 {
+		t.Errorf("TryAdd failed: got %v/%v.\n", s, err)
+	}
+	var ve *ValidationError[string]
+	if !errors.As(err, &ve) || ve.Elem != "" {
+		t.Errorf("TryAdd failed: expected a ValidationError, got %v.\n", err)
+	}
+	if n := strings.Count(err.Error(), "invalid element"); n != 2 {
+		t.Errorf("TryAdd failed: expected 2 collected errors, got %d.\n", n)
+	}
+
+	// derived sets keep the validator
+	u := s.Union(New("x y"))
+	u.Add("z w")
+	if u.Contains("z w") || u.Contains("x y") || !u.Contains("alice", "bob") {
+		t.Errorf("Union failed: derived set did not keep the validator: %v.\n", u)
+	}
+	// elements of the other sets are validated
+	if d := s.SymDiff(New("x y", "carol", "bob")); !d.IsEqual(New("alice", "carol")) {
+		t.Errorf("SymDiff failed: invalid element added: %v.\n", d)
+	}
+
+	if err := New[string]().TryAdd("a b"); err != nil {
+		t.Errorf("TryAdd failed: plain set returned %v.\n", err)
+	}
+}
+
+func TestCanonicalizer(t *testing.T) {
+	s := NewWith(WithCanonicalizer(func(s string) string {
+		return strings.ToLower(strings.TrimSpace(s))
+	}), WithValidator(validID))
+
+	s.Add("Alice", " BOB ", "bob")
+	if s.Len() != 2 || !s.Contains("ALICE", "bob ") || !s.ContainsAny("x", " alice") {
+		t.Errorf("Add/Contains failed: got %v.\n", s)
+	}
+	// validation happens after canonicalization
+	if err := s.TryAdd("  carol  "); err != nil {
+		t.Errorf("TryAdd failed: canonical form was not validated: %v.\n", err)
+	}
+	s.Remove("CAROL", "Bob")
+	if !s.IsEqual(New("alice")) {
+		t.Errorf("Remove failed: got %v.\n", s)
+	}
+}
+
+func TestCanonicalizerAlgebra(t *testing.T) {
+	s := NewWith(WithCanonicalizer(strings.ToLower), WithValidator(validID))
+	s.Add("foo", "bar")
+	o := New("BAR", "Baz")
+
+	if u := s.Union(o); !u.IsEqual(New("foo", "bar", "baz")) || !u.Contains("BAZ") {
+		t.Errorf("Union failed: got %v.\n", u)
+	}
+	if i := s.Intersect(o); !i.IsEqual(New("bar")) {
+		t.Errorf("Intersect failed: got %v.\n", i)
+	}
+	if d := s.Diff(o); !d.IsEqual(New("foo")) {
+		t.Errorf("Diff failed: got %v.\n", d)
+	}
+	d := s.SymDiff(o)
+	if !d.IsEqual(New("foo", "baz")) {
+		t.Errorf("SymDiff failed: got %v.\n", d)
+	}
+	d.Remove("BAZ")
+	if !d.IsEqual(New("foo")) {
+		t.Errorf("Remove failed on a derived set: got %v.\n", d)
+	}
+}
+
+func TestValidatorAlgebra(t *testing.T) {
+	nonNegative := func(i int) error {
+		if i < 0 {
+			return errors.New("negative")
+		}
+		return nil
+	}
+	s := NewWith(WithValidator(nonNegative))
+	s.Add(1, 2)
+	if u := s.Union(New(-5, 3)); !u.IsEqual(New(1, 2, 3)) {
+		t.Errorf("Union failed: got %v.\n", u)
+	}
+	if d := s.SymDiff(New(-5, 2)); !d.IsEqual(New(1)) {
+		t.Errorf("SymDiff failed: got %v.\n", d)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"container/list"
+	"fmt"
+	"iter"
+)
+
+// ----- OrderedSet definition -----
+
+// An OrderedSet is a set which remembers the insertion order of its
+// elements, like the first-seen order of command line arguments. Iteration,
+// List and String follow the insertion order; membership tests take O(1)
+// time. Adding an element which is already in the set keeps its position.
+type OrderedSet[T comparable] struct {
+	elems map[T]*list.Element
+	order *list.List
+}
+
+// ----- constructor -----
+
+// NewOrdered creates a new ordered set and initializes it with the argument
+// values, in their order.
+func NewOrdered[T comparable](e ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{elems: make(map[T]*list.Element, len(e)), order: list.New()}
+	s.Add(e...)
+	return s
+}
+
+// ----- methods that modify the receiver -----
+
+// Add appends one or more elements to the given set, unless they are
+// already in it.
+func (s *OrderedSet[T]) Add(e ...T) {
+	for _, i := range e {
+		if _, ok := s.elems[i]; !ok {
+			s.elems[i] = s.order.PushBack(i)
+		}
+	}
+}
+
+// Remove removes one or more elements from the given set.
+func (s *OrderedSet[T]) Remove(e ...T) {
+	for _, i := range e {
+		if el, ok := s.elems[i]; ok {
+			s.order.Remove(el)
+			delete(s.elems, i)
+		}
+	}
+}
+
+// Clear removes all elements from the given set.
+func (s *OrderedSet[T]) Clear() {
+	clear(s.elems)
+	s.order.Init()
+}
+
+// Reset removes all elements from the given set, and replaces its index with
+// a new one presized for capacityHint elements, which releases the memory of
+// the old index.
+func (s *OrderedSet[T]) Reset(capacityHint int) {
+	s.elems = make(map[T]*list.Element, max(capacityHint, 0))
+	s.order.Init()
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *OrderedSet[T]) IsEmpty() bool {
+	return len(s.elems) == 0
+}
+
+// Len returns the length of the set.
+func (s *OrderedSet[T]) Len() int {
+	return len(s.elems)
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *OrderedSet[T]) Contains(e ...T) bool {
+	for _, i := range e {
+		if _, ok := s.elems[i]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEqual tests if two ordered sets have the same elements, regardless of
+// their order.
+func (s *OrderedSet[T]) IsEqual(t *OrderedSet[T]) bool {
+	if len(s.elems) != len(t.elems) {
+		return false
+	}
+	for k := range s.elems {
+		if _, ok := t.elems[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// First returns the oldest element of the set. The second return value is
+// false if the set is empty.
+func (s *OrderedSet[T]) First() (T, bool) {
+	if el := s.order.Front(); el != nil {
+		return el.Value.(T), true
+	}
+	var zero T
+	return zero, false
+}
+
+// Last returns the newest element of the set. The second return value is
+// false if the set is empty.
+func (s *OrderedSet[T]) Last() (T, bool) {
+	if el := s.order.Back(); el != nil {
+		return el.Value.(T), true
+	}
+	var zero T
+	return zero, false
+}
+
+// ----- methods that return a new set -----
+
+// Copy returns a copy of the set, with the same order.
+func (s *OrderedSet[T]) Copy() *OrderedSet[T] {
+	r := NewOrdered[T]()
+	for e := range s.All() {
+		r.Add(e)
+	}
+	return r
+}
+
+// Union returns a new ordered set with the elements of s followed by the new
+// elements of t, in their order.
+func (s *OrderedSet[T]) Union(t *OrderedSet[T]) *OrderedSet[T] {
+	r := s.Copy()
+	for e := range t.All() {
+		r.Add(e)
+	}
+	return r
+}
+
+// Intersect returns a new ordered set with the elements of s which are also
+// in t, in the order of s.
+func (s *OrderedSet[T]) Intersect(t *OrderedSet[T]) *OrderedSet[T] {
+	r := NewOrdered[T]()
+	for e := range s.All() {
+		if t.Contains(e) {
+			r.Add(e)
+		}
+	}
+	return r
+}
+
+// Diff returns a new ordered set with the elements of s which are not in t,
+// in the order of s.
+func (s *OrderedSet[T]) Diff(t *OrderedSet[T]) *OrderedSet[T] {
+	r := NewOrdered[T]()
+	for e := range s.All() {
+		if !t.Contains(e) {
+			r.Add(e)
+		}
+	}
+	return r
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all elements in insertion order. The loop body
+// may remove the current element, or elements not produced yet, which are
+// then skipped; removing both the current element and its successor ends the
+// iteration. Elements added during the iteration are produced.
+func (s *OrderedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for el := s.order.Front(); el != nil; {
+			v, next := el.Value.(T), el.Next()
+			if !yield(v) {
+				return
+			}
+			if s.elems[v] == el {
+				next = el.Next() // the successor may have been removed
+			} else if next != nil && s.elems[next.Value.(T)] != next {
+				return // the element and its successor were removed
+			}
+			el = next
+		}
+	}
+}
+
+// List returns the set elements in insertion order in a slice.
+func (s *OrderedSet[T]) List() []T {
+	l := make([]T, 0, len(s.elems))
+	for e := range s.All() {
+		l = append(l, e)
+	}
+	return l
+}
+
+// Set returns the elements as a new, unordered set.
+func (s *OrderedSet[T]) Set() Set[T] {
+	r := Set[T]{set: make(map[T]struct{}, len(s.elems))}
+	for k := range s.elems {
+		r.set[k] = struct{}{}
+	}
+	return r
+}
+
+// String returns a textual representation of the set in a string, with the
+// elements in insertion order.
+func (s *OrderedSet[T]) String() string {
+	b := make([]byte, 0, 2+8*len(s.elems))
+	b = append(b, "{ "...)
+	for e := range s.All() {
+		b = append(fmt.Append(b, e), ' ')
+	}
+	b = append(b, '}')
+	return string(b)
+}
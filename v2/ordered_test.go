@@ -0,0 +1,86 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOrderedSet(t *testing.T) {
+	s := NewOrdered("-v", "-x", "-v", "file", "-x")
+	if l := s.List(); !slices.Equal(l, []string{"-v", "-x", "file"}) {
+		t.Errorf("NewOrdered failed: got %v.\n", l)
+	}
+	s.Add("-v", "-q")
+	s.Remove("-x")
+	if str := s.String(); str != "{ -v file -q }" {
+		t.Errorf("String failed: got %q.\n", str)
+	}
+	if f, _ := s.First(); f != "-v" {
+		t.Errorf("First failed: got %q.\n", f)
+	}
+	if l, _ := s.Last(); l != "-q" {
+		t.Errorf("Last failed: got %q.\n", l)
+	}
+	if !s.Contains("file", "-q") || s.Contains("-x") || s.Len() != 3 {
+		t.Errorf("Contains failed.\n")
+	}
+
+	t2 := NewOrdered("x", "file", "-v")
+	if l := s.Union(t2).List(); !slices.Equal(l, []string{"-v", "file", "-q", "x"}) {
+		t.Errorf("Union failed: got %v.\n", l)
+	}
+	if l := s.Intersect(t2).List(); !slices.Equal(l, []string{"-v", "file"}) {
+		t.Errorf("Intersect failed: got %v.\n", l)
+	}
+	if l := s.Diff(t2).List(); !slices.Equal(l, []string{"-q"}) {
+		t.Errorf("Diff failed: got %v.\n", l)
+	}
+	if !s.IsEqual(NewOrdered("-q", "file", "-v")) || !s.Set().IsEqual(New("-q", "file", "-v")) {
+		t.Errorf("IsEqual/Set failed.\n")
+	}
+
+	c := s.Copy()
+	s.Clear()
+	if !s.IsEmpty() || c.Len() != 3 {
+		t.Errorf("Clear/Copy failed.\n")
+	}
+	if _, ok := s.First(); ok {
+		t.Errorf("First failed: got an element of the empty set.\n")
+	}
+}
+
+func TestOrderedSetRemoveDuringAll(t *testing.T) {
+	s := NewOrdered(1, 2, 3, 4)
+	for e := range s.All() {
+		s.Remove(e)
+	}
+	if !s.IsEmpty() {
+		t.Errorf("All failed: removing the current element left %v.\n", s.List())
+	}
+
+	s = NewOrdered(1, 2, 3, 4, 5)
+	var l []int
+	for e := range s.All() {
+		l = append(l, e)
+		if e == 2 {
+			s.Remove(3)
+		}
+	}
+	if !slices.Equal(l, []int{1, 2, 4, 5}) {
+		t.Errorf("All failed: removing the next element produced %v.\n", l)
+	}
+
+	l = nil
+	for e := range s.All() {
+		l = append(l, e)
+		s.Clear()
+	}
+	if !slices.Equal(l, []int{1}) || !s.IsEmpty() {
+		t.Errorf("All failed: clearing the set produced %v.\n", l)
+	}
+}
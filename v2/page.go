@@ -0,0 +1,115 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+
+	"github.com/hweidner/set/v2/internal/elemcodec"
+)
+
+// ----- stable pagination -----
+
+// Page returns a page of up to limit elements of the set in the order of
+// cmp, which must be a total order. The first page starts with afterToken
+// "", and each further page with the continuation token returned by the
+// previous page; the token is "" after the last page. As the token holds the
+// last element of the page, pages stay stable while the set changes: no
+// element is returned twice, and elements added before the position of a
+// client are not returned.
+//
+// A page takes O(n log limit) time, without sorting the whole set. The token
+// is opaque, but not encrypted; it is the encoding of the element for
+// strings, integers and floats, and its JSON encoding for all other element
+// types. A malformed token, or a limit <= 0, yields an empty page with an
+// empty token.
+func Page[T comparable](s Set[T], cmp func(a, b T) int, afterToken string, limit int) ([]T, string) {
+	if limit <= 0 {
+		return nil, ""
+	}
+	var after T
+	hasAfter := afterToken != ""
+	if hasAfter {
+		var ok bool
+		if after, ok = decodePageToken[T](afterToken); !ok {
+			return nil, ""
+		}
+	}
+
+	// a max-heap of the smallest elements after the token
+	h := &pageHeap[T]{cmp: cmp}
+	more := false
+	for k := range s.set {
+		if hasAfter && cmp(k, after) <= 0 {
+			continue
+		}
+		if len(h.elems) < limit {
+			heap.Push(h, k)
+			continue
+		}
+		more = true
+		if cmp(k, h.elems[0]) < 0 {
+			h.elems[0] = k
+			heap.Fix(h, 0)
+		}
+	}
+	page := slices.SortedFunc(slices.Values(h.elems), cmp)
+	if !more {
+		return page, ""
+	}
+	return page, encodePageToken(page[len(page)-1])
+}
+
+// encodePageToken returns the continuation token of an element.
+func encodePageToken[T comparable](e T) string {
+	b, ok := elemcodec.AppendValue([]byte{'v'}, e)
+	if !ok {
+		j, err := json.Marshal(e)
+		if err != nil {
+			return ""
+		}
+		b = append([]byte{'j'}, j...)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodePageToken returns the element of a continuation token.
+func decodePageToken[T comparable](token string) (T, bool) {
+	var e T
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) == 0 {
+		return e, false
+	}
+	switch b[0] {
+	case 'v':
+		r := bytes.NewReader(b[1:])
+		e, err = elemcodec.ReadValue[T](r, len(b))
+		return e, err == nil && r.Len() == 0
+	case 'j':
+		return e, json.Unmarshal(b[1:], &e) == nil
+	}
+	return e, false
+}
+
+// pageHeap is a max-heap of elements for Page.
+type pageHeap[T comparable] struct {
+	elems []T
+	cmp   func(a, b T) int
+}
+
+func (h *pageHeap[T]) Len() int           { return len(h.elems) }
+func (h *pageHeap[T]) Less(i, j int) bool { return h.cmp(h.elems[i], h.elems[j]) > 0 }
+func (h *pageHeap[T]) Swap(i, j int)      { h.elems[i], h.elems[j] = h.elems[j], h.elems[i] }
+func (h *pageHeap[T]) Push(x any)         { h.elems = append(h.elems, x.(T)) }
+func (h *pageHeap[T]) Pop() any {
+	x := h.elems[len(h.elems)-1]
+	h.elems = h.elems[:len(h.elems)-1]
+	return x
+}
@@ -0,0 +1,60 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	s := New[int]()
+	for i := 0; i < 95; i++ {
+		s.Add(i * 2)
+	}
+	var all []int
+	token, pages := "", 0
+	for {
+		page, next := Page(s, cmp.Compare[int], token, 10)
+		all = append(all, page...)
+		pages++
+		if next == "" {
+			break
+		}
+		token = next
+		if pages == 5 {
+			// changes behind the position are not seen, changes ahead are
+			s.Remove(0, 2)
+			s.Add(1, 189)
+		}
+	}
+	want := s.List()
+	slices.Sort(want)
+	want = append([]int{0, 2}, want[1:]...)
+	if pages != 10 || !slices.Equal(all, want) {
+		t.Errorf("Page failed: got %d pages with %v.\n", pages, all)
+	}
+
+	// a page ending with the last element has no continuation token
+	if page, next := Page(New("a", "b"), strings.Compare, "", 2); next != "" || !slices.Equal(page, []string{"a", "b"}) {
+		t.Errorf("Page failed: got %v, %q.\n", page, next)
+	}
+	if page, next := Page(s, cmp.Compare[int], "!bad", 10); page != nil || next != "" {
+		t.Errorf("Page failed: got %v, %q for a malformed token.\n", page, next)
+	}
+
+	// elements without a value encoding have JSON tokens
+	type point struct{ X, Y int }
+	p := New(point{1, 2}, point{1, 3}, point{2, 0})
+	cmpPoint := func(a, b point) int { return cmp.Or(cmp.Compare(a.X, b.X), cmp.Compare(a.Y, b.Y)) }
+	page, next := Page(p, cmpPoint, "", 2)
+	page2, next2 := Page(p, cmpPoint, next, 2)
+	if !slices.Equal(page, []point{{1, 2}, {1, 3}}) || !slices.Equal(page2, []point{{2, 0}}) || next2 != "" {
+		t.Errorf("Page failed: got %v and %v.\n", page, page2)
+	}
+}
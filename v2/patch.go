@@ -0,0 +1,47 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+// ----- changes between snapshots -----
+
+// Changes returns the elements added and removed between two snapshots of a
+// set, as new sets. The snapshots themselves are not modified.
+func Changes[T comparable](old, new Set[T]) (added, removed Set[T]) {
+	return new.Diff(old), old.Diff(new)
+}
+
+// A Patch holds the changes between two snapshots of a set, so they can be
+// sent over the wire instead of the full set. Its fields are exported, so it
+// can be encoded with encoding/json or encoding/gob.
+type Patch[T comparable] struct {
+	Added   []T `json:"added,omitempty"`
+	Removed []T `json:"removed,omitempty"`
+}
+
+// MakePatch returns the patch which turns the snapshot old into new.
+func MakePatch[T comparable](old, new Set[T]) Patch[T] {
+	added, removed := Changes(old, new)
+	return Patch[T]{Added: added.List(), Removed: removed.List()}
+}
+
+// Apply applies the patch to the given set, by removing the removed and
+// adding the added elements. Applying a patch made from old to new turns
+// any set equal to old into a set equal to new; it is idempotent.
+func (p Patch[T]) Apply(s Set[T]) {
+	s.Remove(p.Removed...)
+	s.Add(p.Added...)
+}
+
+// IsEmpty tests if the patch has no changes.
+func (p Patch[T]) IsEmpty() bool {
+	return len(p.Added) == 0 && len(p.Removed) == 0
+}
+
+// Reverse returns the patch which undoes p, i.e. which turns new back into
+// old.
+func (p Patch[T]) Reverse() Patch[T] {
+	return Patch[T]{Added: p.Removed, Removed: p.Added}
+}
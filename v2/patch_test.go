@@ -0,0 +1,42 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	old, new := New(1, 2, 3), New(2, 3, 4, 5)
+	added, removed := Changes(old, new)
+	if !added.IsEqual(New(4, 5)) || !removed.IsEqual(New(1)) {
+		t.Errorf("Changes failed: got %v, %v.\n", added, removed)
+	}
+
+	p := MakePatch(old, new)
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v.\n", err)
+	}
+	var q Patch[int]
+	if err := json.Unmarshal(b, &q); err != nil {
+		t.Fatalf("Unmarshal failed: %v.\n", err)
+	}
+	s := old.Copy()
+	q.Apply(s)
+	q.Apply(s)
+	if !s.IsEqual(new) {
+		t.Errorf("Apply failed: got %v.\n", s)
+	}
+	q.Reverse().Apply(s)
+	if !s.IsEqual(old) {
+		t.Errorf("Reverse failed: got %v.\n", s)
+	}
+	if p.IsEmpty() || !MakePatch(old, old.Copy()).IsEmpty() {
+		t.Errorf("IsEmpty failed.\n")
+	}
+}
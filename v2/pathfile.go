@@ -0,0 +1,202 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DefaultFoldCase tells if the file systems of the platform are usually case
+// insensitive, like on Windows and macOS.
+var DefaultFoldCase = runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "ios"
+
+// ----- PathFileSet definition -----
+
+// A PathFileSet is a set of file paths of the operating system, like the
+// inputs of a build tool. Paths are normalized before all operations: they
+// are made absolute, relative to the root directory of the set, cleaned,
+// and lower cased on case insensitive file systems, so "src/../main.go" and
+// "./MAIN.go" are the same file. Paths are returned in their normalized
+// form, with the separator of the platform. The file system is not accessed;
+// symbolic links are not resolved.
+//
+// It is backed by a PathSet of the slash separated normalized paths, whose
+// first segment is the volume name on Windows.
+type PathFileSet struct {
+	paths PathSet
+	root  string
+	fold  bool
+}
+
+// PathFileOptions holds the options of a PathFileSet.
+type PathFileOptions struct {
+	// Root is the directory of relative paths. If it is empty, the current
+	// working directory is used.
+	Root string
+
+	// FoldCase lower cases all paths, for case insensitive file systems.
+	// Use DefaultFoldCase for the usual behavior of the platform.
+	FoldCase bool
+}
+
+// ----- constructor -----
+
+// NewPathFileSet creates a new file path set with the given options, and
+// initializes it with the argument paths. It returns an error if the
+// current working directory is needed but unknown.
+func NewPathFileSet(o PathFileOptions, p ...string) (PathFileSet, error) {
+	root, err := filepath.Abs(o.Root)
+	if err != nil {
+		return PathFileSet{}, err
+	}
+	s := PathFileSet{paths: NewPathSet(), root: root, fold: o.FoldCase}
+	s.Add(p...)
+	return s, nil
+}
+
+// key returns the normalized, slash separated form of a path.
+func (s PathFileSet) key(p string) string {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(s.root, p)
+	}
+	p = filepath.Clean(p)
+	vol := filepath.VolumeName(p)
+	k := vol + "/" + filepath.ToSlash(p[len(vol):])
+	if s.fold {
+		k = strings.ToLower(k)
+	}
+	return k
+}
+
+// filePath returns the path of a normalized key.
+func filePath(k string) string {
+	seg := splitPath(k)
+	if len(seg) > 0 && seg[0] != "" && filepath.VolumeName(seg[0]) == seg[0] {
+		return seg[0] + string(filepath.Separator) + strings.Join(seg[1:], string(filepath.Separator))
+	}
+	return string(filepath.Separator) + strings.Join(seg, string(filepath.Separator))
+}
+
+// keys returns the normalized forms of paths.
+func (s PathFileSet) keys(p []string) []string {
+	k := make([]string, len(p))
+	for i, x := range p {
+		k[i] = s.key(x)
+	}
+	return k
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more paths to the given set.
+func (s PathFileSet) Add(p ...string) {
+	s.paths.Add(s.keys(p)...)
+}
+
+// Remove removes one or more paths from the given set. Files below a
+// removed directory stay in the set.
+func (s PathFileSet) Remove(p ...string) {
+	s.paths.Remove(s.keys(p)...)
+}
+
+// Clear removes all paths from the given set.
+func (s PathFileSet) Clear() {
+	s.paths.Clear()
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s PathFileSet) IsEmpty() bool {
+	return s.paths.IsEmpty()
+}
+
+// Len returns the number of paths in the set.
+func (s PathFileSet) Len() int {
+	return s.paths.Len()
+}
+
+// Contains checks if a set contains one or more paths. The return value
+// is true only if all given paths are in the set.
+func (s PathFileSet) Contains(p ...string) bool {
+	return s.paths.Contains(s.keys(p)...)
+}
+
+// ContainsSubtree checks if the directory dir or any file below it is in the
+// set.
+func (s PathFileSet) ContainsSubtree(dir string) bool {
+	return s.paths.ContainsSubtree(s.key(dir))
+}
+
+// Covers checks if the path p or any of its parent directories is in the
+// set.
+func (s PathFileSet) Covers(p string) bool {
+	return s.paths.Covers(s.key(p))
+}
+
+// DescendantOf checks if the path p is strictly below the directory dir,
+// after normalization. It does not depend on the elements of the set.
+func (s PathFileSet) DescendantOf(p, dir string) bool {
+	k, d := splitPath(s.key(p)), splitPath(s.key(dir))
+	if len(k) <= len(d) {
+		return false
+	}
+	for i, seg := range d {
+		if k[i] != seg {
+			return false
+		}
+	}
+	return true
+}
+
+// DescendantsOf returns a new set of all paths in s which are strictly
+// below the directory dir. The set s is not modified.
+func (s PathFileSet) DescendantsOf(dir string) Set[string] {
+	r := New[string]()
+	for k := range s.paths.DescendantsOf(s.key(dir)).All() {
+		r.Add(filePath(k))
+	}
+	return r
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all paths in the set in lexical order of the
+// path segments.
+func (s PathFileSet) All() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for k := range s.paths.All() {
+			if !yield(filePath(k)) {
+				return
+			}
+		}
+	}
+}
+
+// List returns a list of the paths in the set in lexical order of the path
+// segments.
+func (s PathFileSet) List() []string {
+	r := make([]string, 0, s.Len())
+	for p := range s.All() {
+		r = append(r, p)
+	}
+	return r
+}
+
+// String returns a textual representation of the set in a string.
+func (s PathFileSet) String() string {
+	var b strings.Builder
+	b.WriteString("{ ")
+	for p := range s.All() {
+		b.WriteString(p)
+		b.WriteByte(' ')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,56 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPathFileSet(t *testing.T) {
+	root, _ := filepath.Abs(filepath.FromSlash("/work/proj"))
+	abs := func(p string) string { return filepath.Join(root, filepath.FromSlash(p)) }
+	s, err := NewPathFileSet(PathFileOptions{Root: root}, "src/main.go", "./src/../README.md", abs("src/util/x.go"))
+	if err != nil {
+		t.Fatalf("NewPathFileSet failed: %v.\n", err)
+	}
+	if s.Len() != 3 || !s.Contains(abs("README.md"), "src//main.go", "src/util/./x.go") || s.Contains("readme.md") {
+		t.Errorf("NewPathFileSet failed: got %v.\n", s)
+	}
+	want := []string{abs("README.md"), abs("src/main.go"), abs("src/util/x.go")}
+	if l := s.List(); !slices.Equal(l, want) {
+		t.Errorf("List failed: got %v, expected %v.\n", l, want)
+	}
+	if d := s.DescendantsOf("src"); !d.IsEqual(New(abs("src/main.go"), abs("src/util/x.go"))) {
+		t.Errorf("DescendantsOf failed: got %v.\n", d)
+	}
+	if !s.DescendantOf("src/a/b.go", "src") || s.DescendantOf("src", "src") || s.DescendantOf("srcx/a", "src") ||
+		!s.DescendantOf("src/a", root) {
+		t.Errorf("DescendantOf failed.\n")
+	}
+	if !s.ContainsSubtree("src/util") || s.ContainsSubtree("doc") || s.Covers("src/other.go") {
+		t.Errorf("ContainsSubtree/Covers failed.\n")
+	}
+	s.Add("src")
+	if !s.Covers("src/other.go") {
+		t.Errorf("Covers failed for a directory.\n")
+	}
+	s.Remove("src", "src/main.go")
+	if s.Len() != 2 || s.Contains("src/main.go") {
+		t.Errorf("Remove failed: got %v.\n", s)
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.String() != "{ }" {
+		t.Errorf("Clear failed: got %v.\n", s)
+	}
+
+	// case insensitive file systems
+	f, _ := NewPathFileSet(PathFileOptions{Root: root, FoldCase: true}, "Src/Main.GO")
+	if !f.Contains("src/main.go", abs("SRC/MAIN.GO")) || f.List()[0] != abs("src/main.go") {
+		t.Errorf("FoldCase failed: got %v.\n", f)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"slices"
+	"strings"
+)
+
+// ----- PathSet definition -----
+
+// A PathSet is a set of slash separated paths like "a/b/c". It is backed by a
+// trie, so membership can also be queried for whole subtrees of the hierarchy.
+// Empty path segments are ignored, so "a//b/" and "/a/b" denote the same path
+// as "a/b".
+type PathSet struct {
+	root *pathNode
+}
+
+// a node of the path trie
+type pathNode struct {
+	children map[string]*pathNode
+	member   bool
+	count    int // number of members in the subtree rooted at this node
+}
+
+// ----- constructor -----
+
+// NewPathSet creates a new path set and initializes it with the argument values.
+func NewPathSet(p ...string) PathSet {
+	s := PathSet{root: &pathNode{}}
+	s.Add(p...)
+	return s
+}
+
+// splitPath splits a path into its non-empty segments.
+func splitPath(p string) []string {
+	return strings.FieldsFunc(p, func(r rune) bool { return r == '/' })
+}
+
+// find returns the node for the given path segments, or nil.
+func (s PathSet) find(seg []string) *pathNode {
+	n := s.root
+	for _, i := range seg {
+		if n = n.children[i]; n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more paths to the given set.
+func (s PathSet) Add(p ...string) {
+	for _, i := range p {
+		seg := splitPath(i)
+		if n := s.find(seg); n != nil && n.member {
+			continue
+		}
+		n := s.root
+		n.count++
+		for _, j := range seg {
+			c := n.children[j]
+			if c == nil {
+				if n.children == nil {
+					n.children = map[string]*pathNode{}
+				}
+				c = &pathNode{}
+				n.children[j] = c
+			}
+			c.count++
+			n = c
+		}
+		n.member = true
+	}
+}
+
+// Remove removes one or more paths from the given set. Descendants of a
+// removed path stay in the set.
+func (s PathSet) Remove(p ...string) {
+	for _, i := range p {
+		seg := splitPath(i)
+		if n := s.find(seg); n == nil || !n.member {
+			continue
+		}
+		n := s.root
+		n.count--
+		for _, j := range seg {
+			c := n.children[j]
+			if c.count--; c.count == 0 {
+				// prune the now empty subtree
+				delete(n.children, j)
+				break
+			}
+			n = c
+		}
+		if n := s.find(seg); n != nil {
+			n.member = false
+		}
+	}
+}
+
+// Clear removes all paths from the given set.
+func (s PathSet) Clear() {
+	*s.root = pathNode{}
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s PathSet) IsEmpty() bool {
+	return s.root.count == 0
+}
+
+// Len returns the number of paths in the set.
+func (s PathSet) Len() int {
+	return s.root.count
+}
+
+// Contains checks if a set contains one or more paths. The return value
+// is true only if all given paths are in the set.
+func (s PathSet) Contains(p ...string) bool {
+	for _, i := range p {
+		if n := s.find(splitPath(i)); n == nil || !n.member {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsSubtree checks if the path p or any of its descendants is in the set.
+func (s PathSet) ContainsSubtree(p string) bool {
+	n := s.find(splitPath(p))
+	return n != nil && n.count > 0
+}
+
+// Covers checks if the path p or any of its ancestors is in the set. This is
+// the usual semantics of hierarchical permissions, where a grant on "a/b"
+// also applies to "a/b/c".
+func (s PathSet) Covers(p string) bool {
+	n := s.root
+	if n.member {
+		return true
+	}
+	for _, i := range splitPath(p) {
+		if n = n.children[i]; n == nil {
+			return false
+		}
+		if n.member {
+			return true
+		}
+	}
+	return false
+}
+
+// DescendantsOf returns a new set of all paths in s which are strict
+// descendants of the path p. The set s is not modified.
+func (s PathSet) DescendantsOf(p string) Set[string] {
+	seg := splitPath(p)
+	r := New[string]()
+	n := s.find(seg)
+	if n == nil {
+		return r
+	}
+	for k := range walkPaths(n, seg) {
+		if len(k) > 0 && len(splitPath(k)) > len(seg) {
+			r.Add(k)
+		}
+	}
+	return r
+}
+
+// ----- iterators -----
+
+// All returns an iterator to all paths in the set in lexical order of the
+// path segments.
+func (s PathSet) All() iter.Seq[string] {
+	return walkPaths(s.root, nil)
+}
+
+// walkPaths returns an iterator to all member paths below the node n, which
+// is reached by the path segments prefix.
+func walkPaths(n *pathNode, prefix []string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		var walk func(n *pathNode, seg []string) bool
+		walk = func(n *pathNode, seg []string) bool {
+			if n.member && !yield(strings.Join(seg, "/")) {
+				return false
+			}
+			keys := make([]string, 0, len(n.children))
+			for k := range n.children {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				if !walk(n.children[k], append(seg, k)) {
+					return false
+				}
+			}
+			return true
+		}
+		walk(n, slices.Clip(prefix))
+	}
+}
+
+// ----- methods that return other data types -----
+
+// List returns a list of the paths in the set in lexical order of the path
+// segments.
+func (s PathSet) List() []string {
+	r := make([]string, 0, s.root.count)
+	for k := range s.All() {
+		r = append(r, k)
+	}
+	return r
+}
+
+// String returns a textual representation of the set in a string.
+func (s PathSet) String() string {
+	str := "{ "
+	for k := range s.All() {
+		str += k + " "
+	}
+	str += "}"
+	return str
+}
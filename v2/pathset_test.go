@@ -0,0 +1,49 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPathSet(t *testing.T) {
+	s := NewPathSet("a/b/c", "a/b/d", "/x//y/", "a/e")
+
+	if s.Len() != 4 {
+		t.Errorf("Len failed: expected 4, got %d.\n", s.Len())
+	}
+	if !s.Contains("a/b/c", "x/y") || s.Contains("a/b") {
+		t.Errorf("Contains failed: got wrong membership for %v.\n", s)
+	}
+	if !s.ContainsSubtree("a/b") || !s.ContainsSubtree("a") || s.ContainsSubtree("a/f") {
+		t.Errorf("ContainsSubtree failed for %v.\n", s)
+	}
+	if !s.Covers("a/b/c/d") || s.Covers("a/b") || s.Covers("q") {
+		t.Errorf("Covers failed for %v.\n", s)
+	}
+
+	d := s.DescendantsOf("a/b")
+	if !d.IsEqual(New("a/b/c", "a/b/d")) {
+		t.Errorf("DescendantsOf failed: got %v.\n", d)
+	}
+
+	str := fmt.Sprint(s.List())
+	if str != "[a/b/c a/b/d a/e x/y]" {
+		t.Errorf("List failed: got %v.\n", str)
+	}
+
+	s.Add("a/b")
+	s.Remove("a/b/c", "a/b/d", "nonexistent")
+	if s.Len() != 3 || !s.Contains("a/b") || s.ContainsSubtree("a/b/c") {
+		t.Errorf("Add/Remove failed: got %v.\n", s)
+	}
+
+	s.Clear()
+	if !s.IsEmpty() || s.ContainsSubtree("a") {
+		t.Errorf("Clear/IsEmpty failed: got %v.\n", s)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// ----- Pool definition -----
+
+// the size classes of a Pool are the powers of two from 2^minPoolClass to
+// 2^maxPoolClass
+const (
+	minPoolClass = 4
+	maxPoolClass = 20
+)
+
+// A Pool recycles sets, for services which create and discard many
+// mid-sized sets per second. Sets are bucketed by size class, so a set taken
+// from the pool has room for the requested number of elements without
+// growing. A Pool is safe for concurrent use; its zero value is ready to use.
+type Pool[T comparable] struct {
+	classes [maxPoolClass - minPoolClass + 1]sync.Pool
+}
+
+// sizeClass returns the index of the smallest size class which holds n
+// elements, or -1 if n exceeds the largest class.
+func sizeClass(n int) int {
+	c := max(bits.Len(uint(max(n, 1)-1)), minPoolClass)
+	if c > maxPoolClass {
+		return -1
+	}
+	return c - minPoolClass
+}
+
+// Get returns an empty set with room for at least sizeHint elements, either
+// from the pool or newly allocated.
+func (p *Pool[T]) Get(sizeHint int) Set[T] {
+	c := sizeClass(sizeHint)
+	if c < 0 {
+		return Set[T]{set: make(map[T]struct{}, sizeHint)}
+	}
+	if m, ok := p.classes[c].Get().(map[T]struct{}); ok {
+		return Set[T]{set: m}
+	}
+	return Set[T]{set: make(map[T]struct{}, 1<<(c+minPoolClass))}
+}
+
+// Put clears the set and returns it to the pool. Neither s nor any copy of
+// it may be used afterwards. The configuration of s is not retained. Since
+// the capacity of a Go map is not observable, the set is classified by its
+// current length: it goes into the largest class it has actually held.
+// Sets with less than 16 or at least 2^21 elements are left to the garbage
+// collector.
+func (p *Pool[T]) Put(s Set[T]) {
+	c := bits.Len(uint(len(s.set))) - 1 - minPoolClass
+	if c < 0 || c > maxPoolClass-minPoolClass {
+		return
+	}
+	clear(s.set)
+	p.classes[c].Put(s.set)
+}
@@ -0,0 +1,51 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "testing"
+
+func TestSizeClass(t *testing.T) {
+	for _, c := range []struct{ n, class int }{
+		{0, 0}, {1, 0}, {16, 0}, {17, 1}, {32, 1}, {1000, 6}, {1 << 20, 16}, {1<<20 + 1, -1},
+	} {
+		if got := sizeClass(c.n); got != c.class {
+			t.Errorf("sizeClass failed for %d: got %d, expected %d.\n", c.n, got, c.class)
+		}
+	}
+}
+
+func TestPool(t *testing.T) {
+	var p Pool[int]
+	s := p.Get(100)
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+	p.Put(s)
+	if s.Len() != 0 {
+		t.Errorf("Put failed: set not cleared.\n")
+	}
+	r := p.Get(50)
+	if !r.IsEmpty() {
+		t.Errorf("Get failed: got a non-empty set %v.\n", r)
+	}
+	r.Add(1)
+	p.Put(r)          // too small to keep
+	p.Put(Set[int]{}) // uninitialized sets are ignored
+	if g := p.Get(1 << 22); g.set == nil {
+		t.Errorf("Get failed: no set beyond the largest class.\n")
+	}
+}
+
+func BenchmarkPool(b *testing.B) {
+	var p Pool[int]
+	for i := 0; i < b.N; i++ {
+		s := p.Get(500)
+		for j := 0; j < 500; j++ {
+			s.Add(j)
+		}
+		p.Put(s)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"iter"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ----- PortSet definition -----
+
+// A PortSet is a set of network port numbers 0-65535, backed by a bitmap of
+// 8 KiB. Its textual representation is a comma separated list of ports and
+// port ranges, like "80,443,8000-8100".
+type PortSet struct {
+	bits [1024]uint64
+}
+
+// the causes of the decode errors of ParsePortSet
+var (
+	errInvalidPort      = errors.New("invalid port")
+	errInvalidPortRange = errors.New("invalid port range")
+)
+
+// ----- constructors -----
+
+// NewPortSet creates a new port set and initializes it with the argument values.
+func NewPortSet(p ...uint16) *PortSet {
+	s := &PortSet{}
+	s.Add(p...)
+	return s
+}
+
+// ParsePortSet parses a comma separated list of ports and port ranges, like
+// "80,443,8000-8100". Whitespace around the list items is ignored. Invalid
+// items are reported as a *DecodeError.
+func ParsePortSet(str string) (*PortSet, error) {
+	s := &PortSet{}
+	if strings.TrimSpace(str) == "" {
+		return s, nil
+	}
+	for pos, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		lo, hi, isRange := strings.Cut(item, "-")
+		l, err := strconv.ParseUint(strings.TrimSpace(lo), 10, 16)
+		if err != nil {
+			return nil, &DecodeError{Pos: pos, Input: item, Err: errInvalidPort}
+		}
+		h := l
+		if isRange {
+			if h, err = strconv.ParseUint(strings.TrimSpace(hi), 10, 16); err != nil || h < l {
+				return nil, &DecodeError{Pos: pos, Input: item, Err: errInvalidPortRange}
+			}
+		}
+		s.AddRange(uint16(l), uint16(h))
+	}
+	return s, nil
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more ports to the given set.
+func (s *PortSet) Add(p ...uint16) {
+	for _, i := range p {
+		s.bits[i>>6] |= 1 << (i & 63)
+	}
+}
+
+// AddRange adds all ports from lo to hi (inclusive) to the given set.
+func (s *PortSet) AddRange(lo, hi uint16) {
+	for i := int(lo); i <= int(hi); i++ {
+		s.bits[i>>6] |= 1 << (i & 63)
+	}
+}
+
+// Remove removes one or more ports from the given set.
+func (s *PortSet) Remove(p ...uint16) {
+	for _, i := range p {
+		s.bits[i>>6] &^= 1 << (i & 63)
+	}
+}
+
+// RemoveRange removes all ports from lo to hi (inclusive) from the given set.
+func (s *PortSet) RemoveRange(lo, hi uint16) {
+	for i := int(lo); i <= int(hi); i++ {
+		s.bits[i>>6] &^= 1 << (i & 63)
+	}
+}
+
+// Clear removes all ports from the given set.
+func (s *PortSet) Clear() {
+	s.bits = [1024]uint64{}
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *PortSet) IsEmpty() bool {
+	return s.bits == [1024]uint64{}
+}
+
+// Len returns the number of ports in the set.
+func (s *PortSet) Len() int {
+	return popcount(s.bits[:])
+}
+
+// Contains checks if a set contains one or more ports. The return value
+// is true only if all given ports are in the set.
+func (s *PortSet) Contains(p ...uint16) bool {
+	for _, i := range p {
+		if s.bits[i>>6]&(1<<(i&63)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsSorted checks the membership of each port of a query slice. It
+// exists for parity with the other ordered sets; as the bitmap is indexed
+// directly, the query does not need to be sorted.
+func (s *PortSet) ContainsSorted(sorted []uint16) []bool {
+	r := make([]bool, len(sorted))
+	for i, p := range sorted {
+		r[i] = s.bits[p>>6]&(1<<(p&63)) != 0
+	}
+	return r
+}
+
+// IsEqual tests if two sets are equal.
+func (s *PortSet) IsEqual(t *PortSet) bool {
+	return s.bits == t.bits
+}
+
+// IsSubsetOf returns true if the set s is a subset of the set t.
+func (s *PortSet) IsSubsetOf(t *PortSet) bool {
+	for i, w := range s.bits {
+		if w&^t.bits[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ----- methods that return a new set -----
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *PortSet) Copy() *PortSet {
+	r := *s
+	return &r
+}
+
+// Union returns a new set, which represents the union of two or more sets.
+// The sets themselves are not modified.
+func (s *PortSet) Union(t ...*PortSet) *PortSet {
+	r := s.Copy()
+	for _, i := range t {
+		orWords(r.bits[:], r.bits[:], i.bits[:])
+	}
+	return r
+}
+
+// Intersect returns a new set which represents the intersection of two or more sets.
+// The sets themselves are not modified.
+func (s *PortSet) Intersect(t ...*PortSet) *PortSet {
+	r := s.Copy()
+	for _, i := range t {
+		andWords(r.bits[:], r.bits[:], i.bits[:])
+	}
+	return r
+}
+
+// Diff returns a new set which represents the difference of two sets.
+// The sets themselves are not modified.
+func (s *PortSet) Diff(t *PortSet) *PortSet {
+	r := s.Copy()
+	andNotWords(r.bits[:], r.bits[:], t.bits[:])
+	return r
+}
+
+// SymDiff returns a new set which represents the symmetric difference of two
+// sets. The sets themselves are not modified.
+func (s *PortSet) SymDiff(t *PortSet) *PortSet {
+	r := s.Copy()
+	xorWords(r.bits[:], r.bits[:], t.bits[:])
+	return r
+}
+
+// Complement returns a new set of all ports which are not in s.
+func (s *PortSet) Complement() *PortSet {
+	r := &PortSet{}
+	for j := range r.bits {
+		r.bits[j] = ^s.bits[j]
+	}
+	return r
+}
+
+// ----- iterators -----
+
+// All returns an iterator to all ports in the set in ascending order.
+func (s *PortSet) All() iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		for i, w := range s.bits {
+			for w != 0 {
+				b := bits.TrailingZeros64(w)
+				if !yield(uint16(i<<6 + b)) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// Ranges returns an iterator to all maximal ranges [lo, hi] of consecutive
+// ports in the set in ascending order.
+func (s *PortSet) Ranges() iter.Seq2[uint16, uint16] {
+	return func(yield func(uint16, uint16) bool) {
+		start, prev, open := 0, 0, false
+		for p := range s.All() {
+			switch {
+			case !open:
+				start, open = int(p), true
+			case int(p) != prev+1:
+				if !yield(uint16(start), uint16(prev)) {
+					return
+				}
+				start = int(p)
+			}
+			prev = int(p)
+		}
+		if open {
+			yield(uint16(start), uint16(prev))
+		}
+	}
+}
+
+// ----- methods that return other data types -----
+
+// List returns a list of the ports in the set in ascending order.
+func (s *PortSet) List() []uint16 {
+	r := make([]uint16, 0, s.Len())
+	for p := range s.All() {
+		r = append(r, p)
+	}
+	return r
+}
+
+// String returns the textual representation of the set, like "80,443,8000-8100",
+// which is understood by ParsePortSet.
+func (s *PortSet) String() string {
+	var b strings.Builder
+	for lo, hi := range s.Ranges() {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(int(lo)))
+		if hi != lo {
+			b.WriteByte('-')
+			b.WriteString(strconv.Itoa(int(hi)))
+		}
+	}
+	return b.String()
+}
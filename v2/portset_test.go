@@ -0,0 +1,63 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"testing"
+)
+
+func TestPortSet(t *testing.T) {
+	s, err := ParsePortSet("80, 443,8000-8100,22")
+	if err != nil {
+		t.Fatalf("ParsePortSet failed: %v.\n", err)
+	}
+	if str := s.String(); str != "22,80,443,8000-8100" {
+		t.Errorf("String failed: got %v.\n", str)
+	}
+	if s.Len() != 104 || !s.Contains(22, 8050) || s.Contains(8101) {
+		t.Errorf("Len/Contains failed: got %d elements.\n", s.Len())
+	}
+
+	for _, i := range []string{"http", "80-", "90-80", "65536"} {
+		if _, err := ParsePortSet(i); err == nil {
+			t.Errorf("ParsePortSet failed: expected an error for %q.\n", i)
+		}
+	}
+
+	web := NewPortSet(80, 443, 9090)
+	if r := s.Intersect(web); r.String() != "80,443" {
+		t.Errorf("Intersect failed: got %v.\n", r)
+	}
+	if r := s.Union(web); r.Len() != 105 {
+		t.Errorf("Union failed: got %v.\n", r)
+	}
+	if r := web.Diff(s); r.String() != "9090" {
+		t.Errorf("Diff failed: got %v.\n", r)
+	}
+	if r := web.SymDiff(NewPortSet(80, 81)); r.String() != "81,443,9090" {
+		t.Errorf("SymDiff failed: got %v.\n", r)
+	}
+	if r := NewPortSet(0, 1, 65535).Complement(); r.String() != "2-65534" {
+		t.Errorf("Complement failed: got %v.\n", r)
+	}
+	if !web.Intersect(s).IsSubsetOf(s) || web.IsSubsetOf(s) {
+		t.Errorf("IsSubsetOf failed.\n")
+	}
+
+	if got := s.ContainsSorted([]uint16{21, 22, 8100}); got[0] || !got[1] || !got[2] {
+		t.Errorf("ContainsSorted failed: got %v.\n", got)
+	}
+
+	s.RemoveRange(8000, 8099)
+	s.Remove(22)
+	if str := s.String(); str != "80,443,8100" {
+		t.Errorf("Remove/RemoveRange failed: got %v.\n", str)
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.String() != "" {
+		t.Errorf("Clear failed: got %v.\n", s)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "slices"
+
+// ----- boolean queries on inverted indexes -----
+
+// Query evaluates a tag expression as a query on an inverted index, like the
+// result of Invert, which maps each term to the set of documents containing
+// it. It returns the new set of documents matching the expression, as if
+// Match was called with the terms of each document.
+//
+// The expression is evaluated like by a search engine on posting lists:
+// the operands of an AND are intersected starting with the smallest one, and
+// AND NOT operands are removed from the result, while unions are lazy views
+// on their operands, which are not materialized unless they are the result.
+// A NOT which is not an operand of an AND selects the documents of the
+// universe all without the operand. If all is empty, the universe is the
+// union of all sets of the index.
+func Query[D comparable](e *TagExpr, index map[string]Set[D], all Set[D]) Set[D] {
+	q := &query[D]{index: index, all: all}
+	p := q.eval(e.root)
+	r := Set[D]{set: make(map[D]struct{}, min(p.size(), 1024))}
+	p.each(func(d D) {
+		r.set[d] = struct{}{}
+	})
+	return r
+}
+
+// a query on an inverted index
+type query[D comparable] struct {
+	index map[string]Set[D]
+	all   Set[D] // the universe, computed on first use if empty
+}
+
+// a posting list of a subexpression
+type posting[D comparable] interface {
+	contains(d D) bool
+	size() int // an upper bound of the number of documents
+	each(f func(D))
+}
+
+// eval returns the posting list of a node.
+func (q *query[D]) eval(n *tagNode) posting[D] {
+	switch n.op {
+	case opTag:
+		return setPosting[D](q.index[n.tag])
+	case opAnd:
+		return q.intersect(n.args)
+	case opOr:
+		u := make(unionPosting[D], len(n.args))
+		for i, a := range n.args {
+			u[i] = q.eval(a)
+		}
+		return u
+	default:
+		return diffPosting[D]{q.universe(), q.eval(n.args[0])}
+	}
+}
+
+// universe returns the posting list of all documents.
+func (q *query[D]) universe() posting[D] {
+	if len(q.all.set) == 0 {
+		q.all = New[D]()
+		for _, s := range q.index {
+			for d := range s.set {
+				q.all.set[d] = struct{}{}
+			}
+		}
+	}
+	return setPosting[D](q.all)
+}
+
+// intersect returns the materialized intersection of the posting lists of
+// the nodes. Negated nodes are subtracted from the result.
+func (q *query[D]) intersect(args []*tagNode) posting[D] {
+	var pos, neg []posting[D]
+	for _, a := range args {
+		if a.op == opNot {
+			neg = append(neg, q.eval(a.args[0]))
+		} else {
+			pos = append(pos, q.eval(a))
+		}
+	}
+	if len(pos) == 0 {
+		pos = append(pos, q.universe())
+	}
+	slices.SortFunc(pos, func(a, b posting[D]) int { return a.size() - b.size() })
+	r := setPosting[D]{set: map[D]struct{}{}}
+	pos[0].each(func(d D) {
+		for _, p := range pos[1:] {
+			if !p.contains(d) {
+				return
+			}
+		}
+		for _, p := range neg {
+			if p.contains(d) {
+				return
+			}
+		}
+		r.set[d] = struct{}{}
+	})
+	return r
+}
+
+// a posting list of a set
+type setPosting[D comparable] Set[D]
+
+func (p setPosting[D]) contains(d D) bool {
+	_, ok := p.set[d]
+	return ok
+}
+
+func (p setPosting[D]) size() int {
+	return len(p.set)
+}
+
+func (p setPosting[D]) each(f func(D)) {
+	for d := range p.set {
+		f(d)
+	}
+}
+
+// a lazy union of posting lists
+type unionPosting[D comparable] []posting[D]
+
+func (u unionPosting[D]) contains(d D) bool {
+	for _, p := range u {
+		if p.contains(d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u unionPosting[D]) size() int {
+	n := 0
+	for _, p := range u {
+		n += p.size()
+	}
+	return n
+}
+
+// each visits each document once, skipping those of the earlier operands.
+func (u unionPosting[D]) each(f func(D)) {
+	for i, p := range u {
+		p.each(func(d D) {
+			if !u[:i].contains(d) {
+				f(d)
+			}
+		})
+	}
+}
+
+// a lazy difference of posting lists
+type diffPosting[D comparable] struct {
+	p, q posting[D]
+}
+
+func (p diffPosting[D]) contains(d D) bool {
+	return p.p.contains(d) && !p.q.contains(d)
+}
+
+func (p diffPosting[D]) size() int {
+	return p.p.size()
+}
+
+func (p diffPosting[D]) each(f func(D)) {
+	p.p.each(func(d D) {
+		if !p.q.contains(d) {
+			f(d)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "testing"
+
+func TestQuery(t *testing.T) {
+	docs := map[int]Set[string]{
+		1: New("prod", "eu"),
+		2: New("prod", "us", "canary"),
+		3: New("dev", "eu"),
+		4: New("prod", "us"),
+		5: New("dev"),
+	}
+	index := Invert(docs)
+	for _, expr := range []string{
+		"prod",
+		"missing",
+		"prod AND eu",
+		"prod AND (eu OR us) AND NOT canary",
+		"eu OR us OR missing",
+		"NOT prod",
+		"NOT (eu OR canary)",
+		"NOT eu AND NOT us",
+		"dev OR NOT prod AND us",
+		"(prod OR dev) AND (eu OR dev)",
+	} {
+		e, err := ParseTagExpr(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := New[int]()
+		for d, tags := range docs {
+			if e.Match(tags) {
+				want.Add(d)
+			}
+		}
+		if got := Query(e, index, Set[int]{}); !got.IsEqual(want) {
+			t.Errorf("Query failed for %q: got %v, want %v.\n", expr, got, want)
+		}
+	}
+
+	// documents without terms are only in an explicit universe
+	e, _ := ParseTagExpr("NOT prod")
+	if got := Query(e, index, New(1, 2, 3, 4, 5, 6)); !got.IsEqual(New(3, 5, 6)) {
+		t.Errorf("Query failed with a universe: got %v.\n", got)
+	}
+}
@@ -0,0 +1,418 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package redisset provides sets which are stored on a Redis server, so code
+written against the operations of a local set can switch to a set which is
+shared by many processes, like a global blocklist or the seen IDs of a
+distributed job.
+
+A Set is a handle to the Redis set under a key. Its methods mirror those of
+set.Set, with a context and an error, and are executed on the server: Add
+with SADD, Contains with SISMEMBER, Intersect with SINTER, and so on. The
+results of the set algebra are returned as local sets, or are stored on the
+server with StoreUnion, StoreIntersect and StoreDiff, which avoid the
+transfer of the elements.
+
+The package does not depend on a Redis client. The Conn interface is a
+single method which sends a command, and is easily implemented with any
+client, like this for github.com/redis/go-redis:
+
+	conn := redisset.ConnFunc(func(ctx context.Context, args ...string) (any, error) {
+		a := make([]any, len(args))
+		for i, s := range args {
+			a[i] = s
+		}
+		return rdb.Do(ctx, a...).Result()
+	})
+
+The elements are stored as Redis strings in the encoding of a Codec. The
+default is their text, as for set.Set.MarshalText, so the members of sets
+of strings and numbers are readable by other clients. Commands of several
+sets require that all keys are on the same server, or in the same hash slot
+of a Redis cluster.
+*/
+package redisset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/hweidner/set/v2"
+	"github.com/hweidner/set/v2/internal/textcodec"
+)
+
+// ErrReply is returned for replies of the server which do not have the
+// type expected for the command.
+var ErrReply = errors.New("redisset: unexpected reply")
+
+// A Conn sends commands to a Redis server. Do sends one command with its
+// arguments, and returns the reply: an int64 for integer replies, a string
+// or []byte for bulk strings, a []any for arrays, and nil for null replies.
+// Error replies are returned as errors. A Conn must be safe for concurrent
+// use if the sets using it are.
+type Conn interface {
+	Do(ctx context.Context, args ...string) (any, error)
+}
+
+// A ConnFunc is a function which implements the Conn interface.
+type ConnFunc func(ctx context.Context, args ...string) (any, error)
+
+// Do implements the Conn interface by calling f.
+func (f ConnFunc) Do(ctx context.Context, args ...string) (any, error) {
+	return f(ctx, args...)
+}
+
+// A Codec encodes the elements of a set as Redis strings. Decode must be the
+// inverse of Encode, and equal elements must have equal encodings.
+type Codec[T comparable] struct {
+	Encode func(T) (string, error)
+	Decode func(string) (T, error)
+}
+
+// TextCodec returns the codec which encodes elements as their text, as for
+// set.Set.MarshalText.
+func TextCodec[T comparable]() Codec[T] {
+	return Codec[T]{Encode: textcodec.Format[T], Decode: textcodec.Parse[T]}
+}
+
+// ----- Set definition -----
+
+// A Set is a set of elements stored on a Redis server under a key. The
+// handle holds no state besides the key, so many handles, in many
+// processes, can share the same set.
+type Set[T comparable] struct {
+	conn  Conn
+	key   string
+	codec Codec[T]
+}
+
+// ----- constructor -----
+
+// New returns a handle to the set under the key, whose elements are encoded
+// by TextCodec. The set is not accessed; a missing key is an empty set.
+func New[T comparable](c Conn, key string) *Set[T] {
+	return NewWithCodec(c, key, TextCodec[T]())
+}
+
+// NewWithCodec returns a handle to the set under the key, whose elements are
+// encoded by the given codec.
+func NewWithCodec[T comparable](c Conn, key string, codec Codec[T]) *Set[T] {
+	return &Set[T]{conn: c, key: key, codec: codec}
+}
+
+// encode appends the encodings of the elements to args.
+func (s *Set[T]) encode(args []string, e []T) ([]string, error) {
+	for _, x := range e {
+		m, err := s.codec.Encode(x)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, m)
+	}
+	return args, nil
+}
+
+// decode decodes the members of an array reply into a new set.
+func (s *Set[T]) decode(r any) (set.Set[T], error) {
+	members, err := bulkStrings(r)
+	if err != nil {
+		return set.Set[T]{}, err
+	}
+	res := set.New[T]()
+	var errs []error
+	for i, m := range members {
+		x, err := s.codec.Decode(m)
+		if err != nil {
+			errs = append(errs, &set.DecodeError{Pos: i, Input: m, Err: err})
+			continue
+		}
+		res.Add(x)
+	}
+	return res, errors.Join(errs...)
+}
+
+// keys returns the command with the keys of s and the sets of t.
+func (s *Set[T]) keys(cmd string, t []*Set[T]) []string {
+	args := make([]string, 0, 2+len(t))
+	args = append(args, cmd, s.key)
+	for _, i := range t {
+		args = append(args, i.key)
+	}
+	return args
+}
+
+// integer returns the integer reply of a command.
+func (s *Set[T]) integer(ctx context.Context, args ...string) (int, error) {
+	r, err := s.conn.Do(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := r.(int64)
+	if !ok {
+		return 0, fmt.Errorf("%w to %s: %T", ErrReply, args[0], r)
+	}
+	return int(n), nil
+}
+
+// bulkStrings returns the members of an array reply.
+func bulkStrings(r any) ([]string, error) {
+	a, ok := r.([]any)
+	if !ok {
+		if s, ok := r.([]string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("%w: %T", ErrReply, r)
+	}
+	l := make([]string, len(a))
+	for i, m := range a {
+		switch m := m.(type) {
+		case string:
+			l[i] = m
+		case []byte:
+			l[i] = string(m)
+		default:
+			return nil, fmt.Errorf("%w: member of type %T", ErrReply, m)
+		}
+	}
+	return l, nil
+}
+
+// ----- methods that modify the set -----
+
+// Add adds one or more elements to the set, with SADD. It returns the number
+// of elements which were not yet in the set.
+func (s *Set[T]) Add(ctx context.Context, e ...T) (int, error) {
+	if len(e) == 0 {
+		return 0, nil
+	}
+	args, err := s.encode([]string{"SADD", s.key}, e)
+	if err != nil {
+		return 0, err
+	}
+	return s.integer(ctx, args...)
+}
+
+// Remove removes one or more elements from the set, with SREM. It returns
+// the number of elements which were in the set.
+func (s *Set[T]) Remove(ctx context.Context, e ...T) (int, error) {
+	if len(e) == 0 {
+		return 0, nil
+	}
+	args, err := s.encode([]string{"SREM", s.key}, e)
+	if err != nil {
+		return 0, err
+	}
+	return s.integer(ctx, args...)
+}
+
+// Clear removes all elements from the set, by deleting its key.
+func (s *Set[T]) Clear(ctx context.Context) error {
+	_, err := s.conn.Do(ctx, "DEL", s.key)
+	return err
+}
+
+// Store replaces the elements of the set with those of the local set t.
+// The elements are written to a temporary key, which is renamed to the key
+// of the set, so other clients never see a partial update.
+func (s *Set[T]) Store(ctx context.Context, t set.Set[T]) error {
+	if t.IsEmpty() {
+		return s.Clear(ctx)
+	}
+	tmp := tempKey(s.key)
+	args, err := s.encode([]string{"SADD", tmp}, t.List())
+	if err != nil {
+		return err
+	}
+	// the temporary key is deleted on errors, as far as possible
+	if _, err := s.integer(ctx, args...); err != nil {
+		s.conn.Do(ctx, "DEL", tmp)
+		return err
+	}
+	if _, err := s.conn.Do(ctx, "RENAME", tmp, s.key); err != nil {
+		s.conn.Do(ctx, "DEL", tmp)
+		return err
+	}
+	return nil
+}
+
+// tempKey returns a new temporary key in the same hash slot of a Redis
+// cluster as the key. A key without braces is made the hash tag of the
+// temporary key; a key with braces should have a hash tag of its own.
+func tempKey(key string) string {
+	if !strings.ContainsAny(key, "{}") {
+		key = "{" + key + "}"
+	}
+	return fmt.Sprintf("%s:tmp:%016x", key, rand.Uint64())
+}
+
+// StoreUnion replaces the set with the union of the sets t, with
+// SUNIONSTORE, and returns its number of elements.
+func (s *Set[T]) StoreUnion(ctx context.Context, t ...*Set[T]) (int, error) {
+	return s.integer(ctx, s.keys("SUNIONSTORE", t)...)
+}
+
+// StoreIntersect replaces the set with the intersection of the sets t, with
+// SINTERSTORE, and returns its number of elements.
+func (s *Set[T]) StoreIntersect(ctx context.Context, t ...*Set[T]) (int, error) {
+	return s.integer(ctx, s.keys("SINTERSTORE", t)...)
+}
+
+// StoreDiff replaces the set with the elements of a which are not in b, with
+// SDIFFSTORE, and returns its number of elements.
+func (s *Set[T]) StoreDiff(ctx context.Context, a, b *Set[T]) (int, error) {
+	return s.integer(ctx, "SDIFFSTORE", s.key, a.key, b.key)
+}
+
+// ----- methods that do not modify the set -----
+
+// Key returns the key of the set.
+func (s *Set[T]) Key() string {
+	return s.key
+}
+
+// Len returns the number of elements, with SCARD.
+func (s *Set[T]) Len(ctx context.Context) (int, error) {
+	return s.integer(ctx, "SCARD", s.key)
+}
+
+// IsEmpty tests if the set is empty.
+func (s *Set[T]) IsEmpty(ctx context.Context) (bool, error) {
+	n, err := s.Len(ctx)
+	return n == 0, err
+}
+
+// Contains checks if the set contains one or more elements, with SISMEMBER,
+// or SMISMEMBER for more than one element. The return value is true only if
+// all given elements are in the set.
+func (s *Set[T]) Contains(ctx context.Context, e ...T) (bool, error) {
+	switch len(e) {
+	case 0:
+		return true, nil
+	case 1:
+		args, err := s.encode([]string{"SISMEMBER", s.key}, e)
+		if err != nil {
+			return false, err
+		}
+		n, err := s.integer(ctx, args...)
+		return n == 1, err
+	}
+	args, err := s.encode([]string{"SMISMEMBER", s.key}, e)
+	if err != nil {
+		return false, err
+	}
+	r, err := s.conn.Do(ctx, args...)
+	if err != nil {
+		return false, err
+	}
+	a, ok := r.([]any)
+	if !ok || len(a) != len(e) {
+		return false, fmt.Errorf("%w to SMISMEMBER: %T", ErrReply, r)
+	}
+	for _, i := range a {
+		if n, ok := i.(int64); !ok || n != 1 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Set returns the elements as a new local set, with SMEMBERS. Members which
+// cannot be decoded are not added; the returned error joins a
+// *set.DecodeError for each of them.
+func (s *Set[T]) Set(ctx context.Context) (set.Set[T], error) {
+	r, err := s.conn.Do(ctx, "SMEMBERS", s.key)
+	if err != nil {
+		return set.Set[T]{}, err
+	}
+	return s.decode(r)
+}
+
+// Union returns the union of the set and the sets t as a new local set, with
+// SUNION.
+func (s *Set[T]) Union(ctx context.Context, t ...*Set[T]) (set.Set[T], error) {
+	r, err := s.conn.Do(ctx, s.keys("SUNION", t)...)
+	if err != nil {
+		return set.Set[T]{}, err
+	}
+	return s.decode(r)
+}
+
+// Intersect returns the intersection of the set and the sets t as a new
+// local set, with SINTER.
+func (s *Set[T]) Intersect(ctx context.Context, t ...*Set[T]) (set.Set[T], error) {
+	r, err := s.conn.Do(ctx, s.keys("SINTER", t)...)
+	if err != nil {
+		return set.Set[T]{}, err
+	}
+	return s.decode(r)
+}
+
+// Diff returns the elements of the set which are not in t as a new local
+// set, with SDIFF.
+func (s *Set[T]) Diff(ctx context.Context, t *Set[T]) (set.Set[T], error) {
+	r, err := s.conn.Do(ctx, "SDIFF", s.key, t.key)
+	if err != nil {
+		return set.Set[T]{}, err
+	}
+	return s.decode(r)
+}
+
+// ----- iterators -----
+
+// All returns an iterator over the elements of the set, which fetches them
+// in batches with SSCAN, for sets too large for SMEMBERS. Like SSCAN, it may
+// yield an element more than once, and elements added or removed during the
+// iteration may or may not be yielded. Errors are yielded with the zero
+// element, and end the iteration.
+func (s *Set[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor := "0"
+		for {
+			r, err := s.conn.Do(ctx, "SSCAN", s.key, cursor, "COUNT", strconv.Itoa(scanCount))
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			a, ok := r.([]any)
+			if !ok || len(a) != 2 {
+				yield(zero, fmt.Errorf("%w to SSCAN: %T", ErrReply, r))
+				return
+			}
+			c, err := bulkStrings(a[:1])
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			members, err := bulkStrings(a[1])
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, m := range members {
+				x, err := s.codec.Decode(m)
+				if err != nil {
+					yield(zero, &set.DecodeError{Input: m, Err: err})
+					return
+				}
+				if !yield(x, nil) {
+					return
+				}
+			}
+			if cursor = c[0]; cursor == "0" {
+				return
+			}
+		}
+	}
+}
+
+// the COUNT hint of SSCAN
+const scanCount = 1000
@@ -0,0 +1,317 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package redisset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+// a fakeServer executes the set commands of Redis in memory
+type fakeServer struct {
+	mu   sync.Mutex
+	keys map[string]set.Set[string]
+	cmds []string // the names of the received commands
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{keys: map[string]set.Set[string]{}}
+}
+
+// get returns the set under a key, which is empty if the key is missing.
+func (f *fakeServer) get(k string) set.Set[string] {
+	if s, ok := f.keys[k]; ok {
+		return s
+	}
+	return set.New[string]()
+}
+
+// put stores a set under a key, or deletes the key if the set is empty.
+func (f *fakeServer) put(k string, s set.Set[string]) int64 {
+	if s.IsEmpty() {
+		delete(f.keys, k)
+	} else {
+		f.keys[k] = s
+	}
+	return int64(s.Len())
+}
+
+// array returns the sorted members of a set as an array reply of bulk
+// strings, like from redigo.
+func array(s set.Set[string]) []any {
+	l := s.List()
+	slices.Sort(l)
+	r := make([]any, len(l))
+	for i, m := range l {
+		r[i] = []byte(m)
+	}
+	return r
+}
+
+func (f *fakeServer) Do(_ context.Context, args ...string) (any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cmd, a := args[0], args[1:]
+	f.cmds = append(f.cmds, cmd)
+	sets := func() []set.Set[string] {
+		var l []set.Set[string]
+		for _, k := range a {
+			l = append(l, f.get(k))
+		}
+		return l
+	}
+	switch cmd {
+	case "SADD":
+		s := f.get(a[0])
+		n := s.Len()
+		s.Add(a[1:]...)
+		f.put(a[0], s)
+		return int64(s.Len() - n), nil
+	case "SREM":
+		s := f.get(a[0])
+		n := s.Len()
+		s.Remove(a[1:]...)
+		f.put(a[0], s)
+		return int64(n - s.Len()), nil
+	case "SISMEMBER":
+		if f.get(a[0]).Contains(a[1]) {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "SMISMEMBER":
+		s := f.get(a[0])
+		var r []any
+		for _, m := range a[1:] {
+			if s.Contains(m) {
+				r = append(r, int64(1))
+			} else {
+				r = append(r, int64(0))
+			}
+		}
+		return r, nil
+	case "SCARD":
+		return int64(f.get(a[0]).Len()), nil
+	case "DEL":
+		_, ok := f.keys[a[0]]
+		delete(f.keys, a[0])
+		if ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "RENAME":
+		s, ok := f.keys[a[0]]
+		if !ok {
+			return nil, errors.New("ERR no such key")
+		}
+		delete(f.keys, a[0])
+		f.keys[a[1]] = s
+		return "OK", nil
+	case "SMEMBERS":
+		return array(f.get(a[0])), nil
+	case "SSCAN":
+		// two members per batch, returned as strings like by go-redis
+		l := f.get(a[0]).List()
+		slices.Sort(l)
+		c, _ := strconv.Atoi(a[1])
+		end := min(c+2, len(l))
+		next := strconv.Itoa(end)
+		if end == len(l) {
+			next = "0"
+		}
+		r := []any{}
+		for _, m := range l[c:end] {
+			r = append(r, m)
+		}
+		return []any{next, r}, nil
+	case "SUNION", "SINTER", "SDIFF":
+		return array(combine(cmd, sets())), nil
+	case "SUNIONSTORE", "SINTERSTORE", "SDIFFSTORE":
+		dst := a[0]
+		a = a[1:]
+		return f.put(dst, combine(strings.TrimSuffix(cmd, "STORE"), sets())), nil
+	}
+	return nil, fmt.Errorf("ERR unknown command %s", cmd)
+}
+
+// combine applies a set command to the sets.
+func combine(cmd string, s []set.Set[string]) set.Set[string] {
+	switch cmd {
+	case "SUNION":
+		return s[0].Union(s[1:]...)
+	case "SINTER":
+		return s[0].Intersect(s[1:]...)
+	}
+	r := s[0].Copy()
+	for _, t := range s[1:] {
+		r = r.Diff(t)
+	}
+	return r
+}
+
+func TestSet(t *testing.T) {
+	ctx := context.Background()
+	srv := newFakeServer()
+	s := New[int](srv, "ports")
+	if n, err := s.Add(ctx, 80, 443, 80); err != nil || n != 2 {
+		t.Errorf("Add failed: got %d, %v.\n", n, err)
+	}
+	if n, err := s.Add(ctx); err != nil || n != 0 || len(srv.cmds) != 1 {
+		t.Errorf("Add failed for no elements: got %d, %v.\n", n, err)
+	}
+	if !srv.keys["ports"].IsEqual(set.New("80", "443")) {
+		t.Errorf("Add failed: got members %v.\n", srv.keys["ports"])
+	}
+	if ok, err := s.Contains(ctx, 80); err != nil || !ok {
+		t.Errorf("Contains failed: got %v, %v.\n", ok, err)
+	}
+	if ok, err := s.Contains(ctx, 80, 8080); err != nil || ok {
+		t.Errorf("Contains failed: got %v, %v.\n", ok, err)
+	}
+	if ok, err := s.Contains(ctx, 80, 443); err != nil || !ok || srv.cmds[len(srv.cmds)-1] != "SMISMEMBER" {
+		t.Errorf("Contains failed: got %v, %v.\n", ok, err)
+	}
+	if n, err := s.Len(ctx); err != nil || n != 2 {
+		t.Errorf("Len failed: got %d, %v.\n", n, err)
+	}
+	if l, err := s.Set(ctx); err != nil || !l.IsEqual(set.New(80, 443)) {
+		t.Errorf("Set failed: got %v, %v.\n", l, err)
+	}
+	if n, err := s.Remove(ctx, 443, 22); err != nil || n != 1 {
+		t.Errorf("Remove failed: got %d, %v.\n", n, err)
+	}
+	if err := s.Clear(ctx); err != nil {
+		t.Errorf("Clear failed: got %v.\n", err)
+	}
+	if ok, err := s.IsEmpty(ctx); err != nil || !ok {
+		t.Errorf("IsEmpty failed: got %v, %v.\n", ok, err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newFakeServer()
+	s := New[string](srv, "tags")
+	s.Add(ctx, "old")
+	if err := s.Store(ctx, set.New("a", "b", "c")); err != nil {
+		t.Errorf("Store failed: got %v.\n", err)
+	}
+	if len(srv.keys) != 1 || !srv.keys["tags"].IsEqual(set.New("a", "b", "c")) {
+		t.Errorf("Store failed: got %v.\n", srv.keys)
+	}
+	if err := s.Store(ctx, set.New[string]()); err != nil || len(srv.keys) != 0 {
+		t.Errorf("Store failed for an empty set: got %v, %v.\n", srv.keys, err)
+	}
+	if k := tempKey("tags"); !strings.HasPrefix(k, "{tags}:tmp:") {
+		t.Errorf("tempKey failed: got %q.\n", k)
+	}
+	if k := tempKey("{user1}.tags"); !strings.HasPrefix(k, "{user1}.tags:tmp:") {
+		t.Errorf("tempKey failed: got %q.\n", k)
+	}
+}
+
+func TestAlgebra(t *testing.T) {
+	ctx := context.Background()
+	srv := newFakeServer()
+	a, b, c := New[string](srv, "a"), New[string](srv, "b"), New[string](srv, "c")
+	a.Add(ctx, "x", "y", "z")
+	b.Add(ctx, "y", "z", "w")
+	c.Add(ctx, "z")
+
+	if u, err := a.Union(ctx, b, c); err != nil || !u.IsEqual(set.New("w", "x", "y", "z")) {
+		t.Errorf("Union failed: got %v, %v.\n", u, err)
+	}
+	if i, err := a.Intersect(ctx, b); err != nil || !i.IsEqual(set.New("y", "z")) {
+		t.Errorf("Intersect failed: got %v, %v.\n", i, err)
+	}
+	if d, err := a.Diff(ctx, b); err != nil || !d.IsEqual(set.New("x")) {
+		t.Errorf("Diff failed: got %v, %v.\n", d, err)
+	}
+
+	dst := New[string](srv, "dst")
+	if n, err := dst.StoreIntersect(ctx, a, b, c); err != nil || n != 1 || !srv.keys["dst"].IsEqual(set.New("z")) {
+		t.Errorf("StoreIntersect failed: got %d, %v.\n", n, err)
+	}
+	if n, err := dst.StoreUnion(ctx, b, c); err != nil || n != 3 {
+		t.Errorf("StoreUnion failed: got %d, %v.\n", n, err)
+	}
+	if n, err := dst.StoreDiff(ctx, b, a); err != nil || n != 1 || !srv.keys["dst"].IsEqual(set.New("w")) {
+		t.Errorf("StoreDiff failed: got %d, %v.\n", n, err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	ctx := context.Background()
+	srv := newFakeServer()
+	s := New[int](srv, "ids")
+	s.Add(ctx, 1, 2, 3, 4, 5)
+	var got []int
+	for x, err := range s.All(ctx) {
+		if err != nil {
+			t.Fatalf("All failed: got %v.\n", err)
+		}
+		got = append(got, x)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("All failed: got %v.\n", got)
+	}
+
+	// early break
+	n := 0
+	for range s.All(ctx) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("All failed for a break: got %d.\n", n)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	srv := newFakeServer()
+	srv.keys["ids"] = set.New("1", "x")
+	s := New[int](srv, "ids")
+	l, err := s.Set(ctx)
+	var de *set.DecodeError
+	if !errors.As(err, &de) || de.Input != "x" || !l.IsEqual(set.New(1)) {
+		t.Errorf("Set failed: got %v, %v.\n", l, err)
+	}
+	for _, err := range s.All(ctx) {
+		if err != nil && !errors.As(err, &de) {
+			t.Errorf("All failed: got %v.\n", err)
+		}
+	}
+
+	// a reply of the wrong type
+	bad := ConnFunc(func(context.Context, ...string) (any, error) {
+		return "OK", nil
+	})
+	if _, err := New[int](bad, "k").Len(ctx); !errors.Is(err, ErrReply) {
+		t.Errorf("Len failed: got %v.\n", err)
+	}
+	if _, err := New[int](bad, "k").Set(ctx); !errors.Is(err, ErrReply) {
+		t.Errorf("Set failed: got %v.\n", err)
+	}
+
+	// a codec error
+	errCodec := errors.New("codec")
+	c := NewWithCodec(srv, "k", Codec[int]{
+		Encode: func(int) (string, error) { return "", errCodec },
+		Decode: func(string) (int, error) { return 0, errCodec },
+	})
+	if _, err := c.Add(ctx, 1); !errors.Is(err, errCodec) {
+		t.Errorf("Add failed: got %v.\n", err)
+	}
+}
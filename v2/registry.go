@@ -0,0 +1,369 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when an addition would exceed the cardinality
+// quota of a tenant.
+var ErrQuotaExceeded = errors.New("set: tenant quota exceeded")
+
+// ErrDropped is returned when adding to a set which was dropped from its
+// registry by Delete or Sweep.
+var ErrDropped = errors.New("set: set was dropped from the registry")
+
+// ----- Registry definition -----
+
+// A Registry manages many named sets of many tenants. Sets are created
+// lazily on first access. The total number of elements in all sets of a
+// tenant is limited by a quota. Sets which were not used for an idle time
+// to live can be dropped by Sweep. All methods are safe for concurrent use.
+type Registry[T comparable] struct {
+	mu      sync.Mutex
+	quota   int
+	tenants map[string]*tenant[T]
+	idleTTL time.Duration
+	onDrop  func(tenant, name string, s Set[T])
+	now     func() time.Time
+
+	rejections atomic.Int64 // additions rejected by quota
+}
+
+// the sets of a tenant
+type tenant[T comparable] struct {
+	mu   sync.Mutex
+	sets map[string]*RegisteredSet[T]
+	size int // total number of elements in all sets
+}
+
+// A RegisteredSet is a set managed by a Registry, which enforces the quota
+// of its tenant. All methods are safe for concurrent use.
+//
+// When the set is dropped from the registry by Delete or Sweep, the handle
+// is detached: Add returns ErrDropped, Remove is a no-op, and the set appears
+// empty. Use Get to create a new set with the same name.
+type RegisteredSet[T comparable] struct {
+	tenantName, name string
+	reg              *Registry[T]
+	tenant           atomic.Pointer[tenant[T]] // nil if dropped
+	set              Set[T]
+	lastUsed         time.Time // protected by the tenant lock
+}
+
+// RegistryStats holds the metrics of a Registry.
+type RegistryStats struct {
+	Tenants         int // number of tenants with at least one set
+	Sets            int // number of sets
+	Elements        int // total number of elements in all sets
+	QuotaRejections int // number of additions rejected by quota
+}
+
+// ----- constructor -----
+
+// NewRegistry creates a new registry where each tenant may store at most
+// quota elements in all its sets. A quota of 0 means no limit.
+func NewRegistry[T comparable](quota int) *Registry[T] {
+	return &Registry[T]{quota: quota, tenants: map[string]*tenant[T]{}, now: time.Now}
+}
+
+// SetIdleTTL sets the time after which unused sets are dropped by Sweep.
+// A ttl of 0, which is the default, means that sets are never dropped.
+func (r *Registry[T]) SetIdleTTL(ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.idleTTL = ttl
+}
+
+// OnDrop registers a function which is called with the elements of each set
+// dropped by Sweep, e.g. to persist them. It is called without holding any
+// lock of the registry.
+func (r *Registry[T]) OnDrop(f func(tenant, name string, s Set[T])) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onDrop = f
+}
+
+// ----- methods of the registry -----
+
+// Get returns the set with the given name of the given tenant, creating it
+// if it does not exist.
+func (r *Registry[T]) Get(tenantName, name string) *RegisteredSet[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tenants[tenantName]
+	if !ok {
+		t = &tenant[T]{sets: map[string]*RegisteredSet[T]{}}
+		r.tenants[tenantName] = t
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sets[name]
+	if !ok {
+		s = &RegisteredSet[T]{tenantName: tenantName, name: name, reg: r, set: New[T]()}
+		s.tenant.Store(t)
+		t.sets[name] = s
+	}
+	s.lastUsed = r.now()
+	return s
+}
+
+// Lookup returns the set with the given name of the given tenant. The second
+// return value is false if the set does not exist.
+func (r *Registry[T]) Lookup(tenantName, name string) (*RegisteredSet[T], bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tenants[tenantName]
+	if !ok {
+		return nil, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sets[name]
+	return s, ok
+}
+
+// Delete removes the set with the given name of the given tenant from the
+// registry. The elements of the set no longer count against the quota.
+func (r *Registry[T]) Delete(tenantName, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delete(tenantName, name)
+}
+
+// delete removes a set. The caller must hold the registry lock.
+func (r *Registry[T]) delete(tenantName, name string) {
+	t, ok := r.tenants[tenantName]
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.sets[name]; ok {
+		t.drop(s)
+	}
+	if len(t.sets) == 0 {
+		delete(r.tenants, tenantName)
+	}
+}
+
+// drop removes a set of the tenant. The caller must hold the tenant lock.
+func (t *tenant[T]) drop(s *RegisteredSet[T]) {
+	t.size -= s.set.Len()
+	delete(t.sets, s.name)
+	s.tenant.Store(nil) // detach, see ErrDropped
+}
+
+// Sweep drops all sets which were not used for the idle time to live, and
+// returns the number of dropped sets. The OnDrop function is called for each
+// of them.
+func (r *Registry[T]) Sweep() int {
+	r.mu.Lock()
+	if r.idleTTL <= 0 {
+		r.mu.Unlock()
+		return 0
+	}
+	deadline := r.now().Add(-r.idleTTL)
+	var dropped []*RegisteredSet[T]
+	for tenantName, t := range r.tenants {
+		// the sets are checked and dropped under the same tenant lock, so a
+		// set used meanwhile is never dropped
+		t.mu.Lock()
+		for _, s := range t.sets {
+			if s.lastUsed.Before(deadline) {
+				t.drop(s)
+				dropped = append(dropped, s)
+			}
+		}
+		if len(t.sets) == 0 {
+			delete(r.tenants, tenantName)
+		}
+		t.mu.Unlock()
+	}
+	onDrop := r.onDrop
+	r.mu.Unlock()
+
+	if onDrop != nil {
+		for _, s := range dropped {
+			onDrop(s.tenantName, s.name, s.set)
+		}
+	}
+	return len(dropped)
+}
+
+// Janitor calls Sweep periodically with the given interval, until the
+// context is cancelled.
+func (r *Registry[T]) Janitor(ctx context.Context, interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			r.Sweep()
+		}
+	}
+}
+
+// Sets returns an iterator to all sets in the registry, in an undefined
+// order. The iterator works on a snapshot of the registered sets.
+func (r *Registry[T]) Sets() iter.Seq[*RegisteredSet[T]] {
+	r.mu.Lock()
+	var l []*RegisteredSet[T]
+	for _, t := range r.tenants {
+		t.mu.Lock()
+		for _, s := range t.sets {
+			l = append(l, s)
+		}
+		t.mu.Unlock()
+	}
+	r.mu.Unlock()
+	return func(yield func(*RegisteredSet[T]) bool) {
+		for _, s := range l {
+			if !yield(s) {
+				return
+			}
+		}
+	}
+}
+
+// Usage returns the total number of elements in all sets of a tenant.
+func (r *Registry[T]) Usage(tenantName string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tenants[tenantName]
+	if !ok {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.size
+}
+
+// Stats returns the metrics of the registry.
+func (r *Registry[T]) Stats() RegistryStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := RegistryStats{QuotaRejections: int(r.rejections.Load())}
+	st.Tenants = len(r.tenants)
+	for _, t := range r.tenants {
+		t.mu.Lock()
+		st.Sets += len(t.sets)
+		st.Elements += t.size
+		t.mu.Unlock()
+	}
+	return st
+}
+
+// ----- methods of registered sets -----
+
+// Tenant returns the name of the tenant owning the set.
+func (s *RegisteredSet[T]) Tenant() string {
+	return s.tenantName
+}
+
+// Name returns the name of the set.
+func (s *RegisteredSet[T]) Name() string {
+	return s.name
+}
+
+// Add adds one or more elements to the set. If the quota of the tenant would
+// be exceeded, no element is added and ErrQuotaExceeded is returned. If the
+// set was dropped from the registry, no element is added and ErrDropped is
+// returned.
+func (s *RegisteredSet[T]) Add(e ...T) error {
+	t := s.lockTenant()
+	if t == nil {
+		return ErrDropped
+	}
+	defer t.mu.Unlock()
+	n := New[T]()
+	for _, i := range e {
+		if !s.set.Contains(i) {
+			n.Add(i)
+		}
+	}
+	if q := s.reg.quota; q > 0 && t.size+n.Len() > q {
+		s.reg.rejections.Add(1)
+		return ErrQuotaExceeded
+	}
+	for k := range n.set {
+		s.set.set[k] = struct{}{}
+	}
+	t.size += n.Len()
+	return nil
+}
+
+// Remove removes one or more elements from the set.
+func (s *RegisteredSet[T]) Remove(e ...T) {
+	t := s.lockTenant()
+	if t == nil {
+		return
+	}
+	defer t.mu.Unlock()
+	for _, i := range e {
+		if s.set.Contains(i) {
+			s.set.Remove(i)
+			t.size--
+		}
+	}
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *RegisteredSet[T]) Contains(e ...T) bool {
+	t := s.lockTenant()
+	if t == nil {
+		return len(e) == 0
+	}
+	defer t.mu.Unlock()
+	return s.set.Contains(e...)
+}
+
+// Len returns the length of the set.
+func (s *RegisteredSet[T]) Len() int {
+	t := s.lockTenant()
+	if t == nil {
+		return 0
+	}
+	defer t.mu.Unlock()
+	return s.set.Len()
+}
+
+// Copy returns a copy of the elements of the set as a plain set.
+func (s *RegisteredSet[T]) Copy() Set[T] {
+	t := s.lockTenant()
+	if t == nil {
+		return New[T]()
+	}
+	defer t.mu.Unlock()
+	return s.set.Copy()
+}
+
+// lockTenant locks and returns the tenant of the set, or returns nil if the
+// set was deleted from the registry. It does not take the registry lock, so
+// sets of different tenants do not contend with each other. The set is
+// detached under the tenant lock, so it is checked again after locking.
+func (s *RegisteredSet[T]) lockTenant() *tenant[T] {
+	t := s.tenant.Load()
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	if s.tenant.Load() != t {
+		t.mu.Unlock()
+		return nil
+	}
+	s.lastUsed = s.reg.now()
+	return t
+}
@@ -0,0 +1,140 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry[int](5)
+
+	a := r.Get("acme", "admins")
+	if err := a.Add(1, 2, 3); err != nil {
+		t.Errorf("Add failed: %v.\n", err)
+	}
+	if r.Get("acme", "admins") != a {
+		t.Errorf("Get failed: returned a different set for the same name.\n")
+	}
+	b := r.Get("acme", "users")
+	if err := b.Add(4, 5, 6); !errors.Is(err, ErrQuotaExceeded) || b.Len() != 0 {
+		t.Errorf("Add failed: expected quota error, got %v.\n", err)
+	}
+	if err := b.Add(1, 4); err != nil || r.Usage("acme") != 5 {
+		t.Errorf("Add failed: got %v, usage %d.\n", err, r.Usage("acme"))
+	}
+	if err := r.Get("other", "users").Add(1, 2, 3, 4, 5); err != nil {
+		t.Errorf("Add failed: quota of other tenant exceeded: %v.\n", err)
+	}
+
+	a.Remove(1, 9)
+	if r.Usage("acme") != 4 || a.Contains(1) || !a.Contains(2, 3) {
+		t.Errorf("Remove failed: usage is %d.\n", r.Usage("acme"))
+	}
+
+	st := r.Stats()
+	if st.Tenants != 2 || st.Sets != 3 || st.Elements != 9 || st.QuotaRejections != 1 {
+		t.Errorf("Stats failed: got %+v.\n", st)
+	}
+
+	n := 0
+	for s := range r.Sets() {
+		n += s.Len()
+	}
+	if n != 9 {
+		t.Errorf("Sets failed: got %d elements.\n", n)
+	}
+
+	r.Delete("acme", "admins")
+	if _, ok := r.Lookup("acme", "admins"); ok || r.Usage("acme") != 2 {
+		t.Errorf("Delete failed: usage is %d.\n", r.Usage("acme"))
+	}
+	if err := a.Add(7); !errors.Is(err, ErrDropped) || a.Len() != 0 {
+		t.Errorf("Add failed: deleted set was modified: %v.\n", err)
+	}
+	if a = r.Get("acme", "admins"); a.Add(7) != nil || a.Len() != 1 {
+		t.Errorf("Get failed: cannot reuse the name of a deleted set.\n")
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	r := NewRegistry[int](100)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				r.Get("t", "s").Add(i*20 + j)
+				r.Stats()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if u := r.Usage("t"); u != 100 {
+		t.Errorf("Concurrent Add failed: usage is %d, expected 100.\n", u)
+	}
+}
+
+func TestRegistryNoGlobalLock(t *testing.T) {
+	r := NewRegistry[int](0)
+	s := r.Get("t", "s")
+	r.mu.Lock()
+	done := make(chan bool)
+	go func() {
+		s.Add(1)
+		done <- s.Contains(1) && s.Len() == 1
+	}()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Errorf("Add failed: element not found.\n")
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Add failed: blocked by the registry lock.\n")
+	}
+	r.mu.Unlock()
+}
+
+func TestRegistrySweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := NewRegistry[string](0)
+	r.now = func() time.Time { return now }
+	r.SetIdleTTL(time.Minute)
+	dropped := map[string]Set[string]{}
+	r.OnDrop(func(tenant, name string, s Set[string]) {
+		dropped[tenant+"/"+name] = s
+	})
+
+	s1 := r.Get("t", "session1")
+	s1.Add("a", "b")
+	r.Get("t", "session2").Add("c")
+	now = now.Add(50 * time.Second)
+	r.Get("t", "session2").Contains("c")
+	now = now.Add(20 * time.Second)
+
+	if n := r.Sweep(); n != 1 {
+		t.Errorf("Sweep failed: dropped %d sets, expected 1.\n", n)
+	}
+	if s, ok := dropped["t/session1"]; !ok || !s.IsEqual(New("a", "b")) {
+		t.Errorf("OnDrop failed: got %v.\n", dropped)
+	}
+	if _, ok := r.Lookup("t", "session1"); ok || r.Usage("t") != 1 {
+		t.Errorf("Sweep failed: session1 is still registered.\n")
+	}
+	if err := s1.Add("c"); !errors.Is(err, ErrDropped) || s1.Contains("a") {
+		t.Errorf("Add failed: swept set was modified: %v.\n", err)
+	}
+
+	now = now.Add(time.Hour)
+	r.Sweep()
+	if st := r.Stats(); st.Sets != 0 || st.Tenants != 0 {
+		t.Errorf("Sweep failed: got %+v.\n", st)
+	}
+}
@@ -0,0 +1,522 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+	"strconv"
+)
+
+// ----- RoaringSet definition -----
+
+// A RoaringSet is a compressed set of uint32 values, like the IDs of an
+// analytics workload, in the style of roaring bitmaps. The domain is split
+// into chunks of 2^16 values, and each non-empty chunk is stored in the
+// smallest fitting container: a sorted array of 16 bit values for sparse
+// chunks (2 bytes per element), a bitmap of 8 KiB for dense chunks, or a
+// list of runs for consecutive values, see RunOptimize. A map based set
+// takes about 50 bytes per element instead.
+type RoaringSet struct {
+	keys  []uint16 // the sorted high 16 bits of the chunks
+	conts []container
+}
+
+// the maximum cardinality of an array container
+const arrayMax = 4096
+
+// a container holds the low 16 bits of the values of one chunk
+type container interface {
+	add(x uint16) container // returns the container, possibly converted
+	remove(x uint16) container
+	contains(x uint16) bool
+	card() int
+	all(yield func(uint16) bool) bool
+	toBitmap() *bitmapContainer // returns a new bitmap
+	sizeBytes() int
+}
+
+// ----- constructor -----
+
+// NewRoaring creates a new roaring set and initializes it with the argument
+// values.
+func NewRoaring(e ...uint32) *RoaringSet {
+	s := &RoaringSet{}
+	s.Add(e...)
+	return s
+}
+
+// find returns the index of the chunk with the given key, and whether it
+// exists.
+func (s *RoaringSet) find(key uint16) (int, bool) {
+	return slices.BinarySearch(s.keys, key)
+}
+
+// set stores the container of the chunk at index i, removing the chunk if
+// the container is empty.
+func (s *RoaringSet) set(i int, c container) {
+	if c == nil || c.card() == 0 {
+		s.keys = slices.Delete(s.keys, i, i+1)
+		s.conts = slices.Delete(s.conts, i, i+1)
+		return
+	}
+	s.conts[i] = c
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more values to the given set.
+func (s *RoaringSet) Add(e ...uint32) {
+	for _, x := range e {
+		key, low := uint16(x>>16), uint16(x)
+		i, ok := s.find(key)
+		if !ok {
+			s.keys = slices.Insert(s.keys, i, key)
+			s.conts = slices.Insert(s.conts, i, container(arrayContainer{low}))
+			continue
+		}
+		s.conts[i] = s.conts[i].add(low)
+	}
+}
+
+// AddRange adds all values of the closed interval [lo, hi] to the given set.
+func (s *RoaringSet) AddRange(lo, hi uint32) {
+	for lo <= hi {
+		key := uint16(lo >> 16)
+		end := min(hi, uint32(key)<<16|0xffff)
+		var c container
+		if uint16(lo) == 0 && uint16(end) == 0xffff {
+			c = runContainer{{0, 0xffff}}
+		} else {
+			i, ok := s.find(key)
+			b := &bitmapContainer{}
+			if ok {
+				b = s.conts[i].toBitmap()
+			}
+			for x := lo; ; x++ {
+				b.add(uint16(x))
+				if x == end { // test before incrementing, end may be 2^32-1
+					break
+				}
+			}
+			c = normalize(b)
+		}
+		i, ok := s.find(key)
+		if !ok {
+			s.keys = slices.Insert(s.keys, i, key)
+			s.conts = slices.Insert(s.conts, i, c)
+		} else {
+			s.conts[i] = c
+		}
+		if end == 0xffffffff {
+			return
+		}
+		lo = end + 1
+	}
+}
+
+// Remove removes one or more values from the given set.
+func (s *RoaringSet) Remove(e ...uint32) {
+	for _, x := range e {
+		if i, ok := s.find(uint16(x >> 16)); ok {
+			s.set(i, s.conts[i].remove(uint16(x)))
+		}
+	}
+}
+
+// Clear removes all values from the given set.
+func (s *RoaringSet) Clear() {
+	clear(s.conts) // release the containers
+	s.keys, s.conts = s.keys[:0], s.conts[:0]
+}
+
+// Reset removes all elements from the given set, and replaces its storage
+// with a new one presized for capacityHint chunks of 2^16 values.
+func (s *RoaringSet) Reset(capacityHint int) {
+	n := max(capacityHint, 0)
+	s.keys, s.conts = make([]uint16, 0, n), make([]container, 0, n)
+}
+
+// RunOptimize converts each container into the smallest representation,
+// which turns chunks of consecutive values into lists of runs. Since
+// modifications convert run containers back, it is best called once a set
+// is built.
+func (s *RoaringSet) RunOptimize() {
+	for i, c := range s.conts {
+		if runs := runsOf(c); runs.sizeBytes() < c.sizeBytes() {
+			s.conts[i] = runs
+		}
+	}
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *RoaringSet) IsEmpty() bool {
+	return len(s.keys) == 0
+}
+
+// Len returns the length of the set.
+func (s *RoaringSet) Len() int {
+	n := 0
+	for _, c := range s.conts {
+		n += c.card()
+	}
+	return n
+}
+
+// SizeBytes returns the approximate number of bytes used by the containers.
+func (s *RoaringSet) SizeBytes() int {
+	n := 2 * len(s.keys)
+	for _, c := range s.conts {
+		n += c.sizeBytes()
+	}
+	return n
+}
+
+// Contains checks if a set contains one or more values. The return value
+// is true only if all given values are in the set.
+func (s *RoaringSet) Contains(e ...uint32) bool {
+	for _, x := range e {
+		i, ok := s.find(uint16(x >> 16))
+		if !ok || !s.conts[i].contains(uint16(x)) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEqual tests if two sets are equal.
+func (s *RoaringSet) IsEqual(t *RoaringSet) bool {
+	if !slices.Equal(s.keys, t.keys) {
+		return false
+	}
+	for i, c := range s.conts {
+		if c.card() != t.conts[i].card() || c.toBitmap().words != t.conts[i].toBitmap().words {
+			return false
+		}
+	}
+	return true
+}
+
+// ----- methods that return a new set -----
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *RoaringSet) Copy() *RoaringSet {
+	return s.combine(&RoaringSet{}, true, false, func(a, _ container) container { return a })
+}
+
+// Union returns a new set, which represents the union of two sets.
+func (s *RoaringSet) Union(t *RoaringSet) *RoaringSet {
+	return s.combine(t, true, true, func(a, b container) container {
+		if x, ok := a.(arrayContainer); ok {
+			if y, ok := b.(arrayContainer); ok && len(x)+len(y) <= arrayMax {
+				return arrayContainer(slices.Compact(slices.Sorted(slices.Values(append(slices.Clone(x), y...)))))
+			}
+		}
+		return bitmapOp(a, b, orWords)
+	})
+}
+
+// Intersect returns a new set which represents the intersection of two sets.
+func (s *RoaringSet) Intersect(t *RoaringSet) *RoaringSet {
+	return s.combine(t, false, false, func(a, b container) container {
+		if x, ok := a.(arrayContainer); ok {
+			if y, ok := b.(arrayContainer); ok {
+				return arrayContainer(IntersectSorted(x, y))
+			}
+		}
+		return bitmapOp(a, b, andWords)
+	})
+}
+
+// Diff returns a new set which represents the difference of two sets.
+func (s *RoaringSet) Diff(t *RoaringSet) *RoaringSet {
+	return s.combine(t, true, false, func(a, b container) container {
+		return bitmapOp(a, b, andNotWords)
+	})
+}
+
+// combine builds a new set from the chunks of s and t. Chunks in both sets
+// are combined with f; chunks only in s are copied if keepS is true, chunks
+// only in t if keepT is true.
+func (s *RoaringSet) combine(t *RoaringSet, keepS, keepT bool, f func(a, b container) container) *RoaringSet {
+	r := &RoaringSet{}
+	push := func(key uint16, c container) {
+		if c != nil && c.card() > 0 {
+			r.keys = append(r.keys, key)
+			r.conts = append(r.conts, c)
+		}
+	}
+	i, j := 0, 0
+	for i < len(s.keys) || j < len(t.keys) {
+		switch {
+		case j == len(t.keys) || (i < len(s.keys) && s.keys[i] < t.keys[j]):
+			if keepS {
+				push(s.keys[i], clone(s.conts[i]))
+			}
+			i++
+		case i == len(s.keys) || t.keys[j] < s.keys[i]:
+			if keepT {
+				push(t.keys[j], clone(t.conts[j]))
+			}
+			j++
+		default:
+			push(s.keys[i], f(s.conts[i], t.conts[j]))
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all values in ascending order.
+func (s *RoaringSet) All() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, c := range s.conts {
+			high := uint32(s.keys[i]) << 16
+			if !c.all(func(x uint16) bool { return yield(high | uint32(x)) }) {
+				return
+			}
+		}
+	}
+}
+
+// String returns a textual representation of the set in a string, with the
+// values in ascending order.
+func (s *RoaringSet) String() string {
+	b := []byte("{ ")
+	for x := range s.All() {
+		b = append(strconv.AppendUint(b, uint64(x), 10), ' ')
+	}
+	b = append(b, '}')
+	return string(b)
+}
+
+// ----- container helpers -----
+
+// normalize returns the array form of a sparse bitmap, or nil if it is empty.
+func normalize(b *bitmapContainer) container {
+	switch {
+	case b.n == 0:
+		return nil
+	case b.n <= arrayMax:
+		a := make(arrayContainer, 0, b.n)
+		b.all(func(x uint16) bool { a = append(a, x); return true })
+		return a
+	}
+	return b
+}
+
+// bitmapOp combines two containers as bitmaps with a bitmap kernel.
+func bitmapOp(a, b container, op func(dst, a, b []uint64)) container {
+	x, y := a.toBitmap(), b.toBitmap()
+	op(x.words[:], x.words[:], y.words[:])
+	x.n = popcount(x.words[:])
+	return normalize(x)
+}
+
+// clone returns a deep copy of a container.
+func clone(c container) container {
+	switch c := c.(type) {
+	case arrayContainer:
+		return slices.Clone(c)
+	case runContainer:
+		return slices.Clone(c)
+	}
+	return c.toBitmap()
+}
+
+// runsOf returns the runs of consecutive values of a container.
+func runsOf(c container) runContainer {
+	var r runContainer
+	c.all(func(x uint16) bool {
+		if n := len(r); n > 0 && r[n-1].last+1 == x {
+			r[n-1].last = x
+		} else {
+			r = append(r, run{x, x})
+		}
+		return true
+	})
+	return r
+}
+
+// ----- array container -----
+
+// an arrayContainer is a sorted list of values
+type arrayContainer []uint16
+
+func (a arrayContainer) add(x uint16) container {
+	i, ok := slices.BinarySearch(a, x)
+	if ok {
+		return a
+	}
+	if len(a) >= arrayMax {
+		return a.toBitmap().add(x)
+	}
+	return slices.Insert(a, i, x)
+}
+
+func (a arrayContainer) remove(x uint16) container {
+	if i, ok := slices.BinarySearch(a, x); ok {
+		return slices.Delete(a, i, i+1)
+	}
+	return a
+}
+
+func (a arrayContainer) contains(x uint16) bool {
+	_, ok := slices.BinarySearch(a, x)
+	return ok
+}
+
+func (a arrayContainer) card() int {
+	return len(a)
+}
+
+func (a arrayContainer) all(yield func(uint16) bool) bool {
+	for _, x := range a {
+		if !yield(x) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a arrayContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{n: len(a)}
+	for _, x := range a {
+		b.words[x>>6] |= 1 << (x & 63)
+	}
+	return b
+}
+
+func (a arrayContainer) sizeBytes() int {
+	return 2 * len(a)
+}
+
+// ----- bitmap container -----
+
+// a bitmapContainer is a bitmap of all 2^16 values, with its cardinality
+type bitmapContainer struct {
+	words [1024]uint64
+	n     int
+}
+
+func (b *bitmapContainer) add(x uint16) container {
+	if w := &b.words[x>>6]; *w&(1<<(x&63)) == 0 {
+		*w |= 1 << (x & 63)
+		b.n++
+	}
+	return b
+}
+
+func (b *bitmapContainer) remove(x uint16) container {
+	if w := &b.words[x>>6]; *w&(1<<(x&63)) != 0 {
+		*w &^= 1 << (x & 63)
+		b.n--
+		if b.n <= arrayMax {
+			return normalize(b)
+		}
+	}
+	return b
+}
+
+func (b *bitmapContainer) contains(x uint16) bool {
+	return b.words[x>>6]&(1<<(x&63)) != 0
+}
+
+func (b *bitmapContainer) card() int {
+	return b.n
+}
+
+func (b *bitmapContainer) all(yield func(uint16) bool) bool {
+	for i, w := range b.words {
+		for w != 0 {
+			if !yield(uint16(i<<6 + bits.TrailingZeros64(w))) {
+				return false
+			}
+			w &= w - 1
+		}
+	}
+	return true
+}
+
+func (b *bitmapContainer) toBitmap() *bitmapContainer {
+	c := *b
+	return &c
+}
+
+func (b *bitmapContainer) sizeBytes() int {
+	return 8 * len(b.words)
+}
+
+// ----- run container -----
+
+// a run is a closed interval of consecutive values
+type run struct {
+	start, last uint16
+}
+
+// a runContainer is a sorted list of disjoint, non-adjacent runs
+type runContainer []run
+
+func (r runContainer) add(x uint16) container {
+	if r.contains(x) {
+		return r
+	}
+	return normalize(r.toBitmap()).add(x)
+}
+
+func (r runContainer) remove(x uint16) container {
+	if !r.contains(x) {
+		return r
+	}
+	b := r.toBitmap()
+	b.remove(x)
+	return normalize(b)
+}
+
+func (r runContainer) contains(x uint16) bool {
+	i, _ := slices.BinarySearchFunc(r, x, func(r run, x uint16) int {
+		if r.last < x {
+			return -1
+		}
+		return 1
+	})
+	return i < len(r) && r[i].start <= x
+}
+
+func (r runContainer) card() int {
+	n := 0
+	for _, i := range r {
+		n += int(i.last-i.start) + 1
+	}
+	return n
+}
+
+func (r runContainer) all(yield func(uint16) bool) bool {
+	for _, i := range r {
+		for x := int(i.start); x <= int(i.last); x++ {
+			if !yield(uint16(x)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r runContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{}
+	r.all(func(x uint16) bool { b.add(x); return true })
+	return b
+}
+
+func (r runContainer) sizeBytes() int {
+	return 4 * len(r)
+}
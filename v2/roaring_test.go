@@ -0,0 +1,100 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// roaringOf checks a roaring set against a reference set.
+func roaringOf(t *testing.T, name string, r *RoaringSet, ref Set[uint32]) {
+	t.Helper()
+	l := slices.Collect(r.All())
+	if !slices.IsSorted(l) || r.Len() != ref.Len() || !Collect(slices.Values(l)).IsEqual(ref) {
+		t.Errorf("%s failed: got %d values, expected %d.\n", name, r.Len(), ref.Len())
+	}
+}
+
+func TestRoaringSet(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	a, b := NewRoaring(), NewRoaring()
+	ra, rb := New[uint32](), New[uint32]()
+	for i := 0; i < 20000; i++ {
+		// a dense chunk, a sparse chunk and values all over the domain
+		for _, x := range []uint32{uint32(rng.IntN(10000)), 1<<20 + uint32(rng.IntN(1<<16)), rng.Uint32()} {
+			if i%2 == 0 {
+				a.Add(x)
+				ra.Add(x)
+			} else {
+				b.Add(x)
+				rb.Add(x)
+			}
+		}
+	}
+	roaringOf(t, "Add", a, ra)
+	roaringOf(t, "Union", a.Union(b), ra.Union(rb))
+	roaringOf(t, "Intersect", a.Intersect(b), ra.Intersect(rb))
+	roaringOf(t, "Diff", a.Diff(b), ra.Diff(rb))
+
+	c := a.Copy()
+	for x := range ra.All() {
+		if x%3 == 0 {
+			c.Remove(x)
+		}
+	}
+	roaringOf(t, "Remove", c, ra.Diff(Collect(func(yield func(uint32) bool) {
+		for x := range ra.All() {
+			if x%3 == 0 && !yield(x) {
+				return
+			}
+		}
+	})))
+	roaringOf(t, "Copy", a, ra)
+	if !a.IsEqual(a.Copy()) || a.IsEqual(b) || !a.Contains(ra.List()[:10]...) {
+		t.Errorf("IsEqual/Contains failed.\n")
+	}
+}
+
+func TestRoaringSetRuns(t *testing.T) {
+	s := NewRoaring()
+	s.AddRange(100, 300000)
+	s.Add(5, 1<<31)
+	if s.Len() != 300000-100+1+2 || !s.Contains(100, 65536, 300000, 5, 1<<31) || s.Contains(99, 300001) {
+		t.Errorf("AddRange failed: got %d values.\n", s.Len())
+	}
+	before := s.SizeBytes()
+	s.RunOptimize()
+	if after := s.SizeBytes(); after >= before/100 {
+		t.Errorf("RunOptimize failed: %d bytes before, %d after.\n", before, after)
+	}
+	s.Remove(200)
+	s.Add(200)
+	if s.Contains(99) || !s.Contains(200, 201) || s.Len() != 300000-100+1+2 {
+		t.Errorf("Remove/Add failed on run containers.\n")
+	}
+
+	r := NewRoaring(1, 2, 3)
+	r.AddRange(0xfffffff0, 0xffffffff)
+	if r.Len() != 19 || r.String()[:8] != "{ 1 2 3 " {
+		t.Errorf("AddRange failed at the end of the domain: got %v.\n", r)
+	}
+	r.Clear()
+	if !r.IsEmpty() {
+		t.Errorf("Clear failed.\n")
+	}
+}
+
+func TestRoaringSetSize(t *testing.T) {
+	s := NewRoaring()
+	for i := uint32(0); i < 1000000; i++ {
+		s.Add(i * 97)
+	}
+	if n := s.SizeBytes(); n > 3*1000000 {
+		t.Errorf("SizeBytes failed: %d bytes for 1M sparse values.\n", n)
+	}
+}
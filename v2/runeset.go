@@ -0,0 +1,95 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"unicode"
+)
+
+// ----- RuneSet definition -----
+
+// A RuneSet is a set of Unicode code points, like a character class of a
+// parser. It is an IntervalSet over the universe 0 to unicode.MaxRune, and
+// can be converted from and to a unicode.RangeTable.
+type RuneSet struct {
+	IntervalSet[rune]
+}
+
+// ----- constructors -----
+
+// NewRuneSet creates a new rune set and initializes it with the argument values.
+func NewRuneSet(r ...rune) *RuneSet {
+	s := &RuneSet{IntervalSet[rune]{universe: Range[rune]{0, unicode.MaxRune}}}
+	s.Add(r...)
+	return s
+}
+
+// NewRuneSetFromTables creates a new rune set which contains all code points
+// of the given range tables, like unicode.Letter or unicode.Greek.
+func NewRuneSetFromTables(t ...*unicode.RangeTable) *RuneSet {
+	s := NewRuneSet()
+	s.AddTables(t...)
+	return s
+}
+
+// ----- methods that modify the receiver -----
+
+// AddTables adds all code points of the given range tables to the set.
+func (s *RuneSet) AddTables(t ...*unicode.RangeTable) {
+	for _, i := range t {
+		for _, r := range i.R16 {
+			s.addStride(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+		}
+		for _, r := range i.R32 {
+			s.addStride(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+		}
+	}
+}
+
+func (s *RuneSet) addStride(lo, hi, stride rune) {
+	if stride == 1 {
+		s.AddRange(lo, hi)
+		return
+	}
+	for r := lo; r <= hi; r += stride {
+		s.AddRange(r, r)
+	}
+}
+
+// ----- methods that return a new set -----
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *RuneSet) Copy() *RuneSet {
+	return &RuneSet{*s.IntervalSet.Copy()}
+}
+
+// Complement returns a new set of all code points which are not in s.
+func (s *RuneSet) Complement() *RuneSet {
+	return &RuneSet{*s.IntervalSet.Complement()}
+}
+
+// ----- methods that return other data types -----
+
+// RangeTable returns a unicode.RangeTable with the code points of the set,
+// which can be used with unicode.Is and related functions.
+func (s *RuneSet) RangeTable() *unicode.RangeTable {
+	t := &unicode.RangeTable{}
+	for _, r := range s.ranges {
+		if r.Lo <= 0xFFFF {
+			hi := min(r.Hi, 0xFFFF)
+			t.R16 = append(t.R16, unicode.Range16{Lo: uint16(r.Lo), Hi: uint16(hi), Stride: 1})
+			if hi <= unicode.MaxLatin1 {
+				t.LatinOffset++
+			}
+			if r.Hi <= 0xFFFF {
+				continue
+			}
+			r.Lo = 0x10000
+		}
+		t.R32 = append(t.R32, unicode.Range32{Lo: uint32(r.Lo), Hi: uint32(r.Hi), Stride: 1})
+	}
+	return t
+}
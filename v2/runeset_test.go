@@ -0,0 +1,49 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestRuneSet(t *testing.T) {
+	ident := NewRuneSet('_')
+	ident.AddRange('a', 'z')
+	ident.AddRange('A', 'Z')
+	ident.AddRange(0xFF00, 0x10010) // crosses the 16 bit boundary
+
+	rt := ident.RangeTable()
+	if len(rt.R16) != 4 || len(rt.R32) != 1 || rt.LatinOffset != 3 {
+		t.Errorf("RangeTable failed: got %d/%d ranges and latin offset %d.\n", len(rt.R16), len(rt.R32), rt.LatinOffset)
+	}
+	for _, r := range []rune{'_', 'q', 'Q', 0xFFFF, 0x10000, 0x10010} {
+		if !unicode.Is(rt, r) {
+			t.Errorf("RangeTable failed: %U not in table.\n", r)
+		}
+	}
+	if unicode.Is(rt, '-') || unicode.Is(rt, 0x10011) {
+		t.Errorf("RangeTable failed: table contains extra code points.\n")
+	}
+
+	greek := NewRuneSetFromTables(unicode.Greek)
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if greek.Contains(r) != unicode.Is(unicode.Greek, r) {
+			t.Fatalf("NewRuneSetFromTables failed: wrong membership for %U.\n", r)
+		}
+	}
+	if !NewRuneSetFromTables(greek.RangeTable()).IsEqual(&greek.IntervalSet) {
+		t.Errorf("RangeTable failed: round trip changed the set.\n")
+	}
+
+	c := ident.Complement()
+	if c.Contains('a') || !c.Contains('-', unicode.MaxRune) {
+		t.Errorf("Complement failed: got %v.\n", c)
+	}
+	if c.Complement().Count() != ident.Count() {
+		t.Errorf("Complement failed: double complement differs.\n")
+	}
+}
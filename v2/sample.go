@@ -0,0 +1,155 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"cmp"
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// ----- random sampling -----
+
+// The sampling functions draw from the given random generator, or from the
+// global generator of math/rand/v2 if it is nil. Since sets iterate in an
+// undefined order, a seeded generator does not make the samples
+// reproducible.
+
+// randFloat returns a random number in [0, 1).
+func randFloat(rng *rand.Rand) float64 {
+	if rng == nil {
+		return rand.Float64()
+	}
+	return rng.Float64()
+}
+
+// randIntN returns a random number in [0, n).
+func randIntN(rng *rand.Rand, n int) int {
+	if rng == nil {
+		return rand.IntN(n)
+	}
+	return rng.IntN(n)
+}
+
+// Sample returns k elements of the set drawn uniformly at random without
+// replacement, by reservoir sampling. If the set has at most k elements, all
+// of them are returned.
+func (s Set[T]) Sample(k int, rng *rand.Rand) []T {
+	k = max(k, 0)
+	r := make([]T, 0, min(k, len(s.set)))
+	i := 0
+	for e := range s.set {
+		if len(r) < k {
+			r = append(r, e)
+		} else if j := randIntN(rng, i+1); j < k {
+			r[j] = e
+		}
+		i++
+	}
+	return r
+}
+
+// WeightedSample returns k elements of the set drawn at random without
+// replacement, where the probability of each element is proportional to its
+// weight, by the reservoir algorithm A-Res of Efraimidis and Spirakis.
+// Elements with a weight <= 0 are never drawn.
+func WeightedSample[T comparable](s Set[T], k int, weight func(T) float64, rng *rand.Rand) []T {
+	if k <= 0 {
+		return nil
+	}
+	h := make(keyHeap[T], 0, min(k, len(s.set)))
+	for e := range s.set {
+		w := weight(e)
+		if w <= 0 {
+			continue
+		}
+		// the elements with the largest keys u^(1/w) are the sample; the
+		// logarithm of the key avoids an underflow for small weights
+		key := math.Log(1-randFloat(rng)) / w
+		if len(h) < k {
+			heap.Push(&h, keyed[T]{e, key})
+		} else if key > h[0].key {
+			h[0] = keyed[T]{e, key}
+			heap.Fix(&h, 0)
+		}
+	}
+	r := make([]T, len(h))
+	for i, x := range h {
+		r[i] = x.elem
+	}
+	return r
+}
+
+// keyed is an element with its random key for WeightedSample.
+type keyed[T comparable] struct {
+	elem T
+	key  float64
+}
+
+// keyHeap is a min-heap of keyed elements for WeightedSample.
+type keyHeap[T comparable] []keyed[T]
+
+func (h keyHeap[T]) Len() int           { return len(h) }
+func (h keyHeap[T]) Less(i, j int) bool { return h[i].key < h[j].key }
+func (h keyHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap[T]) Push(x any)        { *h = append(*h, x.(keyed[T])) }
+func (h *keyHeap[T]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// StratifiedSample draws a sample of about n elements from a collection of
+// disjoint sets, the strata, like the partitions of a set of IDs by class.
+// Each stratum contributes at least its minimum, if it has that many
+// elements, and the rest of the sample is allocated proportionally to the
+// remaining sizes of the strata, by the largest remainder method. Within a
+// stratum, the elements are drawn uniformly like with Sample.
+//
+// The sample has exactly n elements, unless the minimums add up to more than
+// n, or the strata have less than n elements in total. Strata missing in
+// minimum have a minimum of 0.
+func StratifiedSample[K, T comparable](strata map[K]Set[T], n int, minimum map[K]int, rng *rand.Rand) map[K][]T {
+	type alloc struct {
+		key       K
+		n, rest   int     // the allocated and the remaining elements
+		remainder float64 // the fractional part of the proportional share
+	}
+	l := make([]*alloc, 0, len(strata))
+	left, rest := n, 0
+	for k, s := range strata {
+		a := &alloc{key: k, n: min(max(minimum[k], 0), s.Len())}
+		a.rest = s.Len() - a.n
+		left -= a.n
+		rest += a.rest
+		l = append(l, a)
+	}
+
+	// distribute the rest of the sample proportionally
+	if left > 0 && rest > 0 {
+		left = min(left, rest)
+		given := 0
+		for _, a := range l {
+			share := float64(left) * float64(a.rest) / float64(rest)
+			a.n += int(share)
+			given += int(share)
+			a.remainder = share - math.Floor(share)
+		}
+		slices.SortFunc(l, func(a, b *alloc) int { return cmp.Compare(b.remainder, a.remainder) })
+		for _, a := range l[:left-given] {
+			a.n++
+		}
+	}
+
+	r := make(map[K][]T, len(l))
+	for _, a := range l {
+		r[a.key] = strata[a.key].Sample(a.n, rng)
+	}
+	return r
+}
@@ -0,0 +1,84 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	s := New[int]()
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+	r := s.Sample(10, rng)
+	if len(r) != 10 || New(r...).Len() != 10 || !s.Contains(r...) {
+		t.Errorf("Sample failed: got %v.\n", r)
+	}
+	if r := New(1, 2).Sample(5, nil); len(r) != 2 {
+		t.Errorf("Sample failed: got %v for a small set.\n", r)
+	}
+
+	// each element is drawn with about the same frequency
+	counts := make([]int, 100)
+	for range 2000 {
+		for _, e := range s.Sample(5, rng) {
+			counts[e]++
+		}
+	}
+	for e, c := range counts {
+		if c < 50 || c > 150 {
+			t.Errorf("Sample failed: element %d drawn %d times.\n", e, c)
+		}
+	}
+}
+
+func TestWeightedSample(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	s := New(0, 1, 2, 3)
+	weight := func(e int) float64 { return float64(e) }
+	counts := make([]int, 4)
+	for range 3000 {
+		r := WeightedSample(s, 1, weight, rng)
+		if len(r) != 1 {
+			t.Fatalf("WeightedSample failed: got %v.\n", r)
+		}
+		counts[r[0]]++
+	}
+	// the expected counts are 0, 500, 1000 and 1500
+	if counts[0] != 0 || counts[1] < 400 || counts[1] > 600 || counts[3] < 1350 || counts[3] > 1650 {
+		t.Errorf("WeightedSample failed: got counts %v.\n", counts)
+	}
+	if r := WeightedSample(s, 10, weight, nil); !New(r...).IsEqual(New(1, 2, 3)) {
+		t.Errorf("WeightedSample failed: got %v.\n", r)
+	}
+}
+
+func TestStratifiedSample(t *testing.T) {
+	strata := map[string]Set[int]{"a": New[int](), "b": New[int](), "c": New(1000, 1001)}
+	for i := 0; i < 900; i++ {
+		strata["a"].Add(i)
+	}
+	for i := 900; i < 990; i++ {
+		strata["b"].Add(i)
+	}
+	r := StratifiedSample(strata, 100, map[string]int{"b": 20, "c": 5}, nil)
+	// c has only 2 elements, and the other 78 elements above the minimums are
+	// shared by the 900 and 70 remaining elements of a and b
+	if len(r["a"]) != 72 || len(r["b"]) != 26 || len(r["c"]) != 2 {
+		t.Errorf("StratifiedSample failed: got %d, %d, %d elements.\n", len(r["a"]), len(r["b"]), len(r["c"]))
+	}
+	if !strata["b"].Contains(r["b"]...) {
+		t.Errorf("StratifiedSample failed: got foreign elements %v.\n", r["b"])
+	}
+
+	r = StratifiedSample(strata, 2000, nil, nil)
+	if len(r["a"])+len(r["b"])+len(r["c"]) != 992 {
+		t.Errorf("StratifiedSample failed: got %d elements for a large sample.\n", len(r["a"])+len(r["b"])+len(r["c"]))
+	}
+}
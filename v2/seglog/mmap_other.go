@@ -0,0 +1,28 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build !unix
+
+package seglog
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads the first size bytes of a file into memory, on platforms
+// without mmap.
+func mapFile(f *os.File, size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(f, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// unmapFile releases the memory of mapFile.
+func unmapFile(b []byte) error {
+	return nil
+}
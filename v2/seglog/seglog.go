@@ -0,0 +1,527 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package seglog provides a persistent, append-only set, stored as a log of
+segment files in a directory. It is meant for ever-growing sets like the keys
+of a deduplication stage, which are too large to be written as full
+snapshots, and too large to be loaded into memory at startup.
+
+New elements are appended in checksummed batches to the active segment, and
+kept in memory as long as it is active. When the active segment reaches its
+size limit, it is sealed: an index of the hashes of its elements is written,
+and its elements are dropped from memory. At startup, the indexes of the
+sealed segments are memory-mapped, so only the active segment is read.
+Contains checks the active segment in memory, and the indexes of the sealed
+segments. Sealed segments are periodically compacted into one segment, to
+keep the number of indexes small.
+
+After a crash, a torn batch at the end of the active segment is discarded,
+and interrupted seals or compactions are repaired. Elements cannot be
+removed.
+*/
+package seglog
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hweidner/set/v2"
+	"github.com/hweidner/set/v2/internal/elemcodec"
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+var (
+	// ErrCorrupt is returned for segment files which are not in the format
+	// of a log.
+	ErrCorrupt = errors.New("seglog: corrupted segment")
+
+	// ErrClosed is returned by the methods of a closed log.
+	ErrClosed = errors.New("seglog: log is closed")
+)
+
+// the defaults of the options
+const (
+	DefaultSegmentSize = 64 << 20
+	DefaultMaxSegments = 16
+)
+
+// Options holds the options of a log.
+type Options struct {
+	// SegmentSize is the size in bytes at which the active segment is
+	// sealed. 0 selects DefaultSegmentSize.
+	SegmentSize int64
+
+	// MaxSegments is the number of sealed segments above which they are
+	// compacted. 0 selects DefaultMaxSegments, and negative values disable
+	// the compaction, which can still be done with Compact.
+	MaxSegments int
+
+	// Sync syncs the active segment to disk after each batch. Without it,
+	// the last batches may be lost in a crash of the system, but not in a
+	// crash of the process.
+	Sync bool
+}
+
+// A Log is a persistent, append-only set of elements, which are stored in the
+// segment files of a directory. The element type must have a stable
+// encoding, like strings, integers, floats and booleans. A log is safe for
+// concurrent use, but a directory must only be opened by one log at a time.
+type Log[T comparable] struct {
+	mu     sync.Mutex
+	dir    string
+	opts   Options
+	sealed []*segment
+	count  int // the number of elements in the sealed segments
+
+	// the active segment
+	active *os.File
+	span   span
+	size   int64
+	elems  map[T]int64 // the elements with the offsets of their encodings
+
+	closed bool
+}
+
+// ----- opening and closing -----
+
+// Open opens the log in a directory, which is created if it does not exist.
+func Open[T comparable](dir string, opts Options) (*Log[T], error) {
+	var zero T
+	if _, ok := elemcodec.AppendValue(nil, zero); !ok {
+		return nil, fmt.Errorf("%w: %T", set.ErrNoStableEncoding, zero)
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+	if opts.MaxSegments == 0 {
+		opts.MaxSegments = DefaultMaxSegments
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	l := &Log[T]{dir: dir, opts: opts}
+	if err := l.load(); err != nil {
+		l.closeFiles()
+		return nil, err
+	}
+	return l, nil
+}
+
+// load opens the segments of the directory and repairs interrupted seals and
+// compactions.
+func (l *Log[T]) load() error {
+	files, err := os.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+	var spans []span
+	indexed := map[span]bool{}
+	for _, f := range files {
+		name := f.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			os.Remove(filepath.Join(l.dir, name))
+		} else if s, ok := parseSpan(name); ok {
+			spans = append(spans, s)
+		} else if s, ok := parseSpan(strings.TrimSuffix(name, ".idx") + ".seg"); ok {
+			indexed[s] = true
+		}
+	}
+
+	// wide spans first, so they supersede the spans they were compacted from
+	slices.SortFunc(spans, func(a, b span) int {
+		return cmp.Or(cmp.Compare(a.first, b.first), cmp.Compare(b.last, a.last))
+	})
+	var keep []span
+	for _, s := range spans {
+		switch {
+		case s.first != s.last && !indexed[s]:
+			// the output of an interrupted compaction
+			l.remove(s)
+		case len(keep) > 0 && keep[len(keep)-1].last >= s.last:
+			// a segment superseded by a compacted one
+			l.remove(s)
+		default:
+			keep = append(keep, s)
+		}
+	}
+	syncDir(l.dir)
+
+	for i, s := range keep {
+		if indexed[s] {
+			g, err := openSegment(l.dir, s)
+			if err == nil {
+				l.sealed = append(l.sealed, g)
+				l.count += g.count
+				continue
+			}
+			if !errors.Is(err, errStaleIndex) {
+				return err
+			}
+		}
+		if err := l.openActive(s); err != nil {
+			return err
+		}
+		// segments without index before the last one are sealed now
+		if i < len(keep)-1 || s.first != s.last {
+			if err := l.seal(); err != nil {
+				return err
+			}
+		}
+	}
+	return l.ensureActive()
+}
+
+// openActive opens or creates the data file of the active segment, loads
+// its elements and truncates its invalid tail.
+func (l *Log[T]) openActive(s span) error {
+	f, err := os.OpenFile(filepath.Join(l.dir, s.name()+".seg"), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	elems := map[T]int64{}
+	valid, err := readBatches(f, st.Size(), func(e T, off int64) bool {
+		elems[e] = off
+		return true
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if valid == 0 {
+		if _, err := f.WriteAt([]byte(dataMagic), 0); err != nil {
+			f.Close()
+			return err
+		}
+		valid = int64(len(dataMagic))
+	}
+	if valid != st.Size() {
+		if err := f.Truncate(valid); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	l.active, l.span, l.size, l.elems = f, s, valid, elems
+	return nil
+}
+
+// ensureActive opens a new active segment after a seal.
+func (l *Log[T]) ensureActive() error {
+	if l.active != nil {
+		return nil
+	}
+	next := uint64(1)
+	if len(l.sealed) > 0 {
+		next = l.sealed[len(l.sealed)-1].span.last + 1
+	}
+	return l.openActive(span{next, next})
+}
+
+// remove removes the files of a segment.
+func (l *Log[T]) remove(s span) {
+	os.Remove(filepath.Join(l.dir, s.name()+".idx"))
+	os.Remove(filepath.Join(l.dir, s.name()+".seg"))
+}
+
+// Close closes the log. The active segment is synced to disk, but not
+// sealed.
+func (l *Log[T]) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	l.closed = true
+	var err error
+	if l.active != nil {
+		err = l.active.Sync()
+	}
+	return errors.Join(err, l.closeFiles())
+}
+
+// closeFiles closes the files of all segments.
+func (l *Log[T]) closeFiles() error {
+	var errs []error
+	for _, g := range l.sealed {
+		errs = append(errs, g.close())
+	}
+	if l.active != nil {
+		errs = append(errs, l.active.Close())
+	}
+	l.sealed, l.active, l.elems = nil, nil, nil
+	return errors.Join(errs...)
+}
+
+// ----- methods that modify the log -----
+
+// Add appends the elements which are not yet in the log in one batch. It
+// returns the number of new elements.
+func (l *Log[T]) Add(e ...T) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, ErrClosed
+	}
+	if err := l.ensureActive(); err != nil {
+		return 0, err
+	}
+
+	var payload []byte
+	var added []T
+	var offs []int64
+	batch := map[T]struct{}{}
+	for _, x := range e {
+		if _, ok := batch[x]; ok {
+			continue
+		}
+		enc, _ := elemcodec.AppendValue(nil, x)
+		ok, err := l.contains(x, enc)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			continue
+		}
+		batch[x] = struct{}{}
+		added = append(added, x)
+		offs = append(offs, int64(len(payload)))
+		payload = append(payload, enc...)
+	}
+	if len(added) == 0 {
+		return 0, nil
+	}
+
+	b := appendBatch(nil, len(added), payload)
+	start := l.size + int64(len(b)-len(payload)-4)
+	if _, err := l.active.WriteAt(b, l.size); err != nil {
+		l.active.Truncate(l.size)
+		return 0, err
+	}
+	if l.opts.Sync {
+		if err := l.active.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	l.size += int64(len(b))
+	for i, x := range added {
+		l.elems[x] = start + offs[i]
+	}
+
+	if l.size >= l.opts.SegmentSize {
+		if err := l.seal(); err != nil {
+			return len(added), err
+		}
+		if err := l.ensureActive(); err != nil {
+			return len(added), err
+		}
+		if l.opts.MaxSegments > 0 && len(l.sealed) > l.opts.MaxSegments {
+			return len(added), l.compact()
+		}
+	}
+	return len(added), nil
+}
+
+// seal writes the index of the active segment, and moves it to the sealed
+// segments. The log has no active segment afterwards.
+func (l *Log[T]) seal() error {
+	entries := make([]entry, 0, len(l.elems))
+	var enc []byte
+	for x, off := range l.elems {
+		enc, _ = elemcodec.AppendValue(enc[:0], x)
+		entries = append(entries, entry{hash.Sum64(enc), off})
+	}
+	if err := l.active.Sync(); err != nil {
+		return err
+	}
+	if err := writeIndex(filepath.Join(l.dir, l.span.name()+".idx"), entries, l.size); err != nil {
+		return err
+	}
+	syncDir(l.dir)
+	g, err := openSegment(l.dir, l.span)
+	if err != nil {
+		return err
+	}
+	l.active.Close()
+	l.sealed = append(l.sealed, g)
+	l.count += g.count
+	l.active, l.elems = nil, nil
+	return nil
+}
+
+// Compact merges all sealed segments into one segment.
+func (l *Log[T]) Compact() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	return l.compact()
+}
+
+// compact merges the sealed segments. The batches of the data files are
+// copied unchanged, so the index entries only need to be moved.
+func (l *Log[T]) compact() error {
+	if len(l.sealed) < 2 {
+		return nil
+	}
+	s := span{l.sealed[0].span.first, l.sealed[len(l.sealed)-1].span.last}
+	path := filepath.Join(l.dir, s.name()+".seg")
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path + ".tmp")
+	entries := make([]entry, 0, l.count)
+	size := int64(len(dataMagic))
+	_, err = f.WriteString(dataMagic)
+	for _, g := range l.sealed {
+		if err != nil {
+			break
+		}
+		shift := size - int64(len(dataMagic))
+		for i := range g.count {
+			entries = append(entries, entry{g.hash(i), g.offset(i) + shift})
+		}
+		var n int64
+		n, err = io.Copy(f, io.NewSectionReader(g.data, int64(len(dataMagic)), g.size-int64(len(dataMagic))))
+		size += n
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err = errors.Join(err, f.Close()); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	// the index completes the compacted segment
+	if err := writeIndex(filepath.Join(l.dir, s.name()+".idx"), entries, size); err != nil {
+		os.Remove(path)
+		return err
+	}
+	syncDir(l.dir)
+	g, err := openSegment(l.dir, s)
+	if err != nil {
+		return err
+	}
+	for _, old := range l.sealed {
+		old.close()
+		l.remove(old.span)
+	}
+	syncDir(l.dir)
+	l.sealed = []*segment{g}
+	return nil
+}
+
+// Sync syncs the active segment to disk.
+func (l *Log[T]) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if l.active == nil {
+		return nil
+	}
+	return l.active.Sync()
+}
+
+// ----- methods that do not modify the log -----
+
+// Contains checks if a log contains one or more elements. The return value
+// is true only if all given elements are in the log.
+func (l *Log[T]) Contains(e ...T) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false, ErrClosed
+	}
+	var enc []byte
+	for _, x := range e {
+		enc, _ = elemcodec.AppendValue(enc[:0], x)
+		if ok, err := l.contains(x, enc); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// contains checks if the element x with the encoding enc is in the log.
+func (l *Log[T]) contains(x T, enc []byte) (bool, error) {
+	if _, ok := l.elems[x]; ok {
+		return true, nil
+	}
+	h := hash.Sum64(enc)
+	for _, g := range slices.Backward(l.sealed) {
+		if ok, err := g.contains(enc, h); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// Len returns the number of elements in the log.
+func (l *Log[T]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.count + len(l.elems)
+}
+
+// Segments returns the number of sealed segments.
+func (l *Log[T]) Segments() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.sealed)
+}
+
+// Walk calls f for all elements of the log in the order they were added,
+// until f returns false. The data files of the sealed segments are read
+// from disk. The log is locked during the walk, so f must not call the
+// methods of the log.
+func (l *Log[T]) Walk(f func(T) bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	done := false
+	visit := func(e T, _ int64) bool {
+		done = !f(e)
+		return !done
+	}
+	for _, g := range l.sealed {
+		if _, err := readBatches(g.data, g.size, visit); err != nil || done {
+			return err
+		}
+	}
+	if l.active == nil {
+		return nil
+	}
+	_, err := readBatches(l.active, l.size, visit)
+	return err
+}
+
+// Set returns the elements of the log in a new set.
+func (l *Log[T]) Set() (set.Set[T], error) {
+	s := set.New[T]()
+	err := l.Walk(func(e T) bool {
+		s.Add(e)
+		return true
+	})
+	return s, err
+}
@@ -0,0 +1,162 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package seglog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+// open opens a log in dir, and fails the test on errors.
+func open(t *testing.T, dir string, opts Options) *Log[string] {
+	t.Helper()
+	l, err := Open[string](dir, opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v.\n", err)
+	}
+	return l
+}
+
+func TestLog(t *testing.T) {
+	dir := t.TempDir()
+	l := open(t, dir, Options{SegmentSize: 200, MaxSegments: -1})
+	want := set.New[string]()
+	for i := 0; i < 100; i++ {
+		k := "key" + strconv.Itoa(i)
+		want.Add(k)
+		if n, err := l.Add(k, k); n != 1 || err != nil {
+			t.Fatalf("Add failed: got %d, %v.\n", n, err)
+		}
+	}
+	if n, _ := l.Add("key5", "key99"); n != 0 {
+		t.Errorf("Add failed: %d existing elements added again.\n", n)
+	}
+	if l.Len() != 100 || l.Segments() < 5 {
+		t.Errorf("Add failed: got %d elements in %d segments.\n", l.Len(), l.Segments())
+	}
+	if ok, err := l.Contains("key0", "key50", "key99"); !ok || err != nil {
+		t.Errorf("Contains failed: got %v, %v.\n", ok, err)
+	}
+	if ok, _ := l.Contains("key100"); ok {
+		t.Errorf("Contains failed: found a missing element.\n")
+	}
+	if s, err := l.Set(); err != nil || !s.IsEqual(want) {
+		t.Errorf("Set failed: got %v, %v.\n", s, err)
+	}
+	var first []string
+	l.Walk(func(e string) bool {
+		first = append(first, e)
+		return len(first) < 3
+	})
+	if len(first) != 3 || first[0] != "key0" || first[2] != "key2" {
+		t.Errorf("Walk failed: got %v.\n", first)
+	}
+
+	// reopening maps the sealed indexes and reads the active segment
+	segs := l.Segments()
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v.\n", err)
+	}
+	if _, err := l.Add("x"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Add failed: got %v for a closed log.\n", err)
+	}
+	l = open(t, dir, Options{SegmentSize: 200, MaxSegments: -1})
+	if s, err := l.Set(); err != nil || !s.IsEqual(want) || l.Len() != 100 || l.Segments() != segs {
+		t.Errorf("Open failed: got %d elements in %d segments, %v.\n", l.Len(), l.Segments(), err)
+	}
+
+	// compaction merges the sealed segments
+	if err := l.Compact(); err != nil || l.Segments() != 1 {
+		t.Fatalf("Compact failed: %d segments, %v.\n", l.Segments(), err)
+	}
+	if ok, err := l.Contains("key0", "key50", "key99"); !ok || err != nil || l.Len() != 100 {
+		t.Errorf("Contains failed after Compact: got %v, %v.\n", ok, err)
+	}
+	l.Add("new")
+	l.Close()
+	l = open(t, dir, Options{SegmentSize: 200, MaxSegments: -1})
+	defer l.Close()
+	if ok, _ := l.Contains("key0", "key99", "new"); !ok || l.Len() != 101 || l.Segments() != 1 {
+		t.Errorf("Open failed after Compact: got %d elements in %d segments.\n", l.Len(), l.Segments())
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 3 {
+		t.Errorf("Compact failed: got files %v.\n", files)
+	}
+}
+
+func TestLogAutoCompact(t *testing.T) {
+	l := open(t, t.TempDir(), Options{SegmentSize: 100, MaxSegments: 3})
+	defer l.Close()
+	for i := 0; i < 200; i++ {
+		l.Add(strconv.Itoa(i))
+	}
+	if l.Segments() > 3 || l.Len() != 200 {
+		t.Errorf("Add failed: got %d elements in %d segments.\n", l.Len(), l.Segments())
+	}
+	if ok, _ := l.Contains("0", "100", "199"); !ok {
+		t.Errorf("Contains failed after compaction.\n")
+	}
+}
+
+func TestLogRecovery(t *testing.T) {
+	dir := t.TempDir()
+	l := open(t, dir, Options{})
+	l.Add("a", "b")
+	l.Add("c")
+	l.Close()
+
+	// a torn batch at the end of the active segment is discarded
+	path := filepath.Join(dir, span{1, 1}.name()+".seg")
+	st, _ := os.Stat(path)
+	os.Truncate(path, st.Size()-2)
+	l = open(t, dir, Options{})
+	if ok, _ := l.Contains("a", "b"); !ok || l.Len() != 2 {
+		t.Errorf("Open failed: got %d elements after a torn batch.\n", l.Len())
+	}
+	if n, err := l.Add("c"); n != 1 || err != nil {
+		t.Errorf("Add failed after recovery: %d, %v.\n", n, err)
+	}
+	l.Close()
+	l = open(t, dir, Options{})
+	if ok, _ := l.Contains("a", "b", "c"); !ok || l.Len() != 3 {
+		t.Errorf("Open failed: got %d elements.\n", l.Len())
+	}
+	l.Close()
+
+	// a missing index is rebuilt, and the output of an interrupted compaction
+	// is removed
+	l = open(t, dir, Options{SegmentSize: 1})
+	l.Add("d")
+	l.Add("e")
+	l.Close()
+	os.Remove(filepath.Join(dir, span{1, 1}.name()+".idx"))
+	os.WriteFile(filepath.Join(dir, span{1, 2}.name()+".seg"), []byte(dataMagic), 0o644)
+	os.WriteFile(filepath.Join(dir, span{2, 2}.name()+".idx.tmp"), nil, 0o644)
+	l = open(t, dir, Options{SegmentSize: 1})
+	defer l.Close()
+	if s, err := l.Set(); err != nil || !s.IsEqual(set.New("a", "b", "c", "d", "e")) {
+		t.Errorf("Open failed: got %v, %v.\n", s, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, span{1, 2}.name()+".seg")); err == nil {
+		t.Errorf("Open failed: incomplete compaction output not removed.\n")
+	}
+
+	// corrupted segments and element types without encoding are rejected
+	bad := t.TempDir()
+	os.WriteFile(filepath.Join(bad, span{1, 1}.name()+".seg"), []byte("garbage"), 0o644)
+	if _, err := Open[string](bad, Options{}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Open failed: got %v for a corrupted segment.\n", err)
+	}
+	if _, err := Open[struct{ a int }](t.TempDir(), Options{}); !errors.Is(err, set.ErrNoStableEncoding) {
+		t.Errorf("Open failed: got %v for a struct type.\n", err)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package seglog
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hweidner/set/v2/internal/elemcodec"
+)
+
+// ----- file formats -----
+
+// A segment consists of a data file and, once it is sealed, an index file.
+//
+// The data file starts with the magic bytes "SEGL\x01", followed by batches
+// of elements. A batch holds the number of elements and the size of the
+// payload as uvarints, the payload with the value encodings of the elements,
+// and the CRC-32C of the payload. A torn or corrupted batch ends the valid
+// part of the file.
+//
+// The index file starts with a header of 24 bytes: the magic bytes "SEGI",
+// 4 reserved bytes, the number of elements and the size of the data file.
+// It is followed by one entry per element of 16 bytes: the stable hash of the
+// encoding and the offset of the encoding in the data file, sorted by hash.
+// All integers are little endian.
+//
+// Segments are named after the range of segment numbers they cover, so a
+// compacted segment supersedes the segments it was merged from.
+
+// the magic bytes of the files
+const (
+	dataMagic  = "SEGL\x01"
+	indexMagic = "SEGI"
+)
+
+// the sizes of the index header and entries
+const (
+	indexHeader = 24
+	indexEntry  = 16
+)
+
+// the CRC-32C table
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// errStaleIndex is returned for an index which does not match its data file.
+var errStaleIndex = errors.New("seglog: stale index")
+
+// a span is the range of segment numbers covered by a segment
+type span struct {
+	first, last uint64
+}
+
+// name returns the file name of the segment without extension.
+func (s span) name() string {
+	return fmt.Sprintf("%016x-%016x", s.first, s.last)
+}
+
+// parseSpan parses the file name of a data file.
+func parseSpan(name string) (span, bool) {
+	base, ok := strings.CutSuffix(name, ".seg")
+	if !ok {
+		return span{}, false
+	}
+	var s span
+	if _, err := fmt.Sscanf(base, "%016x-%016x", &s.first, &s.last); err != nil || s.name() != base ||
+		s.first > s.last {
+		return span{}, false
+	}
+	return s, true
+}
+
+// ----- sealed segments -----
+
+// a segment is a sealed segment with its mapped index
+type segment struct {
+	span  span
+	data  *os.File
+	size  int64 // the size of the data file
+	index []byte
+	count int
+}
+
+// openSegment opens a sealed segment of the directory. It returns
+// errStaleIndex if the index does not match the data file.
+func openSegment(dir string, s span) (*segment, error) {
+	data, err := os.Open(filepath.Join(dir, s.name()+".seg"))
+	if err != nil {
+		return nil, err
+	}
+	st, err := data.Stat()
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+	index, count, err := openIndex(filepath.Join(dir, s.name()+".idx"), st.Size())
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+	return &segment{span: s, data: data, size: st.Size(), index: index, count: count}, nil
+}
+
+// openIndex maps an index file and checks it against the size of its data
+// file.
+func openIndex(path string, size int64) ([]byte, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if st.Size() < indexHeader {
+		return nil, 0, errStaleIndex
+	}
+	b, err := mapFile(f, int(st.Size()))
+	if err != nil {
+		return nil, 0, err
+	}
+	count := binary.LittleEndian.Uint64(b[8:])
+	if string(b[:len(indexMagic)]) != indexMagic || binary.LittleEndian.Uint64(b[16:]) != uint64(size) ||
+		count != uint64(len(b)-indexHeader)/indexEntry || (len(b)-indexHeader)%indexEntry != 0 {
+		unmapFile(b)
+		return nil, 0, errStaleIndex
+	}
+	return b, int(count), nil
+}
+
+// hash returns the hash of the i-th index entry.
+func (g *segment) hash(i int) uint64 {
+	return binary.LittleEndian.Uint64(g.index[indexHeader+i*indexEntry:])
+}
+
+// offset returns the offset of the i-th index entry.
+func (g *segment) offset(i int) int64 {
+	return int64(binary.LittleEndian.Uint64(g.index[indexHeader+i*indexEntry+8:]))
+}
+
+// contains checks if the segment contains the element with the encoding
+// enc. Entries with the same hash are verified against the data file.
+func (g *segment) contains(enc []byte, h uint64) (bool, error) {
+	b := make([]byte, len(enc))
+	for i := sort.Search(g.count, func(i int) bool { return g.hash(i) >= h }); i < g.count && g.hash(i) == h; i++ {
+		if _, err := g.data.ReadAt(b, g.offset(i)); err != nil && err != io.EOF {
+			return false, err
+		}
+		// the encodings are self-delimiting, so an equal prefix is the element
+		if bytes.Equal(b, enc) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// close unmaps the index and closes the data file.
+func (g *segment) close() error {
+	return errors.Join(unmapFile(g.index), g.data.Close())
+}
+
+// ----- reading and writing -----
+
+// an entry of an index
+type entry struct {
+	hash uint64
+	off  int64
+}
+
+// appendBatch appends a batch with n encoded elements in payload to b.
+func appendBatch(b []byte, n int, payload []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(n))
+	b = binary.AppendUvarint(b, uint64(len(payload)))
+	b = append(b, payload...)
+	return binary.LittleEndian.AppendUint32(b, crc32.Checksum(payload, castagnoli))
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// readBatches reads the first size bytes of a data file, and calls fn for
+// each element with the offset of its encoding, until fn returns false. It
+// returns the size of the valid part of the file, which ends before a torn
+// or corrupted batch. The error is only set for I/O errors and data files
+// with a wrong magic.
+func readBatches[T comparable](f *os.File, size int64, fn func(e T, off int64) bool) (int64, error) {
+	cr := &countingReader{r: bufio.NewReaderSize(io.NewSectionReader(f, 0, size), 1<<16)}
+	magic := make([]byte, len(dataMagic))
+	if _, err := io.ReadFull(cr, magic); err != nil {
+		return 0, ioError(err)
+	}
+	if string(magic) != dataMagic {
+		return 0, fmt.Errorf("%w: %s", ErrCorrupt, f.Name())
+	}
+	type elem struct {
+		e   T
+		off int64
+	}
+	var batch []elem
+	for {
+		valid := cr.n
+		n, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return valid, ioError(err)
+		}
+		l, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return valid, ioError(err)
+		}
+		if l > uint64(size-cr.n) || n > l {
+			return valid, nil
+		}
+		start := cr.n
+		payload := make([]byte, l+4)
+		if _, err := io.ReadFull(cr, payload); err != nil {
+			return valid, ioError(err)
+		}
+		payload, sum := payload[:l], payload[l:]
+		if crc32.Checksum(payload, castagnoli) != binary.LittleEndian.Uint32(sum) {
+			return valid, nil
+		}
+		pr := bytes.NewReader(payload)
+		batch = batch[:0]
+		for range n {
+			off := start + int64(len(payload)-pr.Len())
+			e, err := elemcodec.ReadValue[T](pr, len(payload))
+			if err != nil {
+				return valid, nil
+			}
+			batch = append(batch, elem{e, off})
+		}
+		if pr.Len() != 0 {
+			return valid, nil
+		}
+		for _, b := range batch {
+			if !fn(b.e, b.off) {
+				return valid, nil
+			}
+		}
+	}
+}
+
+// ioError filters the errors of truncated input, which end the valid part
+// of a data file.
+func ioError(err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return err
+	}
+	return nil
+}
+
+// writeIndex writes the index of a data file of the given size.
+func writeIndex(path string, entries []entry, size int64) error {
+	slices.SortFunc(entries, func(a, b entry) int {
+		return cmp.Or(cmp.Compare(a.hash, b.hash), cmp.Compare(a.off, b.off))
+	})
+	b := make([]byte, indexHeader, indexHeader+indexEntry*len(entries))
+	copy(b, indexMagic)
+	binary.LittleEndian.PutUint64(b[8:], uint64(len(entries)))
+	binary.LittleEndian.PutUint64(b[16:], uint64(size))
+	for _, e := range entries {
+		b = binary.LittleEndian.AppendUint64(b, e.hash)
+		b = binary.LittleEndian.AppendUint64(b, uint64(e.off))
+	}
+	return writeFile(path, b)
+}
+
+// writeFile writes a file atomically, by writing a temporary file which is
+// renamed when it is complete.
+func writeFile(path string, b []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err = errors.Join(err, f.Close()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// syncDir makes renames and removals in a directory durable. It is best
+// effort, as some platforms cannot sync directories.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package seglog
+
+import "testing"
+
+func TestParseSpan(t *testing.T) {
+	s := span{1, 0x2a}
+	if got, ok := parseSpan(s.name() + ".seg"); !ok || got != s {
+		t.Errorf("parseSpan failed: got %v, %v.\n", got, ok)
+	}
+	for _, n := range []string{"0000000000000001-0000000000000002.idx", "1-2.seg",
+		"0000000000000002-0000000000000001.seg", "0000000000000001-000000000000000G.seg"} {
+		if _, ok := parseSpan(n); ok {
+			t.Errorf("parseSpan failed: %s accepted.\n", n)
+		}
+	}
+}
@@ -12,10 +12,11 @@ language extension in Go 1.18 and higher.
 package set
 
 import (
-	"fmt"
+	"context"
 	"iter"
 	"log"
 	"maps"
+	"slices"
 )
 
 // ----- global state -----
@@ -26,6 +27,7 @@ var deprecation_warning bool = true
 // The Set is implemented as a map without values.
 type Set[T comparable] struct {
 	set map[T]struct{}
+	cfg *config[T] // optional configuration, nil for plain sets
 }
 
 // ----- constructor -----
@@ -40,10 +42,31 @@ func New[T comparable](e ...T) Set[T] {
 	return s
 }
 
+// Collect creates a new set from the values of an iterator, like
+// slices.Values or maps.Keys, without an intermediate slice.
+func Collect[T comparable](seq iter.Seq[T]) Set[T] {
+	s := New[T]()
+	for i := range seq {
+		s.set[i] = struct{}{}
+	}
+	return s
+}
+
 // ----- methods that modify the receiver -----
 
-// Add adds one or more elements to the given set.
+// Add adds one or more elements to the given set. If the set has a
+// canonicalizer, the canonical forms of the elements are added. If the set
+// has a validator, invalid elements are silently rejected; use TryAdd to learn
+// about them.
 func (s Set[T]) Add(e ...T) {
+	if s.set == nil {
+		s.misuse("Add", errUninitialized)
+		return
+	}
+	if s.cfg != nil {
+		s.TryAdd(e...)
+		return
+	}
 	for _, i := range e {
 		s.set[i] = struct{}{}
 	}
@@ -52,15 +75,23 @@ func (s Set[T]) Add(e ...T) {
 // Remove removes one or more elements from the given set.
 func (s Set[T]) Remove(e ...T) {
 	for _, i := range e {
-		delete(s.set, i)
+		delete(s.set, s.canon(i))
 	}
 }
 
 // Clear removes all elements from the given set.
 func (s Set[T]) Clear() {
-	for k := range s.set {
-		delete(s.set, k)
-	}
+	clear(s.set)
+}
+
+// Reset returns a new, empty set with the configuration of s, whose map is
+// presized for capacityHint elements. Unlike Clear, which keeps the memory
+// of the map for reuse, it releases the memory of s once s is no longer
+// used.
+//
+//	s = s.Reset(1000)
+func (s Set[T]) Reset(capacityHint int) Set[T] {
+	return Set[T]{set: make(map[T]struct{}, max(capacityHint, 0)), cfg: s.cfg}
 }
 
 // ----- methods that do not modify the receiver -----
@@ -79,7 +110,7 @@ func (s Set[T]) Len() int {
 // is true only if all given elements are in the set.
 func (s Set[T]) Contains(e ...T) bool {
 	for _, i := range e {
-		if _, ok := s.set[i]; !ok {
+		if _, ok := s.set[s.canon(i)]; !ok {
 			return false
 		}
 	}
@@ -90,7 +121,7 @@ func (s Set[T]) Contains(e ...T) bool {
 // is true if at least one of the given elements is in the set.
 func (s Set[T]) ContainsAny(e ...T) bool {
 	for _, i := range e {
-		if _, ok := s.set[i]; ok {
+		if _, ok := s.set[s.canon(i)]; ok {
 			return true
 		}
 	}
@@ -99,6 +130,9 @@ func (s Set[T]) ContainsAny(e ...T) bool {
 
 // IsEqual tests if two sets are equal.
 func (s Set[T]) IsEqual(t Set[T]) bool {
+	if !s.checkUniverse("IsEqual", t) {
+		return false
+	}
 	if len(s.set) != len(t.set) {
 		return false
 	}
@@ -113,6 +147,9 @@ func (s Set[T]) IsEqual(t Set[T]) bool {
 // IsSubsetOf returns true if the set s is a subset of the set t, e.g. if
 // all elements of s are also in t.
 func (s Set[T]) IsSubsetOf(t Set[T]) bool {
+	if !s.checkUniverse("IsSubsetOf", t) {
+		return false
+	}
 	for k := range s.set {
 		if _, ok := t.set[k]; !ok {
 			return false
@@ -131,7 +168,7 @@ func (s Set[T]) IsSupersetOf(t Set[T]) bool {
 
 // Copy returns a copy of a set. The set s is not modified.
 func (s Set[T]) Copy() Set[T] {
-	r := Set[T]{set: make(map[T]struct{}, len(s.set))}
+	r := Set[T]{set: make(map[T]struct{}, len(s.set)), cfg: s.cfg}
 	for k := range s.set {
 		r.set[k] = struct{}{}
 	}
@@ -139,8 +176,14 @@ func (s Set[T]) Copy() Set[T] {
 }
 
 // Union returns a new set, which represents the union of two or more sets.
-// The sets themselves are not modified.
+// The sets themselves are not modified. All sets must belong to the same
+// universe, if any. The elements of the sets t are added like with Add: in
+// their canonical form, and only if the validator of s accepts them.
 func (s Set[T]) Union(t ...Set[T]) Set[T] {
+	if !s.checkUniverse("Union", t...) {
+		return s.empty()
+	}
+
 	// calculate overall length of sets
 	l := len(s.set)
 	for _, i := range t {
@@ -149,14 +192,22 @@ func (s Set[T]) Union(t ...Set[T]) Set[T] {
 
 	// create result set. As a heuristic, the estimated length is 50% of the sum of the lengths
 	// of each input set.
-	r := Set[T]{set: make(map[T]struct{}, l/2)}
+	r := Set[T]{set: make(map[T]struct{}, l/2), cfg: s.cfg}
 
 	for k := range s.set {
 		r.set[k] = struct{}{}
 	}
 	for _, i := range t {
+		if s.cfg == nil || i.cfg == s.cfg {
+			for k := range i.set {
+				r.set[k] = struct{}{}
+			}
+			continue
+		}
 		for k := range i.set {
-			r.set[k] = struct{}{}
+			if k, err := s.admit(k); err == nil {
+				r.set[k] = struct{}{}
+			}
 		}
 	}
 	return r
@@ -165,7 +216,16 @@ func (s Set[T]) Union(t ...Set[T]) Set[T] {
 // Intersect returns a new set which represents the intersection of two or more sets.
 // The sets themselves are not modified.
 func (s Set[T]) Intersect(t ...Set[T]) Set[T] {
-	r := Set[T]{set: make(map[T]struct{}, len(s.set))}
+	if !s.checkUniverse("Intersect", t...) {
+		return s.empty()
+	}
+	r := Set[T]{set: make(map[T]struct{}, len(s.set)), cfg: s.cfg}
+	if s.cfg != nil && s.cfg.canon != nil {
+		t = slices.Clone(t)
+		for j, i := range t {
+			t[j] = s.canonical(i)
+		}
+	}
 next_s_elem:
 	for k := range s.set {
 		for _, i := range t {
@@ -181,7 +241,11 @@ next_s_elem:
 // Diff returns a new set which represents the difference of two sets.
 // The sets themselves are not modified.
 func (s Set[T]) Diff(t Set[T]) Set[T] {
-	r := Set[T]{set: make(map[T]struct{}, len(s.set))}
+	if !s.checkUniverse("Diff", t) {
+		return s.empty()
+	}
+	t = s.canonical(t)
+	r := Set[T]{set: make(map[T]struct{}, len(s.set)), cfg: s.cfg}
 	for k := range s.set {
 		if _, ok := t.set[k]; !ok {
 			r.set[k] = struct{}{}
@@ -191,12 +255,19 @@ func (s Set[T]) Diff(t Set[T]) Set[T] {
 }
 
 // SymDiff returns a new set which represents the symmetric difference of two
-// sets. The sets themselves are not modified.
+// sets. The sets themselves are not modified. The elements of t are added
+// like with Add.
 func (s Set[T]) SymDiff(t Set[T]) Set[T] {
+	if !s.checkUniverse("SymDiff", t) {
+		return s.empty()
+	}
 	r := s.Copy()
-	for k := range t.set {
+	admitted := s.cfg == nil || t.cfg == s.cfg
+	for k := range s.canonical(t).set {
 		if _, ok := s.set[k]; !ok {
-			r.set[k] = struct{}{}
+			if admitted || s.check(k) == nil {
+				r.set[k] = struct{}{}
+			}
 		} else {
 			delete(r.set, k)
 		}
@@ -214,13 +285,35 @@ func (s Set[T]) All() iter.Seq[T] {
 // ----- methods that return other data types -----
 
 // List returns an unsorted list of the set elements in a slice.
+// To fill an existing slice, use AppendTo; to pass the elements to a function
+// consuming an iter.Seq[T], use All. Both avoid an intermediate copy.
 func (s Set[T]) List() []T {
-	r := make([]T, 0, len(s.set))
+	return s.AppendTo(make([]T, 0, len(s.set)))
+}
 
+// AppendTo appends the set elements in an undefined order to dst and returns
+// the extended slice. The slice is grown at most once.
+func (s Set[T]) AppendTo(dst []T) []T {
+	dst = slices.Grow(dst, len(s.set))
 	for k := range s.set {
-		r = append(r, k)
+		dst = append(dst, k)
 	}
-	return r
+	return dst
+}
+
+// Send sends the set elements in an undefined order to the channel ch, for
+// consumers which expect a channel. Unlike Iterator, it runs in the calling
+// goroutine and cannot leak it. If the context is cancelled before all
+// elements are sent, the context error is returned.
+func (s Set[T]) Send(ctx context.Context, ch chan<- T) error {
+	for k := range s.set {
+		select {
+		case ch <- k:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
 }
 
 // Iterator returns a channel that can be used to iterate over the set. A second
@@ -228,7 +321,8 @@ func (s Set[T]) List() []T {
 // the done channel.
 //
 // Deprecated: The Iterator method will be removed in a future release.
-// Use the All() method to iterate over the set instead.
+// Use the All() method to iterate over the set instead, or Send to feed an
+// existing channel.
 func (s Set[T]) Iterator() (iterch <-chan T, donech chan<- struct{}) {
 	// TODO: remove this function before the release of v2
 	if deprecation_warning {
@@ -254,10 +348,9 @@ func (s Set[T]) Iterator() (iterch <-chan T, donech chan<- struct{}) {
 // String returns a textual representation of the set in a string.
 // It is there for implementing the fmt.Stringer interface to prettyprint the set.
 func (s Set[T]) String() string {
-	str := "{ "
-	for k := range s.set {
-		str += fmt.Sprint(k) + " "
-	}
-	str += "}"
-	return str
+	b := make([]byte, 0, 2+8*len(s.set))
+	b = append(b, "{ "...)
+	b = appendElems(b, s.set)
+	b = append(b, '}')
+	return string(b)
 }
@@ -6,6 +6,7 @@
 package set
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"testing"
@@ -126,3 +127,103 @@ func TestIterator(t *testing.T) {
 		t.Errorf("Iterator failed: got %d iterations and sum %d, expected %d iterations and sum %d", num, sum, 8, 33)
 	}
 }
+
+func TestCollect(t *testing.T) {
+	a := Collect(slices.Values([]int{3, 1, 3, 2}))
+	if !a.IsEqual(New(1, 2, 3)) {
+		t.Errorf("Collect failed: got %v, expected %v", a, New(1, 2, 3))
+	}
+	// early termination of the consumer does not leak anything
+	num := 0
+	for range a.All() {
+		num++
+		break
+	}
+	if b := Collect(New(1, 2, 3).All()); num != 1 || !b.IsEqual(a) {
+		t.Errorf("Collect failed on All: got %v", b)
+	}
+}
+
+func TestAppendToSend(t *testing.T) {
+	a := New(1, 2, 3)
+	l := a.AppendTo([]int{0})
+	slices.Sort(l)
+	if !slices.Equal(l, []int{0, 1, 2, 3}) {
+		t.Errorf("AppendTo failed: got %v.\n", l)
+	}
+
+	ch := make(chan int, 3)
+	if err := a.Send(context.Background(), ch); err != nil {
+		t.Errorf("Send failed: %v.\n", err)
+	}
+	close(ch)
+	sum := 0
+	for i := range ch {
+		sum += i
+	}
+	if sum != 6 {
+		t.Errorf("Send failed: got sum %d, expected 6.\n", sum)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.Send(ctx, make(chan int)); err != context.Canceled {
+		t.Errorf("Send failed: expected context.Canceled, got %v.\n", err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	s := NewWith(WithCanonicalizer(func(i int) int { return i % 10 }))
+	s.Add(1, 2, 13)
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Errorf("Clear failed: got %v.\n", s)
+	}
+	s.Add(1, 2)
+	r := s.Reset(100)
+	r.Add(15)
+	if !r.IsEqual(New(5)) || !s.IsEqual(New(1, 2)) {
+		t.Errorf("Reset failed: got %v/%v.\n", r, s)
+	}
+
+	sy := NewSync(1, 2, 3)
+	sy.Reset(10)
+	if !sy.IsEmpty() {
+		t.Errorf("SyncSet.Reset failed.\n")
+	}
+	o := NewOrdered(3, 1, 2)
+	o.Reset(10)
+	o.Add(5)
+	if !slices.Equal(o.List(), []int{5}) {
+		t.Errorf("OrderedSet.Reset failed: got %v.\n", o.List())
+	}
+	b := NewBitSet(1, 500)
+	b.Reset(128)
+	if !b.IsEmpty() || len(b.words) != 2 {
+		t.Errorf("BitSet.Reset failed: %d words.\n", len(b.words))
+	}
+	iv := NewIntervalSet(1, 2, 3)
+	iv.Reset(4)
+	if !iv.IsEmpty() || cap(iv.ranges) != 4 {
+		t.Errorf("IntervalSet.Reset failed.\n")
+	}
+	ro := NewRoaring(1, 1<<20)
+	ro.Reset(2)
+	ro.Add(7)
+	if ro.Len() != 1 || !ro.Contains(7) {
+		t.Errorf("RoaringSet.Reset failed.\n")
+	}
+	f := NewFrecency[int](time.Hour, 0.1)
+	f.Touch(1)
+	f.Reset(10)
+	if f.Len() != 0 || f.touches != 0 {
+		t.Errorf("FrecencySet.Reset failed.\n")
+	}
+	sh := NewSharded[int](4)
+	sh.Add(1, 2, 3)
+	sh.Reset(100)
+	sh.Add(4)
+	if sh.Len() != 1 || !sh.Contains(4) {
+		t.Errorf("ShardedSet.Reset failed.\n")
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package setbench provides reusable benchmark scenarios for set implementations,
+so backends can be compared on the hardware and data shapes of the user.
+
+A benchmark of a backend is a one-liner in a _test.go file:
+
+	func BenchmarkMySet(b *testing.B) {
+		setbench.Run(b, func() settest.Target { return mysets.New() })
+	}
+*/
+package setbench
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hweidner/set/v2/settest"
+)
+
+// A Factory creates a new, empty set of the backend under test.
+type Factory func() settest.Target
+
+// A Scenario is a benchmark of one operation at one size and overlap ratio.
+type Scenario struct {
+	Name    string
+	Size    int     // the number of elements of each input set
+	Overlap float64 // the fraction of elements the input sets share
+	Run     func(b *testing.B, f Factory)
+}
+
+// the parameters of the scenarios
+var (
+	sizes    = []int{100, 10000, 100000}
+	overlaps = []float64{0, 0.5, 1}
+)
+
+// Scenarios returns the benchmark scenarios: building a set, and the union
+// and intersection of two sets, at several sizes and overlap ratios.
+func Scenarios() []Scenario {
+	var sc []Scenario
+	for _, n := range sizes {
+		sc = append(sc, Scenario{Name: fmt.Sprintf("build/n=%d", n), Size: n, Run: build(n)})
+		for _, o := range overlaps {
+			sc = append(sc,
+				Scenario{Name: fmt.Sprintf("union/n=%d/overlap=%.1f", n, o), Size: n, Overlap: o, Run: union(n, o)},
+				Scenario{Name: fmt.Sprintf("intersect/n=%d/overlap=%.1f", n, o), Size: n, Overlap: o, Run: intersect(n, o)},
+			)
+		}
+	}
+	return sc
+}
+
+// Run runs all scenarios as sub-benchmarks of b, reporting allocations.
+func Run(b *testing.B, f Factory) {
+	for _, sc := range Scenarios() {
+		b.Run(sc.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			sc.Run(b, f)
+		})
+	}
+}
+
+// inputs returns two sets of n elements each, sharing the given fraction of
+// their elements.
+func inputs(f Factory, n int, overlap float64) (settest.Target, settest.Target) {
+	a, c := f(), f()
+	shared := int(float64(n) * overlap)
+	for i := 0; i < n; i++ {
+		a.Add(i)
+		if i < shared {
+			c.Add(i)
+		} else {
+			c.Add(n + i)
+		}
+	}
+	return a, c
+}
+
+// build benchmarks adding n distinct elements to a new set.
+func build(n int) func(*testing.B, Factory) {
+	return func(b *testing.B, f Factory) {
+		for i := 0; i < b.N; i++ {
+			s := f()
+			for j := 0; j < n; j++ {
+				s.Add(j)
+			}
+		}
+	}
+}
+
+// union benchmarks the union of two sets into a new set.
+func union(n int, overlap float64) func(*testing.B, Factory) {
+	return func(b *testing.B, f Factory) {
+		a, c := inputs(f, n, overlap)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r := f()
+			for e := range a.All() {
+				r.Add(e)
+			}
+			for e := range c.All() {
+				r.Add(e)
+			}
+		}
+	}
+}
+
+// intersect benchmarks the intersection of two sets into a new set.
+func intersect(n int, overlap float64) func(*testing.B, Factory) {
+	return func(b *testing.B, f Factory) {
+		a, c := inputs(f, n, overlap)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r := f()
+			for e := range a.All() {
+				if c.Contains(e) {
+					r.Add(e)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package setbench
+
+import (
+	"testing"
+
+	"github.com/hweidner/set/v2"
+	"github.com/hweidner/set/v2/settest"
+)
+
+func newSet() settest.Target { return set.New[int]() }
+
+func TestScenarios(t *testing.T) {
+	sc := Scenarios()
+	if len(sc) != len(sizes)*(1+2*len(overlaps)) {
+		t.Errorf("Scenarios failed: got %d scenarios.\n", len(sc))
+	}
+	a, c := inputs(newSet, 100, 0.5)
+	r := set.Collect(a.All()).Intersect(set.Collect(c.All()))
+	if r.Len() != 50 {
+		t.Errorf("inputs failed: got an overlap of %d elements.\n", r.Len())
+	}
+	// the smallest union scenario runs without failing
+	if testing.Short() {
+		return
+	}
+	res := testing.Benchmark(func(b *testing.B) { sc[1].Run(b, newSet) })
+	if res.N == 0 {
+		t.Errorf("Scenario %s failed: no iterations.\n", sc[1].Name)
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	Run(b, newSet)
+}
+
+func BenchmarkSyncSet(b *testing.B) {
+	Run(b, func() settest.Target { return set.NewSync[int]() })
+}
+
+func BenchmarkSortedSet(b *testing.B) {
+	Run(b, func() settest.Target { return set.NewSorted[int]() })
+}
@@ -0,0 +1,155 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package setbench
+
+import (
+	"iter"
+	"math/rand/v2"
+	"sync"
+	"testing"
+
+	"github.com/hweidner/set/v2/settest"
+)
+
+// ----- workload generator -----
+
+// A Distribution is the distribution of the elements of a workload.
+type Distribution int
+
+const (
+	Uniform Distribution = iota // all elements are equally likely
+	Zipf                        // few hot elements, with zipfian skew
+)
+
+// A Churn is the pattern of additions and removals of a workload.
+type Churn int
+
+const (
+	// RandomChurn adds and removes random elements.
+	RandomChurn Churn = iota
+
+	// SlidingChurn adds ever new elements and removes the oldest ones, so
+	// the set holds a sliding window of the given domain size.
+	SlidingChurn
+)
+
+// A Workload describes a reproducible random workload of set operations.
+// Workloads with the same parameters generate the same operations.
+type Workload struct {
+	Seed    uint64
+	Ops     int          // the number of operations
+	Domain  int          // the number of distinct elements, or the window size
+	Dist    Distribution // the distribution of the elements
+	Skew    float64      // the zipfian skew, > 1; defaults to 1.1
+	Churn   Churn
+	Reads   float64 // the fraction of Contains operations
+	Removes float64 // the fraction of Remove operations among the others
+}
+
+// Generate returns an iterator over the operations of the workload. The
+// operations are Add, Remove and Contains with a single element each.
+func (w Workload) Generate() iter.Seq[settest.Op] {
+	return func(yield func(settest.Op) bool) {
+		rng := rand.New(rand.NewPCG(w.Seed, w.Seed^0x9e3779b97f4a7c15))
+		domain := max(w.Domain, 1)
+		var zipf *rand.Zipf
+		if w.Dist == Zipf {
+			skew := w.Skew
+			if skew <= 1 {
+				skew = 1.1
+			}
+			zipf = rand.NewZipf(rng, skew, 1, uint64(domain-1))
+		}
+		pick := func() int {
+			if zipf != nil {
+				return int(zipf.Uint64())
+			}
+			return rng.IntN(domain)
+		}
+
+		next := 0 // the next new element of a sliding window
+		for range w.Ops {
+			var o settest.Op
+			switch r := rng.Float64(); {
+			case r < w.Reads:
+				e := pick()
+				if w.Churn == SlidingChurn {
+					e = next - 1 - e
+				}
+				o = settest.Op{Kind: settest.OpContains, Elems: []int{e}}
+			case w.Churn == SlidingChurn:
+				o = settest.Op{Kind: settest.OpAdd, Elems: []int{next}}
+				if next >= domain {
+					if !yield(o) {
+						return
+					}
+					o = settest.Op{Kind: settest.OpRemove, Elems: []int{next - domain}}
+				}
+				next++
+			case rng.Float64() < w.Removes:
+				o = settest.Op{Kind: settest.OpRemove, Elems: []int{pick()}}
+			default:
+				o = settest.Op{Kind: settest.OpAdd, Elems: []int{pick()}}
+			}
+			if !yield(o) {
+				return
+			}
+		}
+	}
+}
+
+// RunWorkload benchmarks replaying the workload against a new set of the
+// backend. The operations are generated once, before the timer starts.
+func RunWorkload(b *testing.B, f Factory, w Workload) {
+	var ops []settest.Op
+	for o := range w.Generate() {
+		ops = append(ops, o)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := f()
+		for _, o := range ops {
+			settest.Apply(s, o)
+		}
+	}
+}
+
+// Stress soak-tests a concurrent set implementation, by replaying the given
+// number of workloads concurrently against the same set. Workload i is w
+// with the seed w.Seed+i. Afterwards, the length of the set must match the
+// number of elements it yields, and the elements must be from the domains
+// of the workloads; violations are reported with t.Errorf.
+func Stress(t testing.TB, s settest.Target, workers int, w Workload) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := range workers {
+		wi := w
+		wi.Seed += uint64(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for o := range wi.Generate() {
+				settest.Apply(s, o)
+			}
+		}()
+	}
+	wg.Wait()
+
+	n, bound := 0, w.Domain
+	if w.Churn == SlidingChurn {
+		bound = w.Ops
+	}
+	for e := range s.All() {
+		n++
+		if e < 0 || e >= bound {
+			t.Errorf("setbench: element %d outside of the workload domain", e)
+		}
+	}
+	if n != s.Len() {
+		t.Errorf("setbench: Len is %d, but the set yields %d elements", s.Len(), n)
+	}
+}
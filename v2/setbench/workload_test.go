@@ -0,0 +1,52 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package setbench
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+	"github.com/hweidner/set/v2/settest"
+)
+
+func TestWorkload(t *testing.T) {
+	w := Workload{Seed: 7, Ops: 2000, Domain: 100, Dist: Zipf, Reads: 0.5, Removes: 0.3}
+	a, b := slices.Collect(w.Generate()), slices.Collect(w.Generate())
+	if len(a) != 2000 || !reflect.DeepEqual(a, b) {
+		t.Fatalf("Generate failed: workload is not reproducible.\n")
+	}
+	w.Seed++
+	if reflect.DeepEqual(a, slices.Collect(w.Generate())) {
+		t.Errorf("Generate failed: the seed has no effect.\n")
+	}
+
+	// zipfian skew makes element 0 the most frequent
+	count := map[int]int{}
+	for _, o := range a {
+		count[o.Elems[0]]++
+	}
+	if count[0] < count[50]*5 {
+		t.Errorf("Generate failed: no skew, %d vs %d.\n", count[0], count[50])
+	}
+
+	// the sliding window never holds more than the domain size
+	s := set.New[int]()
+	sw := Workload{Seed: 1, Ops: 1000, Domain: 10, Churn: SlidingChurn}
+	if err := settest.Run(s, slices.Collect(sw.Generate())); err != nil || s.Len() != 10 {
+		t.Errorf("Generate failed for sliding churn: %v, length %d.\n", err, s.Len())
+	}
+}
+
+func TestStress(t *testing.T) {
+	Stress(t, set.NewSync[int](), 8, Workload{Seed: 1, Ops: 5000, Domain: 500, Reads: 0.3, Removes: 0.4})
+	Stress(t, set.NewSync[int](), 4, Workload{Seed: 1, Ops: 2000, Domain: 50, Churn: SlidingChurn})
+}
+
+func BenchmarkWorkload(b *testing.B) {
+	RunWorkload(b, newSet, Workload{Seed: 1, Ops: 100000, Domain: 10000, Dist: Zipf, Reads: 0.8, Removes: 0.5})
+}
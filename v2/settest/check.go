@@ -0,0 +1,118 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package settest
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// ----- consistency checker -----
+
+// scenarios are hand written operation sequences for the corner cases of the
+// set semantics.
+var scenarios = [][]Op{
+	// empty set
+	{{Kind: OpLen}, {Kind: OpContains}, {Kind: OpContains, Elems: []int{0}}},
+	// duplicates in the arguments and repeated additions
+	{{Kind: OpAdd, Elems: []int{1, 1, 2}}, {Kind: OpAdd, Elems: []int{2}}, {Kind: OpLen}},
+	// removal of absent elements
+	{{Kind: OpRemove, Elems: []int{5}}, {Kind: OpAdd, Elems: []int{5}}, {Kind: OpRemove, Elems: []int{5, 5, 6}}, {Kind: OpLen}},
+	// Contains is true only if all elements are present
+	{{Kind: OpAdd, Elems: []int{1, 2}}, {Kind: OpContains, Elems: []int{1, 3}}, {Kind: OpContains, Elems: []int{2, 1}}},
+	// negative elements and zero
+	{{Kind: OpAdd, Elems: []int{-128, 0, 127}}, {Kind: OpCheck}, {Kind: OpIntersect, Elems: []int{0}}, {Kind: OpCheck}},
+	// Clear and reuse
+	{{Kind: OpAdd, Elems: []int{1, 2, 3}}, {Kind: OpClear}, {Kind: OpLen}, {Kind: OpUnion, Elems: []int{4}}, {Kind: OpCheck}},
+}
+
+// randomRuns is the number of random operation sequences of CheckBackend.
+const randomRuns = 200
+
+// A CheckOption configures CheckBackend.
+type CheckOption func(*checkConfig)
+
+type checkConfig struct {
+	lo, hi  int // the domain of the elements
+	bounded bool
+}
+
+// InDomain restricts the elements of the operations of CheckBackend to the
+// range [lo, hi], for set implementations which hold only some integers,
+// like set.BitSet, which ignores negative numbers, or set.SparseSet. The
+// elements of the hand written sequences outside of the domain are left
+// out.
+func InDomain(lo, hi int) CheckOption {
+	return func(c *checkConfig) {
+		c.lo, c.hi, c.bounded = lo, hi, true
+	}
+}
+
+// CheckBackend verifies that a set implementation satisfies the semantics of
+// package set, by running hand written and random operation sequences
+// against it and the reference model. Each sequence starts with a call to
+// Clear. Differences are reported with t.Errorf, together with the encoded
+// sequence, which can be added to the fuzzing corpus.
+func CheckBackend(t testing.TB, impl Target, opts ...CheckOption) {
+	t.Helper()
+	c := checkConfig{lo: -4, hi: 11}
+	for _, o := range opts {
+		o(&c)
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	var runs [][]Op
+	for _, ops := range scenarios {
+		runs = append(runs, c.restrict(ops))
+	}
+	for range randomRuns {
+		runs = append(runs, c.randomOps(rng, 1+rng.IntN(40)))
+	}
+	for _, ops := range runs {
+		impl.Clear()
+		if err := Run(impl, ops); err != nil {
+			t.Errorf("%v (sequence %q)", err, Encode(ops))
+		}
+	}
+}
+
+// restrict returns the operations without the elements outside of the
+// domain.
+func (c checkConfig) restrict(ops []Op) []Op {
+	if !c.bounded {
+		return ops
+	}
+	r := make([]Op, len(ops))
+	for i, o := range ops {
+		r[i] = Op{Kind: o.Kind}
+		for _, e := range o.Elems {
+			if e >= c.lo && e <= c.hi {
+				r[i].Elems = append(r[i].Elems, e)
+			}
+		}
+	}
+	return r
+}
+
+// randomOps returns n random operations on a small range of the domain, so
+// that additions and removals collide often.
+func (c checkConfig) randomOps(rng *rand.Rand, n int) []Op {
+	lo, hi := c.lo, c.hi
+	if c.bounded {
+		lo, hi = max(lo, -4), min(hi, max(lo, -4)+15)
+	}
+	ops := make([]Op, n)
+	for i := range ops {
+		o := Op{Kind: OpKind(rng.IntN(int(numOps)))}
+		if hi >= lo {
+			o.Elems = make([]int, rng.IntN(maxElems+1))
+		}
+		for j := range o.Elems {
+			o.Elems[j] = lo + rng.IntN(hi-lo+1)
+		}
+		ops[i] = o
+	}
+	return ops
+}
@@ -0,0 +1,44 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package settest
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+// recorder is a testing.TB which records the reported errors.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, format)
+}
+
+func TestCheckBackend(t *testing.T) {
+	CheckBackend(t, set.New[int]())
+	CheckBackend(t, set.NewSync[int]())
+	CheckBackend(t, set.NewSorted[int]())
+	CheckBackend(t, set.NewOrdered[int]())
+	CheckBackend(t, set.NewSharded[int](4))
+	CheckBackend(t, set.NewGeneration[int]())
+	CheckBackend(t, set.NewBitSet(), InDomain(0, math.MaxInt))
+	CheckBackend(t, set.NewSparse[int](128), InDomain(0, 127))
+	CheckBackend(t, make(Model))
+
+	r := &recorder{TB: t}
+	CheckBackend(r, lossy{make(Model)})
+	if len(r.errs) == 0 || !strings.Contains(r.errs[0], "sequence") {
+		t.Errorf("CheckBackend failed: broken backend not detected.\n")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package settest provides tools for testing set implementations against the
+semantics of package set.
+
+Sequences of set operations have a compact binary encoding, so they can be
+generated by a fuzzer. Run applies a sequence of operations both to the set
+under test and to a naive reference model, and reports the first difference.
+*/
+package settest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ----- operations -----
+
+// An OpKind is the kind of a set operation.
+type OpKind uint8
+
+const (
+	OpAdd       OpKind = iota // add the elements
+	OpRemove                  // remove the elements
+	OpClear                   // remove all elements
+	OpContains                // check if all elements are contained
+	OpLen                     // check the number of elements
+	OpUnion                   // unite with the set of the elements
+	OpIntersect               // intersect with the set of the elements
+	OpCheck                   // compare all elements
+	numOps
+)
+
+var opNames = [...]string{"Add", "Remove", "Clear", "Contains", "Len", "Union", "Intersect", "Check"}
+
+func (k OpKind) String() string {
+	if k < numOps {
+		return opNames[k]
+	}
+	return fmt.Sprintf("OpKind(%d)", k)
+}
+
+// An Op is a set operation with its arguments.
+type Op struct {
+	Kind  OpKind
+	Elems []int
+}
+
+func (o Op) String() string {
+	var b strings.Builder
+	b.WriteString(o.Kind.String())
+	b.WriteByte('(')
+	for i, e := range o.Elems {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprint(&b, e)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// maxElems is the maximum number of arguments of an encoded operation.
+const maxElems = 7
+
+// ----- encoding -----
+
+// Encode encodes a sequence of operations. Each operation takes one byte for
+// the kind and the number of arguments, and one byte per argument. Arguments
+// are truncated to int8, and at most 7 arguments are encoded.
+func Encode(ops []Op) []byte {
+	var b []byte
+	for _, o := range ops {
+		n := min(len(o.Elems), maxElems)
+		b = append(b, byte(o.Kind)<<3|byte(n))
+		for _, e := range o.Elems[:n] {
+			b = append(b, byte(int8(e)))
+		}
+	}
+	return b
+}
+
+// Decode decodes a sequence of operations. Every input is valid, so it can
+// be used with random data from a fuzzer: unknown kinds are mapped to known
+// ones, and a truncated last operation keeps the arguments present.
+func Decode(data []byte) []Op {
+	var ops []Op
+	for len(data) > 0 {
+		kind, n := OpKind(data[0]>>3)%numOps, int(data[0]&7)
+		data = data[1:]
+		n = min(n, len(data))
+		o := Op{Kind: kind, Elems: make([]int, n)}
+		for i := range n {
+			o.Elems[i] = int(int8(data[i]))
+		}
+		data = data[n:]
+		ops = append(ops, o)
+	}
+	return ops
+}
@@ -0,0 +1,35 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package settest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	ops := []Op{
+		{Kind: OpAdd, Elems: []int{1, 2, -3}},
+		{Kind: OpClear, Elems: []int{}},
+		{Kind: OpIntersect, Elems: []int{127, -128}},
+	}
+	data := Encode(ops)
+	if len(data) != 8 {
+		t.Errorf("Encode failed: got %d bytes, expected 8.\n", len(data))
+	}
+	if got := Decode(data); !reflect.DeepEqual(got, ops) {
+		t.Errorf("Decode failed: got %v, expected %v.\n", got, ops)
+	}
+
+	// arbitrary input decodes, with a truncated last operation
+	got := Decode([]byte{0xff, 5})
+	if len(got) != 1 || got[0].Kind >= numOps || len(got[0].Elems) != 1 {
+		t.Errorf("Decode failed on random input: got %v.\n", got)
+	}
+	if s := (Op{Kind: OpUnion, Elems: []int{1, 2}}).String(); s != "Union(1, 2)" {
+		t.Errorf("String failed: got %q.\n", s)
+	}
+}
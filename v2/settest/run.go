@@ -0,0 +1,134 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package settest
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+
+	"github.com/hweidner/set/v2"
+)
+
+// ----- set under test -----
+
+// A Target is a set of integers under test. All implementations of
+// set.Interface[int] are Targets.
+type Target = set.Interface[int]
+
+// ----- reference model -----
+
+// A Model is the naive reference implementation of a set of integers. Its
+// zero value is not usable; use make.
+type Model map[int]bool
+
+// Add adds one or more elements.
+func (m Model) Add(e ...int) {
+	for _, i := range e {
+		m[i] = true
+	}
+}
+
+// Remove removes one or more elements.
+func (m Model) Remove(e ...int) {
+	for _, i := range e {
+		delete(m, i)
+	}
+}
+
+// Contains checks if all elements are in the model.
+func (m Model) Contains(e ...int) bool {
+	for _, i := range e {
+		if !m[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the number of elements.
+func (m Model) Len() int {
+	return len(m)
+}
+
+// All returns an iterator to all elements.
+func (m Model) All() iter.Seq[int] {
+	return maps.Keys(m)
+}
+
+// Clear removes all elements.
+func (m Model) Clear() {
+	clear(m)
+}
+
+// ----- interpreter -----
+
+// A Mismatch describes the first difference between the set under test and
+// the reference model.
+type Mismatch struct {
+	Step      int // the index of the operation
+	Op        Op
+	Got, Want string
+}
+
+func (e *Mismatch) Error() string {
+	return fmt.Sprintf("settest: step %d %v: got %s, want %s", e.Step, e.Op, e.Got, e.Want)
+}
+
+// Apply applies an operation to a target. Union and Intersect are applied as
+// in-place operations, using Add, All and Remove. For the query operations,
+// the result is returned as text; for all others, it is empty.
+func Apply(t Target, o Op) string {
+	switch o.Kind {
+	case OpAdd, OpUnion:
+		t.Add(o.Elems...)
+	case OpRemove:
+		t.Remove(o.Elems...)
+	case OpClear:
+		t.Clear()
+	case OpContains:
+		return fmt.Sprint(t.Contains(o.Elems...))
+	case OpLen:
+		return fmt.Sprint(t.Len())
+	case OpIntersect:
+		keep := make(Model)
+		keep.Add(o.Elems...)
+		var drop []int
+		for e := range t.All() {
+			if !keep[e] {
+				drop = append(drop, e)
+			}
+		}
+		t.Remove(drop...)
+	case OpCheck:
+		return fmt.Sprint(sorted(t))
+	}
+	return ""
+}
+
+// Run applies the operations both to the target and to a new reference
+// model, and returns a *Mismatch for the first result which differs. The
+// contents are also compared after the last operation. The target should be
+// empty.
+func Run(t Target, ops []Op) error {
+	m := make(Model)
+	for i, o := range ops {
+		if got, want := Apply(t, o), Apply(m, o); got != want {
+			return &Mismatch{Step: i, Op: o, Got: got, Want: want}
+		}
+	}
+	final := Op{Kind: OpCheck}
+	if got, want := Apply(t, final), Apply(m, final); got != want {
+		return &Mismatch{Step: len(ops), Op: final, Got: got, Want: want}
+	}
+	return nil
+}
+
+// sorted returns the elements of the target in ascending order.
+func sorted(t Target) []int {
+	return slices.Sorted(t.All())
+}
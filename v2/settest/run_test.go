@@ -0,0 +1,50 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package settest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+// lossy is a broken target which drops negative elements.
+type lossy struct{ Model }
+
+func (l lossy) Add(e ...int) {
+	for _, i := range e {
+		if i >= 0 {
+			l.Model.Add(i)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	ops := []Op{
+		{Kind: OpAdd, Elems: []int{1, 2, 3}},
+		{Kind: OpContains, Elems: []int{2, 3}},
+		{Kind: OpIntersect, Elems: []int{2, 3, 4}},
+		{Kind: OpUnion, Elems: []int{-5}},
+		{Kind: OpLen},
+	}
+	if err := Run(set.New[int](), ops); err != nil {
+		t.Errorf("Run failed on Set: %v.\n", err)
+	}
+	var m *Mismatch
+	if err := Run(lossy{make(Model)}, ops); !errors.As(err, &m) || m.Step != 4 {
+		t.Errorf("Run failed: expected a mismatch at step 4, got %v.\n", err)
+	}
+}
+
+func FuzzSet(f *testing.F) {
+	f.Add(Encode([]Op{{Kind: OpAdd, Elems: []int{1, 2}}, {Kind: OpRemove, Elems: []int{1}}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := Run(set.New[int](), Decode(data)); err != nil {
+			t.Error(err)
+		}
+	})
+}
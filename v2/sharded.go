@@ -0,0 +1,258 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+// ----- ShardedSet definition -----
+
+// A ShardedSet is a set which is safe for concurrent use, split into shards
+// with a lock each, so that goroutines working on different elements rarely
+// wait for each other. Per-shard sizes and lock contention are reported by
+// Stats; when the shards become skewed, Resize or Rebalance redistributes
+// the elements.
+type ShardedSet[T comparable] struct {
+	resize sync.RWMutex // held for writing while the shards are replaced
+	shards []*shard[T]
+	seed   uint64
+}
+
+// a shard of a ShardedSet, with its counters
+type shard[T comparable] struct {
+	mu        sync.RWMutex
+	set       map[T]struct{}
+	locks     atomic.Int64
+	contended atomic.Int64
+}
+
+// ShardStats are the statistics of one shard.
+type ShardStats struct {
+	Len       int   // the number of elements
+	Locks     int64 // the number of lock acquisitions
+	Contended int64 // the number of lock acquisitions which had to wait
+}
+
+// ----- constructor -----
+
+// NewSharded creates a new sharded set with n shards, at least one.
+func NewSharded[T comparable](n int) *ShardedSet[T] {
+	s := &ShardedSet[T]{}
+	s.shards = newShards[T](n)
+	return s
+}
+
+// newShards creates n empty shards, at least one.
+func newShards[T comparable](n int) []*shard[T] {
+	sh := make([]*shard[T], max(n, 1))
+	for i := range sh {
+		sh[i] = &shard[T]{set: map[T]struct{}{}}
+	}
+	return sh
+}
+
+// shardOf returns the shard of an element. The caller holds s.resize.
+func (s *ShardedSet[T]) shardOf(e T) *shard[T] {
+	return s.shards[hash.Mix64(hash.Of(e)^s.seed)%uint64(len(s.shards))]
+}
+
+// lock acquires the write lock of the shard, counting contention.
+func (sh *shard[T]) lock() {
+	sh.locks.Add(1)
+	if !sh.mu.TryLock() {
+		sh.contended.Add(1)
+		sh.mu.Lock()
+	}
+}
+
+// rlock acquires the read lock of the shard, counting contention.
+func (sh *shard[T]) rlock() {
+	sh.locks.Add(1)
+	if !sh.mu.TryRLock() {
+		sh.contended.Add(1)
+		sh.mu.RLock()
+	}
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s *ShardedSet[T]) Add(e ...T) {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	for _, i := range e {
+		sh := s.shardOf(i)
+		sh.lock()
+		sh.set[i] = struct{}{}
+		sh.mu.Unlock()
+	}
+}
+
+// Remove removes one or more elements from the given set.
+func (s *ShardedSet[T]) Remove(e ...T) {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	for _, i := range e {
+		sh := s.shardOf(i)
+		sh.lock()
+		delete(sh.set, i)
+		sh.mu.Unlock()
+	}
+}
+
+// Clear removes all elements from the given set.
+func (s *ShardedSet[T]) Clear() {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	for _, sh := range s.shards {
+		sh.lock()
+		clear(sh.set)
+		sh.mu.Unlock()
+	}
+}
+
+// Reset removes all elements from the given set, and replaces the maps of the
+// shards with new ones presized for capacityHint elements in total.
+func (s *ShardedSet[T]) Reset(capacityHint int) {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	n := max(capacityHint, 0) / len(s.shards)
+	for _, sh := range s.shards {
+		sh.lock()
+		sh.set = make(map[T]struct{}, n)
+		sh.mu.Unlock()
+	}
+}
+
+// Resize redistributes the elements over n shards, with a new assignment of
+// elements to shards. It blocks all other operations while it runs.
+func (s *ShardedSet[T]) Resize(n int) {
+	s.resize.Lock()
+	defer s.resize.Unlock()
+	old := s.shards
+	s.shards = newShards[T](n)
+	s.seed = hash.Mix64(s.seed + 1)
+	for _, sh := range old {
+		for k := range sh.set {
+			s.shardOf(k).set[k] = struct{}{}
+		}
+	}
+}
+
+// Rebalance resizes the set if its skew exceeds maxSkew, keeping the number
+// of shards. It returns true if the set was resized. Elements of a skewed
+// set are assigned to shards anew, so a skew caused by an unlucky
+// distribution is resolved; a single hot element still hits one shard.
+func (s *ShardedSet[T]) Rebalance(maxSkew float64) bool {
+	if s.Skew() <= maxSkew {
+		return false
+	}
+	s.Resize(s.NumShards())
+	return true
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Len returns the length of the set.
+func (s *ShardedSet[T]) Len() int {
+	n := 0
+	for _, st := range s.Stats() {
+		n += st.Len
+	}
+	return n
+}
+
+// IsEmpty tests if the set is empty.
+func (s *ShardedSet[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *ShardedSet[T]) Contains(e ...T) bool {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	for _, i := range e {
+		sh := s.shardOf(i)
+		sh.rlock()
+		_, ok := sh.set[i]
+		sh.mu.RUnlock()
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// NumShards returns the number of shards.
+func (s *ShardedSet[T]) NumShards() int {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	return len(s.shards)
+}
+
+// Stats returns the statistics of all shards. The lock counters are reset
+// by Resize.
+func (s *ShardedSet[T]) Stats() []ShardStats {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	st := make([]ShardStats, len(s.shards))
+	for i, sh := range s.shards {
+		sh.mu.RLock()
+		st[i].Len = len(sh.set)
+		sh.mu.RUnlock()
+		st[i].Locks, st[i].Contended = sh.locks.Load(), sh.contended.Load()
+	}
+	return st
+}
+
+// Skew returns the ratio of the size of the largest shard to the mean size
+// of all shards. It is 1 for perfectly balanced and for empty sets.
+func (s *ShardedSet[T]) Skew() float64 {
+	st := s.Stats()
+	total, largest := 0, 0
+	for _, i := range st {
+		total += i.Len
+		largest = max(largest, i.Len)
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(largest) * float64(len(st)) / float64(total)
+}
+
+// Snapshot returns a copy of the set as a plain Set. It is consistent per
+// shard, but not across shards modified concurrently.
+func (s *ShardedSet[T]) Snapshot() Set[T] {
+	s.resize.RLock()
+	defer s.resize.RUnlock()
+	r := New[T]()
+	for _, sh := range s.shards {
+		sh.rlock()
+		for k := range sh.set {
+			r.set[k] = struct{}{}
+		}
+		sh.mu.RUnlock()
+	}
+	return r
+}
+
+// All returns an iterator to all elements in an undefined order. It iterates
+// over a snapshot, so the loop body may modify the set.
+func (s *ShardedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := range s.Snapshot().set {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestShardedSet(t *testing.T) {
+	s := NewSharded[int](8)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				s.Add(g*1000 + i)
+				s.Contains(i)
+			}
+			s.Remove(g * 1000)
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 8000-8 || s.Contains(0) || !s.Contains(1, 7999) {
+		t.Errorf("ShardedSet failed: got length %d.\n", s.Len())
+	}
+
+	st := s.Stats()
+	var locks int64
+	for _, i := range st {
+		locks += i.Locks
+		if i.Len == 0 {
+			t.Errorf("Stats failed: empty shard.\n")
+		}
+	}
+	if len(st) != 8 || locks < 16000 {
+		t.Errorf("Stats failed: got %d shards, %d locks.\n", len(st), locks)
+	}
+	if sk := s.Skew(); sk < 1 || sk > 1.5 {
+		t.Errorf("Skew failed: got %.2f for an even distribution.\n", sk)
+	}
+
+	snap := s.Snapshot()
+	s.Resize(3)
+	if s.NumShards() != 3 || !s.Snapshot().IsEqual(snap) {
+		t.Errorf("Resize failed: elements lost.\n")
+	}
+	for i := range s.All() {
+		s.Remove(i)
+	}
+	if !s.IsEmpty() || s.Skew() != 1 {
+		t.Errorf("All/Remove failed: got length %d.\n", s.Len())
+	}
+}
+
+func TestShardedSetRebalance(t *testing.T) {
+	s := NewSharded[int](4)
+	s.Add(1, 2, 3, 4, 5, 6, 7, 8)
+	// skew the set by force
+	for _, sh := range s.shards[1:] {
+		for k := range sh.set {
+			s.shards[0].set[k] = struct{}{}
+			delete(sh.set, k)
+		}
+	}
+	if s.Skew() != 4 {
+		t.Fatalf("Skew failed: got %.2f.\n", s.Skew())
+	}
+	if s.Rebalance(10) || !s.Rebalance(2) {
+		t.Errorf("Rebalance failed: wrong decision.\n")
+	}
+	if s.Skew() >= 4 || s.Len() != 8 || s.NumShards() != 4 {
+		t.Errorf("Rebalance failed: skew %.2f, length %d.\n", s.Skew(), s.Len())
+	}
+}
+
+func TestShardedSetNegativeZero(t *testing.T) {
+	s := NewSharded[float64](16)
+	s.Add(0.0, math.Copysign(0, -1))
+	if s.Len() != 1 || !s.Contains(math.Copysign(0, -1)) {
+		t.Errorf("Add failed: 0.0 and -0.0 stored as %d elements.\n", s.Len())
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build !unix
+
+package shmset
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile fails on platforms without shared mappings.
+func mapFile(f *os.File, size int) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// unmapFile does nothing on platforms without shared mappings.
+func unmapFile(b []byte) error {
+	return nil
+}
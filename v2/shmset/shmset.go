@@ -0,0 +1,298 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package shmset provides a set of 64 bit keys in shared memory, which is used
+by several processes on one host at the same time, like an application and
+its sidecar.
+
+The set is a hash table with open addressing and a fixed capacity, stored in
+a memory-mapped file; on Linux, files in /dev/shm, or memfd descriptors
+inherited by child processes, are not backed by a disk. All operations are
+lock-free atomic operations on the mapped memory, so no process can block
+the others, and a crashed process leaves the set consistent.
+
+Removed keys leave tombstones, which are only reclaimed by Clear, so the
+capacity must cover all keys added between two calls of Clear. For string
+keys, map them to IDs or use a 64 bit hash, accepting its collisions.
+
+Mapping files needs a Unix system; on other systems, Create and Open return
+errors.ErrUnsupported.
+*/
+package shmset
+
+import (
+	"errors"
+	"iter"
+	"math/bits"
+	"os"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/hweidner/set/v2/internal/hash"
+)
+
+var (
+	// ErrFull is returned by Add if the set has no free slot for the key.
+	ErrFull = errors.New("shmset: set is full")
+
+	// ErrFormat is returned by Open for files which do not hold a set.
+	ErrFormat = errors.New("shmset: not a shared set")
+)
+
+// ----- memory layout -----
+
+// The mapped file starts with a header of 4 words: the magic number, the
+// number of slots, the number of keys and the flags of the keys 0 and 1. It
+// is followed by the slots. A slot holds a key, or one of the markers for
+// empty and removed slots, which is why the keys 0 and 1 are stored as flags.
+// All words are in the byte order of the host.
+
+// the magic number, "SHMS" and the version 1
+const magic = 0x01_534d4853
+
+// the markers of slots
+const (
+	empty   = 0
+	removed = 1
+)
+
+// the size of the header in words
+const headerWords = 4
+
+// the maximum number of slots
+const maxSlots = 1 << 40
+
+// ----- Set definition -----
+
+// A Set is a set of uint64 keys in a shared memory region. It is safe for
+// concurrent use by the goroutines of all processes mapping the region.
+type Set struct {
+	mem   []byte   // the mapping
+	words []uint64 // the mapping as words
+	mask  uint64   // the number of slots minus 1
+}
+
+// ----- constructors -----
+
+// Create creates a set with room for at least capacity keys in a new file.
+// The capacity is rounded up to a power of two; for short probe sequences,
+// it should be about twice the number of keys.
+func Create(path string, capacity int) (*Set, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := Init(f, capacity)
+	if err != nil {
+		os.Remove(path)
+	}
+	return s, err
+}
+
+// Init creates a set with room for at least capacity keys in an empty file,
+// like a descriptor of memfd_create on Linux, which can be passed to other
+// processes. The file can be closed after Init returns.
+func Init(f *os.File, capacity int) (*Set, error) {
+	n := uint64(1) << bits.Len64(uint64(max(capacity, 2)-1))
+	size := int64(headerWords+n) * 8
+	if n > maxSlots || int64(int(size)) != size {
+		return nil, ErrFull
+	}
+	if err := f.Truncate(size); err != nil {
+		return nil, err
+	}
+	mem, err := mapFile(f, int(size))
+	if err != nil {
+		return nil, err
+	}
+	s := newSet(mem, n)
+	s.words[1] = n
+	// the magic number is stored last, as it marks the set as ready
+	atomic.StoreUint64(&s.words[0], magic)
+	return s, nil
+}
+
+// Open opens a set created by Create, or shared as a file descriptor.
+func Open(path string) (*Set, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return OpenFile(f)
+}
+
+// OpenFile maps a file which holds a set. The file can be closed after
+// OpenFile returns.
+func OpenFile(f *os.File) (*Set, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := st.Size()
+	if size < headerWords*8 || size%8 != 0 {
+		return nil, ErrFormat
+	}
+	mem, err := mapFile(f, int(size))
+	if err != nil {
+		return nil, err
+	}
+	n := uint64(size/8 - headerWords)
+	s := newSet(mem, n)
+	if atomic.LoadUint64(&s.words[0]) != magic || s.words[1] != n || n&(n-1) != 0 {
+		unmapFile(mem)
+		return nil, ErrFormat
+	}
+	return s, nil
+}
+
+// newSet returns a set for a mapping with n slots.
+func newSet(mem []byte, n uint64) *Set {
+	words := unsafe.Slice((*uint64)(unsafe.Pointer(unsafe.SliceData(mem))), len(mem)/8)
+	return &Set{mem: mem, words: words, mask: n - 1}
+}
+
+// Close unmaps the set. The set remains in the file for the other processes.
+func (s *Set) Close() error {
+	err := unmapFile(s.mem)
+	s.mem, s.words = nil, nil
+	return err
+}
+
+// ----- slots -----
+
+// count returns the address of the number of keys.
+func (s *Set) count() *int64 {
+	return (*int64)(unsafe.Pointer(&s.words[2]))
+}
+
+// flags returns the address of the flags of the keys 0 and 1.
+func (s *Set) flags() *uint64 {
+	return &s.words[3]
+}
+
+// slot returns the address of the i-th slot.
+func (s *Set) slot(i uint64) *uint64 {
+	return &s.words[headerWords+i&s.mask]
+}
+
+// ----- methods that modify the set -----
+
+// Add adds a key to the set. It returns true if the key was not in the set
+// before, and ErrFull if there is no free slot for it.
+func (s *Set) Add(k uint64) (bool, error) {
+	if k <= removed {
+		bit := uint64(1) << k
+		if atomic.OrUint64(s.flags(), bit)&bit != 0 {
+			return false, nil
+		}
+		atomic.AddInt64(s.count(), 1)
+		return true, nil
+	}
+	h := hash.Mix64(k)
+	for i := range s.mask + 1 {
+		p := s.slot(h + i)
+		v := atomic.LoadUint64(p)
+		if v == empty {
+			if atomic.CompareAndSwapUint64(p, empty, k) {
+				atomic.AddInt64(s.count(), 1)
+				return true, nil
+			}
+			// another process took the slot, maybe for k; slots never
+			// become empty again, so it is loaded only once more
+			v = atomic.LoadUint64(p)
+		}
+		if v == k {
+			return false, nil
+		}
+	}
+	return false, ErrFull
+}
+
+// Remove removes a key from the set. It returns true if the key was in the
+// set.
+func (s *Set) Remove(k uint64) bool {
+	if k <= removed {
+		bit := uint64(1) << k
+		if atomic.AndUint64(s.flags(), ^bit)&bit == 0 {
+			return false
+		}
+		atomic.AddInt64(s.count(), -1)
+		return true
+	}
+	p := s.find(k)
+	if p == nil || !atomic.CompareAndSwapUint64(p, k, removed) {
+		return false
+	}
+	atomic.AddInt64(s.count(), -1)
+	return true
+}
+
+// Clear removes all keys and tombstones from the set. It must not run
+// concurrently with other operations on the set, as they may see a
+// partially cleared table.
+func (s *Set) Clear() {
+	for i := range s.mask + 1 {
+		atomic.StoreUint64(s.slot(i), empty)
+	}
+	atomic.StoreUint64(s.flags(), 0)
+	atomic.StoreInt64(s.count(), 0)
+}
+
+// ----- methods that do not modify the set -----
+
+// find returns the address of the slot of k, or nil if k is not in the set.
+func (s *Set) find(k uint64) *uint64 {
+	h := hash.Mix64(k)
+	for i := range s.mask + 1 {
+		p := s.slot(h + i)
+		switch atomic.LoadUint64(p) {
+		case k:
+			return p
+		case empty:
+			return nil
+		}
+	}
+	return nil
+}
+
+// Contains checks if a key is in the set.
+func (s *Set) Contains(k uint64) bool {
+	if k <= removed {
+		return atomic.LoadUint64(s.flags())&(1<<k) != 0
+	}
+	return s.find(k) != nil
+}
+
+// Len returns the number of keys in the set.
+func (s *Set) Len() int {
+	return int(atomic.LoadInt64(s.count()))
+}
+
+// Cap returns the number of slots of the set.
+func (s *Set) Cap() int {
+	return int(s.mask + 1)
+}
+
+// All returns an iterator to all keys of the set, in no particular order.
+// Keys which are added or removed during the iteration may or may not be
+// visited.
+func (s *Set) All() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		f := atomic.LoadUint64(s.flags())
+		for k := range uint64(removed + 1) {
+			if f&(1<<k) != 0 && !yield(k) {
+				return
+			}
+		}
+		for i := range s.mask + 1 {
+			if v := atomic.LoadUint64(s.slot(i)); v > removed && !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+//go:build unix
+
+package shmset
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "set")
+	s, err := Create(path, 100)
+	if err != nil {
+		t.Fatalf("Create failed: %v.\n", err)
+	}
+	defer s.Close()
+	if s.Cap() != 128 || s.Len() != 0 {
+		t.Errorf("Create failed: got capacity %d, length %d.\n", s.Cap(), s.Len())
+	}
+	for _, k := range []uint64{0, 1, 2, 1 << 63, 42} {
+		if ok, err := s.Add(k); !ok || err != nil {
+			t.Errorf("Add failed for %d: %v, %v.\n", k, ok, err)
+		}
+	}
+	if ok, _ := s.Add(42); ok || s.Len() != 5 || !s.Contains(0) || !s.Contains(1) || !s.Contains(1<<63) ||
+		s.Contains(3) {
+		t.Errorf("Add failed: got length %d.\n", s.Len())
+	}
+
+	// a second mapping, as in another process, shares the keys
+	o, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v.\n", err)
+	}
+	defer o.Close()
+	if !o.Remove(1) || !o.Remove(42) || o.Remove(42) || o.Len() != 3 {
+		t.Errorf("Remove failed: got length %d.\n", o.Len())
+	}
+	if s.Contains(1) || s.Contains(42) || s.Len() != 3 {
+		t.Errorf("Remove failed: not visible in the first mapping.\n")
+	}
+	if got := slices.Sorted(s.All()); !slices.Equal(got, []uint64{0, 2, 1 << 63}) {
+		t.Errorf("All failed: got %v.\n", got)
+	}
+	if ok, _ := s.Add(42); !ok || !o.Contains(42) {
+		t.Errorf("Add failed after Remove.\n")
+	}
+	o.Clear()
+	if s.Len() != 0 || s.Contains(0) || s.Contains(42) {
+		t.Errorf("Clear failed: got length %d.\n", s.Len())
+	}
+
+	// a full set rejects new keys
+	for k := uint64(2); k < 130; k++ {
+		s.Add(k)
+	}
+	if _, err := s.Add(1000); !errors.Is(err, ErrFull) || s.Len() != 128 {
+		t.Errorf("Add failed: got %v for a full set with %d keys.\n", err, s.Len())
+	}
+
+	if _, err := Create(path, 10); err == nil {
+		t.Errorf("Create failed: existing file overwritten.\n")
+	}
+	bad := filepath.Join(t.TempDir(), "bad")
+	os.WriteFile(bad, make([]byte, 64), 0o600)
+	if _, err := Open(bad); !errors.Is(err, ErrFormat) {
+		t.Errorf("Open failed: got %v for a file of zeros.\n", err)
+	}
+}
+
+func TestSetConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "set")
+	s, err := Create(path, 1<<14)
+	if err != nil {
+		t.Fatalf("Create failed: %v.\n", err)
+	}
+	defer s.Close()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for w := 0; w < 8; w++ {
+		m, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open failed: %v.\n", err)
+		}
+		defer m.Close()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := 0
+			for k := uint64(0); k < 4000; k++ {
+				if ok, _ := m.Add(k); ok {
+					n++
+				}
+			}
+			mu.Lock()
+			added += n
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if added != 4000 || s.Len() != 4000 {
+		t.Errorf("Add failed: %d keys added, length %d.\n", added, s.Len())
+	}
+}
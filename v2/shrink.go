@@ -0,0 +1,60 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+// ----- shrinking -----
+
+// minShrinkPeak is the peak size below which maps are never rebuilt, since
+// their memory does not matter.
+const minShrinkPeak = 1024
+
+// WithShrink sets the fraction of the peak size below which the map of a set
+// is rebuilt automatically, since Go maps never release memory on deletion.
+// For example, with 0.25, the map is rebuilt once less than a quarter of the
+// largest number of elements it held is left. Maps with a peak size below
+// 1024 are never rebuilt.
+//
+// A Set is a value, and its methods cannot replace the map shared by all
+// copies of that value. The policy is therefore applied only by the types
+// which own their set, and the option is accepted only by their
+// constructors, like NewSyncWith. NewWith rejects it as a misuse. For a
+// plain Set, use Compact.
+func WithShrink[T comparable](fraction float64) Option[T] {
+	return func(c *config[T]) {
+		c.shrink = fraction
+	}
+}
+
+// errShrinkUnowned is the reason for passing WithShrink to NewWith.
+const errShrinkUnowned = "WithShrink needs a set which owns its map, use NewSyncWith"
+
+// Compact returns a copy of the set in a map sized to its current length,
+// which releases the memory of deleted elements. The set s must not be used
+// afterwards, if the memory of its map is to be freed.
+//
+//	s = s.Compact()
+func (s Set[T]) Compact() Set[T] {
+	return s.Copy()
+}
+
+// shrinkTracker tracks the peak size of an owned set and rebuilds its map
+// according to the shrink policy of the set.
+type shrinkTracker struct {
+	peak int
+}
+
+// shrinkUpdate records the size of s after a modification, and returns the
+// compacted set if its map should be rebuilt, or s itself.
+func shrinkUpdate[T comparable](t *shrinkTracker, s Set[T]) Set[T] {
+	n := len(s.set)
+	t.peak = max(t.peak, n)
+	if s.cfg == nil || s.cfg.shrink <= 0 || t.peak < minShrinkPeak ||
+		float64(n) >= s.cfg.shrink*float64(t.peak) {
+		return s
+	}
+	t.peak = n
+	return s.Compact()
+}
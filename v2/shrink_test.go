@@ -0,0 +1,64 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"reflect"
+	"testing"
+)
+
+// mapID returns the identity of the map of a set.
+func mapID[T comparable](s Set[T]) uintptr {
+	return reflect.ValueOf(s.set).Pointer()
+}
+
+func TestShrink(t *testing.T) {
+	s := NewSyncWith(WithShrink[int](0.25))
+	for i := 0; i < 4000; i++ {
+		s.Add(i)
+	}
+	id := mapID(s.set)
+	for i := 0; i < 3000; i++ {
+		s.Remove(i)
+	}
+	if mapID(s.set) != id {
+		t.Errorf("Remove failed: map rebuilt at a quarter of the peak.\n")
+	}
+	s.RemoveIfPresent(3000)
+	if mapID(s.set) == id || s.Len() != 999 || !s.Contains(3999) || s.shrink.peak != 999 {
+		t.Errorf("RemoveIfPresent failed: map not rebuilt below a quarter of the peak.\n")
+	}
+
+	// sets without the option are never rebuilt
+	p := NewSync[int]()
+	for i := 0; i < 4000; i++ {
+		p.Add(i)
+	}
+	id = mapID(p.set)
+	p.Clear()
+	if mapID(p.set) != id {
+		t.Errorf("Clear failed: map of a plain set rebuilt.\n")
+	}
+
+	// plain sets reject the option
+	func() {
+		defer func() {
+			if _, ok := recover().(*MisuseError); !ok {
+				t.Errorf("NewWith failed: WithShrink accepted for a plain set.\n")
+			}
+		}()
+		NewWith(WithShrink[int](0.25))
+	}()
+	r := NewWith(WithShrink[int](0.25), WithMisusePolicy[int](RecordMisuse))
+	if r.cfg.shrink != 0 || r.Err() == nil {
+		t.Errorf("NewWith failed: WithShrink not rejected with RecordMisuse.\n")
+	}
+
+	c := New(1, 2, 3)
+	if d := c.Compact(); !d.IsEqual(c) || mapID(d) == mapID(c) {
+		t.Errorf("Compact failed: got %v.\n", d)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package snapsync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ----- HTTP store -----
+
+// An HTTPStore is a store with an HTTP interface, which reads objects with GET
+// and writes them with PUT under BaseURL + "/" + key, using the If-None-Match
+// and If-Match headers for conditional requests.
+type HTTPStore struct {
+	// Client is the HTTP client; nil selects http.DefaultClient.
+	Client *http.Client
+
+	// BaseURL is the URL of the bucket or directory of the snapshots.
+	BaseURL string
+
+	// Header holds additional headers of all requests, like credentials.
+	Header http.Header
+}
+
+// client returns the HTTP client of the store.
+func (h *HTTPStore) client() *http.Client {
+	if h.Client != nil {
+		return h.Client
+	}
+	return http.DefaultClient
+}
+
+// request creates a request for the object with the key.
+func (h *HTTPStore) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(h.BaseURL, "/")+"/"+key, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range h.Header {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+// Get implements Store.Get.
+func (h *HTTPStore) Get(ctx context.Context, key, etag string) (io.ReadCloser, string, error) {
+	req, err := h.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, resp.Header.Get("ETag"), nil
+	case http.StatusNotModified:
+		resp.Body.Close()
+		return nil, "", ErrNotModified
+	default:
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("snapsync: GET %s: %s", req.URL, resp.Status)
+	}
+}
+
+// Put implements Store.Put.
+func (h *HTTPStore) Put(ctx context.Context, key string, r io.Reader, size int64, ifMatch string) (string, error) {
+	req, err := h.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	switch ifMatch {
+	case "":
+	case "*":
+		req.Header.Set("If-None-Match", "*")
+	default:
+		req.Header.Set("If-Match", ifMatch)
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict:
+		return "", ErrConflict
+	case resp.StatusCode/100 != 2:
+		return "", fmt.Errorf("snapsync: PUT %s: %s", req.URL, resp.Status)
+	}
+	return resp.Header.Get("ETag"), nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package snapsync
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hweidner/set/v2"
+)
+
+// newServer returns a server with the conditional GET and PUT requests of an
+// object store.
+func newServer(t *testing.T) *httptest.Server {
+	objs := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		b, ok := objs[r.URL.Path]
+		switch r.Method {
+		case http.MethodGet:
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", etagOf(b))
+			if r.Header.Get("If-None-Match") == etagOf(b) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write(b)
+		case http.MethodPut:
+			if m := r.Header.Get("If-Match"); m != "" && (!ok || m != etagOf(b)) ||
+				r.Header.Get("If-None-Match") == "*" && ok {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			nb, _ := io.ReadAll(r.Body)
+			objs[r.URL.Path] = nb
+			w.Header().Set("ETag", etagOf(nb))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	st := &HTTPStore{BaseURL: srv.URL + "/bucket/", Header: http.Header{"Authorization": {"secret"}}}
+	etag, err := Publish(ctx, st, "deny", set.New(10, 20), "*")
+	if err != nil || etag == "" {
+		t.Fatalf("Publish failed: %q, %v.\n", etag, err)
+	}
+	if _, err := Publish(ctx, st, "deny", set.New(30), `"old"`); !errors.Is(err, ErrConflict) {
+		t.Errorf("Publish failed: got %v for a stale ETag.\n", err)
+	}
+
+	live := set.NewCOW[int]()
+	f := NewFetcher(st, "deny", live)
+	if ok, err := f.Refresh(ctx); !ok || err != nil || !live.Load().IsEqual(set.New(10, 20)) {
+		t.Errorf("Refresh failed: got %v, %v.\n", live, err)
+	}
+	if ok, err := f.Refresh(ctx); ok || err != nil {
+		t.Errorf("Refresh failed: not modified snapshot swapped in: %v.\n", err)
+	}
+
+	if _, err := NewFetcher(st, "missing", live).Refresh(ctx); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Refresh failed: got %v for a missing snapshot.\n", err)
+	}
+	bad := &HTTPStore{BaseURL: srv.URL}
+	if _, err := Publish(ctx, bad, "deny", set.New(1), ""); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Publish failed: got %v without credentials.\n", err)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+/*
+Package snapsync publishes snapshots of sets to object storage, and refreshes
+live sets from them, like an allowlist which a central service publishes and
+many instances fetch periodically.
+
+Snapshots are written in the compressed format of set.EncodeCompressed, with
+sorted elements, and are versioned by the ETag of the store. A Fetcher only
+downloads a snapshot if its ETag changed, and swaps the decoded set into a
+set.COWSet, so readers never see a partial update. Publish can be made
+conditional on the ETag of the previous snapshot, so concurrent publishers
+do not overwrite each other.
+
+The Store interface abstracts the object storage; HTTPStore implements it for
+stores with an HTTP interface and conditional requests, like S3 and GCS with
+presigned URLs, or plain web servers for fetching.
+*/
+package snapsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/hweidner/set/v2"
+)
+
+var (
+	// ErrNotModified is returned by Store.Get if the object still has the
+	// given ETag.
+	ErrNotModified = errors.New("snapsync: snapshot not modified")
+
+	// ErrConflict is returned by Store.Put if the object does not have the
+	// expected ETag.
+	ErrConflict = errors.New("snapsync: snapshot was modified concurrently")
+)
+
+// A Store is an object storage holding snapshots under keys.
+type Store interface {
+	// Get returns the content and the ETag of an object. If etag is not
+	// empty and the object still has this ETag, it returns ErrNotModified.
+	Get(ctx context.Context, key, etag string) (io.ReadCloser, string, error)
+
+	// Put writes an object and returns its new ETag. If ifMatch is not
+	// empty, the object is only written if it has this ETag, and if
+	// ifMatch is "*", only if it does not exist; otherwise, Put returns
+	// ErrConflict.
+	Put(ctx context.Context, key string, r io.Reader, size int64, ifMatch string) (string, error)
+}
+
+// ----- publishing -----
+
+// Publish writes a snapshot of the set s under the key, and returns its ETag.
+// The ETag ifMatch works like for Store.Put.
+func Publish[T comparable](ctx context.Context, st Store, key string, s set.Set[T], ifMatch string) (string, error) {
+	var b bytes.Buffer
+	if err := s.EncodeCompressed(&b, set.CompressOptions{Sorted: true}); err != nil {
+		return "", err
+	}
+	return st.Put(ctx, key, &b, int64(b.Len()), ifMatch)
+}
+
+// ----- fetching -----
+
+// A Fetcher refreshes a live set from the snapshot under a key.
+type Fetcher[T comparable] struct {
+	store  Store
+	key    string
+	target *set.COWSet[T]
+	etag   string
+}
+
+// NewFetcher creates a fetcher which refreshes target from the snapshot
+// under the key of the store.
+func NewFetcher[T comparable](st Store, key string, target *set.COWSet[T]) *Fetcher[T] {
+	return &Fetcher[T]{store: st, key: key, target: target}
+}
+
+// Refresh downloads the snapshot if it changed since the last refresh, and
+// swaps it into the target. It returns true if the target was replaced. On
+// errors, the target keeps its current version.
+func (f *Fetcher[T]) Refresh(ctx context.Context) (bool, error) {
+	r, etag, err := f.store.Get(ctx, f.key, f.etag)
+	if errors.Is(err, ErrNotModified) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+	s, err := set.DecodeCompressed[T](r)
+	if err != nil {
+		return false, err
+	}
+	f.target.Swap(s)
+	f.etag = etag
+	return true, nil
+}
+
+// ETag returns the ETag of the snapshot of the last successful refresh.
+func (f *Fetcher[T]) ETag() string {
+	return f.etag
+}
+
+// Run refreshes the target immediately, and then in the given interval
+// until the context is canceled. Errors are passed to onError, if it is not
+// nil, and the next refresh is tried in the next interval.
+func (f *Fetcher[T]) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if _, err := f.Refresh(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package snapsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hweidner/set/v2"
+)
+
+// memStore is a store in memory, with the hash of the content as ETag.
+type memStore struct {
+	mu   sync.Mutex
+	objs map[string][]byte
+	gets int
+}
+
+func etagOf(b []byte) string {
+	h := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(h[:8]) + `"`
+}
+
+func (m *memStore) Get(ctx context.Context, key, etag string) (io.ReadCloser, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	b, ok := m.objs[key]
+	if !ok {
+		return nil, "", errors.New("not found")
+	}
+	if etag == etagOf(b) {
+		return nil, "", ErrNotModified
+	}
+	return io.NopCloser(bytes.NewReader(b)), etagOf(b), nil
+}
+
+func (m *memStore) Put(ctx context.Context, key string, r io.Reader, size int64, ifMatch string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.objs[key]
+	if ifMatch == "*" && ok || ifMatch != "" && ifMatch != "*" && (!ok || ifMatch != etagOf(b)) {
+		return "", ErrConflict
+	}
+	nb, err := io.ReadAll(r)
+	if err != nil || int64(len(nb)) != size {
+		return "", errors.New("short body")
+	}
+	m.objs[key] = nb
+	return etagOf(nb), nil
+}
+
+func TestFetcher(t *testing.T) {
+	ctx := context.Background()
+	st := &memStore{objs: map[string][]byte{}}
+	etag, err := Publish(ctx, st, "allow", set.New("a", "b"), "*")
+	if err != nil {
+		t.Fatalf("Publish failed: %v.\n", err)
+	}
+	if _, err := Publish(ctx, st, "allow", set.New("x"), "*"); !errors.Is(err, ErrConflict) {
+		t.Errorf("Publish failed: got %v for an existing snapshot.\n", err)
+	}
+
+	live := set.NewCOW[string]()
+	f := NewFetcher(st, "allow", live)
+	if ok, err := f.Refresh(ctx); !ok || err != nil || !live.Load().IsEqual(set.New("a", "b")) || f.ETag() != etag {
+		t.Errorf("Refresh failed: got %v, %v, %v.\n", ok, err, live)
+	}
+	if ok, err := f.Refresh(ctx); ok || err != nil {
+		t.Errorf("Refresh failed: unchanged snapshot swapped in: %v.\n", err)
+	}
+
+	// equal sets give equal snapshots, so republishing them changes nothing
+	if e, err := Publish(ctx, st, "allow", set.New("b", "a"), etag); err != nil || e != etag {
+		t.Errorf("Publish failed: got %s, %v.\n", e, err)
+	}
+	if _, err := Publish(ctx, st, "allow", set.New("c"), `"stale"`); !errors.Is(err, ErrConflict) {
+		t.Errorf("Publish failed: got %v for a stale ETag.\n", err)
+	}
+	Publish(ctx, st, "allow", set.New("c"), etag)
+	if ok, _ := f.Refresh(ctx); !ok || !live.Load().IsEqual(set.New("c")) {
+		t.Errorf("Refresh failed: got %v.\n", live)
+	}
+
+	// a corrupted snapshot keeps the current version
+	st.objs["allow"] = []byte("garbage")
+	if ok, err := f.Refresh(ctx); ok || err == nil || !live.Load().IsEqual(set.New("c")) {
+		t.Errorf("Refresh failed: got %v, %v for a corrupted snapshot.\n", live, err)
+	}
+}
+
+func TestFetcherRun(t *testing.T) {
+	st := &memStore{objs: map[string][]byte{}}
+	Publish(context.Background(), st, "ids", set.New(1, 2, 3), "")
+	live := set.NewCOW[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewFetcher(st, "ids", live).Run(ctx, time.Millisecond, func(err error) { t.Error(err) })
+		close(done)
+	}()
+	for !live.Contains(1, 2, 3) {
+		time.Sleep(time.Millisecond)
+	}
+	for {
+		st.mu.Lock()
+		gets := st.gets
+		st.mu.Unlock()
+		if gets >= 3 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,84 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"cmp"
+	"sort"
+)
+
+// ----- algorithms on sorted slices -----
+
+// gallopRatio is the size ratio of two sorted slices above which galloping
+// search beats a linear merge for intersections.
+const gallopRatio = 32
+
+// IntersectSorted returns the intersection of two slices sorted in ascending
+// order without duplicates, as a new sorted slice. If the sizes of the slices
+// differ by a large factor, the larger one is searched with galloping
+// (exponential) search, which takes O(m log(n/m)) instead of O(m+n) steps.
+func IntersectSorted[T cmp.Ordered](a, b []T) []T {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) == 0 {
+		return []T{}
+	}
+	if len(b)/len(a) >= gallopRatio {
+		return intersectGallop(a, b)
+	}
+	return intersectMerge(a, b)
+}
+
+// intersectMerge intersects two sorted slices with a linear merge.
+func intersectMerge[T cmp.Ordered](a, b []T) []T {
+	r := make([]T, 0, len(a))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			r = append(r, a[i])
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// intersectGallop intersects a small sorted slice with a large one, using
+// galloping search in the large slice.
+func intersectGallop[T cmp.Ordered](small, large []T) []T {
+	r := make([]T, 0, len(small))
+	lo := 0
+	for _, x := range small {
+		lo = gallop(large, lo, x)
+		if lo == len(large) {
+			break
+		}
+		if large[lo] == x {
+			r = append(r, x)
+			lo++
+		}
+	}
+	return r
+}
+
+// gallop returns the smallest index i >= lo with s[i] >= x, or len(s). It
+// probes the positions lo, lo+1, lo+3, lo+7, ... before a binary search in
+// the last step.
+func gallop[T cmp.Ordered](s []T, lo int, x T) int {
+	step, hi := 1, lo
+	for hi < len(s) && s[hi] < x {
+		lo = hi + 1
+		hi += step
+		step *= 2
+	}
+	hi = min(hi, len(s))
+	return lo + sort.Search(hi-lo, func(i int) bool { return s[lo+i] >= x })
+}
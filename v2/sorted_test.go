@@ -0,0 +1,44 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIntersectSorted(t *testing.T) {
+	large := make([]int, 0, 10000)
+	for i := 0; i < 30000; i += 3 {
+		large = append(large, i)
+	}
+	small := []int{-1, 0, 4, 9, 100, 101, 102, 29997, 29999, 40000}
+	want := []int{0, 9, 102, 29997}
+
+	if r := IntersectSorted(small, large); !slices.Equal(r, want) {
+		t.Errorf("IntersectSorted failed (gallop): got %v, expected %v.\n", r, want)
+	}
+	if r := IntersectSorted(large, small); !slices.Equal(r, want) {
+		t.Errorf("IntersectSorted failed (gallop, swapped): got %v, expected %v.\n", r, want)
+	}
+	if r := IntersectSorted(small, large[:100]); !slices.Equal(r, []int{0, 9, 102}) {
+		t.Errorf("IntersectSorted failed (merge): got %v.\n", r)
+	}
+	if r := IntersectSorted(nil, large); len(r) != 0 {
+		t.Errorf("IntersectSorted failed on empty input: got %v.\n", r)
+	}
+
+	// both strategies agree on random-ish input
+	a := []int{}
+	for i := 0; i < 200; i++ {
+		a = append(a, i*i%1009)
+	}
+	slices.Sort(a)
+	a = slices.Compact(a)
+	if r1, r2 := intersectMerge(a, large), intersectGallop(a, large); !slices.Equal(r1, r2) {
+		t.Errorf("IntersectSorted failed: merge %v and gallop %v differ.\n", r1, r2)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+)
+
+// ----- SortedSet definition -----
+
+// the maximum height of the skip list, which suffices for 4^16 elements
+const maxSkipLevel = 16
+
+// A SortedSet is a set of ordered elements, backed by a skip list. Besides
+// membership tests in O(log n) time, it answers ordered queries like Min,
+// Max, Floor, Ceiling and Range, which a hash based set cannot. Iteration
+// yields the elements in ascending order.
+type SortedSet[T cmp.Ordered] struct {
+	head  skipNode[T] // sentinel; its value is unused
+	level int         // the number of levels in use
+	len   int
+	rng   *rand.Rand
+}
+
+// a node of the skip list with its forward pointers
+type skipNode[T cmp.Ordered] struct {
+	value T
+	next  []*skipNode[T]
+}
+
+// ----- constructor -----
+
+// NewSorted creates a new sorted set and initializes it with the argument
+// values.
+func NewSorted[T cmp.Ordered](e ...T) *SortedSet[T] {
+	s := &SortedSet[T]{
+		head:  skipNode[T]{next: make([]*skipNode[T], maxSkipLevel)},
+		level: 1,
+		rng:   rand.New(rand.NewPCG(1, 2)),
+	}
+	s.Add(e...)
+	return s
+}
+
+// search returns the last node before x on every level, and the first node
+// with a value >= x on the lowest level, or nil.
+func (s *SortedSet[T]) search(x T, update *[maxSkipLevel]*skipNode[T]) *skipNode[T] {
+	n := &s.head
+	for l := s.level - 1; l >= 0; l-- {
+		for n.next[l] != nil && n.next[l].value < x {
+			n = n.next[l]
+		}
+		if update != nil {
+			update[l] = n
+		}
+	}
+	return n.next[0]
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s *SortedSet[T]) Add(e ...T) {
+	var update [maxSkipLevel]*skipNode[T]
+	for _, x := range e {
+		if n := s.search(x, &update); n != nil && n.value == x {
+			continue
+		}
+		level := 1
+		for level < maxSkipLevel && s.rng.IntN(4) == 0 {
+			level++
+		}
+		for ; s.level < level; s.level++ {
+			update[s.level] = &s.head
+		}
+		n := &skipNode[T]{value: x, next: make([]*skipNode[T], level)}
+		for l := range level {
+			n.next[l] = update[l].next[l]
+			update[l].next[l] = n
+		}
+		s.len++
+	}
+}
+
+// Remove removes one or more elements from the given set.
+func (s *SortedSet[T]) Remove(e ...T) {
+	var update [maxSkipLevel]*skipNode[T]
+	for _, x := range e {
+		n := s.search(x, &update)
+		if n == nil || n.value != x {
+			continue
+		}
+		for l := range n.next {
+			update[l].next[l] = n.next[l]
+		}
+		for s.level > 1 && s.head.next[s.level-1] == nil {
+			s.level--
+		}
+		s.len--
+	}
+}
+
+// Clear removes all elements from the given set.
+func (s *SortedSet[T]) Clear() {
+	clear(s.head.next)
+	s.level, s.len = 1, 0
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *SortedSet[T]) IsEmpty() bool {
+	return s.len == 0
+}
+
+// Len returns the length of the set.
+func (s *SortedSet[T]) Len() int {
+	return s.len
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *SortedSet[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if n := s.search(x, nil); n == nil || n.value != x {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsSorted checks the membership of each element of a query slice,
+// which must be sorted in ascending order. The skip list and the query are
+// scanned in a single merge pass along the lowest level. The result is
+// undefined if the query is not sorted.
+func (s *SortedSet[T]) ContainsSorted(sorted []T) []bool {
+	r := make([]bool, len(sorted))
+	n := s.head.next[0]
+	for i, x := range sorted {
+		for n != nil && n.value < x {
+			n = n.next[0]
+		}
+		if n == nil {
+			break
+		}
+		r[i] = n.value == x
+	}
+	return r
+}
+
+// Min returns the smallest element. The second return value is false if the
+// set is empty.
+func (s *SortedSet[T]) Min() (T, bool) {
+	if n := s.head.next[0]; n != nil {
+		return n.value, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Max returns the largest element. The second return value is false if the
+// set is empty.
+func (s *SortedSet[T]) Max() (T, bool) {
+	n := &s.head
+	for l := s.level - 1; l >= 0; l-- {
+		for n.next[l] != nil {
+			n = n.next[l]
+		}
+	}
+	return n.value, n != &s.head
+}
+
+// Floor returns the largest element <= x. The second return value is false
+// if there is no such element.
+func (s *SortedSet[T]) Floor(x T) (T, bool) {
+	var update [maxSkipLevel]*skipNode[T]
+	if n := s.search(x, &update); n != nil && n.value == x {
+		return x, true
+	}
+	return update[0].value, update[0] != &s.head
+}
+
+// Ceiling returns the smallest element >= x. The second return value is
+// false if there is no such element.
+func (s *SortedSet[T]) Ceiling(x T) (T, bool) {
+	if n := s.search(x, nil); n != nil {
+		return n.value, true
+	}
+	var zero T
+	return zero, false
+}
+
+// ----- set operations -----
+
+// Intersect returns a new sorted set with the elements which are in s and
+// in all of the argument sets. The sets are intersected pairwise with
+// IntersectSorted, which gallops through much larger sets.
+func (s *SortedSet[T]) Intersect(t ...*SortedSet[T]) *SortedSet[T] {
+	l := s.List()
+	for _, u := range t {
+		if len(l) == 0 {
+			break
+		}
+		l = IntersectSorted(l, u.List())
+	}
+	return NewSorted(l...)
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all elements in ascending order.
+func (s *SortedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := s.head.next[0]; n != nil; n = n.next[0] {
+			if !yield(n.value) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator to the elements of the closed interval
+// [lo, hi] in ascending order.
+func (s *SortedSet[T]) Range(lo, hi T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := s.search(lo, nil); n != nil && n.value <= hi; n = n.next[0] {
+			if !yield(n.value) {
+				return
+			}
+		}
+	}
+}
+
+// List returns the set elements in ascending order in a slice.
+func (s *SortedSet[T]) List() []T {
+	l := make([]T, 0, s.len)
+	for x := range s.All() {
+		l = append(l, x)
+	}
+	return l
+}
+
+// Set returns the elements as a new, unordered set.
+func (s *SortedSet[T]) Set() Set[T] {
+	r := Set[T]{set: make(map[T]struct{}, s.len)}
+	for x := range s.All() {
+		r.set[x] = struct{}{}
+	}
+	return r
+}
+
+// String returns a textual representation of the set in a string, with the
+// elements in ascending order.
+func (s *SortedSet[T]) String() string {
+	b := make([]byte, 0, 2+8*s.len)
+	b = append(b, "{ "...)
+	for x := range s.All() {
+		b = append(fmt.Append(b, x), ' ')
+	}
+	b = append(b, '}')
+	return string(b)
+}
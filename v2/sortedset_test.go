@@ -0,0 +1,104 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSortedSet(t *testing.T) {
+	s := NewSorted(50, 10, 30, 20, 40, 30)
+	if l := s.List(); !slices.Equal(l, []int{10, 20, 30, 40, 50}) || s.Len() != 5 {
+		t.Errorf("NewSorted failed: got %v.\n", l)
+	}
+	if m, _ := s.Min(); m != 10 {
+		t.Errorf("Min failed: got %d.\n", m)
+	}
+	if m, _ := s.Max(); m != 50 {
+		t.Errorf("Max failed: got %d.\n", m)
+	}
+	if f, ok := s.Floor(35); f != 30 || !ok {
+		t.Errorf("Floor failed: got %d.\n", f)
+	}
+	if f, ok := s.Floor(30); f != 30 || !ok {
+		t.Errorf("Floor failed on an element: got %d.\n", f)
+	}
+	if _, ok := s.Floor(5); ok {
+		t.Errorf("Floor failed: found an element below the minimum.\n")
+	}
+	if c, ok := s.Ceiling(35); c != 40 || !ok {
+		t.Errorf("Ceiling failed: got %d.\n", c)
+	}
+	if _, ok := s.Ceiling(51); ok {
+		t.Errorf("Ceiling failed: found an element above the maximum.\n")
+	}
+	if r := slices.Collect(s.Range(15, 40)); !slices.Equal(r, []int{20, 30, 40}) {
+		t.Errorf("Range failed: got %v.\n", r)
+	}
+	s.Remove(10, 50, 99)
+	if str := s.String(); str != "{ 20 30 40 }" || s.Contains(10) || !s.Contains(20, 40) {
+		t.Errorf("Remove failed: got %s.\n", str)
+	}
+	if !s.Set().IsEqual(New(20, 30, 40)) {
+		t.Errorf("Set failed: got %v.\n", s.Set())
+	}
+	s.Clear()
+	if _, ok := s.Max(); ok || !s.IsEmpty() {
+		t.Errorf("Clear failed: got %v.\n", s)
+	}
+}
+
+func TestSortedSetLarge(t *testing.T) {
+	s := NewSorted[int]()
+	ref := New[int]()
+	for i := 0; i < 10000; i++ {
+		x := i * 7919 % 10007
+		s.Add(x)
+		ref.Add(x)
+		if i%3 == 0 {
+			s.Remove(x / 2)
+			ref.Remove(x / 2)
+		}
+	}
+	l := s.List()
+	if !slices.IsSorted(l) || len(l) != ref.Len() || !s.Set().IsEqual(ref) {
+		t.Errorf("SortedSet failed: inconsistent with the reference set.\n")
+	}
+}
+
+func TestSortedSetIntersect(t *testing.T) {
+	a := NewSorted(1, 3, 5, 7, 9)
+	b := NewSorted[int]()
+	for i := 0; i < 1000; i += 3 {
+		b.Add(i)
+	}
+	c := NewSorted(3, 9, 10)
+	if r := a.Intersect(b); !slices.Equal(r.List(), []int{3, 9}) {
+		t.Errorf("Intersect failed: got %v.\n", r)
+	}
+	if r := a.Intersect(b, c); !slices.Equal(r.List(), []int{3, 9}) {
+		t.Errorf("Intersect failed: got %v.\n", r)
+	}
+	if r := a.Intersect(NewSorted[int](), b); !r.IsEmpty() {
+		t.Errorf("Intersect failed: got %v.\n", r)
+	}
+	if r := a.Intersect(); !slices.Equal(r.List(), a.List()) {
+		t.Errorf("Intersect failed: got %v.\n", r)
+	}
+}
+
+func TestSortedSetContainsSorted(t *testing.T) {
+	s := NewSorted(2, 4, 6, 8)
+	got := s.ContainsSorted([]int{1, 2, 2, 5, 6, 8, 9})
+	want := []bool{false, true, true, false, true, true, false}
+	if !slices.Equal(got, want) {
+		t.Errorf("ContainsSorted failed: got %v, expected %v.\n", got, want)
+	}
+	if got := NewSorted[int]().ContainsSorted([]int{1}); got[0] {
+		t.Errorf("ContainsSorted failed on empty set.\n")
+	}
+}
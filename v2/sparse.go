@@ -0,0 +1,152 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// ----- SparseSet definition -----
+
+// A SparseSet is a set of integer IDs in the range [0, n), like the entity
+// IDs of a game engine. It stores the elements in a dense slice, and the
+// position of each element in a sparse index of size n. Add, Remove,
+// Contains and Clear take O(1) time without hashing, and iteration visits
+// only the elements. Elements out of range are ignored.
+//
+// The sparse index takes 4 bytes per possible ID, so a SparseSet is meant for
+// bounded ranges; use BitSet or RoaringSet for large domains.
+type SparseSet[T Integer] struct {
+	dense  []T
+	sparse []uint32 // sparse[x] is the position of x in dense, if x is in the set
+}
+
+// ----- constructor -----
+
+// NewSparse creates a new sparse set for the IDs in the range [0, n), and
+// initializes it with the argument values.
+func NewSparse[T Integer](n int, e ...T) *SparseSet[T] {
+	s := &SparseSet[T]{sparse: make([]uint32, max(n, 0))}
+	s.Add(e...)
+	return s
+}
+
+// index returns the index of x in the sparse slice, or -1 if x is out of
+// range.
+func (s *SparseSet[T]) index(x T) int {
+	if x < 0 || uint64(x) >= uint64(len(s.sparse)) {
+		return -1
+	}
+	return int(x)
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s *SparseSet[T]) Add(e ...T) {
+	for _, x := range e {
+		if i := s.index(x); i >= 0 && !s.contains(i, x) {
+			s.sparse[i] = uint32(len(s.dense))
+			s.dense = append(s.dense, x)
+		}
+	}
+}
+
+// Remove removes one or more elements from the given set. The last element
+// of the dense slice takes the place of each removed element.
+func (s *SparseSet[T]) Remove(e ...T) {
+	for _, x := range e {
+		i := s.index(x)
+		if i < 0 || !s.contains(i, x) {
+			continue
+		}
+		p, last := s.sparse[i], s.dense[len(s.dense)-1]
+		s.dense[p] = last
+		s.sparse[int(last)] = p
+		s.dense = s.dense[:len(s.dense)-1]
+	}
+}
+
+// Clear removes all elements from the given set in O(1) time. The sparse
+// index is not touched, as stale entries are detected by Contains.
+func (s *SparseSet[T]) Clear() {
+	s.dense = s.dense[:0]
+}
+
+// ----- methods that do not modify the receiver -----
+
+// contains checks if x with the sparse index i is in the set.
+func (s *SparseSet[T]) contains(i int, x T) bool {
+	p := s.sparse[i]
+	return int(p) < len(s.dense) && s.dense[p] == x
+}
+
+// IsEmpty tests if the set is empty.
+func (s *SparseSet[T]) IsEmpty() bool {
+	return len(s.dense) == 0
+}
+
+// Len returns the length of the set.
+func (s *SparseSet[T]) Len() int {
+	return len(s.dense)
+}
+
+// Cap returns the size n of the range [0, n) of possible elements.
+func (s *SparseSet[T]) Cap() int {
+	return len(s.sparse)
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *SparseSet[T]) Contains(e ...T) bool {
+	for _, x := range e {
+		if i := s.index(x); i < 0 || !s.contains(i, x) {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy returns a copy of a set. The set s is not modified.
+func (s *SparseSet[T]) Copy() *SparseSet[T] {
+	return &SparseSet[T]{dense: slices.Clone(s.dense), sparse: slices.Clone(s.sparse)}
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all elements, in the order of the dense slice.
+// It is the order of insertion, as far as no elements were removed.
+func (s *SparseSet[T]) All() iter.Seq[T] {
+	return slices.Values(s.dense)
+}
+
+// List returns the set elements in a slice, in the order of All.
+func (s *SparseSet[T]) List() []T {
+	return slices.Clone(s.dense)
+}
+
+// Set returns the elements as a new, unordered set.
+func (s *SparseSet[T]) Set() Set[T] {
+	r := Set[T]{set: make(map[T]struct{}, len(s.dense))}
+	for _, x := range s.dense {
+		r.set[x] = struct{}{}
+	}
+	return r
+}
+
+// String returns a textual representation of the set in a string, with the
+// elements in ascending order.
+func (s *SparseSet[T]) String() string {
+	b := make([]byte, 0, 2+8*len(s.dense))
+	b = append(b, "{ "...)
+	for _, x := range slices.Sorted(slices.Values(s.dense)) {
+		b = append(fmt.Append(b, x), ' ')
+	}
+	b = append(b, '}')
+	return string(b)
+}
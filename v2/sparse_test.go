@@ -0,0 +1,56 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSparseSet(t *testing.T) {
+	s := NewSparse[int32](100, 5, 3, 99, 5, -1, 100)
+	if s.Len() != 3 || !s.Contains(3, 5, 99) || s.Contains(-1) || s.Contains(100) || s.Cap() != 100 {
+		t.Errorf("NewSparse failed: got %v.\n", s)
+	}
+	if !slices.Equal(s.List(), []int32{5, 3, 99}) {
+		t.Errorf("List failed: got %v, expected insertion order.\n", s.List())
+	}
+
+	// the last element takes the place of a removed one
+	s.Remove(5, 42)
+	if !slices.Equal(s.List(), []int32{99, 3}) || s.Contains(5) {
+		t.Errorf("Remove failed: got %v.\n", s.List())
+	}
+	if s.String() != "{ 3 99 }" || !s.Set().IsEqual(New[int32](3, 99)) {
+		t.Errorf("String/Set failed: got %v.\n", s)
+	}
+
+	c := s.Copy()
+	s.Clear()
+	if !s.IsEmpty() || s.Contains(3) || !c.Contains(3, 99) {
+		t.Errorf("Clear failed: got %v, copy %v.\n", s, c)
+	}
+	// stale entries of the sparse index are not mistaken for elements
+	s.Add(7)
+	if s.Contains(3) || !s.Contains(7) || s.Len() != 1 {
+		t.Errorf("Add after Clear failed: got %v.\n", s)
+	}
+
+	u := NewSparse[uint8](300, 255)
+	if !u.Contains(255) {
+		t.Errorf("NewSparse failed for uint8: got %v.\n", u)
+	}
+}
+
+func BenchmarkSparseSet(b *testing.B) {
+	s := NewSparse[int](1 << 16)
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 1024; j++ {
+			s.Add(j * 61 & 0xffff)
+		}
+		s.Clear()
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ----- specializations for common element types -----
+
+// The Go runtime already uses specialized map implementations for string
+// and 32/64 bit integer keys, so lookups and insertions need no help. The
+// remaining gap to hand written typed sets lies in the code which formats
+// elements through the reflection based fmt package. The functions below
+// switch on the concrete map type once per call, and then use strconv in a
+// tight loop for the common element types.
+//
+// The hashes computed by the set types of this module itself, like
+// ShardedSet and hamt.ImmutableSet, are specialized the same way: integers
+// of all predeclared types and strings skip the generic maphash path, see
+// internal/hash.Of.
+//
+// A Set does not promote small integer element types to a bitmap
+// automatically. Set is a value type whose copies share one map, so it
+// cannot switch its representation behind the back of the other copies,
+// and many operations access the map directly. Dense integer sets should
+// use BitSet, PortSet or RoaringSet explicitly instead.
+
+// appendElems appends the elements of m to b, each followed by a space, in
+// the format of fmt.Sprint.
+func appendElems[T comparable](b []byte, m map[T]struct{}) []byte {
+	switch tm := any(m).(type) {
+	case map[string]struct{}:
+		for k := range tm {
+			b = append(append(b, k...), ' ')
+		}
+	case map[int]struct{}:
+		for k := range tm {
+			b = append(strconv.AppendInt(b, int64(k), 10), ' ')
+		}
+	case map[int64]struct{}:
+		for k := range tm {
+			b = append(strconv.AppendInt(b, k, 10), ' ')
+		}
+	case map[int32]struct{}:
+		for k := range tm {
+			b = append(strconv.AppendInt(b, int64(k), 10), ' ')
+		}
+	case map[uint64]struct{}:
+		for k := range tm {
+			b = append(strconv.AppendUint(b, k, 10), ' ')
+		}
+	case map[uint32]struct{}:
+		for k := range tm {
+			b = append(strconv.AppendUint(b, uint64(k), 10), ' ')
+		}
+	default:
+		for k := range m {
+			b = append(fmt.Append(b, k), ' ')
+		}
+	}
+	return b
+}
@@ -0,0 +1,48 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+type testPoint struct{ x, y int }
+
+func TestSpecializedString(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{New("a").String(), "{ a }"},
+		{New(-5).String(), "{ -5 }"},
+		{New[int64](-7).String(), "{ -7 }"},
+		{New[int32](8).String(), "{ 8 }"},
+		{New[uint64](1 << 63).String(), "{ 9223372036854775808 }"},
+		{New[uint32](9).String(), "{ 9 }"},
+		{New(1.5).String(), "{ 1.5 }"},
+		{New(testPoint{1, 2}).String(), "{ {1 2} }"},
+		{New[int]().String(), "{ }"},
+	}
+	for _, i := range tests {
+		if i.got != i.want {
+			t.Errorf("String failed: got %q, expected %q.\n", i.got, i.want)
+		}
+	}
+	// the specialized formatting agrees with fmt
+	if s, f := New(42).String(), fmt.Sprintf("{ %v }", 42); s != f {
+		t.Errorf("String failed: %q differs from fmt output %q.\n", s, f)
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	s := New[int]()
+	for i := 0; i < 1000; i++ {
+		s.Add(i)
+	}
+	for i := 0; i < b.N; i++ {
+		_ = s.String()
+	}
+}
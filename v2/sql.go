@@ -0,0 +1,207 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ----- database columns -----
+
+// A Column adapts a set to a database column for database/sql. It
+// implements sql.Scanner, to be passed to Scan, and driver.Valuer, to be
+// passed as a query argument. A NULL column scans as an empty set. Columns
+// are created by TextColumn, JSONColumn and PGArray.
+type Column[T comparable] struct {
+	s      *Set[T]
+	format columnFormat
+}
+
+// the encodings of a column
+type columnFormat int
+
+const (
+	columnText columnFormat = iota
+	columnJSON
+	columnPGArray
+)
+
+// TextColumn returns a column which stores the set as a delimited string,
+// like "a,b,c", encoded by MarshalText with the separator of the set.
+func TextColumn[T comparable](s *Set[T]) Column[T] {
+	return Column[T]{s, columnText}
+}
+
+// JSONColumn returns a column which stores the set as a JSON array, like for
+// the json and jsonb types of PostgreSQL or the JSON type of MySQL.
+func JSONColumn[T comparable](s *Set[T]) Column[T] {
+	return Column[T]{s, columnJSON}
+}
+
+// PGArray returns a column which stores the set as a one-dimensional
+// PostgreSQL array, like text[] or integer[], in the text format of array
+// literals, like {a,"b c"}. The elements are converted to and from text like
+// by MarshalText; an array with NULL elements cannot be scanned.
+func PGArray[T comparable](s *Set[T]) Column[T] {
+	return Column[T]{s, columnPGArray}
+}
+
+// Scan implements the sql.Scanner interface. It replaces the elements of the
+// set with the elements of the column value.
+func (c Column[T]) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		return c.s.UnmarshalText(nil)
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into a set", ErrWrongType, src)
+	}
+	switch c.format {
+	case columnJSON:
+		return c.s.UnmarshalJSON(b)
+	case columnPGArray:
+		return c.scanPGArray(string(b))
+	}
+	return c.s.UnmarshalText(b)
+}
+
+// Value implements the driver.Valuer interface. The value is a string, with
+// the elements in ascending order of their text like for MarshalText, or in
+// the order of MarshalJSON.
+func (c Column[T]) Value() (driver.Value, error) {
+	var b []byte
+	var err error
+	switch c.format {
+	case columnJSON:
+		b, err = c.s.MarshalJSON()
+	case columnPGArray:
+		return c.pgArray()
+	default:
+		b, err = c.s.MarshalText()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// ----- PostgreSQL array literals -----
+
+// errPGArray is returned for malformed PostgreSQL array literals.
+var errPGArray = errors.New("set: malformed PostgreSQL array")
+
+// pgArray returns the set as a PostgreSQL array literal.
+func (c Column[T]) pgArray() (string, error) {
+	l := make([]string, 0, c.s.Len())
+	for k := range c.s.set {
+		t, err := formatText(k)
+		if err != nil {
+			return "", err
+		}
+		l = append(l, t)
+	}
+	slices.Sort(l)
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, t := range l {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if !pgNeedsQuotes(t) {
+			b.WriteString(t)
+			continue
+		}
+		b.WriteByte('"')
+		for _, r := range t {
+			if r == '"' || r == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// pgNeedsQuotes checks if an element of an array literal must be quoted.
+func pgNeedsQuotes(t string) bool {
+	return t == "" || strings.EqualFold(t, "NULL") || strings.ContainsAny(t, "{},\"\\ \t\n\r\v\f")
+}
+
+// scanPGArray replaces the elements of the set with those of a PostgreSQL
+// array literal.
+func (c Column[T]) scanPGArray(t string) error {
+	t = strings.TrimSpace(t)
+	if len(t) < 2 || t[0] != '{' || t[len(t)-1] != '}' {
+		return errPGArray
+	}
+	t = t[1 : len(t)-1]
+	var l []T
+	for pos := 0; strings.TrimSpace(t) != ""; pos++ {
+		t = strings.TrimLeft(t, " \t\n\r\v\f")
+		var f string
+		if t[0] == '"' {
+			var b strings.Builder
+			i := 1
+			for ; i < len(t) && t[i] != '"'; i++ {
+				if t[i] == '\\' {
+					i++
+					if i == len(t) {
+						break
+					}
+				}
+				b.WriteByte(t[i])
+			}
+			if i >= len(t) {
+				return errPGArray
+			}
+			f, t = b.String(), strings.TrimLeft(t[i+1:], " \t\n\r\v\f")
+		} else {
+			i := strings.IndexByte(t, ',')
+			if i < 0 {
+				i = len(t)
+			}
+			f, t = strings.TrimSpace(t[:i]), t[i:]
+			if f == "" || strings.ContainsAny(f, "{}\"") {
+				return errPGArray
+			}
+			if strings.EqualFold(f, "NULL") {
+				return &DecodeError{Pos: pos, Input: f, Err: errors.New("NULL element")}
+			}
+		}
+		e, err := parseText[T](f)
+		if errors.Is(err, ErrWrongType) {
+			return err
+		}
+		if err != nil {
+			return &DecodeError{Pos: pos, Input: f, Err: err}
+		}
+		l = append(l, e)
+
+		// the elements are separated by commas
+		if t != "" {
+			if t[0] != ',' || strings.TrimSpace(t[1:]) == "" {
+				return errPGArray
+			}
+			t = t[1:]
+		}
+	}
+	if c.s.set == nil {
+		c.s.set = make(map[T]struct{}, len(l))
+	} else {
+		clear(c.s.set)
+	}
+	return c.s.TryAdd(l...)
+}
@@ -0,0 +1,76 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// the columns implement the interfaces of database/sql
+var (
+	_ sql.Scanner   = Column[string]{}
+	_ driver.Valuer = Column[string]{}
+)
+
+func TestColumn(t *testing.T) {
+	s := New("go", "sql")
+	for _, c := range []struct {
+		col  func(*Set[string]) Column[string]
+		want string
+	}{
+		{TextColumn[string], "go,sql"},
+		{JSONColumn[string], ""},
+		{PGArray[string], "{go,sql}"},
+	} {
+		v, err := c.col(&s).Value()
+		if err != nil || (c.want != "" && v != c.want) {
+			t.Errorf("Value failed: got %v, %v.\n", v, err)
+		}
+		var r Set[string]
+		if err := c.col(&r).Scan([]byte(v.(string))); err != nil || !r.IsEqual(s) {
+			t.Errorf("Scan failed for %v: got %v, %v.\n", v, r, err)
+		}
+		if err := c.col(&r).Scan(nil); err != nil || !r.IsEmpty() {
+			t.Errorf("Scan failed for NULL: got %v, %v.\n", r, err)
+		}
+	}
+	var r Set[string]
+	if err := TextColumn(&r).Scan(42); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Scan failed: got %v for an integer.\n", err)
+	}
+}
+
+func TestPGArray(t *testing.T) {
+	s := New("", "a b", `q"uote`, `back\slash`, "null", "x,y", "plain")
+	v, err := PGArray(&s).Value()
+	if err != nil || v != `{"","a b","back\\slash","null",plain,"q\"uote","x,y"}` {
+		t.Errorf("Value failed: got %v, %v.\n", v, err)
+	}
+	var r Set[string]
+	if err := PGArray(&r).Scan(v); err != nil || !r.IsEqual(s) {
+		t.Errorf("Scan failed: got %v, %v.\n", r, err)
+	}
+
+	var ints Set[int]
+	if err := PGArray(&ints).Scan(" { 1, 2 ,3} "); err != nil || !ints.IsEqual(New(1, 2, 3)) {
+		t.Errorf("Scan failed for integers: got %v, %v.\n", ints, err)
+	}
+	if err := PGArray(&ints).Scan("{}"); err != nil || !ints.IsEmpty() {
+		t.Errorf("Scan failed for an empty array: got %v, %v.\n", ints, err)
+	}
+	var de *DecodeError
+	for _, in := range []string{"1,2", "{1,}", "{,1}", `{"1}`, "{{1},{2}}", "{1 2}"} {
+		if err := PGArray(&ints).Scan(in); err == nil {
+			t.Errorf("Scan failed: no error for %q.\n", in)
+		}
+	}
+	if err := PGArray(&ints).Scan("{1,NULL}"); !errors.As(err, &de) || de.Pos != 1 {
+		t.Errorf("Scan failed: got %v for a NULL element.\n", err)
+	}
+}
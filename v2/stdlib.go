@@ -0,0 +1,127 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import "iter"
+
+// ----- adapters matching the maps and slices packages -----
+
+// Clone returns a copy of the set, like maps.Clone. It is the same as Copy.
+func (s Set[T]) Clone() Set[T] {
+	return s.Copy()
+}
+
+// Insert adds the values of an iterator to the set, like maps.Insert.
+func (s Set[T]) Insert(seq iter.Seq[T]) {
+	for i := range seq {
+		s.Add(i)
+	}
+}
+
+// DeleteFunc removes all elements for which del returns true, like
+// maps.DeleteFunc.
+func (s Set[T]) DeleteFunc(del func(T) bool) {
+	for k := range s.set {
+		if del(k) {
+			delete(s.set, k)
+		}
+	}
+}
+
+// ContainsFunc checks if at least one element satisfies f, like
+// slices.ContainsFunc.
+func (s Set[T]) ContainsFunc(f func(T) bool) bool {
+	for k := range s.set {
+		if f(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// EqualFunc tests if two sets, possibly of different element types, are
+// equal under the equality function eq, like maps.EqualFunc. The sets are
+// equal if their elements can be paired one to one, such that eq holds for
+// each pair. Sets of different lengths are never equal. Otherwise, EqualFunc
+// first pairs each element of s with the first unpaired element of t for
+// which eq holds, which calls eq O(len(s)*len(t)) times. Only if this fails,
+// it calls eq for all pairs, and finds the pairing with augmenting paths,
+// which takes O(len(s)^3) steps in the worst case.
+func EqualFunc[T, U comparable](s Set[T], t Set[U], eq func(T, U) bool) bool {
+	if len(s.set) != len(t.set) {
+		return false
+	}
+	us := make([]U, 0, len(t.set))
+	for l := range t.set {
+		us = append(us, l)
+	}
+	if equalGreedy(s, us, eq) {
+		return true
+	}
+
+	// adj[i] lists the elements of t which are equal to the i-th element of s
+	var adj [][]int
+	for k := range s.set {
+		var a []int
+		for j, l := range us {
+			if eq(k, l) {
+				a = append(a, j)
+			}
+		}
+		if a == nil {
+			return false
+		}
+		adj = append(adj, a)
+	}
+
+	// match[j] is the index of the element of s paired with us[j], or -1
+	match := make([]int, len(us))
+	for j := range match {
+		match[j] = -1
+	}
+	seen := make([]bool, len(us))
+	var augment func(i int) bool
+	augment = func(i int) bool {
+		for _, j := range adj[i] {
+			if seen[j] {
+				continue
+			}
+			seen[j] = true
+			if match[j] < 0 || augment(match[j]) {
+				match[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range adj {
+		clear(seen)
+		if !augment(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalGreedy reports if the elements of s can be paired with the elements
+// us by taking the first unpaired match of each element. A false result does
+// not imply that there is no pairing. It reorders us.
+func equalGreedy[T, U comparable](s Set[T], us []U, eq func(T, U) bool) bool {
+	// the unpaired elements are kept in us[n:]
+	n := 0
+next:
+	for k := range s.set {
+		for j := n; j < len(us); j++ {
+			if eq(k, us[j]) {
+				us[n], us[j] = us[j], us[n]
+				n++
+				continue next
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestStdlibAdapters(t *testing.T) {
+	s := New(1, 2, 3, 4)
+	c := s.Clone()
+	c.Insert(slices.Values([]int{5, 6}))
+	if s.Len() != 4 || !c.IsEqual(New(1, 2, 3, 4, 5, 6)) {
+		t.Errorf("Clone/Insert failed: got %v and %v.\n", s, c)
+	}
+	c.Insert(maps.Keys(map[int]bool{7: true}))
+	c.DeleteFunc(func(i int) bool { return i%2 == 0 })
+	if !c.IsEqual(New(1, 3, 5, 7)) {
+		t.Errorf("DeleteFunc failed: got %v.\n", c)
+	}
+	if !c.ContainsFunc(func(i int) bool { return i > 6 }) || c.ContainsFunc(func(i int) bool { return i > 7 }) {
+		t.Errorf("ContainsFunc failed.\n")
+	}
+
+	strs := New("1", "3", "5", "7")
+	eq := func(i int, s string) bool { return strconv.Itoa(i) == s }
+	if !EqualFunc(c, strs, eq) {
+		t.Errorf("EqualFunc failed: equal sets reported as different.\n")
+	}
+	if EqualFunc(c, New("1", "3", "5", "8"), eq) || EqualFunc(c, New("1"), eq) {
+		t.Errorf("EqualFunc failed: different sets reported as equal.\n")
+	}
+
+	// the elements must be paired one to one
+	toA := func(int, string) bool { return true }
+	if !EqualFunc(New(1, 2), New("a", "b"), toA) {
+		t.Errorf("EqualFunc failed: equal sets reported as different.\n")
+	}
+	onlyA := func(_ int, u string) bool { return u == "a" }
+	if EqualFunc(New(1, 2), New("a", "b"), onlyA) {
+		t.Errorf("EqualFunc failed: two elements paired with the same element.\n")
+	}
+	// a pairing which needs to reassign an earlier choice
+	pairs := map[int][]string{1: {"x", "y"}, 2: {"x"}, 3: {"y", "z"}}
+	inPairs := func(k int, u string) bool { return slices.Contains(pairs[k], u) }
+	if !EqualFunc(New(1, 2, 3), New("x", "y", "z"), inPairs) {
+		t.Errorf("EqualFunc failed: one to one pairing not found.\n")
+	}
+
+	// sets of different lengths do not call eq, and equal sets are paired
+	// greedily
+	calls := 0
+	counting := func(i int, s string) bool {
+		calls++
+		return eq(i, s)
+	}
+	if EqualFunc(New(1, 2), New("1"), counting) || calls != 0 {
+		t.Errorf("EqualFunc failed: called eq %d times for sets of different lengths.\n", calls)
+	}
+	if !EqualFunc(c, strs, counting) || calls > 4*5/2 {
+		t.Errorf("EqualFunc failed: called eq %d times for a greedy pairing.\n", calls)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// A DuplicateError is returned by the strict constructors and loaders when
+// their input contains duplicate elements.
+type DuplicateError[T comparable] struct {
+	Elements []T // the duplicate elements, in the order of their first repetition
+}
+
+func (e *DuplicateError[T]) Error() string {
+	return fmt.Sprintf("set: duplicate elements %v", e.Elements)
+}
+
+// Is reports whether the target is ErrDuplicate.
+func (e *DuplicateError[T]) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// ----- strict constructors -----
+
+// NewStrict creates a new set and initializes it with the argument values,
+// like New. If the arguments contain duplicates, a *DuplicateError
+// listing them is returned together with the set of all distinct values.
+func NewStrict[T comparable](e ...T) (Set[T], error) {
+	s := New[T]()
+	return s, s.addStrict(func(yield func(T) bool) {
+		for _, i := range e {
+			if !yield(i) {
+				return
+			}
+		}
+	}, false)
+}
+
+// CollectStrict creates a new set from the values of the iterator. If the
+// iterator yields duplicates, a *DuplicateError listing them is returned
+// together with the set of all distinct values.
+func CollectStrict[T comparable](seq iter.Seq[T]) (Set[T], error) {
+	s := New[T]()
+	return s, s.addStrict(seq, false)
+}
+
+// ----- strict loaders -----
+
+// AddStrict adds one or more elements to the given set. If any of the
+// elements is already in the set or repeated in the arguments, the set is
+// not modified and a *DuplicateError listing the offending elements is
+// returned. Like TryAdd, it adds the canonical forms of the elements, and
+// invalid or foreign elements are reported as a *ValidationError, joined
+// with the *DuplicateError; they leave the set unmodified too.
+func (s Set[T]) AddStrict(e ...T) error {
+	if s.set == nil {
+		return s.misuse("AddStrict", errUninitialized)
+	}
+	return s.addStrict(func(yield func(T) bool) {
+		for _, i := range e {
+			if !yield(i) {
+				return
+			}
+		}
+	}, true)
+}
+
+// addStrict adds the values of seq to s and reports duplicates. If atomic is
+// true, elements already in s are duplicates too, and s is only modified if
+// there are no duplicates and no invalid elements at all.
+func (s Set[T]) addStrict(seq iter.Seq[T], atomic bool) error {
+	target := s
+	if atomic {
+		target = New[T]()
+	}
+	var dup []T
+	var errs []error
+	reported := New[T]()
+	for i := range seq {
+		i, err := s.admit(i)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, seen := target.set[i]
+		if atomic && !seen {
+			_, seen = s.set[i]
+		}
+		if seen {
+			if !reported.Contains(i) {
+				reported.Add(i)
+				dup = append(dup, i)
+			}
+			continue
+		}
+		target.set[i] = struct{}{}
+	}
+	if dup != nil {
+		errs = append([]error{&DuplicateError[T]{Elements: dup}}, errs...)
+	}
+	switch len(errs) {
+	case 0:
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+	if atomic {
+		for k := range target.set {
+			s.set[k] = struct{}{}
+		}
+	}
+	return nil
+}
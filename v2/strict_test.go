@@ -0,0 +1,77 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	s, err := NewStrict(1, 2, 3)
+	if err != nil || s.Len() != 3 {
+		t.Errorf("NewStrict failed: got %v/%v.\n", s, err)
+	}
+
+	str, err := NewStrict("a", "b", "a", "c", "b", "a")
+	var de *DuplicateError[string]
+	if !errors.As(err, &de) || !slices.Equal(de.Elements, []string{"a", "b"}) || str.Len() != 3 {
+		t.Errorf("NewStrict failed: got %v/%v.\n", str, err)
+	}
+	if err.Error() != "set: duplicate elements [a b]" {
+		t.Errorf("DuplicateError failed: got %q.\n", err.Error())
+	}
+
+	str, err = CollectStrict(slices.Values([]string{"x", "y", "x"}))
+	if err == nil || str.Len() != 2 {
+		t.Errorf("CollectStrict failed: got %v/%v.\n", str, err)
+	}
+
+	a := New(1, 2)
+	if err := a.AddStrict(3, 4); err != nil || a.Len() != 4 {
+		t.Errorf("AddStrict failed: got %v/%v.\n", a, err)
+	}
+	err = a.AddStrict(5, 1, 6, 6)
+	var di *DuplicateError[int]
+	if !errors.As(err, &di) || !slices.Equal(di.Elements, []int{1, 6}) || a.Len() != 4 {
+		t.Errorf("AddStrict failed: got %v/%v.\n", a, err)
+	}
+}
+
+func TestAddStrictOptions(t *testing.T) {
+	c := NewWith(WithCanonicalizer(strings.ToLower))
+	if err := c.AddStrict("A", "b"); err != nil || !slices.Equal(slices.Sorted(maps.Keys(c.set)), []string{"a", "b"}) {
+		t.Errorf("AddStrict with canonicalizer failed: got %v/%v.\n", c, err)
+	}
+	if err := c.AddStrict("B"); err == nil || c.Len() != 2 {
+		t.Errorf("AddStrict with canonicalizer failed: got %v/%v.\n", c, err)
+	}
+
+	errOdd := errors.New("odd")
+	v := NewWith(WithValidator(func(i int) error {
+		if i%2 != 0 {
+			return errOdd
+		}
+		return nil
+	}))
+	err := v.AddStrict(2, 3, 4)
+	var ve *ValidationError[int]
+	if !errors.As(err, &ve) || ve.Elem != 3 || !errors.Is(err, errOdd) || v.Len() != 0 {
+		t.Errorf("AddStrict with validator failed: got %v/%v.\n", v, err)
+	}
+
+	u := NewUniverse("colors", "red", "green", "blue")
+	us, _ := u.New("red")
+	if err := us.AddStrict("green", "xyz"); !errors.Is(err, ErrForeignElement) || us.Len() != 1 {
+		t.Errorf("AddStrict with universe failed: got %v/%v.\n", us, err)
+	}
+	if err := us.AddStrict("green"); err != nil || us.Len() != 2 {
+		t.Errorf("AddStrict with universe failed: got %v/%v.\n", us, err)
+	}
+}
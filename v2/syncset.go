@@ -0,0 +1,315 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// ----- SyncSet definition -----
+
+// A SyncSet is a set which is safe for concurrent use. It has the same
+// methods as Set, each guarded by a read-write mutex, and additional atomic
+// compound operations. Operations with a second SyncSet take a snapshot of
+// it first, so they never hold two locks at once. A SyncSet applies the
+// shrink policy of its set, see WithShrink.
+//
+// SnapshotIter iterates over an epoch of the set without holding its lock
+// and without copying it up front. The map of an epoch is never modified
+// while an iteration over it is in progress: the first writer of a pinned
+// epoch copies the map and starts a new epoch.
+type SyncSet[T comparable] struct {
+	mu     sync.RWMutex
+	set    Set[T]
+	shrink shrinkTracker
+	epoch  uint64       // the epoch of the map, protected by mu
+	pins   atomic.Int64 // the number of iterations over the map of the epoch
+}
+
+// ----- constructors -----
+
+// NewSync creates a new concurrent set and initializes it with the argument
+// values.
+func NewSync[T comparable](e ...T) *SyncSet[T] {
+	return &SyncSet[T]{set: New(e...)}
+}
+
+// NewSyncWith creates a new, empty concurrent set with the given options.
+// Unlike NewWith, it accepts WithShrink.
+func NewSyncWith[T comparable](opts ...Option[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: newWith(opts)}
+}
+
+// NewSyncFrom creates a new concurrent set from a copy of s, including its
+// configuration.
+func NewSyncFrom[T comparable](s Set[T]) *SyncSet[T] {
+	return &SyncSet[T]{set: s.Copy()}
+}
+
+// own makes the map of the set writable, by starting a new epoch with a copy
+// if iterations over the current one are in progress. The caller must hold
+// the write lock.
+func (s *SyncSet[T]) own() {
+	if s.pins.Load() > 0 {
+		s.newEpoch(s.set.Copy())
+	}
+}
+
+// newEpoch replaces the map of the set, and starts a new epoch. The caller
+// must hold the write lock.
+func (s *SyncSet[T]) newEpoch(t Set[T]) {
+	s.set = t
+	s.epoch++
+	s.pins.Store(0)
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s *SyncSet[T]) Add(e ...T) {
+	s.mu.Lock()
+	s.own()
+	s.set.Add(e...)
+	s.set = shrinkUpdate(&s.shrink, s.set)
+	s.mu.Unlock()
+}
+
+// Remove removes one or more elements from the given set.
+func (s *SyncSet[T]) Remove(e ...T) {
+	s.mu.Lock()
+	s.own()
+	s.set.Remove(e...)
+	s.set = shrinkUpdate(&s.shrink, s.set)
+	s.mu.Unlock()
+}
+
+// Clear removes all elements from the given set.
+func (s *SyncSet[T]) Clear() {
+	s.mu.Lock()
+	if s.pins.Load() > 0 {
+		s.newEpoch(s.set.empty())
+	} else {
+		s.set.Clear()
+	}
+	s.set = shrinkUpdate(&s.shrink, s.set)
+	s.mu.Unlock()
+}
+
+// Reset removes all elements from the given set, and replaces its map with a
+// new one presized for capacityHint elements, which releases the memory of
+// the old map.
+func (s *SyncSet[T]) Reset(capacityHint int) {
+	s.mu.Lock()
+	s.newEpoch(s.set.Reset(capacityHint))
+	s.shrink = shrinkTracker{}
+	s.mu.Unlock()
+}
+
+// AddIfAbsent adds the element if it is not in the set. It returns true if
+// the element was added.
+func (s *SyncSet[T]) AddIfAbsent(e T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.set.Contains(e) {
+		return false
+	}
+	s.own()
+	s.set.Add(e)
+	s.set = shrinkUpdate(&s.shrink, s.set)
+	return s.set.Contains(e) // the element may be rejected by a validator
+}
+
+// RemoveIfPresent removes the element if it is in the set. It returns true
+// if the element was removed.
+func (s *SyncSet[T]) RemoveIfPresent(e T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.set.Contains(e) {
+		return false
+	}
+	s.own()
+	s.set.Remove(e)
+	s.set = shrinkUpdate(&s.shrink, s.set)
+	return true
+}
+
+// Do calls f with the underlying set while holding the write lock, for
+// compound operations which must be atomic, like a union followed by an Add.
+// The set must not be retained or used after f returns.
+func (s *SyncSet[T]) Do(f func(Set[T])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.own()
+	f(s.set)
+	s.set = shrinkUpdate(&s.shrink, s.set)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set is empty.
+func (s *SyncSet[T]) IsEmpty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.IsEmpty()
+}
+
+// Len returns the length of the set.
+func (s *SyncSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Len()
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s *SyncSet[T]) Contains(e ...T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Contains(e...)
+}
+
+// ContainsAny checks if a set contains one or more elements. The return value
+// is true if at least one of the given elements is in the set.
+func (s *SyncSet[T]) ContainsAny(e ...T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.ContainsAny(e...)
+}
+
+// IsEqual tests if two sets are equal.
+func (s *SyncSet[T]) IsEqual(t *SyncSet[T]) bool {
+	ts := t.Snapshot()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.IsEqual(ts)
+}
+
+// IsSubsetOf returns true if the set s is a subset of the set t.
+func (s *SyncSet[T]) IsSubsetOf(t *SyncSet[T]) bool {
+	ts := t.Snapshot()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.IsSubsetOf(ts)
+}
+
+// IsSupersetOf returns true if the set s is a superset of the set t.
+func (s *SyncSet[T]) IsSupersetOf(t *SyncSet[T]) bool {
+	return t.IsSubsetOf(s)
+}
+
+// Snapshot returns a copy of the set as a plain Set.
+func (s *SyncSet[T]) Snapshot() Set[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Copy()
+}
+
+// ----- methods that return a new set -----
+
+// Copy returns a copy of a set.
+func (s *SyncSet[T]) Copy() *SyncSet[T] {
+	return &SyncSet[T]{set: s.Snapshot()}
+}
+
+// Union returns a new set, which represents the union of two or more sets.
+func (s *SyncSet[T]) Union(t ...*SyncSet[T]) *SyncSet[T] {
+	ts := snapshots(t)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SyncSet[T]{set: s.set.Union(ts...)}
+}
+
+// Intersect returns a new set which represents the intersection of two or
+// more sets.
+func (s *SyncSet[T]) Intersect(t ...*SyncSet[T]) *SyncSet[T] {
+	ts := snapshots(t)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SyncSet[T]{set: s.set.Intersect(ts...)}
+}
+
+// Diff returns a new set which represents the difference of two sets.
+func (s *SyncSet[T]) Diff(t *SyncSet[T]) *SyncSet[T] {
+	ts := t.Snapshot()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SyncSet[T]{set: s.set.Diff(ts)}
+}
+
+// SymDiff returns a new set which represents the symmetric difference of two
+// sets.
+func (s *SyncSet[T]) SymDiff(t *SyncSet[T]) *SyncSet[T] {
+	ts := t.Snapshot()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SyncSet[T]{set: s.set.SymDiff(ts)}
+}
+
+// snapshots returns plain copies of the sets.
+func snapshots[T comparable](t []*SyncSet[T]) []Set[T] {
+	ts := make([]Set[T], len(t))
+	for i, x := range t {
+		ts[i] = x.Snapshot()
+	}
+	return ts
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to all elements in the set in an undefined order.
+// It iterates over a snapshot taken when the iteration starts, so the loop
+// body may modify the set.
+func (s *SyncSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, k := range s.List() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// SnapshotIter returns an iterator to all elements of the set, as they were
+// when the iteration starts, in an undefined order. Unlike All, it does not
+// copy the set: it pins the current epoch of the set, and a writer only
+// copies the set once, if it modifies a pinned epoch. The loop body may
+// modify the set.
+func (s *SyncSet[T]) SnapshotIter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.mu.RLock()
+		m, epoch := s.set.set, s.epoch
+		s.pins.Add(1)
+		s.mu.RUnlock()
+		defer func() {
+			s.mu.RLock()
+			if s.epoch == epoch {
+				s.pins.Add(-1)
+			}
+			s.mu.RUnlock()
+		}()
+		for k := range m {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// List returns an unsorted list of the set elements in a slice.
+func (s *SyncSet[T]) List() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.List()
+}
+
+// String returns a textual representation of the set in a string.
+func (s *SyncSet[T]) String() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.String()
+}
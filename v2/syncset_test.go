@@ -0,0 +1,136 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSyncSet(t *testing.T) {
+	s := NewSync(1, 2, 3)
+	u := NewSync(3, 4)
+	if r := s.Union(u); !r.Snapshot().IsEqual(New(1, 2, 3, 4)) {
+		t.Errorf("Union failed: got %v.\n", r)
+	}
+	if r := s.Intersect(u); !r.Snapshot().IsEqual(New(3)) {
+		t.Errorf("Intersect failed: got %v.\n", r)
+	}
+	if r := s.Diff(u); !r.IsEqual(NewSync(1, 2)) || !s.SymDiff(u).IsEqual(NewSync(1, 2, 4)) {
+		t.Errorf("Diff/SymDiff failed: got %v.\n", r)
+	}
+	if !s.IsSupersetOf(s.Diff(u)) || s.IsSubsetOf(u) {
+		t.Errorf("IsSupersetOf/IsSubsetOf failed.\n")
+	}
+	if !s.AddIfAbsent(4) || s.AddIfAbsent(4) || !s.RemoveIfPresent(1) || s.RemoveIfPresent(1) {
+		t.Errorf("AddIfAbsent/RemoveIfPresent failed: got %v.\n", s)
+	}
+	for e := range s.All() {
+		s.Remove(e) // the iteration runs on a snapshot
+	}
+	if !s.IsEmpty() || s.Len() != 0 {
+		t.Errorf("All failed: set not emptied, got %v.\n", s)
+	}
+	if c := NewSyncFrom(New("x")).Copy(); !c.Contains("x") || c.ContainsAny("y") {
+		t.Errorf("NewSyncFrom/Copy failed: got %v.\n", c)
+	}
+}
+
+func TestSyncSetConcurrent(t *testing.T) {
+	s := NewSync[int]()
+	var added atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if s.AddIfAbsent(i) {
+					added.Add(1)
+				}
+				s.Do(func(v Set[int]) {
+					v.Add(-i)
+				})
+				if i%100 == 0 {
+					_ = s.Union(s).Len()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if added.Load() != 1000 || s.Len() != 1999 {
+		t.Errorf("AddIfAbsent failed: %d additions, length %d.\n", added.Load(), s.Len())
+	}
+}
+
+func TestSyncSetSnapshotIter(t *testing.T) {
+	s := NewSync(1, 2, 3)
+	// the loop body modifies the set, but sees the epoch of the start
+	got := New[int]()
+	for e := range s.SnapshotIter() {
+		got.Add(e)
+		s.Add(e + 10)
+		s.Remove(e)
+	}
+	if !got.IsEqual(New(1, 2, 3)) || !s.Snapshot().IsEqual(New(11, 12, 13)) {
+		t.Errorf("SnapshotIter failed: got %v, set %v.\n", got, s)
+	}
+	if s.pins.Load() != 0 {
+		t.Errorf("SnapshotIter failed: %d pins left.\n", s.pins.Load())
+	}
+
+	// without a pinned epoch, writers do not copy the map
+	epoch := s.epoch
+	s.Add(4)
+	for range s.SnapshotIter() {
+		break
+	}
+	s.Add(5)
+	if s.epoch != epoch {
+		t.Errorf("SnapshotIter failed: a new epoch without iteration.\n")
+	}
+
+	// Clear and Reset during an iteration
+	n := 0
+	for range s.SnapshotIter() {
+		s.Clear()
+		s.Reset(10)
+		n++
+	}
+	if n != 5 || !s.IsEmpty() {
+		t.Errorf("SnapshotIter failed with Clear: got %d elements, set %v.\n", n, s)
+	}
+}
+
+func TestSyncSetSnapshotIterConcurrent(t *testing.T) {
+	s := NewSync[int]()
+	for i := range 1000 {
+		s.Add(i)
+	}
+	var wg sync.WaitGroup
+	var stop atomic.Bool
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1000; !stop.Load(); i++ {
+			s.Add(i)
+			s.Remove(i - 1000)
+		}
+	}()
+	for range 20 {
+		n := 0
+		for range s.SnapshotIter() {
+			n++
+		}
+		// the writer keeps the size between 1000 and 1001
+		if n < 1000 || n > 1001 {
+			t.Errorf("SnapshotIter failed: got %d elements.\n", n)
+		}
+	}
+	stop.Store(true)
+	wg.Wait()
+}
@@ -0,0 +1,235 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ----- TagExpr definition -----
+
+// A TagExpr is a parsed boolean expression over tags, like
+// "prod AND (eu OR us) AND NOT canary". It can be evaluated against sets of
+// tags. The operators AND, OR and NOT must be written in upper case; NOT binds
+// stronger than AND, which binds stronger than OR.
+type TagExpr struct {
+	src  string
+	root *tagNode
+}
+
+// the operators of a tag expression
+type tagOp int
+
+const (
+	opTag tagOp = iota
+	opAnd
+	opOr
+	opNot
+)
+
+// a node of the tag expression syntax tree
+type tagNode struct {
+	op   tagOp
+	tag  string
+	args []*tagNode
+}
+
+// ----- parser -----
+
+// ParseTagExpr parses a tag expression.
+func ParseTagExpr(expr string) (*TagExpr, error) {
+	p := tagParser{tokens: tokenizeTagExpr(expr)}
+	n, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set: invalid tag expression %q: %w", expr, err)
+	}
+	return &TagExpr{src: expr, root: n}, nil
+}
+
+// tokenizeTagExpr splits the expression into parentheses and words.
+func tokenizeTagExpr(expr string) []string {
+	var tokens []string
+	word := strings.Builder{}
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range expr {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// a recursive descent parser for tag expressions
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *tagParser) parseOr() (*tagNode, error) {
+	return p.parseBinary(opOr, "OR", p.parseAnd)
+}
+
+func (p *tagParser) parseAnd() (*tagNode, error) {
+	return p.parseBinary(opAnd, "AND", p.parseNot)
+}
+
+func (p *tagParser) parseBinary(op tagOp, keyword string, operand func() (*tagNode, error)) (*tagNode, error) {
+	n, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != keyword {
+		return n, nil
+	}
+	r := &tagNode{op: op, args: []*tagNode{n}}
+	for p.peek() == keyword {
+		p.pos++
+		if n, err = operand(); err != nil {
+			return nil, err
+		}
+		r.args = append(r.args, n)
+	}
+	return r, nil
+}
+
+func (p *tagParser) parseNot() (*tagNode, error) {
+	if p.peek() != "NOT" {
+		return p.parsePrimary()
+	}
+	p.pos++
+	n, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return &tagNode{op: opNot, args: []*tagNode{n}}, nil
+}
+
+func (p *tagParser) parsePrimary() (*tagNode, error) {
+	switch tok := p.peek(); tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case "(":
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return n, nil
+	case ")", "AND", "OR":
+		return nil, fmt.Errorf("unexpected %q", tok)
+	default:
+		p.pos++
+		return &tagNode{op: opTag, tag: tok}, nil
+	}
+}
+
+// ----- evaluation -----
+
+// Match evaluates the expression against a set of tags.
+func (e *TagExpr) Match(tags Set[string]) bool {
+	return e.root.eval(tags)
+}
+
+func (n *tagNode) eval(tags Set[string]) bool {
+	switch n.op {
+	case opTag:
+		return tags.Contains(n.tag)
+	case opAnd:
+		for _, i := range n.args {
+			if !i.eval(tags) {
+				return false
+			}
+		}
+		return true
+	case opOr:
+		for _, i := range n.args {
+			if i.eval(tags) {
+				return true
+			}
+		}
+		return false
+	default:
+		return !n.args[0].eval(tags)
+	}
+}
+
+// String returns the source text of the expression.
+func (e *TagExpr) String() string {
+	return e.src
+}
+
+// ----- TagMatcher definition -----
+
+// A TagMatcher evaluates tag expressions given as strings and caches the
+// parsed expressions. It is safe for concurrent use.
+type TagMatcher struct {
+	mu    sync.Mutex
+	size  int
+	cache map[string]*TagExpr
+}
+
+// NewTagMatcher creates a new matcher which caches up to size parsed
+// expressions. If the cache is full, it is emptied.
+func NewTagMatcher(size int) *TagMatcher {
+	size = max(size, 0)
+	return &TagMatcher{size: size, cache: make(map[string]*TagExpr, size)}
+}
+
+// Compile returns the parsed expression, using the cache if possible.
+func (m *TagMatcher) Compile(expr string) (*TagExpr, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.cache[expr]; ok {
+		return e, nil
+	}
+	e, err := ParseTagExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.cache) >= m.size {
+		clear(m.cache)
+	}
+	m.cache[expr] = e
+	return e, nil
+}
+
+// Match evaluates the expression against a set of tags.
+func (m *TagMatcher) Match(expr string, tags Set[string]) (bool, error) {
+	e, err := m.Compile(expr)
+	if err != nil {
+		return false, err
+	}
+	return e.Match(tags), nil
+}
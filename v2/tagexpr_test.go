@@ -0,0 +1,66 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"testing"
+)
+
+func TestTagExpr(t *testing.T) {
+	tests := []struct {
+		expr string
+		tags Set[string]
+		want bool
+	}{
+		{"prod AND (eu OR us) AND NOT canary", New("prod", "eu"), true},
+		{"prod AND (eu OR us) AND NOT canary", New("prod", "us", "canary"), false},
+		{"prod AND (eu OR us) AND NOT canary", New("prod", "asia"), false},
+		{"a OR b AND c", New("a"), true},
+		{"(a OR b) AND c", New("a"), false},
+		{"NOT NOT a", New("a"), true},
+	}
+	for _, i := range tests {
+		e, err := ParseTagExpr(i.expr)
+		if err != nil {
+			t.Errorf("ParseTagExpr failed: %q returned %v.\n", i.expr, err)
+			continue
+		}
+		if got := e.Match(i.tags); got != i.want {
+			t.Errorf("Match failed: %q on %v returned %t, expected %t.\n", i.expr, i.tags, got, i.want)
+		}
+	}
+
+	for _, i := range []string{"", "a AND", "(a OR b", "a b", "OR a", "a)"} {
+		if _, err := ParseTagExpr(i); err == nil {
+			t.Errorf("ParseTagExpr failed: expected an error for %q.\n", i)
+		}
+	}
+}
+
+func TestTagMatcher(t *testing.T) {
+	m := NewTagMatcher(1)
+	ok, err := m.Match("a AND b", New("a", "b"))
+	if !ok || err != nil {
+		t.Errorf("Match failed: got %t/%v, expected true/<nil>.\n", ok, err)
+	}
+	e1, _ := m.Compile("a AND b")
+	e2, _ := m.Compile("a AND b")
+	if e1 != e2 {
+		t.Errorf("Compile failed: expression was not cached.\n")
+	}
+	m.Compile("c")
+	if len(m.cache) != 1 {
+		t.Errorf("Compile failed: cache size is %d, expected 1.\n", len(m.cache))
+	}
+	if _, err := m.Match("(", New[string]()); err == nil {
+		t.Errorf("Match failed: expected an error for an invalid expression.\n")
+	}
+
+	// a negative size is treated as 0
+	if ok, err := NewTagMatcher(-1).Match("a", New("a")); !ok || err != nil {
+		t.Errorf("Match failed for a negative cache size: got %t/%v.\n", ok, err)
+	}
+}
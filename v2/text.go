@@ -0,0 +1,104 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hweidner/set/v2/internal/textcodec"
+)
+
+// ----- text encoding -----
+
+// the separator of the text encoding, if the set has none configured
+const defaultSeparator = ","
+
+// WithSeparator sets the separator of the elements in the text encoding of
+// the set, like ";" or " ". The default separator is ",".
+func WithSeparator[T comparable](sep string) Option[T] {
+	return func(c *config[T]) {
+		c.separator = sep
+	}
+}
+
+// separator returns the separator of the text encoding.
+func (s Set[T]) separator() string {
+	if s.cfg != nil && s.cfg.separator != "" {
+		return s.cfg.separator
+	}
+	return defaultSeparator
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, so sets can be
+// used in struct fields of configuration files and environment variables.
+// The elements are formatted with their MarshalText method if they have one,
+// and with fmt otherwise, and joined by the separator in ascending order of
+// their text. An element whose text contains the separator is an error, as
+// it could not be decoded.
+func (s Set[T]) MarshalText() ([]byte, error) {
+	sep := s.separator()
+	l := make([]string, 0, len(s.set))
+	for k := range s.set {
+		t, err := formatText(k)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(t, sep) {
+			return nil, fmt.Errorf("set: element %q contains the separator %q", t, sep)
+		}
+		l = append(l, t)
+	}
+	slices.Sort(l)
+	return []byte(strings.Join(l, sep)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It splits
+// the text at the separator and replaces the elements of the set, like
+// UnmarshalJSON; blanks around the elements are trimmed, and an empty text
+// yields an empty set. Elements are parsed by their UnmarshalText method if
+// they have one, or else by their kind: strings are taken as they are, and
+// booleans and numbers are parsed with strconv. Elements which cannot be
+// parsed are reported as a *DecodeError, and other element types as
+// ErrWrongType.
+func (s *Set[T]) UnmarshalText(text []byte) error {
+	var l []T
+	if t := strings.TrimSpace(string(text)); t != "" {
+		for i, f := range strings.Split(t, s.separator()) {
+			f = strings.TrimSpace(f)
+			e, err := parseText[T](f)
+			if errors.Is(err, ErrWrongType) {
+				return err
+			}
+			if err != nil {
+				return &DecodeError{Pos: i, Input: f, Err: err}
+			}
+			l = append(l, e)
+		}
+	}
+	if s.set == nil {
+		s.set = make(map[T]struct{}, len(l))
+	} else {
+		clear(s.set)
+	}
+	return s.TryAdd(l...)
+}
+
+// formatText returns the text of an element.
+func formatText[T comparable](e T) (string, error) {
+	return textcodec.Format(e)
+}
+
+// parseText parses an element from its text.
+func parseText[T comparable](t string) (T, error) {
+	e, err := textcodec.Parse[T](t)
+	if errors.Is(err, textcodec.ErrNoTextEncoding) {
+		return e, fmt.Errorf("%w: cannot parse %T from text", ErrWrongType, e)
+	}
+	return e, err
+}
@@ -0,0 +1,64 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestText(t *testing.T) {
+	b, err := New("b", "a", "c").MarshalText()
+	if err != nil || string(b) != "a,b,c" {
+		t.Errorf("MarshalText failed: got %q, %v.\n", b, err)
+	}
+	var s Set[string]
+	if err := s.UnmarshalText([]byte(" x , y,x ")); err != nil || !s.IsEqual(New("x", "y")) {
+		t.Errorf("UnmarshalText failed: got %v, %v.\n", s, err)
+	}
+	if err := s.UnmarshalText(nil); err != nil || !s.IsEmpty() {
+		t.Errorf("UnmarshalText failed for empty text: got %v, %v.\n", s, err)
+	}
+
+	// a configured separator
+	p := NewWith(WithSeparator[int](";"))
+	if err := p.UnmarshalText([]byte("80; 443;0x1f90")); err != nil || !p.IsEqual(New(80, 443, 8080)) {
+		t.Errorf("UnmarshalText failed: got %v, %v.\n", p, err)
+	}
+	if b, err := p.MarshalText(); err != nil || string(b) != "443;80;8080" {
+		t.Errorf("MarshalText failed: got %q, %v.\n", b, err)
+	}
+	var de *DecodeError
+	if err := p.UnmarshalText([]byte("80;http")); !errors.As(err, &de) || de.Pos != 1 || de.Input != "http" {
+		t.Errorf("UnmarshalText failed: got %v.\n", err)
+	}
+	if _, err := New("a,b").MarshalText(); err == nil {
+		t.Errorf("MarshalText failed: no error for an element with the separator.\n")
+	}
+
+	// elements with text methods
+	var a Set[netip.Addr]
+	if err := a.UnmarshalText([]byte("10.0.0.1,::1")); err != nil || !a.Contains(netip.MustParseAddr("::1")) {
+		t.Errorf("UnmarshalText failed for addresses: got %v, %v.\n", a, err)
+	}
+	if b, err := a.MarshalText(); err != nil || string(b) != "10.0.0.1,::1" {
+		t.Errorf("MarshalText failed for addresses: got %q, %v.\n", b, err)
+	}
+	var d Set[time.Duration]
+	if err := d.UnmarshalText([]byte("1,2")); err != nil || !d.Contains(1, 2) {
+		t.Errorf("UnmarshalText failed for durations: got %v, %v.\n", d, err)
+	}
+	var f Set[float64]
+	if err := f.UnmarshalText([]byte("1.5,2")); err != nil || !f.IsEqual(New(1.5, 2)) {
+		t.Errorf("UnmarshalText failed for floats: got %v, %v.\n", f, err)
+	}
+	var tp Set[testPoint]
+	if err := tp.UnmarshalText([]byte("x")); !errors.Is(err, ErrWrongType) {
+		t.Errorf("UnmarshalText failed: got %v for structs.\n", err)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"sync"
+)
+
+// ----- TieredSet definition -----
+
+// A Backend is a (usually slow) secondary storage for the elements of a
+// TieredSet, like a disk store or a Redis server.
+type Backend[T comparable] interface {
+	Contains(e T) (bool, error)
+	Add(e ...T) error
+	Remove(e ...T) error
+}
+
+// A TieredSet is a set where a hot in-memory set fronts a slower backend.
+// Lookups read through to the backend and cache positive results, additions
+// and removals write through to the backend. All methods are safe for
+// concurrent use. The lock of the set is not held during backend calls, so
+// a slow backend does not block lookups answered by the hot set.
+type TieredSet[T comparable] struct {
+	mu      sync.Mutex
+	hot     Set[T]
+	maxHot  int
+	backend Backend[T]
+	stats   TieredStats
+	gen     uint64 // incremented by removals, to avoid caching stale elements
+}
+
+// TieredStats holds the hit/miss statistics of a TieredSet.
+type TieredStats struct {
+	Hits   int // lookups answered by the hot set
+	Misses int // lookups answered by the backend
+	Errors int // failed backend operations
+}
+
+// ----- constructor -----
+
+// NewTiered creates a new tiered set in front of the backend. The hot set
+// holds at most maxHot elements; it is emptied when this size is exceeded.
+func NewTiered[T comparable](backend Backend[T], maxHot int) *TieredSet[T] {
+	return &TieredSet[T]{hot: New[T](), maxHot: maxHot, backend: backend}
+}
+
+// cache adds an element to the hot set. The caller must hold the lock.
+func (s *TieredSet[T]) cache(e ...T) {
+	if s.hot.Len()+len(e) > s.maxHot {
+		s.hot.Clear()
+	}
+	if len(e) <= s.maxHot {
+		s.hot.Add(e...)
+	}
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the backend and the hot set. If the
+// backend fails, the hot set is not modified.
+func (s *TieredSet[T]) Add(e ...T) error {
+	s.mu.Lock()
+	gen := s.gen
+	s.mu.Unlock()
+
+	err := s.backend.Add(e...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.stats.Errors++
+		return err
+	}
+	if s.gen == gen {
+		s.cache(e...)
+	}
+	return nil
+}
+
+// Remove removes one or more elements from the hot set and the backend.
+func (s *TieredSet[T]) Remove(e ...T) error {
+	s.mu.Lock()
+	s.hot.Remove(e...)
+	s.gen++
+	s.mu.Unlock()
+
+	err := s.backend.Remove(e...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// a concurrent lookup may have cached the elements in the meantime
+	s.hot.Remove(e...)
+	s.gen++
+	if err != nil {
+		s.stats.Errors++
+		return err
+	}
+	return nil
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Contains checks if an element is in the set. The hot set is asked first,
+// then the backend. Elements found in the backend are added to the hot set.
+func (s *TieredSet[T]) Contains(e T) (bool, error) {
+	s.mu.Lock()
+	if s.hot.Contains(e) {
+		s.stats.Hits++
+		s.mu.Unlock()
+		return true, nil
+	}
+	s.stats.Misses++
+	gen := s.gen
+	s.mu.Unlock()
+
+	ok, err := s.backend.Contains(e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.stats.Errors++
+		return false, err
+	}
+	if ok && s.gen == gen {
+		s.cache(e)
+	}
+	return ok, nil
+}
+
+// Stats returns the hit/miss statistics of the set.
+func (s *TieredSet[T]) Stats() TieredStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// HotLen returns the number of elements in the hot set.
+func (s *TieredSet[T]) HotLen() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hot.Len()
+}
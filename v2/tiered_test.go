@@ -0,0 +1,116 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"testing"
+)
+
+// a Backend based on a plain set, which can be switched to failure mode
+type testBackend struct {
+	set  Set[int]
+	fail bool
+}
+
+var errBackend = errors.New("backend failure")
+
+func (b *testBackend) Contains(e int) (bool, error) {
+	if b.fail {
+		return false, errBackend
+	}
+	return b.set.Contains(e), nil
+}
+
+func (b *testBackend) Add(e ...int) error {
+	if b.fail {
+		return errBackend
+	}
+	b.set.Add(e...)
+	return nil
+}
+
+func (b *testBackend) Remove(e ...int) error {
+	if b.fail {
+		return errBackend
+	}
+	b.set.Remove(e...)
+	return nil
+}
+
+func TestTieredSet(t *testing.T) {
+	b := &testBackend{set: New(1, 2, 3)}
+	s := NewTiered[int](b, 2)
+
+	if ok, err := s.Contains(1); !ok || err != nil {
+		t.Errorf("Contains failed: got %t/%v, expected true/<nil>.\n", ok, err)
+	}
+	s.Contains(1)
+	s.Contains(5)
+	if st := s.Stats(); st.Hits != 1 || st.Misses != 2 {
+		t.Errorf("Stats failed: got %+v.\n", st)
+	}
+
+	if err := s.Add(4, 5); err != nil || !b.set.Contains(4, 5) || s.HotLen() != 2 {
+		t.Errorf("Add failed: backend is %v, hot set has %d elements.\n", b.set, s.HotLen())
+	}
+	if err := s.Remove(4); err != nil || b.set.Contains(4) {
+		t.Errorf("Remove failed: backend is %v.\n", b.set)
+	}
+	if ok, _ := s.Contains(4); ok {
+		t.Errorf("Remove failed: 4 is still in the hot set.\n")
+	}
+
+	b.fail = true
+	if err := s.Add(6); err == nil {
+		t.Errorf("Add failed: expected a backend error.\n")
+	}
+	if ok, err := s.Contains(5); !ok || err != nil {
+		t.Errorf("Contains failed: hot element not found during backend failure.\n")
+	}
+	if s.Stats().Errors != 1 {
+		t.Errorf("Stats failed: got %d errors, expected 1.\n", s.Stats().Errors)
+	}
+}
+
+// a Backend which blocks in Contains until it is released
+type slowBackend struct {
+	testBackend
+	entered, release chan struct{}
+}
+
+func (b *slowBackend) Contains(e int) (bool, error) {
+	b.entered <- struct{}{}
+	<-b.release
+	return b.testBackend.Contains(e)
+}
+
+func TestTieredSetSlowBackend(t *testing.T) {
+	b := &slowBackend{
+		testBackend: testBackend{set: New(1, 2)},
+		entered:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	s := NewTiered[int](b, 10)
+	s.Add(1)
+
+	done := make(chan bool)
+	go func() {
+		ok, _ := s.Contains(2)
+		done <- ok
+	}()
+	<-b.entered
+	if ok, err := s.Contains(1); !ok || err != nil {
+		t.Errorf("Contains failed: got %t/%v during a backend call.\n", ok, err)
+	}
+	if st := s.Stats(); st.Hits != 1 || st.Misses != 1 {
+		t.Errorf("Stats failed: got %+v during a backend call.\n", st)
+	}
+	close(b.release)
+	if !<-done || s.HotLen() != 2 {
+		t.Errorf("Contains failed: element of the backend not cached.\n")
+	}
+}
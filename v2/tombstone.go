@@ -0,0 +1,181 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"maps"
+	"time"
+)
+
+// ----- TombstoneSet definition -----
+
+// A TombstoneSet is a set with soft deletes: Remove does not forget an
+// element, but marks it with a tombstone and the time of its removal, so the
+// deletion can be propagated to downstream consumers, like the replicas of
+// a cache. Purge forgets the tombstones once they are old enough. Adding a
+// removed element again revives it.
+type TombstoneSet[T comparable] struct {
+	now     func() time.Time
+	live    map[T]struct{}
+	removed map[T]time.Time // the tombstones, with the time of removal
+}
+
+// An IterMode selects the elements visited by the iterators of a
+// TombstoneSet.
+type IterMode int
+
+const (
+	// Live selects the elements which are in the set.
+	Live IterMode = iota
+
+	// Tombstoned selects the removed elements which were not purged.
+	Tombstoned
+
+	// Everything selects both the live and the removed elements.
+	Everything
+)
+
+// ----- constructor -----
+
+// NewTombstone creates a new set with soft deletes and initializes it with
+// the argument values.
+func NewTombstone[T comparable](e ...T) *TombstoneSet[T] {
+	s := &TombstoneSet[T]{now: time.Now, live: map[T]struct{}{}, removed: map[T]time.Time{}}
+	s.Add(e...)
+	return s
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set. Removed elements are
+// revived, and lose their tombstones.
+func (s *TombstoneSet[T]) Add(e ...T) {
+	for _, i := range e {
+		s.live[i] = struct{}{}
+		delete(s.removed, i)
+	}
+}
+
+// Remove marks one or more elements of the given set as removed, with the
+// current time. Elements which are not in the set are ignored, and the
+// tombstones of removed elements keep their original time.
+func (s *TombstoneSet[T]) Remove(e ...T) {
+	t := s.now()
+	for _, i := range e {
+		if _, ok := s.live[i]; ok {
+			delete(s.live, i)
+			s.removed[i] = t
+		}
+	}
+}
+
+// Purge forgets the tombstones of the elements removed longer than the
+// given duration ago, and returns their number.
+func (s *TombstoneSet[T]) Purge(olderThan time.Duration) int {
+	cutoff := s.now().Add(-olderThan)
+	n := 0
+	for i, t := range s.removed {
+		if t.Before(cutoff) {
+			delete(s.removed, i)
+			n++
+		}
+	}
+	return n
+}
+
+// Clear removes all elements and tombstones from the given set.
+func (s *TombstoneSet[T]) Clear() {
+	clear(s.live)
+	clear(s.removed)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// IsEmpty tests if the set has no live elements.
+func (s *TombstoneSet[T]) IsEmpty() bool {
+	return len(s.live) == 0
+}
+
+// Len returns the number of live elements of the set.
+func (s *TombstoneSet[T]) Len() int {
+	return len(s.live)
+}
+
+// Tombstones returns the number of tombstones of the set.
+func (s *TombstoneSet[T]) Tombstones() int {
+	return len(s.removed)
+}
+
+// Contains checks if a set contains one or more live elements. The return
+// value is true only if all given elements are in the set.
+func (s *TombstoneSet[T]) Contains(e ...T) bool {
+	for _, i := range e {
+		if _, ok := s.live[i]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Removed returns the time at which an element was removed. The second
+// return value is false if the element has no tombstone.
+func (s *TombstoneSet[T]) Removed(e T) (time.Time, bool) {
+	t, ok := s.removed[e]
+	return t, ok
+}
+
+// Copy returns a copy of a set, including its tombstones. The set s is not
+// modified.
+func (s *TombstoneSet[T]) Copy() *TombstoneSet[T] {
+	return &TombstoneSet[T]{now: s.now, live: maps.Clone(s.live), removed: maps.Clone(s.removed)}
+}
+
+// ----- iterators and other data types -----
+
+// All returns an iterator to the elements selected by the mode, in an
+// undefined order.
+func (s *TombstoneSet[T]) All(mode IterMode) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if mode != Tombstoned {
+			for i := range s.live {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+		if mode != Live {
+			for i := range s.removed {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Entries returns an iterator to the elements selected by the mode, with
+// true for the live and false for the removed elements, like the stream of
+// upserts and deletes for a downstream consumer.
+func (s *TombstoneSet[T]) Entries(mode IterMode) iter.Seq2[T, bool] {
+	return func(yield func(T, bool) bool) {
+		for i := range s.All(mode) {
+			_, ok := s.live[i]
+			if !yield(i, ok) {
+				return
+			}
+		}
+	}
+}
+
+// Set returns the live elements as a new set.
+func (s *TombstoneSet[T]) Set() Set[T] {
+	r := Set[T]{set: make(map[T]struct{}, len(s.live))}
+	for i := range s.live {
+		r.set[i] = struct{}{}
+	}
+	return r
+}
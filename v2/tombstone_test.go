@@ -0,0 +1,65 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTombstone(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewTombstone(1, 2, 3, 4)
+	s.now = func() time.Time { return now }
+
+	s.Remove(1, 5)
+	now = now.Add(time.Hour)
+	s.Remove(2, 1)
+	if !s.Set().IsEqual(New(3, 4)) || s.Len() != 2 || s.Tombstones() != 2 || s.Contains(1) {
+		t.Errorf("Remove failed: got %v with %d tombstones.\n", s.Set(), s.Tombstones())
+	}
+	if r, ok := s.Removed(1); !ok || !r.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Removed failed: got %v, %v.\n", r, ok)
+	}
+
+	if l := slices.Sorted(s.All(Tombstoned)); !slices.Equal(l, []int{1, 2}) {
+		t.Errorf("All failed: got %v for tombstones.\n", l)
+	}
+	if l := slices.Sorted(s.All(Everything)); !slices.Equal(l, []int{1, 2, 3, 4}) {
+		t.Errorf("All failed: got %v for everything.\n", l)
+	}
+	live := 0
+	for e, ok := range s.Entries(Everything) {
+		if ok != s.Contains(e) {
+			t.Errorf("Entries failed: got %d, %v.\n", e, ok)
+		}
+		if ok {
+			live++
+		}
+	}
+	if live != 2 {
+		t.Errorf("Entries failed: got %d live elements.\n", live)
+	}
+
+	c := s.Copy()
+	if n := s.Purge(30 * time.Minute); n != 1 || s.Tombstones() != 1 {
+		t.Errorf("Purge failed: purged %d, %d tombstones left.\n", n, s.Tombstones())
+	}
+	if _, ok := s.Removed(1); ok || c.Tombstones() != 2 {
+		t.Errorf("Purge failed: tombstone of 1 kept.\n")
+	}
+
+	// an added element is revived
+	s.Add(2)
+	if !s.Contains(2) || s.Tombstones() != 0 {
+		t.Errorf("Add failed: got %v with %d tombstones.\n", s.Set(), s.Tombstones())
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.Tombstones() != 0 {
+		t.Errorf("Clear failed.\n")
+	}
+}
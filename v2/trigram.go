@@ -0,0 +1,142 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"slices"
+	"strings"
+)
+
+// ----- TrigramSet definition -----
+
+// A TrigramSet is a set of strings with an additional trigram index, which
+// allows fast queries for all elements containing a given substring. The
+// index maps every three byte sequence to the elements containing it.
+type TrigramSet struct {
+	set   Set[string]
+	index map[string]Set[string]
+}
+
+// ----- constructor -----
+
+// NewTrigram creates a new trigram indexed set and initializes it with the
+// argument values.
+func NewTrigram(e ...string) TrigramSet {
+	s := TrigramSet{set: New[string](), index: map[string]Set[string]{}}
+	s.Add(e...)
+	return s
+}
+
+// trigrams calls f for each distinct trigram of w.
+func trigrams(w string, f func(string)) {
+	seen := make(map[string]struct{}, len(w))
+	for i := 0; i+3 <= len(w); i++ {
+		g := w[i : i+3]
+		if _, ok := seen[g]; !ok {
+			seen[g] = struct{}{}
+			f(g)
+		}
+	}
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more elements to the given set.
+func (s TrigramSet) Add(e ...string) {
+	for _, i := range e {
+		if s.set.Contains(i) {
+			continue
+		}
+		s.set.Add(i)
+		trigrams(i, func(g string) {
+			p, ok := s.index[g]
+			if !ok {
+				p = New[string]()
+				s.index[g] = p
+			}
+			p.Add(i)
+		})
+	}
+}
+
+// Remove removes one or more elements from the given set.
+func (s TrigramSet) Remove(e ...string) {
+	for _, i := range e {
+		if !s.set.Contains(i) {
+			continue
+		}
+		s.set.Remove(i)
+		trigrams(i, func(g string) {
+			p := s.index[g]
+			if p.Remove(i); p.IsEmpty() {
+				delete(s.index, g)
+			}
+		})
+	}
+}
+
+// Clear removes all elements from the given set.
+func (s TrigramSet) Clear() {
+	s.set.Clear()
+	clear(s.index)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Len returns the length of the set.
+func (s TrigramSet) Len() int {
+	return s.set.Len()
+}
+
+// Contains checks if a set contains one or more elements. The return value
+// is true only if all given elements are in the set.
+func (s TrigramSet) Contains(e ...string) bool {
+	return s.set.Contains(e...)
+}
+
+// ContainingSubstring returns a new set of all elements containing sub. For
+// substrings of at least three bytes, only the elements sharing all trigrams
+// with sub are examined. Shorter substrings require a scan of all elements.
+func (s TrigramSet) ContainingSubstring(sub string) Set[string] {
+	if len(sub) < 3 {
+		r := New[string]()
+		for k := range s.set.set {
+			if strings.Contains(k, sub) {
+				r.set[k] = struct{}{}
+			}
+		}
+		return r
+	}
+
+	var postings []Set[string]
+	missing := false
+	trigrams(sub, func(g string) {
+		if p, ok := s.index[g]; ok {
+			postings = append(postings, p)
+		} else {
+			missing = true
+		}
+	})
+	if missing {
+		return New[string]()
+	}
+
+	// intersect, starting with the smallest posting set
+	slices.SortFunc(postings, func(a, b Set[string]) int { return a.Len() - b.Len() })
+	r := postings[0].Intersect(postings[1:]...)
+	for k := range r.set {
+		if !strings.Contains(k, sub) {
+			delete(r.set, k)
+		}
+	}
+	return r
+}
+
+// Set returns a copy of the elements of the trigram set as a plain set, so
+// modifications of the returned set do not corrupt the trigram index.
+func (s TrigramSet) Set() Set[string] {
+	return s.set.Copy()
+}
@@ -0,0 +1,50 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"testing"
+)
+
+func TestTrigramSet(t *testing.T) {
+	s := NewTrigram("frontend-prod", "backend-prod", "frontend-dev", "db")
+
+	r := s.ContainingSubstring("end-pro")
+	if !r.IsEqual(New("frontend-prod", "backend-prod")) {
+		t.Errorf("ContainingSubstring failed: got %v.\n", r)
+	}
+	r = s.ContainingSubstring("d")
+	if r.Len() != 4 {
+		t.Errorf("ContainingSubstring failed for short substring: got %v.\n", r)
+	}
+	r = s.ContainingSubstring("xyz")
+	if !r.IsEmpty() {
+		t.Errorf("ContainingSubstring failed: expected empty set, got %v.\n", r)
+	}
+	// all trigrams present, but not in sequence
+	r = s.ContainingSubstring("endend")
+	if !r.IsEmpty() {
+		t.Errorf("ContainingSubstring failed: expected empty set, got %v.\n", r)
+	}
+
+	s.Remove("backend-prod")
+	r = s.ContainingSubstring("prod")
+	if !r.IsEqual(New("frontend-prod")) || s.Len() != 3 {
+		t.Errorf("Remove/ContainingSubstring failed: got %v.\n", r)
+	}
+
+	// the plain set is a copy
+	p := s.Set()
+	p.Add("injected")
+	if s.Len() != 3 || s.Set().Contains("injected") {
+		t.Errorf("Set failed: the returned set is shared with the trigram set.\n")
+	}
+
+	s.Clear()
+	if s.Len() != 0 || len(s.index) != 0 {
+		t.Errorf("Clear failed: got %v.\n", s.Set())
+	}
+}
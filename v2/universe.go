@@ -0,0 +1,123 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ----- Universe definition -----
+
+// ErrForeignElement is the error of a *ValidationError for an element which
+// is not part of the universe of a set.
+var ErrForeignElement = errors.New("element not in universe")
+
+// A Universe is a declared finite domain, like weekdays, states or shards.
+// Sets created from a universe reject foreign elements and can be
+// complemented. Operations which combine sets of different universes, or
+// a universe set with a plain set, are a misuse and handled according to the
+// misuse policy.
+type Universe[T comparable] struct {
+	name  string
+	elems Set[T]
+}
+
+// NewUniverse declares a universe with the given name, which is used in
+// error messages, and elements.
+func NewUniverse[T comparable](name string, e ...T) *Universe[T] {
+	return &Universe[T]{name: name, elems: New(e...)}
+}
+
+// Name returns the name of the universe.
+func (u *Universe[T]) Name() string {
+	return u.name
+}
+
+// Len returns the number of elements in the universe.
+func (u *Universe[T]) Len() int {
+	return u.elems.Len()
+}
+
+// Contains checks if the universe contains one or more elements.
+func (u *Universe[T]) Contains(e ...T) bool {
+	return u.elems.Contains(e...)
+}
+
+// New creates a new set of the universe and initializes it with the argument
+// values. Foreign elements are not added; the returned error joins a
+// *ValidationError wrapping ErrForeignElement for each of them.
+func (u *Universe[T]) New(e ...T) (Set[T], error) {
+	s := NewWith(func(c *config[T]) { c.universe = u })
+	return s, s.TryAdd(e...)
+}
+
+// Full returns a new set of the universe which contains all its elements.
+func (u *Universe[T]) Full() Set[T] {
+	s, _ := u.New()
+	for k := range u.elems.set {
+		s.set[k] = struct{}{}
+	}
+	return s
+}
+
+// Complement returns a new set of the universe which contains all its
+// elements which are not in s. The set s must belong to the universe.
+func (u *Universe[T]) Complement(s Set[T]) Set[T] {
+	r, _ := u.New()
+	if s.universe() != u {
+		s.misuse("Complement", fmt.Sprintf("set is not in universe %q", u.name))
+		return r
+	}
+	for k := range u.elems.set {
+		if _, ok := s.set[k]; !ok {
+			r.set[k] = struct{}{}
+		}
+	}
+	return r
+}
+
+// Universe returns the universe of the set, or nil for sets created without
+// a universe.
+func (s Set[T]) Universe() *Universe[T] {
+	return s.universe()
+}
+
+// ----- helper functions -----
+
+// universe returns the universe of the set, or nil.
+func (s Set[T]) universe() *Universe[T] {
+	if s.cfg == nil {
+		return nil
+	}
+	return s.cfg.universe
+}
+
+// checkUniverse reports a misuse if one of the sets t has a different
+// universe than s. It returns false on a mismatch.
+func (s Set[T]) checkUniverse(op string, t ...Set[T]) bool {
+	u := s.universe()
+	for _, i := range t {
+		if v := i.universe(); v != u {
+			s.misuse(op, fmt.Sprintf("universes %s and %s differ", u.describe(), v.describe()))
+			return false
+		}
+	}
+	return true
+}
+
+// describe returns the quoted name of the universe for error messages.
+func (u *Universe[T]) describe() string {
+	if u == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("%q", u.name)
+}
+
+// empty returns a new, empty set with the configuration of s.
+func (s Set[T]) empty() Set[T] {
+	return Set[T]{set: map[T]struct{}{}, cfg: s.cfg}
+}
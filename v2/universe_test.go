@@ -0,0 +1,61 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUniverse(t *testing.T) {
+	days := NewUniverse("weekdays", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun")
+	weekend, err := days.New("Sat", "Sun", "Holiday")
+	if !errors.Is(err, ErrForeignElement) {
+		t.Errorf("New failed: expected ErrForeignElement, got %v.\n", err)
+	}
+	if weekend.Len() != 2 || weekend.Universe() != days {
+		t.Errorf("New failed: got %v.\n", weekend)
+	}
+	weekend.Add("Holiday")
+	if weekend.Contains("Holiday") {
+		t.Errorf("Add failed: foreign element added.\n")
+	}
+
+	work := days.Complement(weekend)
+	if work.Len() != 5 || work.ContainsAny("Sat", "Sun") || work.Universe() != days {
+		t.Errorf("Complement failed: got %v.\n", work)
+	}
+	if !work.Union(weekend).IsEqual(days.Full()) {
+		t.Errorf("Union failed: expected the full universe.\n")
+	}
+	if New[string]().Universe() != nil {
+		t.Errorf("Universe failed: plain set has a universe.\n")
+	}
+}
+
+func TestUniverseMismatch(t *testing.T) {
+	SetMisusePolicy(RecordMisuse)
+	defer SetMisusePolicy(PanicOnMisuse)
+
+	a, _ := NewUniverse("a", 1, 2, 3).New(1, 2)
+	b, _ := NewUniverse("b", 1, 2, 3).New(1, 2)
+	if !a.Union(b).IsEmpty() || a.IsEqual(b) || a.IsSubsetOf(New(1, 2, 3)) {
+		t.Errorf("mixing universes failed: expected empty results.\n")
+	}
+	var me *MisuseError
+	if err := a.Err(); !errors.As(err, &me) || me.Op != "Union" {
+		t.Errorf("Err failed: expected a Union misuse, got %v.\n", err)
+	}
+	if !b.Diff(b).IsEmpty() || b.Err() != nil {
+		t.Errorf("Diff failed: same universe reported as misuse.\n")
+	}
+
+	// complement of a set from another universe
+	NewUniverse("c", 1).Complement(b)
+	if b.Err() == nil {
+		t.Errorf("Complement failed: foreign set not reported.\n")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"iter"
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// ----- WeakSet definition -----
+
+// A WeakSet is a set of pointers which does not keep its elements alive,
+// like a registry of the live objects of a cache. Once an element is
+// garbage collected, it vanishes from the set; a cleanup registered with
+// runtime.AddCleanup removes its entry. It is safe for concurrent use, as
+// the cleanups run on a goroutine of their own.
+//
+// WeakSet is experimental. The elements vanish at the discretion of the
+// garbage collector, so Len and All only report the elements which were
+// not collected yet.
+type WeakSet[T any] struct {
+	mu sync.Mutex
+	m  map[weak.Pointer[T]]runtime.Cleanup
+}
+
+// ----- constructor -----
+
+// NewWeak creates a new weak set and initializes it with the argument
+// pointers. Nil pointers are ignored.
+func NewWeak[T any](p ...*T) *WeakSet[T] {
+	s := &WeakSet[T]{m: map[weak.Pointer[T]]runtime.Cleanup{}}
+	s.Add(p...)
+	return s
+}
+
+// ----- methods that modify the receiver -----
+
+// Add adds one or more pointers to the given set. Nil pointers are ignored.
+func (s *WeakSet[T]) Add(p ...*T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, x := range p {
+		if x == nil {
+			continue
+		}
+		w := weak.Make(x)
+		if _, ok := s.m[w]; !ok {
+			s.m[w] = runtime.AddCleanup(x, s.forget, w)
+		}
+	}
+}
+
+// forget removes the entry of a collected element.
+func (s *WeakSet[T]) forget(w weak.Pointer[T]) {
+	s.mu.Lock()
+	delete(s.m, w)
+	s.mu.Unlock()
+}
+
+// Remove removes one or more pointers from the given set.
+func (s *WeakSet[T]) Remove(p ...*T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, x := range p {
+		if x == nil {
+			continue
+		}
+		w := weak.Make(x)
+		if c, ok := s.m[w]; ok {
+			c.Stop()
+			delete(s.m, w)
+		}
+	}
+}
+
+// Clear removes all pointers from the given set.
+func (s *WeakSet[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.m {
+		c.Stop()
+	}
+	clear(s.m)
+}
+
+// ----- methods that do not modify the receiver -----
+
+// Len returns the number of elements of the set which were not collected.
+func (s *WeakSet[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for w := range s.m {
+		if w.Value() != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Contains checks if a set contains one or more pointers. The return value
+// is true only if all given pointers are in the set.
+func (s *WeakSet[T]) Contains(p ...*T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, x := range p {
+		if x == nil {
+			return false
+		}
+		if _, ok := s.m[weak.Make(x)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns an iterator to the elements of the set which were not
+// collected, in an undefined order. It iterates over a snapshot, which keeps
+// the elements alive until the iteration is done.
+func (s *WeakSet[T]) All() iter.Seq[*T] {
+	return func(yield func(*T) bool) {
+		for _, x := range s.List() {
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
+
+// List returns the elements of the set which were not collected in a slice,
+// which keeps them alive.
+func (s *WeakSet[T]) List() []*T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := make([]*T, 0, len(s.m))
+	for w := range s.m {
+		if x := w.Value(); x != nil {
+			l = append(l, x)
+		}
+	}
+	return l
+}
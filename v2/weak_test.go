@@ -0,0 +1,59 @@
+// Copyright 2014-2022 by Harald Weidner <hweidner@gmx.net>. All rights reserved.
+// Use of this source code is governed by the MIT license. See the LICENSE file
+// for a full text of the license.
+// SPDX-License-Identifier: MIT
+
+package set
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+type weakObj struct {
+	id   int
+	data [64]byte
+}
+
+func TestWeakSet(t *testing.T) {
+	a, b := &weakObj{id: 1}, &weakObj{id: 2}
+	s := NewWeak(a, b, nil)
+	if !s.Contains(a, b) || s.Contains(&weakObj{id: 1}) || s.Contains(nil) || s.Len() != 2 {
+		t.Errorf("Contains failed: got %v.\n", s.List())
+	}
+	s.Remove(b)
+	if s.Contains(b) || s.Len() != 1 {
+		t.Errorf("Remove failed: got %v.\n", s.List())
+	}
+
+	// collected elements vanish from the set
+	for i := 0; i < 100; i++ {
+		s.Add(&weakObj{id: 100 + i})
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runtime.GC()
+		s.mu.Lock()
+		n := len(s.m)
+		s.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("WeakSet failed: %d entries left after GC.\n", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for x := range s.All() {
+		if x != a {
+			t.Errorf("All failed: got %v.\n", x)
+		}
+	}
+	runtime.KeepAlive(a)
+
+	s.Clear()
+	if s.Len() != 0 || s.Contains(a) {
+		t.Errorf("Clear failed.\n")
+	}
+}